@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/BekzatS8/buhpro/internal/middleware"
 	"github.com/BekzatS8/buhpro/internal/transport/router"
 	"github.com/BekzatS8/buhpro/pkg/config"
 	"github.com/BekzatS8/buhpro/pkg/db"
@@ -23,11 +24,16 @@ func main() {
 	}
 	defer pool.Close()
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
+
+	appCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
 	deps := &router.AppDeps{
 		DB:  pool,
 		Cfg: cfg,
+		Ctx: appCtx,
 	}
 
 	router.InitAndRegister(deps, r)
@@ -53,6 +59,8 @@ func main() {
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
+	// закрываем SSE-потоки и фоновые воркеры до ожидания активных запросов
+	stopWorkers()
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -8,7 +9,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(secret string) gin.HandlerFunc {
+// TokenValidator checks that a parsed token has not been revoked and returns
+// the user's current primary role and full role set.
+type TokenValidator interface {
+	Validate(ctx context.Context, userID string, tokenVersion int) (string, []string, error)
+}
+
+func AuthMiddleware(cfg auth.JWTConfig, v TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		h := c.GetHeader("Authorization")
 		if h == "" {
@@ -21,14 +28,58 @@ func AuthMiddleware(secret string) gin.HandlerFunc {
 			return
 		}
 		tok := parts[1]
-		claims, err := auth.ParseAccessToken(secret, tok)
+		claims, err := auth.ParseAccessToken(cfg, tok)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
+		// статус и роль берём из БД (через кэш), а не из токена
+		role, roles, err := v.Validate(c.Request.Context(), claims.UserID, claims.TokenVersion)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			return
+		}
+
+		if claims.ReadOnly {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "read-only session"})
+				return
+			}
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
 
 		c.Set("user_id", claims.UserID)
-		c.Set("role", claims.Role)
+		c.Set("role", role)
+		c.Set("roles", roles)
+		c.Set("jti", claims.ID)
+		c.Next()
+	}
+}
+
+// Optional runs auth only when the request carries a token: anonymous
+// requests pass without user_id, an invalid token is still rejected
+func Optional(auth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// TokenFromQuery lets clients that cannot set headers (browser EventSource)
+// pass the access token as ?access_token=. Use only on streaming routes; the
+// request log (Logger) masks the parameter.
+func TokenFromQuery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if tok := c.Query("access_token"); tok != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+tok)
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// secretParams — query-параметры, которые не должны попадать в лог запросов
+var secretParams = []string{"access_token"}
+
+// Logger — gin.Logger в стандартном формате, но с замаскированными секретами в query
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{Formatter: func(p gin.LogFormatterParams) string {
+		p.Path = redactQuery(p.Path)
+		var statusColor, methodColor, resetColor string
+		if p.IsOutputColor() {
+			statusColor, methodColor, resetColor = p.StatusCodeColor(), p.MethodColor(), p.ResetColor()
+		}
+		if p.Latency > time.Minute {
+			p.Latency = p.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, p.StatusCode, resetColor,
+			p.Latency, p.ClientIP,
+			methodColor, p.Method, resetColor,
+			p.Path, p.ErrorMessage,
+		)
+	}})
+}
+
+func redactQuery(path string) string {
+	i := strings.IndexByte(path, '?')
+	if i < 0 {
+		return path
+	}
+	q, err := url.ParseQuery(path[i+1:])
+	if err != nil {
+		// не разобрали — не рискуем
+		return path[:i] + "?REDACTED"
+	}
+	changed := false
+	for _, k := range secretParams {
+		if q.Has(k) {
+			q.Set(k, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return path
+	}
+	return path[:i] + "?" + q.Encode()
+}
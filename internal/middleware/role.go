@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole пропускает запрос, если у пользователя есть хотя бы одна из ролей.
+// Должен стоять после AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasAnyRole(c, roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasAnyRole checks the role set put into the context by AuthMiddleware
+func HasAnyRole(c *gin.Context, roles ...string) bool {
+	primary := c.GetString("role")
+	have := c.GetStringSlice("roles")
+	for _, want := range roles {
+		if primary == want {
+			return true
+		}
+		for _, r := range have {
+			if r == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -12,11 +12,14 @@ type Bid struct {
 	CoverText        string          `json:"cover_text" db:"cover_text"`
 	Price            *int64          `json:"price,omitempty" db:"price"`
 	ProposedDeadline *time.Time      `json:"proposed_deadline,omitempty" db:"proposed_deadline"`
-	Attachments      json.RawMessage `json:"attachments,omitempty" db:"attachments"` // jsonb (array/object)
+	Attachments      []string        `json:"attachments,omitempty" db:"attachments"` // jsonb: file ids
 	Status           string          `json:"status" db:"status"`
 	PaidAt           *time.Time      `json:"paid_at,omitempty" db:"paid_at"`
 	VisibleToClient  bool            `json:"visibility_to_client" db:"visibility_to_client"`
+	Priority         int             `json:"priority" db:"priority"` // приоритет тарифа исполнителя
 	Metadata         json.RawMessage `json:"metadata,omitempty" db:"metadata"`
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time       `json:"updated_at" db:"updated_at"`
+	ExecutorRating   *RatingSummary  `json:"executor_rating,omitempty" db:"-"`
+	ExecutorBadge    string          `json:"executor_badge,omitempty" db:"-"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Conversation struct {
+	ID                 string     `json:"id"`
+	OrderID            string     `json:"order_id"`
+	ClientID           string     `json:"client_id"`
+	ExecutorID         string     `json:"executor_id"`
+	BidID              string     `json:"bid_id"`
+	ClientLastReadAt   *time.Time `json:"client_last_read_at,omitempty"`
+	ExecutorLastReadAt *time.Time `json:"executor_last_read_at,omitempty"`
+	LastMessageAt      *time.Time `json:"last_message_at,omitempty"`
+	ArchivedAt         *time.Time `json:"archived_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	Unread             int        `json:"unread"` // для текущего пользователя
+}
+
+type Message struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	SenderID       string    `json:"sender_id"`
+	Body           string    `json:"body"`
+	Attachments    []string  `json:"attachments,omitempty"` // file ids
+	CreatedAt      time.Time `json:"created_at"`
+	Read           bool      `json:"read"` // прочитано второй стороной
+}
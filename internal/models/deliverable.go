@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	DeliverableSubmitted        = "submitted"
+	DeliverableAccepted         = "accepted"
+	DeliverableChangesRequested = "changes_requested"
+)
+
+// Deliverable — результат работы (версия сдачи) по заказу
+type Deliverable struct {
+	ID            string     `json:"id"`
+	OrderID       string     `json:"order_id"`
+	ExecutorID    string     `json:"executor_id"`
+	Version       int        `json:"version"`
+	Summary       string     `json:"summary"`
+	Files         []string   `json:"files"` // file ids
+	Status        string     `json:"status"`
+	ReviewComment *string    `json:"review_comment,omitempty"`
+	ReviewedBy    *string    `json:"reviewed_by,omitempty"`
+	AutoAccepted  bool       `json:"auto_accepted"`
+	SubmittedAt   time.Time  `json:"submitted_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+const (
+	DisputeOpen     = "open"
+	DisputeInReview = "in_review" // назначен арбитр
+	DisputeResolved = "resolved"
+
+	ResolutionRefund  = "refund"  // всё клиенту
+	ResolutionSplit   = "split"   // частично исполнителю, остаток клиенту
+	ResolutionRelease = "release" // всё исполнителю
+)
+
+type Dispute struct {
+	ID             string     `json:"id"`
+	OrderID        string     `json:"order_id"`
+	OpenedBy       string     `json:"opened_by"`
+	ClientID       string     `json:"client_id"`
+	ExecutorID     string     `json:"executor_id"`
+	PriorStatus    string     `json:"prior_status"`
+	Reason         string     `json:"reason"`
+	Evidence       []string   `json:"evidence"` // file ids
+	Status         string     `json:"status"`
+	ArbiterID      *string    `json:"arbiter_id,omitempty"`
+	Resolution     *string    `json:"resolution,omitempty"`
+	ExecutorAmount *int64     `json:"executor_amount,omitempty"`
+	ClientAmount   *int64     `json:"client_amount,omitempty"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	AssignDueAt    time.Time  `json:"assign_due_at"`
+	ResolveDueAt   *time.Time `json:"resolve_due_at,omitempty"`
+	SLABreachedAt  *time.Time `json:"sla_breached_at,omitempty"`
+	OpenedAt       time.Time  `json:"opened_at"`
+	AssignedAt     *time.Time `json:"assigned_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+type DisputeMessage struct {
+	ID          string    `json:"id"`
+	DisputeID   string    `json:"dispute_id"`
+	AuthorID    string    `json:"author_id"`
+	Body        string    `json:"body"`
+	Attachments []string  `json:"attachments"` // file ids
+	CreatedAt   time.Time `json:"created_at"`
+}
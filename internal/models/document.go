@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	DocumentInvoice = "invoice" // счёт на оплату
+	DocumentAct     = "act"     // акт выполненных работ (АВР)
+
+	// IssuerPlatform — нумерация документов, выставляемых самой платформой
+	IssuerPlatform = "platform"
+)
+
+// Requisites — реквизиты стороны документа
+type Requisites struct {
+	Name    string `json:"name"`
+	BIN     string `json:"bin,omitempty"` // БИН/ИИН
+	Address string `json:"address,omitempty"`
+	IBAN    string `json:"iban,omitempty"` // ИИК
+	Bank    string `json:"bank,omitempty"`
+	BIK     string `json:"bik,omitempty"`
+	Kbe     string `json:"kbe,omitempty"`
+}
+
+type DocumentLine struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Unit     string `json:"unit"`
+	Price    int64  `json:"price"`
+	Amount   int64  `json:"amount"`
+}
+
+// Document — сгенерированный PDF (счёт или АВР); файл лежит в хранилище
+// под StorageKey и отдаётся по presigned-ссылке
+type Document struct {
+	ID           string         `json:"id"`
+	Kind         string         `json:"kind"` // invoice | act
+	IssuerKey    string         `json:"-"`
+	Number       string         `json:"number"`
+	IssuerUserID *string        `json:"issuer_user_id,omitempty"`
+	BuyerUserID  string         `json:"buyer_user_id"`
+	Issuer       Requisites     `json:"issuer"`
+	Buyer        Requisites     `json:"buyer"`
+	PaymentID    *string        `json:"payment_id,omitempty"`
+	OrderID      *string        `json:"order_id,omitempty"`
+	Lines        []DocumentLine `json:"lines"`
+	Amount       int64          `json:"amount"`
+	VATRate      int            `json:"vat_rate"`
+	VATAmount    int64          `json:"vat_amount"`
+	Currency     string         `json:"currency"`
+	StorageKey   string         `json:"-"`
+	Size         int64          `json:"size"`
+	IssuedAt     time.Time      `json:"issued_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+	DownloadURL  string         `json:"download_url,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ESFExport — выгрузка электронных счетов-фактур (XML v2.esf) по сборам
+// платформы, оплаченным организациями за период
+type ESFExport struct {
+	ID          string          `json:"id"`
+	PeriodFrom  time.Time       `json:"period_from"`
+	PeriodTo    time.Time       `json:"period_to"`
+	CreatedBy   string          `json:"created_by"`
+	Invoices    int             `json:"invoices"`
+	Total       int64           `json:"total"`
+	StorageKey  string          `json:"-"`
+	Size        int64           `json:"size"`
+	Skipped     []ESFSkipped    `json:"skipped"`
+	Items       []ESFExportItem `json:"items,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	DownloadURL string          `json:"download_url,omitempty"`
+}
+
+type ESFExportItem struct {
+	PaymentID string `json:"payment_id"`
+	Num       string `json:"num"`
+	Amount    int64  `json:"amount"`
+}
+
+// ESFSkipped — платёж, не прошедший проверку по схеме (нет БИН, адреса и т.п.);
+// остаётся невыгруженным и попадёт в следующую выгрузку после исправления
+type ESFSkipped struct {
+	PaymentID string   `json:"payment_id"`
+	Errors    []string `json:"errors"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы событий real-time канала
+const (
+	EventBidNew              = "bid.new"
+	EventBidWon              = "bid.won"
+	EventBidLost             = "bid.lost"
+	EventOrderStatusChanged  = "order.status_changed"
+	EventPaymentSucceeded    = "payment.succeeded"
+	EventChatMessage         = "chat.message"
+	EventFileQuarantined     = "file.quarantined"
+	EventFileRejected        = "file.rejected"
+	EventDisputeOpened       = "dispute.opened"
+	EventDisputeMessage      = "dispute.message"
+	EventDisputeAssigned     = "dispute.assigned"
+	EventDisputeResolved     = "dispute.resolved"
+	EventDisputeSLABreached  = "dispute.sla_breached"
+	EventReviewReceived      = "review.received"
+	EventCertificateReviewed = "certificate.reviewed"
+	EventInvitationReceived  = "invitation.received"
+	EventInvitationAnswered  = "invitation.answered"
+	EventOrdersMatched       = "orders.matched"
+	EventSavedSearchMatch    = "saved_search.match"
+	EventSavedSearchDigest   = "saved_search.digest"
+	EventSubscriptionActive  = "subscription.activated"
+	EventSubscriptionDue     = "subscription.renewal_due"
+	EventSubscriptionExpired = "subscription.expired"
+	EventSessionBooked       = "session.booked"
+	EventSessionRescheduled  = "session.rescheduled"
+	EventSessionCancelled    = "session.cancelled"
+	EventSessionCompleted    = "session.completed"
+)
+
+// Event — событие для конкретного получателя
+type Event struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Программы, которые исполнитель может указать в профиле
+var ExecutorSoftware = map[string]bool{
+	"1c":               true, // 1С:Бухгалтерия
+	"1c_zup":           true, // 1С:Зарплата и управление персоналом
+	"esf":              true, // ИС ЭСФ
+	"taxpayer_cabinet": true, // Кабинет налогоплательщика
+	"sono":             true,
+	"excel":            true,
+}
+
+const (
+	CertificateCAP    = "CAP"
+	CertificateCIPA   = "CIPA"
+	CertificateDipIFR = "DipIFR"
+	CertificateACCA   = "ACCA"
+	CertificateOther  = "other"
+
+	CertificatePending  = "pending"
+	CertificateVerified = "verified"
+	CertificateRejected = "rejected"
+)
+
+const (
+	WorkModeAny     = "any"
+	WorkModeOnline  = "online"  // только удалённо
+	WorkModeOffline = "offline" // только с выездом
+)
+
+var CertificateKinds = map[string]bool{
+	CertificateCAP: true, CertificateCIPA: true, CertificateDipIFR: true, CertificateACCA: true, CertificateOther: true,
+}
+
+type ExecutorProfile struct {
+	UserID          string    `json:"user_id"`
+	Bio             string    `json:"bio"`
+	Specializations []string  `json:"specializations"` // order categories
+	Regions         []string  `json:"regions"`
+	Software        []string  `json:"software"`
+	ExperienceYears int       `json:"experience_years"`
+	PriceFrom       *int64    `json:"price_from,omitempty"` // KZT
+	Available       bool      `json:"available"`            // принимает новые заказы
+	WorkMode        string    `json:"work_mode"`            // any | online | offline
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type Certificate struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Kind         string     `json:"kind"`
+	Title        string     `json:"title,omitempty"`
+	Number       string     `json:"number,omitempty"`
+	IssuedAt     *time.Time `json:"issued_at,omitempty"`
+	FileID       *string    `json:"file_id,omitempty"`
+	Status       string     `json:"status"`
+	RejectReason *string    `json:"reject_reason,omitempty"`
+	VerifiedBy   *string    `json:"verified_by,omitempty"`
+	VerifiedAt   *time.Time `json:"verified_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type PortfolioCase struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category,omitempty"`
+	Year        *int      `json:"year,omitempty"`
+	Files       []string  `json:"files"` // file ids
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ExecutorSummary — строка каталога исполнителей (GET /executors)
+type ExecutorSummary struct {
+	ID              string         `json:"id"`
+	FullName        string         `json:"full_name"`
+	Bio             string         `json:"bio"`
+	Specializations []string       `json:"specializations"`
+	Regions         []string       `json:"regions"`
+	Software        []string       `json:"software"`
+	ExperienceYears int            `json:"experience_years"`
+	PriceFrom       *int64         `json:"price_from,omitempty"`
+	Available       bool           `json:"available"`
+	Certified       []string       `json:"certified"`       // kinds of verified certificates
+	Badge           string         `json:"badge,omitempty"` // тариф подписки
+	Rating          *RatingSummary `json:"rating"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Типы сущностей, к которым привязываются файлы
+const (
+	FileLinkOrder          = "order"
+	FileLinkBid            = "bid"
+	FileLinkMessage        = "message"
+	FileLinkOrganization   = "organization"
+	FileLinkDeliverable    = "deliverable"
+	FileLinkDispute        = "dispute"
+	FileLinkDisputeMessage = "dispute_message"
+	FileLinkCertificate    = "certificate"
+	FileLinkPortfolio      = "portfolio"
+)
+
+// Статусы файла: скачать можно только clean
+const (
+	FileStatusPending     = "pending"   // ждём загрузку по presigned URL
+	FileStatusVerifying   = "verifying" // Complete: копирование в постоянный ключ и проверка
+	FileStatusPendingScan = "pending_scan"
+	FileStatusClean       = "clean"
+	FileStatusQuarantined = "quarantined" // найдено вредоносное содержимое
+	FileStatusRejected    = "rejected"    // содержимое не соответствует mime_type
+)
+
+type File struct {
+	ID         string     `json:"id"`
+	OwnerID    string     `json:"owner_id"`
+	StorageKey string     `json:"-"`
+	Name       string     `json:"name"`
+	Size       int64      `json:"size"`
+	MimeType   string     `json:"mime_type"`
+	Checksum   string     `json:"checksum_sha256"`
+	Status     string     `json:"status"`
+	LinkedType *string    `json:"linked_type,omitempty"`
+	LinkedID   *string    `json:"linked_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UploadedAt *time.Time `json:"uploaded_at,omitempty"`
+	ScannedAt  *time.Time `json:"scanned_at,omitempty"`
+	ScanResult *string    `json:"scan_result,omitempty"`
+
+	ScanAttempts int `json:"-"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	InvitationPending   = "pending"
+	InvitationAccepted  = "accepted"
+	InvitationDeclined  = "declined"
+	InvitationRevoked   = "revoked"
+	InvitationBidPlaced = "bid_placed"
+)
+
+// Invitation — приглашение исполнителя к заказу; по нему ставка дешевле или бесплатна
+type Invitation struct {
+	ID          string     `json:"id"`
+	OrderID     string     `json:"order_id"`
+	ClientID    string     `json:"client_id"`
+	ExecutorID  string     `json:"executor_id"`
+	Message     string     `json:"message,omitempty"`
+	FeeDiscount int        `json:"fee_discount"` // percent off the bid fee
+	Status      string     `json:"status"`
+	BidID       *string    `json:"bid_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	Order       *Order     `json:"order,omitempty"`
+}
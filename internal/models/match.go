@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Коды причин совпадения заказа с профилем исполнителя
+const (
+	MatchSpecialization = "specialization"
+	MatchRegion         = "region"
+	MatchOnline         = "online"
+	MatchHistory        = "history"
+	MatchBudget         = "budget"
+)
+
+// MatchReason — "подходит, потому что…": код, значение и вклад в оценку
+type MatchReason struct {
+	Code   string `json:"code"`
+	Value  string `json:"value,omitempty"`
+	Points int    `json:"points"`
+	Text   string `json:"text"`
+}
+
+// OrderMatch — оценка опубликованного заказа для исполнителя (0–100)
+type OrderMatch struct {
+	OrderID    string        `json:"order_id"`
+	ExecutorID string        `json:"executor_id"`
+	Score      int           `json:"score"`
+	Reasons    []MatchReason `json:"reasons"`
+	CreatedAt  time.Time     `json:"created_at"`
+	NotifiedAt *time.Time    `json:"notified_at,omitempty"`
+	Order      *Order        `json:"order,omitempty"`
+}
+
+// ExecutorHistory — заказы, где исполнитель был выбран: число по категориям и средняя цена
+type ExecutorHistory struct {
+	WonByCategory map[string]int
+	AvgPrice      int64 // 0 — нет данных
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+const (
+	SlotOpen   = "open"
+	SlotBooked = "booked"
+
+	SessionPendingPayment = "pending_payment"
+	SessionScheduled      = "scheduled"
+	SessionCompleted      = "completed"
+	SessionCancelled      = "cancelled"
+
+	// AuthorRole отзыва подопечного о наставнике; наставник пишет с ролью coach
+	ReviewAuthorMentee = "mentee"
+)
+
+type CoachProfile struct {
+	UserID          string    `json:"user_id"`
+	Headline        string    `json:"headline"`
+	Bio             string    `json:"bio"`
+	Topics          []string  `json:"topics"`    // category codes
+	Languages       []string  `json:"languages"` // ru | kk | en
+	ExperienceYears int       `json:"experience_years"`
+	HourlyRate      int64     `json:"hourly_rate"` // KZT
+	Active          bool      `json:"active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CoachSummary — карточка наставника в каталоге
+type CoachSummary struct {
+	ID              string         `json:"id"`
+	FullName        string         `json:"full_name"`
+	Headline        string         `json:"headline"`
+	Topics          []string       `json:"topics"`
+	Languages       []string       `json:"languages"`
+	ExperienceYears int            `json:"experience_years"`
+	HourlyRate      int64          `json:"hourly_rate"`
+	NextSlot        *time.Time     `json:"next_slot,omitempty"` // ближайшее свободное окно
+	Rating          *RatingSummary `json:"rating"`
+}
+
+type CoachSlot struct {
+	ID        string    `json:"id"`
+	CoachID   string    `json:"coach_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Status    string    `json:"status"` // open | booked
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MentoringSession — оплаченная консультация наставника; деньги удерживаются
+// на платеже (held) до окончания сессии
+type MentoringSession struct {
+	ID           string     `json:"id"`
+	CoachID      string     `json:"coach_id"`
+	MenteeID     string     `json:"mentee_id"`
+	SlotID       *string    `json:"slot_id,omitempty"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	Topic        string     `json:"topic"`
+	Price        int64      `json:"price"`
+	Status       string     `json:"status"` // pending_payment | scheduled | completed | cancelled
+	PaymentID    *string    `json:"payment_id,omitempty"`
+	Reschedules  int        `json:"reschedules"`
+	CancelledBy  *string    `json:"cancelled_by,omitempty"`
+	CancelReason *string    `json:"cancel_reason,omitempty"`
+	Refunded     int64      `json:"refunded"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
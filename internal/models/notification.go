@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Notification struct {
+	ID        string          `json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// NotificationPreferences — язык и внешние каналы (email/SMS);
+// DisabledTypes — типы событий, по которым не слать email/SMS.
+type NotificationPreferences struct {
+	UserID        string    `json:"user_id"`
+	Locale        string    `json:"locale"`
+	EmailEnabled  bool      `json:"email_enabled"`
+	SMSEnabled    bool      `json:"sms_enabled"`
+	DisabledTypes []string  `json:"disabled_types"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
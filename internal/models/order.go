@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+const (
+	OrderPublic  = "public"
+	OrderPrivate = "private" // не в ленте, ставки только по приглашению
+)
+
 type Order struct {
 	ID           string                 `json:"id"`
 	OrgID        string                 `json:"org_id,omitempty"`
@@ -13,14 +18,16 @@ type Order struct {
 	Region       string                 `json:"region,omitempty"`
 	ModeOnline   bool                   `json:"mode_online"`
 	Deadline     *time.Time             `json:"deadline,omitempty"`
-	BudgetMin    *int64                 `json:"budget_min,omitempty"`
+	BudgetMin    *int64                 `json:"budget_min,omitempty"` // бюджет — сумма к оплате клиентом, с НДС (разбивка — /orders/:id/quote)
 	BudgetMax    *int64                 `json:"budget_max,omitempty"`
 	Currency     string                 `json:"currency,omitempty"`
 	Status       string                 `json:"status"`
-	Promotion    map[string]interface{} `json:"promotion,omitempty"` // JSONB
-	Attachments  map[string]interface{} `json:"attachments,omitempty"`
+	Visibility   string                 `json:"visibility"`            // public | private
+	Promotion    map[string]interface{} `json:"promotion,omitempty"`   // JSONB
+	Attachments  []string               `json:"attachments,omitempty"` // file ids
 	ChosenBidID  *string                `json:"chosen_bid_id,omitempty"`
 	CreatedAt    time.Time              `json:"created_at"`
 	PublishedAt  *time.Time             `json:"published_at,omitempty"`
+	CompletedAt  *time.Time             `json:"completed_at,omitempty"`
 	UpdatedAt    time.Time              `json:"updated_at"`
 }
@@ -10,7 +10,11 @@ type Payment struct {
 	RelatedID         *string                `json:"related_id,omitempty"`
 	Provider          string                 `json:"provider"`
 	ProviderPaymentID *string                `json:"provider_payment_id,omitempty"`
-	Amount            int64                  `json:"amount"`
+	Amount            int64                  `json:"amount"` // с НДС
+	NetAmount         int64                  `json:"net_amount"`
+	VATAmount         int64                  `json:"vat_amount"`
+	VATRate           int                    `json:"vat_rate"` // %; 0 — без НДС
+	PayerVAT          bool                   `json:"payer_vat"`
 	Currency          string                 `json:"currency"`
 	Status            string                 `json:"status"`
 	Items             map[string]interface{} `json:"items,omitempty"`
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+const (
+	ReviewPublished = "published"
+	ReviewHidden    = "hidden" // скрыт модератором
+
+	ReportOpen      = "open"
+	ReportUpheld    = "upheld" // отзыв скрыт
+	ReportDismissed = "dismissed"
+)
+
+// Review — отзыв одной стороны заказа о другой; AuthorRole — роль автора
+// в заказе (client оценивает исполнителя, executor — клиента). Отзывы по
+// консультации несут SessionID вместо OrderID (mentee оценивает наставника, coach — подопечного)
+type Review struct {
+	ID            string     `json:"id"`
+	OrderID       string     `json:"order_id,omitempty"`
+	SessionID     *string    `json:"session_id,omitempty"`
+	AuthorID      string     `json:"author_id"`
+	TargetID      string     `json:"target_id"`
+	AuthorRole    string     `json:"author_role"`
+	Rating        int        `json:"rating"`
+	Quality       int        `json:"quality"`
+	Timeliness    int        `json:"timeliness"`
+	Communication int        `json:"communication"`
+	Text          string     `json:"text"`
+	Status        string     `json:"status"`
+	EditableUntil time.Time  `json:"editable_until"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// RatingSummary — агрегат опубликованных отзывов о пользователе
+type RatingSummary struct {
+	Average       float64 `json:"average"`
+	Count         int     `json:"count"`
+	Quality       float64 `json:"quality"`
+	Timeliness    float64 `json:"timeliness"`
+	Communication float64 `json:"communication"`
+}
+
+type ReviewReport struct {
+	ID         string     `json:"id"`
+	ReviewID   string     `json:"review_id"`
+	ReporterID string     `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	ResolvedBy *string    `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Review     *Review    `json:"review,omitempty"`
+}
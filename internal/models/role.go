@@ -0,0 +1,40 @@
+package models
+
+// Роли пользователей (users.role / users.roles)
+const (
+	RoleClient   = "client"
+	RoleExecutor = "executor"
+	RoleCoach    = "coach"
+	RoleAdmin    = "admin"
+)
+
+// AllRoles — полный список допустимых ролей
+var AllRoles = []string{RoleClient, RoleExecutor, RoleCoach, RoleAdmin}
+
+// SelfServiceRoles — роли, которые пользователь может выбрать сам при регистрации
+var SelfServiceRoles = []string{RoleClient, RoleExecutor}
+
+func ValidRole(role string) bool {
+	return containsRole(AllRoles, role)
+}
+
+func IsSelfServiceRole(role string) bool {
+	return containsRole(SelfServiceRoles, role)
+}
+
+// HasRole checks the user's full role set (falls back to the primary role)
+func (u *User) HasRole(role string) bool {
+	if u.Role == role {
+		return true
+	}
+	return containsRole(u.Roles, role)
+}
+
+func containsRole(list []string, role string) bool {
+	for _, r := range list {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
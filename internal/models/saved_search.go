@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+const (
+	AlertChannelInApp = "in_app"
+	AlertChannelEmail = "email"
+
+	AlertInstant = "instant"
+	AlertDaily   = "daily"
+)
+
+// SavedSearchFilters — ключи фильтров OrderRepo.List, допустимые в сохранённом поиске
+var SavedSearchFilters = map[string]bool{"category": true, "region": true, "min_budget": true, "max_budget": true}
+
+// SavedSearch — подписка на новые заказы по фильтру
+type SavedSearch struct {
+	ID           string            `json:"id"`
+	UserID       string            `json:"user_id"`
+	Name         string            `json:"name"`
+	Filters      map[string]string `json:"filters"`
+	Channel      string            `json:"channel"`   // in_app | email
+	Frequency    string            `json:"frequency"` // instant | daily
+	LastDigestAt *time.Time        `json:"last_digest_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// StatsRange — период и группировка для аналитики
+type StatsRange struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Group string    `json:"group"` // day|week|month
+}
+
+type OrdersPerPeriod struct {
+	Period    time.Time `json:"period"`
+	Created   int       `json:"created"`
+	Published int       `json:"published"`
+	Completed int       `json:"completed"`
+}
+
+type FunnelStats struct {
+	Published        int     `json:"published"`
+	ExecutorSelected int     `json:"executor_selected"`
+	Conversion       float64 `json:"conversion"` // executor_selected / published
+}
+
+type BidStats struct {
+	Orders                  int      `json:"orders"`
+	MedianBidCount          float64  `json:"median_bid_count"`
+	MedianTimeToFirstBidSec *float64 `json:"median_time_to_first_bid_sec,omitempty"`
+}
+
+type RevenueRow struct {
+	Period      time.Time `json:"period"`
+	RelatedType string    `json:"related_type"`
+	Currency    string    `json:"currency"`
+	Amount      int64     `json:"amount"`
+	Payments    int       `json:"payments"`
+}
+
+type ActiveExecutorsRow struct {
+	Key       string `json:"key"` // region or category
+	Executors int    `json:"executors"`
+}
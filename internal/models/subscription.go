@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+const (
+	SubscriptionPending = "pending" // первая оплата ещё не прошла
+	SubscriptionActive  = "active"
+	SubscriptionGrace   = "grace" // период закончился, продление ждёт оплаты
+	SubscriptionExpired = "expired"
+
+	SubscriptionPayNew     = "new"
+	SubscriptionPayRenewal = "renewal"
+	SubscriptionPayUpgrade = "upgrade"
+)
+
+type SubscriptionPlan struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Price      int64  `json:"price"`       // KZT в месяц
+	BidQuota   int    `json:"bid_quota"`   // ставок в месяц без оплаты
+	OverageFee int64  `json:"overage_fee"` // KZT за ставку сверх квоты
+	Badge      string `json:"badge,omitempty"`
+	Priority   int    `json:"priority"`
+	Active     bool   `json:"active"`
+	Sort       int    `json:"sort"`
+}
+
+// Subscription — текущая подписка исполнителя
+type Subscription struct {
+	UserID           string            `json:"user_id"`
+	PlanCode         string            `json:"plan_code"`
+	Plan             *SubscriptionPlan `json:"plan,omitempty"`
+	Status           string            `json:"status"` // pending | active | grace | expired
+	PeriodStart      *time.Time        `json:"period_start,omitempty"`
+	PeriodEnd        *time.Time        `json:"period_end,omitempty"`
+	GraceUntil       *time.Time        `json:"grace_until,omitempty"`
+	BidsUsed         int               `json:"bids_used"`
+	AutoRenew        bool              `json:"auto_renew"`
+	NextPlanCode     *string           `json:"next_plan_code,omitempty"`
+	PendingPaymentID *string           `json:"pending_payment_id,omitempty"`
+	PendingKind      *string           `json:"pending_kind,omitempty"` // new | renewal | upgrade
+	PendingPlanCode  *string           `json:"pending_plan_code,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// Current — условия тарифа действуют (active или grace)
+func (s *Subscription) Current() bool {
+	return s.Status == SubscriptionActive || s.Status == SubscriptionGrace
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// TaxRate — ставка налога (tax_rates), действует с EffectiveFrom до следующей
+type TaxRate struct {
+	Code          string    `json:"code"`
+	Rate          int       `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	Note          string    `json:"note,omitempty"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TaxBreakdown — разбивка суммы по НДС. НДС начисляется, только если эмитент
+// (продавец) зарегистрирован по НДС; плательщику-плательщику НДС он идёт к зачёту.
+type TaxBreakdown struct {
+	Net        int64  `json:"net"`
+	VAT        int64  `json:"vat"`
+	Gross      int64  `json:"gross"`
+	Rate       int    `json:"rate"`
+	IssuerVAT  bool   `json:"issuer_vat"`
+	PayerVAT   bool   `json:"payer_vat"`
+	Deductible int64  `json:"deductible"` // НДС к зачёту у плательщика
+	PayerCost  int64  `json:"payer_cost"` // расход плательщика за вычетом зачёта
+	On         string `json:"on"`         // дата, на которую взята ставка (YYYY-MM-DD)
+}
+
+// OrderQuote — бюджет заказа и цена выбранной ставки с НДС. Бюджет — сумма,
+// которую платит клиент (с НДС, если исполнитель его плательщик).
+type OrderQuote struct {
+	OrderID   string        `json:"order_id"`
+	Currency  string        `json:"currency"`
+	PayerVAT  bool          `json:"payer_vat"`
+	BudgetMin *BudgetQuote  `json:"budget_min,omitempty"`
+	BudgetMax *BudgetQuote  `json:"budget_max,omitempty"`
+	ChosenBid *TaxBreakdown `json:"chosen_bid,omitempty"`
+}
+
+// BudgetQuote — одна сумма бюджета для исполнителя с НДС и без
+type BudgetQuote struct {
+	VATExecutor   TaxBreakdown `json:"vat_executor"`
+	NoVATExecutor TaxBreakdown `json:"no_vat_executor"`
+}
+
+// Receipt — квитанция об оплате с разбивкой по НДС
+type Receipt struct {
+	PaymentID string       `json:"payment_id"`
+	Status    string       `json:"status"`
+	Subject   string       `json:"subject"`
+	Issuer    Requisites   `json:"issuer"`
+	Payer     Requisites   `json:"payer"`
+	Currency  string       `json:"currency"`
+	Tax       TaxBreakdown `json:"tax"`
+	PaidAt    time.Time    `json:"paid_at"`
+}
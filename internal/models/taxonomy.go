@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Category — категория заказа (parent_code пустой) или подкатегория
+type Category struct {
+	Code       string      `json:"code"`
+	ParentCode *string     `json:"parent_code,omitempty"`
+	NameRu     string      `json:"name_ru"`
+	NameKk     string      `json:"name_kk"`
+	NameEn     string      `json:"name_en"`
+	Aliases    []string    `json:"aliases"`
+	Sort       int         `json:"sort"`
+	Active     bool        `json:"active"`
+	Children   []*Category `json:"children,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Region — регион по КАТО; вложенность любой глубины
+type Region struct {
+	KATO       string    `json:"kato"`
+	ParentKATO *string   `json:"parent_kato,omitempty"`
+	NameRu     string    `json:"name_ru"`
+	NameKk     string    `json:"name_kk"`
+	NameEn     string    `json:"name_en"`
+	Aliases    []string  `json:"aliases"`
+	Sort       int       `json:"sort"`
+	Active     bool      `json:"active"`
+	Children   []*Region `json:"children,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Taxonomy — деревья категорий и регионов (GET /taxonomy)
+type Taxonomy struct {
+	Categories []*Category `json:"categories"`
+	Regions    []*Region   `json:"regions"`
+}
+
+// TaxonomyUnmapped — значение, не сопоставленное справочнику при переносе
+type TaxonomyUnmapped struct {
+	ID         int64     `json:"id"`
+	ObjectType string    `json:"object_type"` // order | executor_profile | saved_search
+	ObjectID   string    `json:"object_id"`
+	Field      string    `json:"field"`
+	Value      string    `json:"value"`
+	CreatedAt  time.Time `json:"created_at"`
+}
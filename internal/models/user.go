@@ -3,16 +3,21 @@ package models
 import "time"
 
 type User struct {
-	ID           string                 `json:"id"`
-	Email        string                 `json:"email"`
-	Phone        string                 `json:"phone"`
-	FullName     string                 `json:"full_name"`
-	Role         string                 `json:"role"`
-	Status       string                 `json:"status"`
-	PasswordHash string                 `json:"-"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
-	CreatedAt    time.Time              `json:"created_at"`
-	UpdatedAt    time.Time              `json:"updated_at"`
+	ID               string                 `json:"id"`
+	Email            string                 `json:"email"`
+	Phone            string                 `json:"phone"`
+	FullName         string                 `json:"full_name"`
+	Role             string                 `json:"role"` // primary role
+	Roles            []string               `json:"roles"`
+	Status           string                 `json:"status"`
+	PasswordHash     string                 `json:"-"`
+	TokenVersion     int                    `json:"-"`
+	PhoneVerifiedAt  *time.Time             `json:"phone_verified_at,omitempty"`
+	FailedLoginCount int                    `json:"-"`
+	LockedUntil      *time.Time             `json:"locked_until,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
 }
 type RefreshToken struct {
 	ID        string    `json:"id"`
@@ -21,3 +26,34 @@ type RefreshToken struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type OTPCode struct {
+	ID         string     `json:"id"`
+	Phone      string     `json:"phone"`
+	CodeHash   string     `json:"-"`
+	Attempts   int        `json:"attempts"`
+	RequestIP  string     `json:"request_ip,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// UserIdentity — внешний логин (OIDC provider + subject), привязанный к пользователю
+type UserIdentity struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+type OIDCState struct {
+	State        string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	LinkUserID   *string
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,78 @@
+// Package realtime — доставка событий подключённым клиентам (SSE).
+// События пишутся в таблицу events и рассылаются через Postgres NOTIFY,
+// поэтому каждый инстанс API получает все события и отдаёт их своим подписчикам.
+package realtime
+
+import (
+	"sync"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+)
+
+const subscriberBuffer = 64
+
+// Hub — in-process pub/sub по user_id
+type Hub struct {
+	mu     sync.RWMutex
+	subs   map[string]map[chan *models.Event]struct{}
+	closed bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[chan *models.Event]struct{})}
+}
+
+// Subscribe returns a channel of events for the user and an unsubscribe func.
+// The channel is closed on unsubscribe or when the hub shuts down.
+func (h *Hub) Subscribe(userID string) (<-chan *models.Event, func()) {
+	ch := make(chan *models.Event, subscriberBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[chan *models.Event]struct{})
+	}
+	h.subs[userID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[userID][ch]; !ok {
+			return
+		}
+		delete(h.subs[userID], ch)
+		if len(h.subs[userID]) == 0 {
+			delete(h.subs, userID)
+		}
+		close(ch)
+	}
+}
+
+// Dispatch delivers the event to local subscribers. A slow subscriber drops
+// the event; it can catch up with Last-Event-ID on reconnect.
+func (h *Hub) Dispatch(e *models.Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[e.UserID] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Close disconnects all subscribers (graceful shutdown)
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, set := range h.subs {
+		for ch := range set {
+			close(ch)
+		}
+	}
+	h.subs = make(map[string]map[chan *models.Event]struct{})
+	h.closed = true
+}
@@ -0,0 +1,90 @@
+package realtime
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	retention     = 7 * 24 * time.Hour
+	cleanupPeriod = time.Hour
+)
+
+// Listener держит отдельное соединение с LISTEN и передаёт события в Hub
+type Listener struct {
+	db     *pgxpool.Pool
+	events repository.EventRepo
+	hub    *Hub
+}
+
+func NewListener(db *pgxpool.Pool, er repository.EventRepo, hub *Hub) *Listener {
+	return &Listener{db: db, events: er, hub: hub}
+}
+
+// Run blocks until ctx is cancelled, reconnecting with backoff on errors
+func (l *Listener) Run(ctx context.Context) {
+	go l.cleanup(ctx)
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if err := l.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("realtime: listener error: %v (retry in %s)", err, backoff)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = time.Second
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := l.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "LISTEN "+repository.EventsChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		id, err := strconv.ParseInt(n.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		e, err := l.events.GetByID(ctx, id)
+		if err != nil {
+			log.Printf("realtime: load event %d: %v", id, err)
+			continue
+		}
+		l.hub.Dispatch(e)
+	}
+}
+
+func (l *Listener) cleanup(ctx context.Context) {
+	t := time.NewTicker(cleanupPeriod)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := l.events.DeleteOlderThan(ctx, time.Now().Add(-retention)); err != nil {
+				log.Printf("realtime: cleanup: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
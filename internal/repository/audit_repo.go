@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepo writes to audit_logs. actorID may be empty for system events.
+type AuditRepo interface {
+	Add(ctx context.Context, actorID, action, objectType, objectID string, payload map[string]interface{}) error
+}
+
+type pgAuditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepo(db *pgxpool.Pool) AuditRepo { return &pgAuditRepo{db: db} }
+
+func (r *pgAuditRepo) Add(ctx context.Context, actorID, action, objectType, objectID string, payload map[string]interface{}) error {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	q := `INSERT INTO audit_logs (actor_id, action, object_type, object_id, payload) VALUES (NULLIF($1,'')::uuid,$2,$3,NULLIF($4,'')::uuid,$5)`
+	_, err := conn(ctx, r.db).Exec(ctx, q, actorID, action, objectType, objectID, payload)
+	return err
+}
@@ -14,6 +14,7 @@ type BidRepo interface {
 	ListByOrder(ctx context.Context, orderID string) ([]*models.Bid, error)
 	Delete(ctx context.Context, id string) error
 	MarkPaid(ctx context.Context, id string, paidAt time.Time) error
+	Hide(ctx context.Context, id string) error
 }
 
 type pgBidRepo struct {
@@ -23,18 +24,18 @@ type pgBidRepo struct {
 func NewBidRepo(db *pgxpool.Pool) BidRepo { return &pgBidRepo{db: db} }
 
 func (r *pgBidRepo) Create(ctx context.Context, b *models.Bid) error {
-	q := `INSERT INTO bids (id, order_id, executor_id, cover_text, price, proposed_deadline, attachments, status, metadata)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING created_at, updated_at`
-	return r.db.QueryRow(ctx, q,
-		b.ID, b.OrderID, b.ExecutorID, b.CoverText, b.Price, b.ProposedDeadline, b.Attachments, b.Status, b.Metadata,
+	q := `INSERT INTO bids (id, order_id, executor_id, cover_text, price, proposed_deadline, attachments, status, metadata, priority)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING created_at, updated_at`
+	return conn(ctx, r.db).QueryRow(ctx, q,
+		b.ID, b.OrderID, b.ExecutorID, b.CoverText, b.Price, b.ProposedDeadline, b.Attachments, b.Status, b.Metadata, b.Priority,
 	).Scan(&b.CreatedAt, &b.UpdatedAt)
 }
 
 func (r *pgBidRepo) GetByID(ctx context.Context, id string) (*models.Bid, error) {
 	b := &models.Bid{}
-	q := `SELECT id,order_id,executor_id,cover_text,price,proposed_deadline,attachments,status,paid_at,visibility_to_client,metadata,created_at,updated_at FROM bids WHERE id=$1`
-	if err := r.db.QueryRow(ctx, q, id).Scan(
-		&b.ID, &b.OrderID, &b.ExecutorID, &b.CoverText, &b.Price, &b.ProposedDeadline, &b.Attachments, &b.Status, &b.PaidAt, &b.VisibleToClient, &b.Metadata, &b.CreatedAt, &b.UpdatedAt,
+	q := `SELECT id,order_id,executor_id,cover_text,price,proposed_deadline,attachments,status,paid_at,visibility_to_client,metadata,created_at,updated_at,priority FROM bids WHERE id=$1 AND hidden_at IS NULL`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, id).Scan(
+		&b.ID, &b.OrderID, &b.ExecutorID, &b.CoverText, &b.Price, &b.ProposedDeadline, &b.Attachments, &b.Status, &b.PaidAt, &b.VisibleToClient, &b.Metadata, &b.CreatedAt, &b.UpdatedAt, &b.Priority,
 	); err != nil {
 		return nil, err
 	}
@@ -42,7 +43,8 @@ func (r *pgBidRepo) GetByID(ctx context.Context, id string) (*models.Bid, error)
 }
 
 func (r *pgBidRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Bid, error) {
-	rows, err := r.db.Query(ctx, `SELECT id,order_id,executor_id,cover_text,price,proposed_deadline,attachments,status,paid_at,visibility_to_client,metadata,created_at,updated_at FROM bids WHERE order_id=$1`, orderID)
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id,order_id,executor_id,cover_text,price,proposed_deadline,attachments,status,paid_at,visibility_to_client,metadata,created_at,updated_at,priority FROM bids WHERE order_id=$1 AND hidden_at IS NULL
+		ORDER BY priority DESC, created_at`, orderID)
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +52,7 @@ func (r *pgBidRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.
 	var out []*models.Bid
 	for rows.Next() {
 		b := &models.Bid{}
-		if err := rows.Scan(&b.ID, &b.OrderID, &b.ExecutorID, &b.CoverText, &b.Price, &b.ProposedDeadline, &b.Attachments, &b.Status, &b.PaidAt, &b.VisibleToClient, &b.Metadata, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		if err := rows.Scan(&b.ID, &b.OrderID, &b.ExecutorID, &b.CoverText, &b.Price, &b.ProposedDeadline, &b.Attachments, &b.Status, &b.PaidAt, &b.VisibleToClient, &b.Metadata, &b.CreatedAt, &b.UpdatedAt, &b.Priority); err != nil {
 			return nil, err
 		}
 		out = append(out, b)
@@ -59,11 +61,17 @@ func (r *pgBidRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.
 }
 
 func (r *pgBidRepo) Delete(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM bids WHERE id=$1 AND status IN ('created','pending_payment')`, id)
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM bids WHERE id=$1 AND status IN ('created','pending_payment')`, id)
 	return err
 }
 
 func (r *pgBidRepo) MarkPaid(ctx context.Context, id string, paidAt time.Time) error {
-	_, err := r.db.Exec(ctx, `UPDATE bids SET status='paid', paid_at=$1, visibility_to_client=true, updated_at=now() WHERE id=$2`, paidAt, id)
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE bids SET status='paid', paid_at=$1, visibility_to_client=true, updated_at=now() WHERE id=$2`, paidAt, id)
+	return err
+}
+
+// Hide — модерация: ставка скрывается от клиента
+func (r *pgBidRepo) Hide(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE bids SET hidden_at=now(), visibility_to_client=false, updated_at=now() WHERE id=$1`, id)
 	return err
 }
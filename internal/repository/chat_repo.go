@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ChatRepo interface {
+	// GetOrCreate returns the conversation for (order, executor), creating it if needed
+	GetOrCreate(ctx context.Context, c *models.Conversation) (*models.Conversation, error)
+	GetByID(ctx context.Context, id string) (*models.Conversation, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.Conversation, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Conversation, error)
+	Archive(ctx context.Context, id string) error
+	ArchiveByOrder(ctx context.Context, orderID string) error
+	MarkRead(ctx context.Context, id string, asClient bool, at time.Time) error
+
+	AddMessage(ctx context.Context, m *models.Message) error
+	ListMessages(ctx context.Context, conversationID string, before *time.Time, limit int) ([]*models.Message, error)
+	GetMessage(ctx context.Context, id string) (*models.Message, error)
+}
+
+type pgChatRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewChatRepo(db *pgxpool.Pool) ChatRepo { return &pgChatRepo{db: db} }
+
+const conversationColumns = `c.id, c.order_id, c.client_id, c.executor_id, c.bid_id, c.client_last_read_at, c.executor_last_read_at,
+	c.last_message_at, c.archived_at, c.created_at`
+
+func scanConversation(row pgx.Row, extra ...interface{}) (*models.Conversation, error) {
+	c := &models.Conversation{}
+	dest := []interface{}{&c.ID, &c.OrderID, &c.ClientID, &c.ExecutorID, &c.BidID, &c.ClientLastReadAt, &c.ExecutorLastReadAt,
+		&c.LastMessageAt, &c.ArchivedAt, &c.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *pgChatRepo) GetOrCreate(ctx context.Context, c *models.Conversation) (*models.Conversation, error) {
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO conversations (id, order_id, client_id, executor_id, bid_id)
+		VALUES ($1,$2,$3,$4,$5) ON CONFLICT (order_id, executor_id) DO NOTHING`,
+		c.ID, c.OrderID, c.ClientID, c.ExecutorID, c.BidID)
+	if err != nil {
+		return nil, err
+	}
+	return scanConversation(conn(ctx, r.db).QueryRow(ctx, `SELECT `+conversationColumns+` FROM conversations c WHERE c.order_id=$1 AND c.executor_id=$2`, c.OrderID, c.ExecutorID))
+}
+
+func (r *pgChatRepo) GetByID(ctx context.Context, id string) (*models.Conversation, error) {
+	return scanConversation(conn(ctx, r.db).QueryRow(ctx, `SELECT `+conversationColumns+` FROM conversations c WHERE c.id=$1`, id))
+}
+
+// ListByUser — диалоги пользователя (как клиента или исполнителя) с числом непрочитанных
+func (r *pgChatRepo) ListByUser(ctx context.Context, userID string) ([]*models.Conversation, error) {
+	q := `SELECT ` + conversationColumns + `,
+		(SELECT count(*) FROM messages m WHERE m.conversation_id = c.id AND m.sender_id <> $1
+			AND m.created_at > COALESCE(CASE WHEN c.client_id = $1 THEN c.client_last_read_at ELSE c.executor_last_read_at END, '-infinity'))::int
+		FROM conversations c WHERE c.client_id=$1 OR c.executor_id=$1
+		ORDER BY c.last_message_at DESC NULLS LAST, c.created_at DESC`
+	rows, err := conn(ctx, r.db).Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Conversation
+	for rows.Next() {
+		var unread int
+		c, err := scanConversation(rows, &unread)
+		if err != nil {
+			return nil, err
+		}
+		c.Unread = unread
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgChatRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Conversation, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+conversationColumns+` FROM conversations c WHERE c.order_id=$1 ORDER BY c.created_at`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Conversation
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgChatRepo) Archive(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE conversations SET archived_at=now() WHERE id=$1 AND archived_at IS NULL`, id)
+	return err
+}
+
+func (r *pgChatRepo) ArchiveByOrder(ctx context.Context, orderID string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE conversations SET archived_at=now() WHERE order_id=$1 AND archived_at IS NULL`, orderID)
+	return err
+}
+
+func (r *pgChatRepo) MarkRead(ctx context.Context, id string, asClient bool, at time.Time) error {
+	col := "executor_last_read_at"
+	if asClient {
+		col = "client_last_read_at"
+	}
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE conversations SET `+col+` = GREATEST(COALESCE(`+col+`, '-infinity'), $1) WHERE id=$2`, at, id)
+	return err
+}
+
+func (r *pgChatRepo) AddMessage(ctx context.Context, m *models.Message) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.QueryRow(ctx, `INSERT INTO messages (id, conversation_id, sender_id, body, attachments)
+		VALUES ($1,$2,$3,$4,$5) RETURNING created_at`,
+		m.ID, m.ConversationID, m.SenderID, m.Body, m.Attachments).Scan(&m.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE conversations SET last_message_at=$1 WHERE id=$2`, m.CreatedAt, m.ConversationID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListMessages — новые сверху; before — курсор для пагинации назад
+func (r *pgChatRepo) ListMessages(ctx context.Context, conversationID string, before *time.Time, limit int) ([]*models.Message, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, conversation_id, sender_id, body, attachments, created_at FROM messages
+		WHERE conversation_id=$1 AND ($2::timestamptz IS NULL OR created_at < $2)
+		ORDER BY created_at DESC LIMIT $3`, conversationID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Message
+	for rows.Next() {
+		m := &models.Message{}
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.Attachments, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgChatRepo) GetMessage(ctx context.Context, id string) (*models.Message, error) {
+	m := &models.Message{}
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT id, conversation_id, sender_id, body, attachments, created_at FROM messages WHERE id=$1`, id).Scan(
+		&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.Attachments, &m.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
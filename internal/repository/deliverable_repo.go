@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DeliverableRepo interface {
+	// Create assigns the next version number for the order
+	Create(ctx context.Context, d *models.Deliverable) error
+	GetByID(ctx context.Context, id string) (*models.Deliverable, error)
+	GetLatest(ctx context.Context, orderID string) (*models.Deliverable, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Deliverable, error)
+	// Review moves a submitted version to accepted/changes_requested;
+	// pgx.ErrNoRows if it was already reviewed
+	Review(ctx context.Context, id, status, comment string, reviewerID *string, auto bool) error
+	ListDueForAutoAccept(ctx context.Context, submittedBefore time.Time, limit int) ([]*models.Deliverable, error)
+}
+
+type pgDeliverableRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDeliverableRepo(db *pgxpool.Pool) DeliverableRepo { return &pgDeliverableRepo{db: db} }
+
+const deliverableColumns = `id, order_id, executor_id, version, summary, files, status, review_comment, reviewed_by, auto_accepted, submitted_at, reviewed_at`
+
+func scanDeliverable(row pgx.Row) (*models.Deliverable, error) {
+	d := &models.Deliverable{}
+	if err := row.Scan(&d.ID, &d.OrderID, &d.ExecutorID, &d.Version, &d.Summary, &d.Files, &d.Status, &d.ReviewComment, &d.ReviewedBy,
+		&d.AutoAccepted, &d.SubmittedAt, &d.ReviewedAt); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *pgDeliverableRepo) Create(ctx context.Context, d *models.Deliverable) error {
+	q := `INSERT INTO deliverables (id, order_id, executor_id, version, summary, files)
+	SELECT $1, $2, $3, COALESCE(MAX(version), 0) + 1, $4, $5 FROM deliverables WHERE order_id=$2
+	RETURNING version, status, submitted_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, d.ID, d.OrderID, d.ExecutorID, d.Summary, d.Files).Scan(&d.Version, &d.Status, &d.SubmittedAt)
+}
+
+func (r *pgDeliverableRepo) GetByID(ctx context.Context, id string) (*models.Deliverable, error) {
+	return scanDeliverable(conn(ctx, r.db).QueryRow(ctx, `SELECT `+deliverableColumns+` FROM deliverables WHERE id=$1`, id))
+}
+
+func (r *pgDeliverableRepo) GetLatest(ctx context.Context, orderID string) (*models.Deliverable, error) {
+	return scanDeliverable(conn(ctx, r.db).QueryRow(ctx, `SELECT `+deliverableColumns+` FROM deliverables WHERE order_id=$1 ORDER BY version DESC LIMIT 1`, orderID))
+}
+
+func (r *pgDeliverableRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Deliverable, error) {
+	return r.list(ctx, `SELECT `+deliverableColumns+` FROM deliverables WHERE order_id=$1 ORDER BY version`, orderID)
+}
+
+func (r *pgDeliverableRepo) Review(ctx context.Context, id, status, comment string, reviewerID *string, auto bool) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE deliverables SET status=$2, review_comment=NULLIF($3,''), reviewed_by=$4, auto_accepted=$5, reviewed_at=now()
+		WHERE id=$1 AND status='submitted'`, id, status, comment, reviewerID, auto)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgDeliverableRepo) ListDueForAutoAccept(ctx context.Context, submittedBefore time.Time, limit int) ([]*models.Deliverable, error) {
+	// сдача заказа, ушедшего из client_review (спор, отмена), автоматически не принимается
+	return r.list(ctx, `SELECT `+deliverableColumns+` FROM deliverables WHERE status='submitted' AND submitted_at < $1
+		AND EXISTS (SELECT 1 FROM orders o WHERE o.id = deliverables.order_id AND o.status = 'client_review')
+		ORDER BY submitted_at LIMIT $2`, submittedBefore, limit)
+}
+
+func (r *pgDeliverableRepo) list(ctx context.Context, q string, args ...interface{}) ([]*models.Deliverable, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Deliverable
+	for rows.Next() {
+		d, err := scanDeliverable(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
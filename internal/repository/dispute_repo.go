@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DisputeRepo interface {
+	Create(ctx context.Context, d *models.Dispute) error
+	GetByID(ctx context.Context, id string) (*models.Dispute, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Dispute, error)
+	// List — для админки. filters: status, arbiter_id, overdue ("true")
+	List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Dispute, int, error)
+	Assign(ctx context.Context, id, arbiterID string, resolveDue time.Time) error
+	// Resolve returns pgx.ErrNoRows if the dispute is already resolved
+	Resolve(ctx context.Context, id, resolution string, executorAmount, clientAmount int64, note string) error
+	// MarkSLABreached flags overdue active disputes once and returns them
+	MarkSLABreached(ctx context.Context, now time.Time) ([]*models.Dispute, error)
+
+	AddMessage(ctx context.Context, m *models.DisputeMessage) error
+	ListMessages(ctx context.Context, disputeID string) ([]*models.DisputeMessage, error)
+	GetMessage(ctx context.Context, id string) (*models.DisputeMessage, error)
+}
+
+type pgDisputeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDisputeRepo(db *pgxpool.Pool) DisputeRepo { return &pgDisputeRepo{db: db} }
+
+const disputeColumns = `id, order_id, opened_by, client_id, executor_id, prior_status, reason, evidence, status, arbiter_id, resolution,
+	executor_amount, client_amount, resolution_note, assign_due_at, resolve_due_at, sla_breached_at, opened_at, assigned_at, resolved_at`
+
+// overdueCond — активный спор вышел за SLA назначения или решения
+const disputeOverdueCond = `status <> 'resolved' AND ((status = 'open' AND assign_due_at < $%d) OR (status = 'in_review' AND resolve_due_at < $%d))`
+
+func scanDispute(row pgx.Row) (*models.Dispute, error) {
+	d := &models.Dispute{}
+	if err := row.Scan(&d.ID, &d.OrderID, &d.OpenedBy, &d.ClientID, &d.ExecutorID, &d.PriorStatus, &d.Reason, &d.Evidence, &d.Status,
+		&d.ArbiterID, &d.Resolution, &d.ExecutorAmount, &d.ClientAmount, &d.ResolutionNote, &d.AssignDueAt, &d.ResolveDueAt,
+		&d.SLABreachedAt, &d.OpenedAt, &d.AssignedAt, &d.ResolvedAt); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *pgDisputeRepo) Create(ctx context.Context, d *models.Dispute) error {
+	q := `INSERT INTO disputes (id, order_id, opened_by, client_id, executor_id, prior_status, reason, evidence, assign_due_at)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING status, opened_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, d.ID, d.OrderID, d.OpenedBy, d.ClientID, d.ExecutorID, d.PriorStatus, d.Reason, d.Evidence, d.AssignDueAt).
+		Scan(&d.Status, &d.OpenedAt)
+}
+
+func (r *pgDisputeRepo) GetByID(ctx context.Context, id string) (*models.Dispute, error) {
+	return scanDispute(conn(ctx, r.db).QueryRow(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE id=$1`, id))
+}
+
+func (r *pgDisputeRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Dispute, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+disputeColumns+` FROM disputes WHERE order_id=$1 ORDER BY opened_at DESC`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return collectDisputes(rows)
+}
+
+func (r *pgDisputeRepo) List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Dispute, int, error) {
+	var where []string
+	var args []interface{}
+	i := 1
+	if v := filters["status"]; v != "" {
+		where = append(where, fmt.Sprintf("status = $%d", i))
+		args = append(args, v)
+		i++
+	}
+	if v := filters["arbiter_id"]; v != "" {
+		where = append(where, fmt.Sprintf("arbiter_id = $%d", i))
+		args = append(args, v)
+		i++
+	}
+	if filters["overdue"] == "true" {
+		where = append(where, fmt.Sprintf(disputeOverdueCond, i, i))
+		args = append(args, time.Now())
+		i++
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT count(*) FROM disputes"+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	q := fmt.Sprintf(`SELECT `+disputeColumns+` FROM disputes`+cond+` ORDER BY opened_at LIMIT $%d OFFSET $%d`, i, i+1)
+	args = append(args, perPage, (page-1)*perPage)
+	rows, err := conn(ctx, r.db).Query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectDisputes(rows)
+	return list, total, err
+}
+
+func (r *pgDisputeRepo) Assign(ctx context.Context, id, arbiterID string, resolveDue time.Time) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE disputes SET arbiter_id=$2, status='in_review', assigned_at=now(), resolve_due_at=$3
+		WHERE id=$1 AND status <> 'resolved'`, id, arbiterID, resolveDue)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgDisputeRepo) Resolve(ctx context.Context, id, resolution string, executorAmount, clientAmount int64, note string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE disputes SET status='resolved', resolution=$2, executor_amount=$3, client_amount=$4,
+		resolution_note=NULLIF($5,''), resolved_at=now() WHERE id=$1 AND status <> 'resolved'`, id, resolution, executorAmount, clientAmount, note)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgDisputeRepo) MarkSLABreached(ctx context.Context, now time.Time) ([]*models.Dispute, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `UPDATE disputes SET sla_breached_at=$1
+		WHERE sla_breached_at IS NULL AND `+fmt.Sprintf(disputeOverdueCond, 1, 1)+` RETURNING `+disputeColumns, now)
+	if err != nil {
+		return nil, err
+	}
+	return collectDisputes(rows)
+}
+
+func collectDisputes(rows pgx.Rows) ([]*models.Dispute, error) {
+	defer rows.Close()
+	var out []*models.Dispute
+	for rows.Next() {
+		d, err := scanDispute(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgDisputeRepo) AddMessage(ctx context.Context, m *models.DisputeMessage) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO dispute_messages (id, dispute_id, author_id, body, attachments) VALUES ($1,$2,$3,$4,$5) RETURNING created_at`,
+		m.ID, m.DisputeID, m.AuthorID, m.Body, m.Attachments).Scan(&m.CreatedAt)
+}
+
+func (r *pgDisputeRepo) ListMessages(ctx context.Context, disputeID string) ([]*models.DisputeMessage, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, dispute_id, author_id, body, attachments, created_at FROM dispute_messages
+		WHERE dispute_id=$1 ORDER BY created_at`, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.DisputeMessage
+	for rows.Next() {
+		m := &models.DisputeMessage{}
+		if err := rows.Scan(&m.ID, &m.DisputeID, &m.AuthorID, &m.Body, &m.Attachments, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgDisputeRepo) GetMessage(ctx context.Context, id string) (*models.DisputeMessage, error) {
+	m := &models.DisputeMessage{}
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT id, dispute_id, author_id, body, attachments, created_at FROM dispute_messages WHERE id=$1`, id).Scan(
+		&m.ID, &m.DisputeID, &m.AuthorID, &m.Body, &m.Attachments, &m.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
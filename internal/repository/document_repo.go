@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DocumentRepo — счета и АВР, а также реквизиты организаций для них
+type DocumentRepo interface {
+	// Issue assigns the next number of the issuer (YYYY-NNNNN, по году IssuedAt)
+	// and inserts the document; store is called with the numbered document
+	// before commit, so a failed upload leaves neither a row nor a gap.
+	Issue(ctx context.Context, d *models.Document, store func(*models.Document) error) error
+	GetByID(ctx context.Context, id string) (*models.Document, error)
+	InvoiceByPayment(ctx context.Context, paymentID string) (*models.Document, error)
+	ActByOrder(ctx context.Context, orderID string) (*models.Document, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Document, error)
+	// Organization returns requisites of the organization and whether it is a VAT payer
+	Organization(ctx context.Context, orgID string) (*models.Requisites, bool, error)
+	// UserOrganization — организация пользователя (сначала проверенные, затем новые)
+	UserOrganization(ctx context.Context, userID string) (*models.Requisites, bool, error)
+	// PaidOut — сколько исполнитель получил по заказу из эскроу (0 — не было удержания)
+	PaidOut(ctx context.Context, orderID, executorID string) (int64, error)
+}
+
+// ErrDuplicateDocument — документ на этот платёж/заказ уже выставлен
+var ErrDuplicateDocument = errors.New("document already issued")
+
+type pgDocumentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDocumentRepo(db *pgxpool.Pool) DocumentRepo { return &pgDocumentRepo{db: db} }
+
+const documentColumns = `id, kind, issuer_key, number, issuer_user_id, buyer_user_id, issuer, buyer, payment_id, order_id,
+	lines, amount, vat_rate, vat_amount, currency, storage_key, size, issued_at, created_at`
+
+func scanDocument(row pgx.Row) (*models.Document, error) {
+	d := &models.Document{}
+	if err := row.Scan(&d.ID, &d.Kind, &d.IssuerKey, &d.Number, &d.IssuerUserID, &d.BuyerUserID, &d.Issuer, &d.Buyer, &d.PaymentID, &d.OrderID,
+		&d.Lines, &d.Amount, &d.VATRate, &d.VATAmount, &d.Currency, &d.StorageKey, &d.Size, &d.IssuedAt, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *pgDocumentRepo) Issue(ctx context.Context, d *models.Document, store func(*models.Document) error) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// документ уже есть — не тратим номер (уникальные индексы страхуют от гонки)
+	var exists bool
+	q := `SELECT EXISTS (SELECT 1 FROM documents WHERE kind=$1 AND (($1='invoice' AND payment_id=$2) OR ($1='act' AND order_id=$3)))`
+	if err := tx.QueryRow(ctx, q, d.Kind, d.PaymentID, d.OrderID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrDuplicateDocument
+	}
+
+	year := d.IssuedAt.Year()
+	var no int
+	if err := tx.QueryRow(ctx, `INSERT INTO document_sequences (issuer_key, kind, year, last_no) VALUES ($1,$2,$3,1)
+		ON CONFLICT (issuer_key, kind, year) DO UPDATE SET last_no = document_sequences.last_no + 1
+		RETURNING last_no`, d.IssuerKey, d.Kind, year).Scan(&no); err != nil {
+		return err
+	}
+	d.Number = fmt.Sprintf("%d-%05d", year, no)
+	if err := store(d); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, `INSERT INTO documents (id, kind, issuer_key, number, issuer_user_id, buyer_user_id, issuer, buyer,
+		payment_id, order_id, lines, amount, vat_rate, vat_amount, currency, storage_key, size, issued_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18) RETURNING created_at`,
+		d.ID, d.Kind, d.IssuerKey, d.Number, d.IssuerUserID, d.BuyerUserID, d.Issuer, d.Buyer,
+		d.PaymentID, d.OrderID, d.Lines, d.Amount, d.VATRate, d.VATAmount, d.Currency, d.StorageKey, d.Size, d.IssuedAt,
+	).Scan(&d.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgDocumentRepo) GetByID(ctx context.Context, id string) (*models.Document, error) {
+	return scanDocument(conn(ctx, r.db).QueryRow(ctx, `SELECT `+documentColumns+` FROM documents WHERE id=$1`, id))
+}
+
+func (r *pgDocumentRepo) InvoiceByPayment(ctx context.Context, paymentID string) (*models.Document, error) {
+	return scanDocument(conn(ctx, r.db).QueryRow(ctx, `SELECT `+documentColumns+` FROM documents WHERE kind='invoice' AND payment_id=$1`, paymentID))
+}
+
+func (r *pgDocumentRepo) ActByOrder(ctx context.Context, orderID string) (*models.Document, error) {
+	return scanDocument(conn(ctx, r.db).QueryRow(ctx, `SELECT `+documentColumns+` FROM documents WHERE kind='act' AND order_id=$1`, orderID))
+}
+
+func (r *pgDocumentRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Document, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+documentColumns+` FROM documents WHERE order_id=$1 ORDER BY issued_at`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Document
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// банковские реквизиты организации хранятся в metadata (iban, bank, bik, kbe, vat_payer)
+const requisitesColumns = `name, COALESCE(bin_iin,''), COALESCE(legal_address,''), COALESCE(metadata->>'iban',''),
+	COALESCE(metadata->>'bank',''), COALESCE(metadata->>'bik',''), COALESCE(metadata->>'kbe',''), COALESCE((metadata->>'vat_payer')::boolean, false)`
+
+func scanRequisites(row pgx.Row) (*models.Requisites, bool, error) {
+	q := &models.Requisites{}
+	var vat bool
+	if err := row.Scan(&q.Name, &q.BIN, &q.Address, &q.IBAN, &q.Bank, &q.BIK, &q.Kbe, &vat); err != nil {
+		return nil, false, err
+	}
+	return q, vat, nil
+}
+
+func (r *pgDocumentRepo) Organization(ctx context.Context, orgID string) (*models.Requisites, bool, error) {
+	return scanRequisites(conn(ctx, r.db).QueryRow(ctx, `SELECT `+requisitesColumns+` FROM organizations WHERE id=$1`, orgID))
+}
+
+func (r *pgDocumentRepo) UserOrganization(ctx context.Context, userID string) (*models.Requisites, bool, error) {
+	return scanRequisites(conn(ctx, r.db).QueryRow(ctx, `SELECT `+requisitesColumns+` FROM organizations
+		WHERE owner_user_id=$1 AND status <> 'rejected'
+		ORDER BY (status = 'verified') DESC, created_at DESC LIMIT 1`, userID))
+}
+
+func (r *pgDocumentRepo) PaidOut(ctx context.Context, orderID, executorID string) (int64, error) {
+	var sum int64
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(SUM(amount),0)::bigint FROM wallet_transactions
+		WHERE user_id=$1 AND type='credit' AND meta->>'order_id'=$2`, executorID, orderID).Scan(&sum)
+	return sum, err
+}
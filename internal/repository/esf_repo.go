@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ESFRepo — выгрузки ЭСФ и учёт выгруженных платежей
+type ESFRepo interface {
+	// Candidates — успешные платежи с organization_id за сборы платформы
+	// (bid_fee, order_publish) с from по to включительно, ещё не выгруженные
+	Candidates(ctx context.Context, from, to time.Time) ([]*models.Payment, error)
+	// Create numbers the items (sequence platform/esf), calls store with the
+	// numbered export and records it with its payments in one transaction;
+	// ErrAlreadyExported if a payment was exported concurrently.
+	Create(ctx context.Context, e *models.ESFExport, store func(*models.ESFExport) error) error
+	GetByID(ctx context.Context, id string) (*models.ESFExport, error)
+	List(ctx context.Context, page, perPage int) ([]*models.ESFExport, int, error)
+}
+
+var ErrAlreadyExported = errors.New("payment already exported to ESF")
+
+type pgESFRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewESFRepo(db *pgxpool.Pool) ESFRepo { return &pgESFRepo{db: db} }
+
+func (r *pgESFRepo) Candidates(ctx context.Context, from, to time.Time) ([]*models.Payment, error) {
+	// только платежи, оплаченные от имени организации: платёж физлица не
+	// переписывается на организацию, которой он не выставлялся
+	q := `SELECT id,user_id,organization_id,related_type,related_id,provider,provider_payment_id,amount,net_amount,vat_amount,vat_rate,payer_vat,currency,status,items,idempotency_key,expires_at,webhook_meta,created_at,updated_at
+		FROM payments p
+		WHERE status='success' AND related_type IN ('bid_fee','order_publish') AND organization_id IS NOT NULL
+		  AND created_at >= $1 AND created_at < $2
+		  AND NOT EXISTS (SELECT 1 FROM esf_exported_payments x WHERE x.payment_id = p.id)
+		ORDER BY created_at`
+	rows, err := conn(ctx, r.db).Query(ctx, q, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Payment
+	for rows.Next() {
+		p := &models.Payment{}
+		if err := rows.Scan(
+			&p.ID, &p.UserID, &p.OrganizationID, &p.RelatedType, &p.RelatedID, &p.Provider, &p.ProviderPaymentID, &p.Amount, &p.NetAmount, &p.VATAmount, &p.VATRate, &p.PayerVAT, &p.Currency, &p.Status, &p.Items, &p.IdempotencyKey, &p.ExpiresAt, &p.WebhookMeta, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgESFRepo) Create(ctx context.Context, e *models.ESFExport, store func(*models.ESFExport) error) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	// выгрузки идут по одной: параллельная не возьмёт те же платежи
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('esf_exports'))`); err != nil {
+		return err
+	}
+	ids := make([]string, len(e.Items))
+	for i, it := range e.Items {
+		ids[i] = it.PaymentID
+	}
+	var exported int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM esf_exported_payments WHERE payment_id = ANY($1::uuid[])`, ids).Scan(&exported); err != nil {
+		return err
+	}
+	if exported > 0 {
+		return ErrAlreadyExported
+	}
+
+	// номера счетов-фактур — своя последовательность платформы (document_sequences)
+	year := e.CreatedAt.Year()
+	var last int
+	if err := tx.QueryRow(ctx, `INSERT INTO document_sequences (issuer_key, kind, year, last_no) VALUES ($1,'esf',$2,$3)
+		ON CONFLICT (issuer_key, kind, year) DO UPDATE SET last_no = document_sequences.last_no + $3
+		RETURNING last_no`, models.IssuerPlatform, year, len(e.Items)).Scan(&last); err != nil {
+		return err
+	}
+	for i := range e.Items {
+		e.Items[i].Num = fmt.Sprintf("ESF-%d-%05d", year, last-len(e.Items)+i+1)
+	}
+	if err := store(e); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO esf_exports (id, period_from, period_to, created_by, invoices, total, storage_key, size, skipped, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		e.ID, e.PeriodFrom, e.PeriodTo, e.CreatedBy, e.Invoices, e.Total, e.StorageKey, e.Size, e.Skipped, e.CreatedAt); err != nil {
+		return err
+	}
+	for _, it := range e.Items {
+		if _, err := tx.Exec(ctx, `INSERT INTO esf_exported_payments (payment_id, export_id, num, amount) VALUES ($1,$2,$3,$4)`,
+			it.PaymentID, e.ID, it.Num, it.Amount); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+const esfExportColumns = `id, period_from, period_to, created_by, invoices, total, storage_key, size, skipped, created_at`
+
+func scanESFExport(row pgx.Row) (*models.ESFExport, error) {
+	e := &models.ESFExport{}
+	if err := row.Scan(&e.ID, &e.PeriodFrom, &e.PeriodTo, &e.CreatedBy, &e.Invoices, &e.Total, &e.StorageKey, &e.Size, &e.Skipped, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *pgESFRepo) GetByID(ctx context.Context, id string) (*models.ESFExport, error) {
+	e, err := scanESFExport(conn(ctx, r.db).QueryRow(ctx, `SELECT `+esfExportColumns+` FROM esf_exports WHERE id=$1`, id))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT payment_id, num, amount FROM esf_exported_payments WHERE export_id=$1 ORDER BY num`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it models.ESFExportItem
+		if err := rows.Scan(&it.PaymentID, &it.Num, &it.Amount); err != nil {
+			return nil, err
+		}
+		e.Items = append(e.Items, it)
+	}
+	return e, rows.Err()
+}
+
+func (r *pgESFRepo) List(ctx context.Context, page, perPage int) ([]*models.ESFExport, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM esf_exports`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+esfExportColumns+` FROM esf_exports ORDER BY created_at DESC LIMIT $1 OFFSET $2`, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.ESFExport
+	for rows.Next() {
+		e, err := scanESFExport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, e)
+	}
+	return out, total, rows.Err()
+}
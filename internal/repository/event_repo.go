@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// EventsChannel — канал LISTEN/NOTIFY; payload уведомления — id события
+const EventsChannel = "buhpro_events"
+
+type EventRepo interface {
+	// Create stores the event and notifies all API instances in the same transaction
+	Create(ctx context.Context, e *models.Event) error
+	GetByID(ctx context.Context, id int64) (*models.Event, error)
+	ListForUserSince(ctx context.Context, userID string, afterID int64, since time.Time, limit int) ([]*models.Event, error)
+	DeleteOlderThan(ctx context.Context, t time.Time) error
+}
+
+type pgEventRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewEventRepo(db *pgxpool.Pool) EventRepo { return &pgEventRepo{db: db} }
+
+func (r *pgEventRepo) Create(ctx context.Context, e *models.Event) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	// id берётся из sequence под блокировкой получателя, которая держится до commit:
+	// события одного пользователя коммитятся в порядке id, и курсор
+	// Last-Event-ID (id > last) не пропускает «обогнанные» транзакции
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('events'), hashtext($1::text))`, e.UserID); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(ctx, `INSERT INTO events (user_id, type, payload) VALUES ($1,$2,$3) RETURNING id, created_at`,
+		e.UserID, e.Type, e.Payload).Scan(&e.ID, &e.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, EventsChannel, strconv.FormatInt(e.ID, 10)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgEventRepo) GetByID(ctx context.Context, id int64) (*models.Event, error) {
+	e := &models.Event{}
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT id, user_id, type, payload, created_at FROM events WHERE id=$1`, id).Scan(
+		&e.ID, &e.UserID, &e.Type, &e.Payload, &e.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (r *pgEventRepo) ListForUserSince(ctx context.Context, userID string, afterID int64, since time.Time, limit int) ([]*models.Event, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, user_id, type, payload, created_at FROM events
+		WHERE user_id=$1 AND id > $2 AND created_at >= $3 ORDER BY id LIMIT $4`, userID, afterID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Event
+	for rows.Next() {
+		e := &models.Event{}
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgEventRepo) DeleteOlderThan(ctx context.Context, t time.Time) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM events WHERE created_at < $1`, t)
+	return err
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExecutorRepo — профиль исполнителя: описание, сертификаты, портфолио
+type ExecutorRepo interface {
+	GetProfile(ctx context.Context, userID string) (*models.ExecutorProfile, error)
+	SaveProfile(ctx context.Context, p *models.ExecutorProfile) error
+	Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.ExecutorSummary, int, error)
+
+	CreateCertificate(ctx context.Context, c *models.Certificate) error
+	GetCertificate(ctx context.Context, id string) (*models.Certificate, error)
+	ListCertificates(ctx context.Context, userID string, onlyVerified bool) ([]*models.Certificate, error)
+	ListCertificatesByStatus(ctx context.Context, status string, page, perPage int) ([]*models.Certificate, int, error)
+	SetCertificateStatus(ctx context.Context, id, status, adminID string, rejectReason *string) error
+	DeleteCertificate(ctx context.Context, id string) error
+
+	CreatePortfolio(ctx context.Context, p *models.PortfolioCase) error
+	GetPortfolio(ctx context.Context, id string) (*models.PortfolioCase, error)
+	ListPortfolio(ctx context.Context, userID string) ([]*models.PortfolioCase, error)
+	UpdatePortfolio(ctx context.Context, p *models.PortfolioCase) error
+	DeletePortfolio(ctx context.Context, id string) error
+}
+
+type pgExecutorRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExecutorRepo(db *pgxpool.Pool) ExecutorRepo { return &pgExecutorRepo{db: db} }
+
+func (r *pgExecutorRepo) GetProfile(ctx context.Context, userID string) (*models.ExecutorProfile, error) {
+	p := &models.ExecutorProfile{}
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT user_id, bio, specializations, regions, software, experience_years, price_from, available, work_mode,
+		created_at, updated_at FROM executor_profiles WHERE user_id=$1`, userID).Scan(
+		&p.UserID, &p.Bio, &p.Specializations, &p.Regions, &p.Software, &p.ExperienceYears, &p.PriceFrom, &p.Available, &p.WorkMode,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgExecutorRepo) SaveProfile(ctx context.Context, p *models.ExecutorProfile) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO executor_profiles (user_id, bio, specializations, regions, software, experience_years, price_from, available, work_mode)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		ON CONFLICT (user_id) DO UPDATE SET bio=EXCLUDED.bio, specializations=EXCLUDED.specializations, regions=EXCLUDED.regions,
+			software=EXCLUDED.software, experience_years=EXCLUDED.experience_years, price_from=EXCLUDED.price_from,
+			available=EXCLUDED.available, work_mode=EXCLUDED.work_mode, updated_at=now()
+		RETURNING created_at, updated_at`,
+		p.UserID, p.Bio, p.Specializations, p.Regions, p.Software, p.ExperienceYears, p.PriceFrom, p.Available, p.WorkMode,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+// Search — каталог: активные исполнители с заполненным профилем.
+// filters: q (имя/описание), specialization, region, software, min_rating, price_min, price_max,
+// certified (true или вид сертификата), available (true|false). Некорректные числа игнорируются.
+func (r *pgExecutorRepo) Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.ExecutorSummary, int, error) {
+	where := []string{"u.status = 'active'", "'executor' = ANY(u.roles)"}
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if v := filters["q"]; v != "" {
+		add("(u.full_name ILIKE $? OR p.bio ILIKE $?)", "%"+v+"%")
+	}
+	if v := filters["specialization"]; v != "" {
+		add("$? = ANY(p.specializations)", v)
+	}
+	if v := filters["region"]; v != "" {
+		add("$? = ANY(p.regions)", v)
+	}
+	if v := filters["software"]; v != "" {
+		add("$? = ANY(p.software)", v)
+	}
+	if v, err := strconv.ParseFloat(filters["min_rating"], 64); err == nil {
+		add("COALESCE(rt.average, 0) >= $?", v)
+	}
+	// price_from — нижняя граница ставки исполнителя; без цены подходит под любой диапазон
+	if v, err := strconv.ParseInt(filters["price_min"], 10, 64); err == nil {
+		add("(p.price_from IS NULL OR p.price_from >= $?)", v)
+	}
+	if v, err := strconv.ParseInt(filters["price_max"], 10, 64); err == nil {
+		add("(p.price_from IS NULL OR p.price_from <= $?)", v)
+	}
+	switch v := filters["certified"]; v {
+	case "":
+	case "true":
+		where = append(where, "EXISTS (SELECT 1 FROM executor_certificates c WHERE c.user_id = u.id AND c.status = 'verified')")
+	default:
+		add("EXISTS (SELECT 1 FROM executor_certificates c WHERE c.user_id = u.id AND c.status = 'verified' AND c.kind = $?)", v)
+	}
+	if v, err := strconv.ParseBool(filters["available"]); err == nil {
+		add("p.available = $?", v)
+	}
+
+	from := ` FROM executor_profiles p JOIN users u ON u.id = p.user_id
+		LEFT JOIN (SELECT target_id, ROUND(AVG(rating)::numeric, 2)::float8 AS average, count(*) AS cnt
+			FROM reviews WHERE author_role = 'client' AND status = 'published' AND published_at IS NOT NULL
+			GROUP BY target_id) rt ON rt.target_id = u.id
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT count(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	q := fmt.Sprintf(`SELECT u.id, COALESCE(u.full_name, ''), p.bio, p.specializations, p.regions, p.software, p.experience_years,
+		p.price_from, p.available,
+		ARRAY(SELECT DISTINCT c.kind FROM executor_certificates c WHERE c.user_id = u.id AND c.status = 'verified' ORDER BY c.kind),
+		COALESCE((SELECT sp.badge FROM executor_subscriptions es JOIN subscription_plans sp ON sp.code = es.plan_code
+			WHERE es.user_id = u.id AND es.status IN ('active','grace')), '')`+from+`
+		ORDER BY COALESCE(rt.average, 0) DESC, COALESCE(rt.cnt, 0) DESC, p.updated_at DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	rows, err := conn(ctx, r.db).Query(ctx, q, append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.ExecutorSummary
+	for rows.Next() {
+		e := &models.ExecutorSummary{}
+		if err := rows.Scan(&e.ID, &e.FullName, &e.Bio, &e.Specializations, &e.Regions, &e.Software, &e.ExperienceYears,
+			&e.PriceFrom, &e.Available, &e.Certified, &e.Badge); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, e)
+	}
+	return out, total, rows.Err()
+}
+
+// --- certificates
+
+const certificateColumns = `id, user_id, kind, title, number, issued_at, file_id, status, reject_reason, verified_by, verified_at, created_at`
+
+func scanCertificate(row pgx.Row) (*models.Certificate, error) {
+	c := &models.Certificate{}
+	if err := row.Scan(&c.ID, &c.UserID, &c.Kind, &c.Title, &c.Number, &c.IssuedAt, &c.FileID, &c.Status, &c.RejectReason,
+		&c.VerifiedBy, &c.VerifiedAt, &c.CreatedAt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func collectCertificates(rows pgx.Rows) ([]*models.Certificate, error) {
+	defer rows.Close()
+	var out []*models.Certificate
+	for rows.Next() {
+		c, err := scanCertificate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgExecutorRepo) CreateCertificate(ctx context.Context, c *models.Certificate) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO executor_certificates (id, user_id, kind, title, number, issued_at, file_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING status, created_at`,
+		c.ID, c.UserID, c.Kind, c.Title, c.Number, c.IssuedAt, c.FileID).Scan(&c.Status, &c.CreatedAt)
+}
+
+func (r *pgExecutorRepo) GetCertificate(ctx context.Context, id string) (*models.Certificate, error) {
+	return scanCertificate(conn(ctx, r.db).QueryRow(ctx, `SELECT `+certificateColumns+` FROM executor_certificates WHERE id=$1`, id))
+}
+
+func (r *pgExecutorRepo) ListCertificates(ctx context.Context, userID string, onlyVerified bool) ([]*models.Certificate, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+certificateColumns+` FROM executor_certificates
+		WHERE user_id=$1 AND (NOT $2 OR status='verified') ORDER BY created_at`, userID, onlyVerified)
+	if err != nil {
+		return nil, err
+	}
+	return collectCertificates(rows)
+}
+
+func (r *pgExecutorRepo) ListCertificatesByStatus(ctx context.Context, status string, page, perPage int) ([]*models.Certificate, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM executor_certificates WHERE ($1='' OR status=$1)`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+certificateColumns+` FROM executor_certificates WHERE ($1='' OR status=$1)
+		ORDER BY created_at LIMIT $2 OFFSET $3`, status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectCertificates(rows)
+	return list, total, err
+}
+
+func (r *pgExecutorRepo) SetCertificateStatus(ctx context.Context, id, status, adminID string, rejectReason *string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE executor_certificates SET status=$2, verified_by=$3, verified_at=now(), reject_reason=$4 WHERE id=$1`,
+		id, status, adminID, rejectReason)
+	return err
+}
+
+func (r *pgExecutorRepo) DeleteCertificate(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM executor_certificates WHERE id=$1`, id)
+	return err
+}
+
+// --- portfolio
+
+const portfolioColumns = `id, user_id, title, description, category, year, files, created_at, updated_at`
+
+func scanPortfolio(row pgx.Row) (*models.PortfolioCase, error) {
+	p := &models.PortfolioCase{}
+	if err := row.Scan(&p.ID, &p.UserID, &p.Title, &p.Description, &p.Category, &p.Year, &p.Files, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgExecutorRepo) CreatePortfolio(ctx context.Context, p *models.PortfolioCase) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO executor_portfolio (id, user_id, title, description, category, year, files)
+		VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING created_at, updated_at`,
+		p.ID, p.UserID, p.Title, p.Description, p.Category, p.Year, p.Files).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *pgExecutorRepo) GetPortfolio(ctx context.Context, id string) (*models.PortfolioCase, error) {
+	return scanPortfolio(conn(ctx, r.db).QueryRow(ctx, `SELECT `+portfolioColumns+` FROM executor_portfolio WHERE id=$1`, id))
+}
+
+func (r *pgExecutorRepo) ListPortfolio(ctx context.Context, userID string) ([]*models.PortfolioCase, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+portfolioColumns+` FROM executor_portfolio WHERE user_id=$1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.PortfolioCase
+	for rows.Next() {
+		p, err := scanPortfolio(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgExecutorRepo) UpdatePortfolio(ctx context.Context, p *models.PortfolioCase) error {
+	return conn(ctx, r.db).QueryRow(ctx, `UPDATE executor_portfolio SET title=$2, description=$3, category=$4, year=$5, files=$6, updated_at=now()
+		WHERE id=$1 RETURNING updated_at`, p.ID, p.Title, p.Description, p.Category, p.Year, p.Files).Scan(&p.UpdatedAt)
+}
+
+func (r *pgExecutorRepo) DeletePortfolio(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM executor_portfolio WHERE id=$1`, id)
+	return err
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrFilesNotAttachable — часть файлов не найдена, чужая, не загружена или уже привязана к другому объекту
+var ErrFilesNotAttachable = errors.New("files not attachable")
+
+type FileRepo interface {
+	Create(ctx context.Context, f *models.File) error
+	GetByID(ctx context.Context, id string) (*models.File, error)
+	// BeginVerify moves a pending file to verifying (Complete копирует и хэширует
+	// содержимое); a verification older than stale may be taken over
+	BeginVerify(ctx context.Context, id string, stale time.Duration) (bool, error)
+	// ResetPending returns a failed verification back to pending
+	ResetPending(ctx context.Context, id string) error
+	// MarkUploaded: verifying -> pending_scan
+	MarkUploaded(ctx context.Context, id string) error
+	// ClaimForScan leases up to limit pending_scan files (safe across instances)
+	ClaimForScan(ctx context.Context, limit int, lease time.Duration) ([]*models.File, error)
+	SetScanResult(ctx context.Context, id, status, result string) error
+	// SetLinks makes ids the exact attachment set of the object: new ids are
+	// linked, previously linked ids not in the list are released.
+	SetLinks(ctx context.Context, ownerID, linkedType, linkedID string, ids []string) error
+	Delete(ctx context.Context, id string) error
+	OrganizationOwner(ctx context.Context, orgID string) (string, error)
+}
+
+type pgFileRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewFileRepo(db *pgxpool.Pool) FileRepo { return &pgFileRepo{db: db} }
+
+const fileColumns = `id, owner_id, storage_key, name, size, mime_type, checksum_sha256, status, linked_type, linked_id, created_at, uploaded_at,
+	scanned_at, scan_result, scan_attempts`
+
+func scanFile(row pgx.Row) (*models.File, error) {
+	f := &models.File{}
+	if err := row.Scan(&f.ID, &f.OwnerID, &f.StorageKey, &f.Name, &f.Size, &f.MimeType, &f.Checksum, &f.Status, &f.LinkedType, &f.LinkedID,
+		&f.CreatedAt, &f.UploadedAt, &f.ScannedAt, &f.ScanResult, &f.ScanAttempts); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (r *pgFileRepo) Create(ctx context.Context, f *models.File) error {
+	q := `INSERT INTO files (id, owner_id, storage_key, name, size, mime_type, checksum_sha256, status, linked_type, linked_id)
+	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING created_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, f.ID, f.OwnerID, f.StorageKey, f.Name, f.Size, f.MimeType, f.Checksum, f.Status, f.LinkedType, f.LinkedID).Scan(&f.CreatedAt)
+}
+
+func (r *pgFileRepo) GetByID(ctx context.Context, id string) (*models.File, error) {
+	return scanFile(conn(ctx, r.db).QueryRow(ctx, `SELECT `+fileColumns+` FROM files WHERE id=$1`, id))
+}
+
+func (r *pgFileRepo) BeginVerify(ctx context.Context, id string, stale time.Duration) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE files SET status='verifying', uploaded_at=now()
+		WHERE id=$1 AND (status='pending' OR (status='verifying' AND uploaded_at < now() - make_interval(secs => $2)))`, id, stale.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgFileRepo) ResetPending(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE files SET status='pending', uploaded_at=NULL WHERE id=$1 AND status='verifying'`, id)
+	return err
+}
+
+func (r *pgFileRepo) MarkUploaded(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE files SET status='pending_scan', uploaded_at=now() WHERE id=$1 AND status='verifying'`, id)
+	return err
+}
+
+func (r *pgFileRepo) ClaimForScan(ctx context.Context, limit int, lease time.Duration) ([]*models.File, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `UPDATE files SET scan_started_at=now(), scan_attempts=scan_attempts+1
+		WHERE id IN (
+			SELECT id FROM files
+			WHERE status='pending_scan' AND (scan_started_at IS NULL OR scan_started_at < now() - make_interval(secs => $2))
+			ORDER BY uploaded_at LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING `+fileColumns, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.File
+	for rows.Next() {
+		f, err := scanFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgFileRepo) SetScanResult(ctx context.Context, id, status, result string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE files SET status=$2, scan_result=NULLIF($3,''), scanned_at=now() WHERE id=$1 AND status='pending_scan'`,
+		id, status, result)
+	return err
+}
+
+func (r *pgFileRepo) SetLinks(ctx context.Context, ownerID, linkedType, linkedID string, ids []string) error {
+	if ids == nil {
+		ids = []string{}
+	}
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE files SET linked_type=NULL, linked_id=NULL
+		WHERE linked_type=$1 AND linked_id=$2 AND NOT (id::text = ANY($3))`, linkedType, linkedID, ids); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		tag, err := tx.Exec(ctx, `UPDATE files SET linked_type=$1, linked_id=$2
+			WHERE id::text = ANY($3) AND owner_id=$4 AND status IN ('pending_scan','clean')
+			  AND (linked_id IS NULL OR (linked_type=$1 AND linked_id=$2))`, linkedType, linkedID, ids, ownerID)
+		if err != nil {
+			return err
+		}
+		if int(tag.RowsAffected()) != len(ids) {
+			return ErrFilesNotAttachable
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *pgFileRepo) Delete(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM files WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgFileRepo) OrganizationOwner(ctx context.Context, orgID string) (string, error) {
+	var owner string
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT owner_user_id FROM organizations WHERE id=$1`, orgID).Scan(&owner)
+	return owner, err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdentityRepo manages linked external identities and pending OIDC states
+type IdentityRepo interface {
+	// Create — ErrDuplicateIdentity, если provider+subject уже привязан
+	Create(ctx context.Context, i *models.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.UserIdentity, error)
+	Delete(ctx context.Context, userID, id string) error
+	TouchLogin(ctx context.Context, id string) error
+
+	SaveState(ctx context.Context, s *models.OIDCState) error
+	// TakeState deletes and returns the state (one-time use); expired states are not returned
+	TakeState(ctx context.Context, state string) (*models.OIDCState, error)
+}
+
+var ErrDuplicateIdentity = errors.New("identity already linked")
+
+type pgIdentityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepo(db *pgxpool.Pool) IdentityRepo { return &pgIdentityRepo{db: db} }
+
+func (r *pgIdentityRepo) Create(ctx context.Context, i *models.UserIdentity) error {
+	q := `INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at)
+	VALUES ($1,$2,$3,$4,$5,now()) ON CONFLICT (provider, subject) DO NOTHING RETURNING created_at, last_login_at`
+	err := conn(ctx, r.db).QueryRow(ctx, q, i.ID, i.UserID, i.Provider, i.Subject, i.Email).Scan(&i.CreatedAt, &i.LastLoginAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDuplicateIdentity
+	}
+	return err
+}
+
+func (r *pgIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	i := &models.UserIdentity{}
+	q := `SELECT id, user_id, provider, subject, COALESCE(email,''), created_at, last_login_at
+		FROM user_identities WHERE provider=$1 AND subject=$2`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, provider, subject).Scan(
+		&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt,
+	); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (r *pgIdentityRepo) ListByUser(ctx context.Context, userID string) ([]*models.UserIdentity, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, user_id, provider, subject, COALESCE(email,''), created_at, last_login_at
+		FROM user_identities WHERE user_id=$1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.UserIdentity
+	for rows.Next() {
+		i := &models.UserIdentity{}
+		if err := rows.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt, &i.LastLoginAt); err != nil {
+			return nil, err
+		}
+		out = append(out, i)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgIdentityRepo) Delete(ctx context.Context, userID, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM user_identities WHERE id=$1 AND user_id=$2`, id, userID)
+	return err
+}
+
+func (r *pgIdentityRepo) TouchLogin(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE user_identities SET last_login_at=now() WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgIdentityRepo) SaveState(ctx context.Context, s *models.OIDCState) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO oidc_states (state, provider, code_verifier, nonce, link_user_id, expires_at)
+		VALUES ($1,$2,$3,$4,$5,$6)`, s.State, s.Provider, s.CodeVerifier, s.Nonce, s.LinkUserID, s.ExpiresAt)
+	return err
+}
+
+func (r *pgIdentityRepo) TakeState(ctx context.Context, state string) (*models.OIDCState, error) {
+	s := &models.OIDCState{}
+	q := `DELETE FROM oidc_states WHERE state=$1 AND expires_at > now()
+		RETURNING state, provider, code_verifier, nonce, link_user_id, expires_at`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, state).Scan(&s.State, &s.Provider, &s.CodeVerifier, &s.Nonce, &s.LinkUserID, &s.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
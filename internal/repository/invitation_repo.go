@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// InvitationRepo — приглашения исполнителей к заказам
+type InvitationRepo interface {
+	// Create returns pgx.ErrNoRows if the executor already has a live invitation
+	// to this order; a revoked one is reissued
+	Create(ctx context.Context, inv *models.Invitation) error
+	GetByID(ctx context.Context, id string) (*models.Invitation, error)
+	// GetOpen — pending/accepted приглашение исполнителя к заказу
+	GetOpen(ctx context.Context, orderID, executorID string) (*models.Invitation, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Invitation, error)
+	ListForExecutor(ctx context.Context, executorID, status string, page, perPage int) ([]*models.Invitation, int, error)
+	// SetStatus changes the status only from one of the given states, otherwise pgx.ErrNoRows
+	SetStatus(ctx context.Context, id, status string, from ...string) error
+	// MarkBidPlaced closes an open (pending/accepted) invitation with the bid, otherwise pgx.ErrNoRows
+	MarkBidPlaced(ctx context.Context, id, bidID string) error
+	// Invited — у исполнителя есть действующее или использованное приглашение к заказу
+	Invited(ctx context.Context, orderID, executorID string) (bool, error)
+}
+
+type pgInvitationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepo(db *pgxpool.Pool) InvitationRepo { return &pgInvitationRepo{db: db} }
+
+const invitationColumns = `id, order_id, client_id, executor_id, message, fee_discount, status, bid_id, created_at, responded_at`
+
+func scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	i := &models.Invitation{}
+	if err := row.Scan(&i.ID, &i.OrderID, &i.ClientID, &i.ExecutorID, &i.Message, &i.FeeDiscount, &i.Status, &i.BidID,
+		&i.CreatedAt, &i.RespondedAt); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func collectInvitations(rows pgx.Rows) ([]*models.Invitation, error) {
+	defer rows.Close()
+	var out []*models.Invitation
+	for rows.Next() {
+		i, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, i)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgInvitationRepo) Create(ctx context.Context, inv *models.Invitation) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO order_invitations (id, order_id, client_id, executor_id, message, fee_discount)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (order_id, executor_id) DO UPDATE SET message=EXCLUDED.message, fee_discount=EXCLUDED.fee_discount,
+			status='pending', bid_id=NULL, created_at=now(), responded_at=NULL
+			WHERE order_invitations.status='revoked'
+		RETURNING id, status, created_at`,
+		inv.ID, inv.OrderID, inv.ClientID, inv.ExecutorID, inv.Message, inv.FeeDiscount,
+	).Scan(&inv.ID, &inv.Status, &inv.CreatedAt)
+}
+
+func (r *pgInvitationRepo) GetByID(ctx context.Context, id string) (*models.Invitation, error) {
+	return scanInvitation(conn(ctx, r.db).QueryRow(ctx, `SELECT `+invitationColumns+` FROM order_invitations WHERE id=$1`, id))
+}
+
+func (r *pgInvitationRepo) GetOpen(ctx context.Context, orderID, executorID string) (*models.Invitation, error) {
+	return scanInvitation(conn(ctx, r.db).QueryRow(ctx, `SELECT `+invitationColumns+` FROM order_invitations
+		WHERE order_id=$1 AND executor_id=$2 AND status IN ('pending','accepted')`, orderID, executorID))
+}
+
+func (r *pgInvitationRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Invitation, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+invitationColumns+` FROM order_invitations WHERE order_id=$1 ORDER BY created_at`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return collectInvitations(rows)
+}
+
+func (r *pgInvitationRepo) ListForExecutor(ctx context.Context, executorID, status string, page, perPage int) ([]*models.Invitation, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM order_invitations WHERE executor_id=$1 AND ($2='' OR status=$2)`,
+		executorID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+invitationColumns+` FROM order_invitations WHERE executor_id=$1 AND ($2='' OR status=$2)
+		ORDER BY created_at DESC LIMIT $3 OFFSET $4`, executorID, status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectInvitations(rows)
+	return list, total, err
+}
+
+func (r *pgInvitationRepo) SetStatus(ctx context.Context, id, status string, from ...string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE order_invitations SET status=$2, responded_at=now() WHERE id=$1 AND status = ANY($3)`,
+		id, status, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgInvitationRepo) MarkBidPlaced(ctx context.Context, id, bidID string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE order_invitations SET status='bid_placed', bid_id=$2, responded_at=COALESCE(responded_at, now())
+		WHERE id=$1 AND status IN ('pending','accepted')`, id, bidID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgInvitationRepo) Invited(ctx context.Context, orderID, executorID string) (bool, error) {
+	var ok bool
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM order_invitations
+		WHERE order_id=$1 AND executor_id=$2 AND status IN ('pending','accepted','bid_placed'))`, orderID, executorID).Scan(&ok)
+	return ok, err
+}
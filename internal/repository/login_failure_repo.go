@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// LoginFailureRepo records failed logins for per-IP throttling
+type LoginFailureRepo interface {
+	Record(ctx context.Context, email, ip string) error
+	CountByIPSince(ctx context.Context, ip string, since time.Time) (int, error)
+}
+
+type pgLoginFailureRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginFailureRepo(db *pgxpool.Pool) LoginFailureRepo { return &pgLoginFailureRepo{db: db} }
+
+func (r *pgLoginFailureRepo) Record(ctx context.Context, email, ip string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO login_failures (email, ip) VALUES ($1,$2)`, email, ip)
+	return err
+}
+
+func (r *pgLoginFailureRepo) CountByIPSince(ctx context.Context, ip string, since time.Time) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM login_failures WHERE ip=$1 AND created_at >= $2`, ip, since).Scan(&n)
+	return n, err
+}
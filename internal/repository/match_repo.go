@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MatchRepo — рекомендации заказов исполнителям
+type MatchRepo interface {
+	// Candidates — доступные исполнители, у которых совпадает категория,
+	// регион или (для online-заказа) формат работы
+	Candidates(ctx context.Context, o *models.Order) ([]*models.ExecutorProfile, error)
+	History(ctx context.Context, executorIDs []string) (map[string]*models.ExecutorHistory, error)
+	// Save upserts the score; notified_at of an existing match is kept
+	Save(ctx context.Context, m *models.OrderMatch) error
+	// Prune drops the executor's matches except for keepOrderIDs
+	Prune(ctx context.Context, executorID string, keepOrderIDs []string) error
+	// ListForExecutor — совпадения по опубликованным заказам, на которые исполнитель ещё не откликался
+	ListForExecutor(ctx context.Context, executorID string, page, perPage int) ([]*models.OrderMatch, int, error)
+	// PendingDigest — ещё не отправленные совпадения по опубликованным заказам, по исполнителю и оценке
+	PendingDigest(ctx context.Context, limit int) ([]*models.OrderMatch, error)
+	MarkNotified(ctx context.Context, executorID string, orderIDs []string) error
+}
+
+type pgMatchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMatchRepo(db *pgxpool.Pool) MatchRepo { return &pgMatchRepo{db: db} }
+
+func (r *pgMatchRepo) Candidates(ctx context.Context, o *models.Order) ([]*models.ExecutorProfile, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT p.user_id, p.specializations, p.regions, p.price_from, p.available, p.work_mode
+		FROM executor_profiles p JOIN users u ON u.id = p.user_id
+		WHERE u.status = 'active' AND 'executor' = ANY(u.roles) AND p.available AND u.id <> $1
+			AND ($2 = ANY(p.specializations) OR $3 = ANY(p.regions) OR ($4 AND p.work_mode <> 'offline'))`,
+		o.ClientUserID, o.Category, o.Region, o.ModeOnline)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.ExecutorProfile
+	for rows.Next() {
+		p := &models.ExecutorProfile{}
+		if err := rows.Scan(&p.UserID, &p.Specializations, &p.Regions, &p.PriceFrom, &p.Available, &p.WorkMode); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgMatchRepo) History(ctx context.Context, executorIDs []string) (map[string]*models.ExecutorHistory, error) {
+	out := map[string]*models.ExecutorHistory{}
+	if len(executorIDs) == 0 {
+		return out, nil
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT b.executor_id, COALESCE(o.category, ''), count(*), COALESCE(SUM(b.price), 0), count(b.price)
+		FROM bids b JOIN orders o ON o.chosen_bid_id = b.id
+		WHERE b.executor_id = ANY($1) AND o.status IN ('executor_selected','in_progress','client_review','completed')
+		GROUP BY 1, 2`, executorIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sums := map[string][2]int64{}
+	for rows.Next() {
+		var id, category string
+		var won int
+		var sum, priced int64
+		if err := rows.Scan(&id, &category, &won, &sum, &priced); err != nil {
+			return nil, err
+		}
+		h := out[id]
+		if h == nil {
+			h = &models.ExecutorHistory{WonByCategory: map[string]int{}}
+			out[id] = h
+		}
+		h.WonByCategory[category] += won
+		acc := sums[id]
+		sums[id] = [2]int64{acc[0] + sum, acc[1] + priced}
+	}
+	for id, acc := range sums {
+		if acc[1] > 0 {
+			out[id].AvgPrice = acc[0] / acc[1]
+		}
+	}
+	return out, rows.Err()
+}
+
+func (r *pgMatchRepo) Save(ctx context.Context, m *models.OrderMatch) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO order_matches (order_id, executor_id, score, reasons) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (order_id, executor_id) DO UPDATE SET score=EXCLUDED.score, reasons=EXCLUDED.reasons
+		RETURNING created_at, notified_at`,
+		m.OrderID, m.ExecutorID, m.Score, m.Reasons).Scan(&m.CreatedAt, &m.NotifiedAt)
+}
+
+func (r *pgMatchRepo) Prune(ctx context.Context, executorID string, keepOrderIDs []string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM order_matches WHERE executor_id=$1 AND NOT (order_id = ANY($2))`, executorID, keepOrderIDs)
+	return err
+}
+
+const activeMatchCond = ` FROM order_matches m JOIN orders o ON o.id = m.order_id
+	WHERE o.status = 'published' AND o.visibility = 'public'
+		AND NOT EXISTS (SELECT 1 FROM bids b WHERE b.order_id = m.order_id AND b.executor_id = m.executor_id)`
+
+func (r *pgMatchRepo) ListForExecutor(ctx context.Context, executorID string, page, perPage int) ([]*models.OrderMatch, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*)`+activeMatchCond+` AND m.executor_id=$1`, executorID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT m.order_id, m.executor_id, m.score, m.reasons, m.created_at, m.notified_at`+activeMatchCond+`
+		AND m.executor_id=$1 ORDER BY m.score DESC, o.published_at DESC LIMIT $2 OFFSET $3`, executorID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectMatches(rows)
+	return list, total, err
+}
+
+func (r *pgMatchRepo) PendingDigest(ctx context.Context, limit int) ([]*models.OrderMatch, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT m.order_id, m.executor_id, m.score, m.reasons, m.created_at, m.notified_at`+activeMatchCond+`
+		AND m.notified_at IS NULL ORDER BY m.executor_id, m.score DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectMatches(rows)
+}
+
+func (r *pgMatchRepo) MarkNotified(ctx context.Context, executorID string, orderIDs []string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE order_matches SET notified_at=now() WHERE executor_id=$1 AND order_id = ANY($2)`, executorID, orderIDs)
+	return err
+}
+
+func collectMatches(rows pgx.Rows) ([]*models.OrderMatch, error) {
+	defer rows.Close()
+	var out []*models.OrderMatch
+	for rows.Next() {
+		m := &models.OrderMatch{}
+		if err := rows.Scan(&m.OrderID, &m.ExecutorID, &m.Score, &m.Reasons, &m.CreatedAt, &m.NotifiedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
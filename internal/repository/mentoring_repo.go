@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MentoringRepo — наставники: профили, свободные окна и консультации
+type MentoringRepo interface {
+	GetProfile(ctx context.Context, userID string) (*models.CoachProfile, error)
+	SaveProfile(ctx context.Context, p *models.CoachProfile) error
+	Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.CoachSummary, int, error)
+
+	// AddSlot returns false if the slot overlaps another slot of the coach
+	AddSlot(ctx context.Context, s *models.CoachSlot) (bool, error)
+	GetSlot(ctx context.Context, id string) (*models.CoachSlot, error)
+	// ListSlots — окна наставника, заканчивающиеся после from
+	ListSlots(ctx context.Context, coachID string, from time.Time, onlyOpen bool) ([]*models.CoachSlot, error)
+	// DeleteSlot removes an open slot; false if it is booked or not the coach's
+	DeleteSlot(ctx context.Context, id, coachID string) (bool, error)
+	// BookSlot takes an open future slot; false if it was taken first
+	BookSlot(ctx context.Context, id string) (bool, error)
+	ReleaseSlot(ctx context.Context, id string) error
+
+	CreateSession(ctx context.Context, s *models.MentoringSession) error
+	GetSession(ctx context.Context, id string) (*models.MentoringSession, error)
+	// UpdateSession saves the session if it is still in fromStatus; pgx.ErrNoRows otherwise
+	UpdateSession(ctx context.Context, s *models.MentoringSession, fromStatus string) error
+	// ListSessions — консультации пользователя; as: coach | mentee | "" (обе стороны)
+	ListSessions(ctx context.Context, userID, as, status string, page, perPage int) ([]*models.MentoringSession, int, error)
+	// Unpaid — записи без оплаты, созданные до before
+	Unpaid(ctx context.Context, before time.Time) ([]*models.MentoringSession, error)
+	// Finished — оплаченные консультации, закончившиеся к now
+	Finished(ctx context.Context, now time.Time) ([]*models.MentoringSession, error)
+}
+
+type pgMentoringRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMentoringRepo(db *pgxpool.Pool) MentoringRepo { return &pgMentoringRepo{db: db} }
+
+func (r *pgMentoringRepo) GetProfile(ctx context.Context, userID string) (*models.CoachProfile, error) {
+	p := &models.CoachProfile{}
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT user_id, headline, bio, topics, languages, experience_years, hourly_rate, active, created_at, updated_at
+		FROM coach_profiles WHERE user_id=$1`, userID).Scan(
+		&p.UserID, &p.Headline, &p.Bio, &p.Topics, &p.Languages, &p.ExperienceYears, &p.HourlyRate, &p.Active, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgMentoringRepo) SaveProfile(ctx context.Context, p *models.CoachProfile) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO coach_profiles (user_id, headline, bio, topics, languages, experience_years, hourly_rate, active)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+		ON CONFLICT (user_id) DO UPDATE SET headline=EXCLUDED.headline, bio=EXCLUDED.bio, topics=EXCLUDED.topics,
+			languages=EXCLUDED.languages, experience_years=EXCLUDED.experience_years, hourly_rate=EXCLUDED.hourly_rate,
+			active=EXCLUDED.active, updated_at=now()
+		RETURNING created_at, updated_at`,
+		p.UserID, p.Headline, p.Bio, p.Topics, p.Languages, p.ExperienceYears, p.HourlyRate, p.Active,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+// Search — каталог активных наставников. filters: q (имя/заголовок/описание),
+// topic, language, rate_max, min_rating, available (true — есть свободное окно)
+func (r *pgMentoringRepo) Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.CoachSummary, int, error) {
+	where := []string{"u.status = 'active'", "'coach' = ANY(u.roles)", "p.active"}
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if v := filters["q"]; v != "" {
+		add("(u.full_name ILIKE $? OR p.headline ILIKE $? OR p.bio ILIKE $?)", "%"+v+"%")
+	}
+	if v := filters["topic"]; v != "" {
+		add("$? = ANY(p.topics)", v)
+	}
+	if v := filters["language"]; v != "" {
+		add("$? = ANY(p.languages)", v)
+	}
+	if v, err := strconv.ParseInt(filters["rate_max"], 10, 64); err == nil {
+		add("p.hourly_rate <= $?", v)
+	}
+	if v, err := strconv.ParseFloat(filters["min_rating"], 64); err == nil {
+		add("COALESCE(rt.average, 0) >= $?", v)
+	}
+	if v, _ := strconv.ParseBool(filters["available"]); v {
+		where = append(where, "ns.starts_at IS NOT NULL")
+	}
+
+	from := ` FROM coach_profiles p JOIN users u ON u.id = p.user_id
+		LEFT JOIN (SELECT target_id, ROUND(AVG(rating)::numeric, 2)::float8 AS average, count(*) AS cnt
+			FROM reviews WHERE author_role = 'mentee' AND status = 'published' AND published_at IS NOT NULL
+			GROUP BY target_id) rt ON rt.target_id = u.id
+		LEFT JOIN LATERAL (SELECT min(s.starts_at) AS starts_at FROM coach_slots s
+			WHERE s.coach_id = u.id AND s.status = 'open' AND s.starts_at > now()) ns ON true
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT count(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	q := fmt.Sprintf(`SELECT u.id, COALESCE(u.full_name, ''), p.headline, p.topics, p.languages, p.experience_years, p.hourly_rate,
+		ns.starts_at`+from+`
+		ORDER BY COALESCE(rt.average, 0) DESC, COALESCE(rt.cnt, 0) DESC, ns.starts_at NULLS LAST LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	rows, err := conn(ctx, r.db).Query(ctx, q, append(args, perPage, (page-1)*perPage)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.CoachSummary
+	for rows.Next() {
+		c := &models.CoachSummary{}
+		if err := rows.Scan(&c.ID, &c.FullName, &c.Headline, &c.Topics, &c.Languages, &c.ExperienceYears, &c.HourlyRate, &c.NextSlot); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, c)
+	}
+	return out, total, rows.Err()
+}
+
+// --- slots
+
+const slotColumns = `id, coach_id, starts_at, ends_at, status, created_at`
+
+func scanSlot(row pgx.Row) (*models.CoachSlot, error) {
+	s := &models.CoachSlot{}
+	if err := row.Scan(&s.ID, &s.CoachID, &s.StartsAt, &s.EndsAt, &s.Status, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *pgMentoringRepo) AddSlot(ctx context.Context, s *models.CoachSlot) (bool, error) {
+	err := conn(ctx, r.db).QueryRow(ctx, `INSERT INTO coach_slots (id, coach_id, starts_at, ends_at)
+		SELECT $1,$2,$3,$4 WHERE NOT EXISTS (
+			SELECT 1 FROM coach_slots WHERE coach_id=$2 AND starts_at < $4 AND ends_at > $3)
+		RETURNING status, created_at`, s.ID, s.CoachID, s.StartsAt, s.EndsAt).Scan(&s.Status, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *pgMentoringRepo) GetSlot(ctx context.Context, id string) (*models.CoachSlot, error) {
+	return scanSlot(conn(ctx, r.db).QueryRow(ctx, `SELECT `+slotColumns+` FROM coach_slots WHERE id=$1`, id))
+}
+
+func (r *pgMentoringRepo) ListSlots(ctx context.Context, coachID string, from time.Time, onlyOpen bool) ([]*models.CoachSlot, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+slotColumns+` FROM coach_slots
+		WHERE coach_id=$1 AND ends_at > $2 AND (NOT $3 OR status='open') ORDER BY starts_at`, coachID, from, onlyOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.CoachSlot
+	for rows.Next() {
+		s, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgMentoringRepo) DeleteSlot(ctx context.Context, id, coachID string) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM coach_slots WHERE id=$1 AND coach_id=$2 AND status='open'`, id, coachID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgMentoringRepo) BookSlot(ctx context.Context, id string) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE coach_slots SET status='booked' WHERE id=$1 AND status='open' AND starts_at > now()`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgMentoringRepo) ReleaseSlot(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE coach_slots SET status='open' WHERE id=$1`, id)
+	return err
+}
+
+// --- sessions
+
+const sessionColumns = `id, coach_id, mentee_id, slot_id, starts_at, ends_at, topic, price, status, payment_id, reschedules,
+	cancelled_by, cancel_reason, refunded, completed_at, created_at, updated_at`
+
+func scanSession(row pgx.Row) (*models.MentoringSession, error) {
+	s := &models.MentoringSession{}
+	if err := row.Scan(&s.ID, &s.CoachID, &s.MenteeID, &s.SlotID, &s.StartsAt, &s.EndsAt, &s.Topic, &s.Price, &s.Status, &s.PaymentID,
+		&s.Reschedules, &s.CancelledBy, &s.CancelReason, &s.Refunded, &s.CompletedAt, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func collectSessions(rows pgx.Rows) ([]*models.MentoringSession, error) {
+	defer rows.Close()
+	var out []*models.MentoringSession
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgMentoringRepo) CreateSession(ctx context.Context, s *models.MentoringSession) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO mentoring_sessions (id, coach_id, mentee_id, slot_id, starts_at, ends_at, topic, price, status, payment_id)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10) RETURNING created_at, updated_at`,
+		s.ID, s.CoachID, s.MenteeID, s.SlotID, s.StartsAt, s.EndsAt, s.Topic, s.Price, s.Status, s.PaymentID,
+	).Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *pgMentoringRepo) GetSession(ctx context.Context, id string) (*models.MentoringSession, error) {
+	return scanSession(conn(ctx, r.db).QueryRow(ctx, `SELECT `+sessionColumns+` FROM mentoring_sessions WHERE id=$1`, id))
+}
+
+func (r *pgMentoringRepo) UpdateSession(ctx context.Context, s *models.MentoringSession, fromStatus string) error {
+	return conn(ctx, r.db).QueryRow(ctx, `UPDATE mentoring_sessions SET slot_id=$3, starts_at=$4, ends_at=$5, status=$6, reschedules=$7,
+			cancelled_by=$8, cancel_reason=$9, refunded=$10, completed_at=$11, updated_at=now()
+		WHERE id=$1 AND status=$2 RETURNING updated_at`,
+		s.ID, fromStatus, s.SlotID, s.StartsAt, s.EndsAt, s.Status, s.Reschedules, s.CancelledBy, s.CancelReason, s.Refunded, s.CompletedAt,
+	).Scan(&s.UpdatedAt)
+}
+
+func (r *pgMentoringRepo) ListSessions(ctx context.Context, userID, as, status string, page, perPage int) ([]*models.MentoringSession, int, error) {
+	cond := ` FROM mentoring_sessions WHERE `
+	switch as {
+	case models.RoleCoach:
+		cond += `coach_id=$1`
+	case models.ReviewAuthorMentee:
+		cond += `mentee_id=$1`
+	default:
+		cond += `(coach_id=$1 OR mentee_id=$1)`
+	}
+	cond += ` AND ($2='' OR status=$2)`
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*)`+cond, userID, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+sessionColumns+cond+` ORDER BY starts_at DESC LIMIT $3 OFFSET $4`,
+		userID, status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectSessions(rows)
+	return list, total, err
+}
+
+func (r *pgMentoringRepo) Unpaid(ctx context.Context, before time.Time) ([]*models.MentoringSession, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+sessionColumns+` FROM mentoring_sessions
+		WHERE status='pending_payment' AND created_at < $1 ORDER BY created_at`, before)
+	if err != nil {
+		return nil, err
+	}
+	return collectSessions(rows)
+}
+
+func (r *pgMentoringRepo) Finished(ctx context.Context, now time.Time) ([]*models.MentoringSession, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+sessionColumns+` FROM mentoring_sessions
+		WHERE status='scheduled' AND ends_at <= $1 ORDER BY ends_at`, now)
+	if err != nil {
+		return nil, err
+	}
+	return collectSessions(rows)
+}
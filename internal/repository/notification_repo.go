@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepo interface {
+	Create(ctx context.Context, n *models.Notification) error
+	List(ctx context.Context, userID string, unreadOnly bool, page, perPage int) ([]*models.Notification, int, error)
+	CountUnread(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, id, userID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	// GetPreferences returns defaults if the user has not saved any
+	GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, p *models.NotificationPreferences) error
+}
+
+type pgNotificationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepo(db *pgxpool.Pool) NotificationRepo { return &pgNotificationRepo{db: db} }
+
+func (r *pgNotificationRepo) Create(ctx context.Context, n *models.Notification) error {
+	q := `INSERT INTO notifications (id, user_id, type, title, body, payload) VALUES ($1,$2,$3,$4,$5,$6) RETURNING created_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, n.ID, n.UserID, n.Type, n.Title, n.Body, n.Payload).Scan(&n.CreatedAt)
+}
+
+func (r *pgNotificationRepo) List(ctx context.Context, userID string, unreadOnly bool, page, perPage int) ([]*models.Notification, int, error) {
+	where := `WHERE user_id=$1`
+	if unreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM notifications `+where, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, user_id, type, title, body, payload, read_at, created_at FROM notifications `+where+
+		` ORDER BY created_at DESC LIMIT $2 OFFSET $3`, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.Notification
+	for rows.Next() {
+		n := &models.Notification{}
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &n.Payload, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, n)
+	}
+	return out, total, rows.Err()
+}
+
+func (r *pgNotificationRepo) CountUnread(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM notifications WHERE user_id=$1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *pgNotificationRepo) MarkRead(ctx context.Context, id, userID string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE notifications SET read_at=COALESCE(read_at, now()) WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (r *pgNotificationRepo) MarkAllRead(ctx context.Context, userID string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE notifications SET read_at=now() WHERE user_id=$1 AND read_at IS NULL`, userID)
+	return err
+}
+
+func (r *pgNotificationRepo) GetPreferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	p := &models.NotificationPreferences{UserID: userID}
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT locale, email_enabled, sms_enabled, disabled_types, updated_at FROM notification_preferences WHERE user_id=$1`, userID).Scan(
+		&p.Locale, &p.EmailEnabled, &p.SMSEnabled, &p.DisabledTypes, &p.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &models.NotificationPreferences{UserID: userID, Locale: "ru", EmailEnabled: true, DisabledTypes: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgNotificationRepo) SavePreferences(ctx context.Context, p *models.NotificationPreferences) error {
+	if p.DisabledTypes == nil {
+		p.DisabledTypes = []string{}
+	}
+	q := `INSERT INTO notification_preferences (user_id, locale, email_enabled, sms_enabled, disabled_types, updated_at)
+	VALUES ($1,$2,$3,$4,$5,now())
+	ON CONFLICT (user_id) DO UPDATE SET locale=EXCLUDED.locale, email_enabled=EXCLUDED.email_enabled,
+		sms_enabled=EXCLUDED.sms_enabled, disabled_types=EXCLUDED.disabled_types, updated_at=now()
+	RETURNING updated_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, p.UserID, p.Locale, p.EmailEnabled, p.SMSEnabled, p.DisabledTypes).Scan(&p.UpdatedAt)
+}
@@ -16,6 +16,8 @@ type OrderRepo interface {
 	Update(ctx context.Context, o *models.Order) error
 	Delete(ctx context.Context, id string) error
 	SetStatus(ctx context.Context, id, status string) error
+	// ChangeStatus — SetStatus, только если заказ сейчас в статусе from (false — уже нет)
+	ChangeStatus(ctx context.Context, id, from, to string) (bool, error)
 	SelectExecutor(ctx context.Context, orderID, bidID string) error
 	AddHistory(ctx context.Context, actorID, action, objectType, objectID string, payload map[string]interface{}) error
 }
@@ -30,25 +32,25 @@ func NewOrderRepo(db *pgxpool.Pool) OrderRepo {
 
 func (r *pgOrderRepo) Create(ctx context.Context, o *models.Order) error {
 	query := `INSERT INTO orders (id, org_id, client_user_id, title, description, category, subcategory, region,
-		mode_online, deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id)
-	VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17)
+		mode_online, deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id, visibility)
+	VALUES ($1,$2,$3,$4,$5,NULLIF($6,''),NULLIF($7,''),NULLIF($8,''),$9,$10,$11,$12,$13,$14,$15,$16,$17,$18)
 	RETURNING created_at, updated_at`
-	err := r.db.QueryRow(ctx, query,
+	err := conn(ctx, r.db).QueryRow(ctx, query,
 		o.ID, o.OrgID, o.ClientUserID, o.Title, o.Description, o.Category, o.Subcategory, o.Region,
-		o.ModeOnline, o.Deadline, o.BudgetMin, o.BudgetMax, o.Currency, o.Status, o.Promotion, o.Attachments, o.ChosenBidID,
+		o.ModeOnline, o.Deadline, o.BudgetMin, o.BudgetMax, o.Currency, o.Status, o.Promotion, o.Attachments, o.ChosenBidID, o.Visibility,
 	).Scan(&o.CreatedAt, &o.UpdatedAt)
 	return err
 }
 
 func (r *pgOrderRepo) GetByID(ctx context.Context, id string) (*models.Order, error) {
 	o := &models.Order{}
-	query := `SELECT id, org_id, client_user_id, title, description, category, subcategory, region, mode_online,
-		deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id, created_at, published_at, updated_at
+	query := `SELECT id, org_id, client_user_id, title, description, COALESCE(category,''), COALESCE(subcategory,''), COALESCE(region,''), mode_online,
+		deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id, created_at, published_at, completed_at, updated_at, visibility
 		FROM orders WHERE id=$1`
-	if err := r.db.QueryRow(ctx, query, id).Scan(
+	if err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
 		&o.ID, &o.OrgID, &o.ClientUserID, &o.Title, &o.Description, &o.Category, &o.Subcategory, &o.Region, &o.ModeOnline,
 		&o.Deadline, &o.BudgetMin, &o.BudgetMax, &o.Currency, &o.Status, &o.Promotion, &o.Attachments, &o.ChosenBidID,
-		&o.CreatedAt, &o.PublishedAt, &o.UpdatedAt,
+		&o.CreatedAt, &o.PublishedAt, &o.CompletedAt, &o.UpdatedAt, &o.Visibility,
 	); err != nil {
 		return nil, err
 	}
@@ -60,19 +62,26 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 	var args []interface{}
 	i := 1
 
-	// filters: status, category, region, min_budget, max_budget, top, pinned
+	// filters: status, category, region, min_budget, max_budget, visibility, top, pinned
 	if v, ok := filters["status"]; ok && v != "" {
 		where = append(where, fmt.Sprintf("status = $%d", i))
 		args = append(args, v)
 		i++
 	}
+	if v, ok := filters["visibility"]; ok && v != "" {
+		where = append(where, fmt.Sprintf("visibility = $%d", i))
+		args = append(args, v)
+		i++
+	}
 	if v, ok := filters["category"]; ok && v != "" {
 		where = append(where, fmt.Sprintf("category = $%d", i))
 		args = append(args, v)
 		i++
 	}
 	if v, ok := filters["region"]; ok && v != "" {
-		where = append(where, fmt.Sprintf("region = $%d", i))
+		// регион вместе с вложенными по КАТО
+		where = append(where, fmt.Sprintf(`region IN (WITH RECURSIVE sub AS (SELECT kato FROM regions WHERE kato = $%d
+			UNION ALL SELECT r.kato FROM regions r JOIN sub ON r.parent_kato = sub.kato) SELECT kato FROM sub)`, i))
 		args = append(args, v)
 		i++
 	}
@@ -88,7 +97,7 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 	}
 	// TODO: top/pinned use promotion_flags JSONB fields if needed
 
-	q := "SELECT id, org_id, client_user_id, title, description, category, subcategory, region, mode_online, deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id, created_at, published_at, updated_at FROM orders"
+	q := "SELECT id, org_id, client_user_id, title, description, COALESCE(category,''), COALESCE(subcategory,''), COALESCE(region,''), mode_online, deadline, budget_min, budget_max, currency, status, promotion_flags, attachments, chosen_bid_id, created_at, published_at, completed_at, updated_at, visibility FROM orders"
 	if len(where) > 0 {
 		q += " WHERE " + strings.Join(where, " AND ")
 	}
@@ -98,7 +107,7 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 		countQ += " WHERE " + strings.Join(where, " AND ")
 	}
 	var total int
-	if err := r.db.QueryRow(ctx, countQ, args...).Scan(&total); err != nil {
+	if err := conn(ctx, r.db).QueryRow(ctx, countQ, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -109,7 +118,7 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 	// replace placeholders
 	q = fmt.Sprintf(q, len(args)-1, len(args))
 
-	rows, err := r.db.Query(ctx, q, args...)
+	rows, err := conn(ctx, r.db).Query(ctx, q, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -121,7 +130,7 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 		if err := rows.Scan(
 			&o.ID, &o.OrgID, &o.ClientUserID, &o.Title, &o.Description, &o.Category, &o.Subcategory, &o.Region, &o.ModeOnline,
 			&o.Deadline, &o.BudgetMin, &o.BudgetMax, &o.Currency, &o.Status, &o.Promotion, &o.Attachments, &o.ChosenBidID,
-			&o.CreatedAt, &o.PublishedAt, &o.UpdatedAt,
+			&o.CreatedAt, &o.PublishedAt, &o.CompletedAt, &o.UpdatedAt, &o.Visibility,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -131,32 +140,46 @@ func (r *pgOrderRepo) List(ctx context.Context, filters map[string]string, page,
 }
 
 func (r *pgOrderRepo) Update(ctx context.Context, o *models.Order) error {
-	query := `UPDATE orders SET title=$1, description=$2, category=$3, subcategory=$4, region=$5, mode_online=$6,
-		deadline=$7, budget_min=$8, budget_max=$9, currency=$10, promotion_flags=$11, attachments=$12, updated_at=now()
-		WHERE id=$13 RETURNING updated_at`
-	return r.db.QueryRow(ctx, query,
+	query := `UPDATE orders SET title=$1, description=$2, category=NULLIF($3,''), subcategory=NULLIF($4,''), region=NULLIF($5,''), mode_online=$6,
+		deadline=$7, budget_min=$8, budget_max=$9, currency=$10, promotion_flags=$11, attachments=$12, visibility=$13, updated_at=now()
+		WHERE id=$14 RETURNING updated_at`
+	return conn(ctx, r.db).QueryRow(ctx, query,
 		o.Title, o.Description, o.Category, o.Subcategory, o.Region, o.ModeOnline,
-		o.Deadline, o.BudgetMin, o.BudgetMax, o.Currency, o.Promotion, o.Attachments, o.ID,
+		o.Deadline, o.BudgetMin, o.BudgetMax, o.Currency, o.Promotion, o.Attachments, o.Visibility, o.ID,
 	).Scan(&o.UpdatedAt)
 }
 
 func (r *pgOrderRepo) Delete(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx, `DELETE FROM orders WHERE id=$1`, id)
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM orders WHERE id=$1`, id)
 	return err
 }
 
 func (r *pgOrderRepo) SetStatus(ctx context.Context, id, status string) error {
-	_, err := r.db.Exec(ctx, `UPDATE orders SET status=$1, updated_at=now() WHERE id=$2`, status, id)
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE orders SET status=$1,
+		published_at = CASE WHEN $1='published' THEN COALESCE(published_at, now()) ELSE published_at END,
+		completed_at = CASE WHEN $1='completed' THEN now() ELSE completed_at END,
+		updated_at=now() WHERE id=$2`, status, id)
 	return err
 }
 
+func (r *pgOrderRepo) ChangeStatus(ctx context.Context, id, from, to string) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE orders SET status=$1,
+		published_at = CASE WHEN $1='published' THEN COALESCE(published_at, now()) ELSE published_at END,
+		completed_at = CASE WHEN $1='completed' THEN now() ELSE completed_at END,
+		updated_at=now() WHERE id=$2 AND status=$3`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 func (r *pgOrderRepo) SelectExecutor(ctx context.Context, orderID, bidID string) error {
-	_, err := r.db.Exec(ctx, `UPDATE orders SET chosen_bid_id=$1, status='executor_selected', updated_at=now() WHERE id=$2`, bidID, orderID)
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE orders SET chosen_bid_id=$1, status='executor_selected', executor_selected_at=now(), updated_at=now() WHERE id=$2`, bidID, orderID)
 	return err
 }
 
 func (r *pgOrderRepo) AddHistory(ctx context.Context, actorID, action, objectType, objectID string, payload map[string]interface{}) error {
 	q := `INSERT INTO audit_logs (actor_id, action, object_type, object_id, payload) VALUES ($1,$2,$3,$4,$5)`
-	_, err := r.db.Exec(ctx, q, actorID, action, objectType, objectID, payload)
+	_, err := conn(ctx, r.db).Exec(ctx, q, actorID, action, objectType, objectID, payload)
 	return err
 }
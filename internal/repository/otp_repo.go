@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OTPRepo stores one-time SMS codes and answers throttling queries
+type OTPRepo interface {
+	Create(ctx context.Context, c *models.OTPCode) error
+	GetActive(ctx context.Context, phone string) (*models.OTPCode, error)
+	// UseAttempt atomically spends one attempt; false — попытки исчерпаны или код погашен
+	UseAttempt(ctx context.Context, id string, max int) (bool, error)
+	// Consume гасит код; false — его уже погасил параллельный запрос или он истёк
+	Consume(ctx context.Context, id string) (bool, error)
+	CountByPhoneSince(ctx context.Context, phone string, since time.Time) (int, error)
+	CountByIPSince(ctx context.Context, ip string, since time.Time) (int, error)
+}
+
+type pgOTPRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOTPRepo(db *pgxpool.Pool) OTPRepo { return &pgOTPRepo{db: db} }
+
+func (r *pgOTPRepo) Create(ctx context.Context, c *models.OTPCode) error {
+	q := `INSERT INTO otp_codes (id, phone, code_hash, request_ip, expires_at) VALUES ($1,$2,$3,$4,$5) RETURNING created_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, c.ID, c.Phone, c.CodeHash, c.RequestIP, c.ExpiresAt).Scan(&c.CreatedAt)
+}
+
+// GetActive returns the latest unconsumed, unexpired code for the phone
+func (r *pgOTPRepo) GetActive(ctx context.Context, phone string) (*models.OTPCode, error) {
+	c := &models.OTPCode{}
+	q := `SELECT id, phone, code_hash, attempts, COALESCE(request_ip,''), expires_at, consumed_at, created_at
+		FROM otp_codes WHERE phone=$1 AND consumed_at IS NULL AND expires_at > now()
+		ORDER BY created_at DESC LIMIT 1`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, phone).Scan(
+		&c.ID, &c.Phone, &c.CodeHash, &c.Attempts, &c.RequestIP, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *pgOTPRepo) UseAttempt(ctx context.Context, id string, max int) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE otp_codes SET attempts = attempts + 1
+		WHERE id=$1 AND attempts < $2 AND consumed_at IS NULL`, id, max)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgOTPRepo) Consume(ctx context.Context, id string) (bool, error) {
+	var got string
+	err := conn(ctx, r.db).QueryRow(ctx, `UPDATE otp_codes SET consumed_at = now()
+		WHERE id=$1 AND consumed_at IS NULL AND expires_at > now() RETURNING id`, id).Scan(&got)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *pgOTPRepo) CountByPhoneSince(ctx context.Context, phone string, since time.Time) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM otp_codes WHERE phone=$1 AND created_at >= $2`, phone, since).Scan(&n)
+	return n, err
+}
+
+func (r *pgOTPRepo) CountByIPSince(ctx context.Context, ip string, since time.Time) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM otp_codes WHERE request_ip=$1 AND created_at >= $2`, ip, since).Scan(&n)
+	return n, err
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,8 +13,27 @@ type PaymentRepo interface {
 	Create(ctx context.Context, p *models.Payment) error
 	GetByID(ctx context.Context, id string) (*models.Payment, error)
 	UpdateStatus(ctx context.Context, id, status string) error
+	// ChangeStatus — UpdateStatus, только если платёж сейчас в статусе from (false — уже нет)
+	ChangeStatus(ctx context.Context, id, from, to string) (bool, error)
+	List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Payment, int, error)
+	// ReleaseEscrow marks held order_escrow payments of the order as released and
+	// credits the executor's wallet; returns the released amount (0 if nothing held)
+	ReleaseEscrow(ctx context.Context, orderID, executorID string) (int64, error)
+	// SettleEscrow splits held order_escrow payments: executorShare goes to the
+	// executor (negative — everything), the rest is refunded to the client.
+	// Returns the amounts credited to the executor and refunded to the client.
+	SettleEscrow(ctx context.Context, orderID, clientID, executorID string, executorShare int64) (int64, int64, error)
+	// HeldEscrow sums held order_escrow payments; inside a transaction the rows
+	// stay locked until commit
+	HeldEscrow(ctx context.Context, orderID string) (int64, error)
+	// SettleHeld — то же для held-платежей консультации (related_type=mentoring_session):
+	// payeeShare уходит наставнику, остаток возвращается плательщику
+	SettleHeld(ctx context.Context, relatedType, relatedID, payerID, payeeID string, payeeShare int64) (int64, int64, error)
 }
 
+// heldRefKeys — ключ, под которым объект платежа пишется в meta проводок кошелька
+var heldRefKeys = map[string]string{"order_escrow": "order_id", "mentoring_session": "session_id"}
+
 type pgPaymentRepo struct {
 	db *pgxpool.Pool
 }
@@ -20,15 +41,21 @@ type pgPaymentRepo struct {
 func NewPaymentRepo(db *pgxpool.Pool) PaymentRepo { return &pgPaymentRepo{db: db} }
 
 func (r *pgPaymentRepo) Create(ctx context.Context, p *models.Payment) error {
-	// make placeholders count match columns (14)
+	// make placeholders count match columns (18)
 	q := `INSERT INTO payments (
         id, user_id, organization_id, related_type, related_id,
         provider, provider_payment_id, amount, currency, status,
-        items, idempotency_key, expires_at, webhook_meta
+        items, idempotency_key, expires_at, webhook_meta,
+        net_amount, vat_amount, vat_rate, payer_vat
     ) VALUES (
-        $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14
+        $1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18
     ) RETURNING created_at, updated_at`
 
+	// без разбивки (НДС не считали) — вся сумма без НДС
+	if p.NetAmount == 0 && p.VATAmount == 0 {
+		p.NetAmount = p.Amount
+	}
+
 	// Convert possible empty string pointers to nil so PG gets NULL instead of "" (invalid uuid)
 	var userID interface{}
 	if p.UserID == nil || *p.UserID == "" {
@@ -63,18 +90,19 @@ func (r *pgPaymentRepo) Create(ctx context.Context, p *models.Payment) error {
 	}
 
 	// execute
-	return r.db.QueryRow(ctx, q,
+	return conn(ctx, r.db).QueryRow(ctx, q,
 		p.ID, userID, orgID, p.RelatedType, relatedID,
 		p.Provider, p.ProviderPaymentID, p.Amount, p.Currency, p.Status,
 		items, p.IdempotencyKey, p.ExpiresAt, webhook,
+		p.NetAmount, p.VATAmount, p.VATRate, p.PayerVAT,
 	).Scan(&p.CreatedAt, &p.UpdatedAt)
 }
 
 func (r *pgPaymentRepo) GetByID(ctx context.Context, id string) (*models.Payment, error) {
 	p := &models.Payment{}
-	q := `SELECT id,user_id,organization_id,related_type,related_id,provider,provider_payment_id,amount,currency,status,items,idempotency_key,expires_at,webhook_meta,created_at,updated_at FROM payments WHERE id=$1`
-	if err := r.db.QueryRow(ctx, q, id).Scan(
-		&p.ID, &p.UserID, &p.OrganizationID, &p.RelatedType, &p.RelatedID, &p.Provider, &p.ProviderPaymentID, &p.Amount, &p.Currency, &p.Status, &p.Items, &p.IdempotencyKey, &p.ExpiresAt, &p.WebhookMeta, &p.CreatedAt, &p.UpdatedAt,
+	q := `SELECT id,user_id,organization_id,related_type,related_id,provider,provider_payment_id,amount,net_amount,vat_amount,vat_rate,payer_vat,currency,status,items,idempotency_key,expires_at,webhook_meta,created_at,updated_at FROM payments WHERE id=$1`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, id).Scan(
+		&p.ID, &p.UserID, &p.OrganizationID, &p.RelatedType, &p.RelatedID, &p.Provider, &p.ProviderPaymentID, &p.Amount, &p.NetAmount, &p.VATAmount, &p.VATRate, &p.PayerVAT, &p.Currency, &p.Status, &p.Items, &p.IdempotencyKey, &p.ExpiresAt, &p.WebhookMeta, &p.CreatedAt, &p.UpdatedAt,
 	); err != nil {
 		return nil, err
 	}
@@ -82,6 +110,156 @@ func (r *pgPaymentRepo) GetByID(ctx context.Context, id string) (*models.Payment
 }
 
 func (r *pgPaymentRepo) UpdateStatus(ctx context.Context, id, status string) error {
-	_, err := r.db.Exec(ctx, `UPDATE payments SET status=$1, updated_at=now() WHERE id=$2`, status, id)
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE payments SET status=$1, updated_at=now() WHERE id=$2`, status, id)
 	return err
 }
+
+func (r *pgPaymentRepo) ChangeStatus(ctx context.Context, id, from, to string) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE payments SET status=$1, updated_at=now() WHERE id=$2 AND status=$3`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// List — для админки. filters: status, user_id, related_type, related_id, provider, from, to (created_at)
+func (r *pgPaymentRepo) List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Payment, int, error) {
+	var where []string
+	var args []interface{}
+	i := 1
+	cols := []struct{ key, cond string }{
+		{"status", "status = $%d"},
+		{"user_id", "user_id = $%d"},
+		{"related_type", "related_type = $%d"},
+		{"related_id", "related_id = $%d"},
+		{"provider", "provider = $%d"},
+		{"from", "created_at >= $%d"},
+		{"to", "created_at < $%d"},
+	}
+	for _, c := range cols {
+		if v, ok := filters[c.key]; ok && v != "" {
+			where = append(where, fmt.Sprintf(c.cond, i))
+			args = append(args, v)
+			i++
+		}
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT count(*) FROM payments"+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	q := fmt.Sprintf(`SELECT id,user_id,organization_id,related_type,related_id,provider,provider_payment_id,amount,net_amount,vat_amount,vat_rate,payer_vat,currency,status,items,idempotency_key,expires_at,webhook_meta,created_at,updated_at
+		FROM payments`+cond+` ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, i, i+1)
+	args = append(args, perPage, offset)
+	rows, err := conn(ctx, r.db).Query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.Payment
+	for rows.Next() {
+		p := &models.Payment{}
+		if err := rows.Scan(
+			&p.ID, &p.UserID, &p.OrganizationID, &p.RelatedType, &p.RelatedID, &p.Provider, &p.ProviderPaymentID, &p.Amount, &p.NetAmount, &p.VATAmount, &p.VATRate, &p.PayerVAT, &p.Currency, &p.Status, &p.Items, &p.IdempotencyKey, &p.ExpiresAt, &p.WebhookMeta, &p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, p)
+	}
+	return out, total, rows.Err()
+}
+
+func (r *pgPaymentRepo) ReleaseEscrow(ctx context.Context, orderID, executorID string) (int64, error) {
+	toExecutor, _, err := r.SettleEscrow(ctx, orderID, "", executorID, -1)
+	return toExecutor, err
+}
+
+func (r *pgPaymentRepo) SettleEscrow(ctx context.Context, orderID, clientID, executorID string, executorShare int64) (int64, int64, error) {
+	return r.SettleHeld(ctx, "order_escrow", orderID, clientID, executorID, executorShare)
+}
+
+func (r *pgPaymentRepo) SettleHeld(ctx context.Context, relatedType, relatedID, clientID, executorID string, executorShare int64) (int64, int64, error) {
+	refKey := heldRefKeys[relatedType]
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+	rows, err := tx.Query(ctx, `SELECT id, amount FROM payments
+		WHERE related_type=$1 AND related_id=$2 AND status='held' ORDER BY created_at FOR UPDATE`, relatedType, relatedID)
+	if err != nil {
+		return 0, 0, err
+	}
+	type held struct {
+		id     string
+		amount int64
+	}
+	var list []held
+	for rows.Next() {
+		var h held
+		if err := rows.Scan(&h.id, &h.amount); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		list = append(list, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	// доля исполнителя списывается с платежей по порядку, остаток каждого — клиенту
+	remaining := executorShare
+	var toExecutor, toClient int64
+	for _, h := range list {
+		execPart := h.amount
+		if remaining >= 0 {
+			if remaining < execPart {
+				execPart = remaining
+			}
+			remaining -= execPart
+		}
+		clientPart := h.amount - execPart
+		if clientPart > 0 && clientID == "" {
+			return 0, 0, fmt.Errorf("settle %s %s: payer is required for a refund", relatedType, relatedID)
+		}
+		status := "split"
+		switch {
+		case clientPart == 0:
+			status = "released"
+		case execPart == 0:
+			status = "refunded"
+		}
+		if _, err := tx.Exec(ctx, `UPDATE payments SET status=$2, updated_at=now() WHERE id=$1`, h.id, status); err != nil {
+			return 0, 0, err
+		}
+		if execPart > 0 {
+			if _, err := tx.Exec(ctx, `INSERT INTO wallet_transactions (user_id, payment_id, amount, type, meta) VALUES ($1,$2,$3,'credit',$4)`,
+				executorID, h.id, execPart, map[string]interface{}{"reason": "escrow_release", refKey: relatedID}); err != nil {
+				return 0, 0, err
+			}
+		}
+		if clientPart > 0 {
+			if _, err := tx.Exec(ctx, `INSERT INTO wallet_transactions (user_id, payment_id, amount, type, meta) VALUES ($1,$2,$3,'refund',$4)`,
+				clientID, h.id, clientPart, map[string]interface{}{"reason": "escrow_refund", refKey: relatedID}); err != nil {
+				return 0, 0, err
+			}
+		}
+		toExecutor += execPart
+		toClient += clientPart
+	}
+	return toExecutor, toClient, tx.Commit(ctx)
+}
+
+// HeldEscrow — сумма, удерживаемая по заказу
+func (r *pgPaymentRepo) HeldEscrow(ctx context.Context, orderID string) (int64, error) {
+	var total int64
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT COALESCE(SUM(amount),0) FROM (
+		SELECT amount FROM payments WHERE related_type='order_escrow' AND related_id=$1 AND status='held' FOR UPDATE) p`, orderID).Scan(&total)
+	return total, err
+}
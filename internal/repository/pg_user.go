@@ -2,18 +2,44 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepo interface {
-	Create(u *models.User) error
+	Create(ctx context.Context, u *models.User) error
 	GetByEmail(email string) (*models.User, error)
 	GetByID(id string) (*models.User, error)
+	GetByPhone(phone string) (*models.User, error)
 	Count() (int, error)
+	Search(filters map[string]string, page, perPage int) ([]*models.User, int, error)
 	Update(u *models.User) error
+	SetStatus(ctx context.Context, id, status string) error
+	SetRoles(ctx context.Context, id string, roles []string) error
+	BumpTokenVersion(id string) error
+	MarkPhoneVerified(ctx context.Context, id string) error
+	// ReleasePhone снимает номер с аккаунта, который его не подтвердил
+	ReleasePhone(ctx context.Context, phone string) error
+	RegisterLoginFailure(id string) (int, error)
+	Lock(id string, until time.Time) error
+	ResetLoginFailures(id string) error
+}
+
+const userColumns = `id,COALESCE(email,''),COALESCE(phone,''),COALESCE(full_name,''),role,roles,status,COALESCE(password_hash,''),
+	token_version,phone_verified_at,failed_login_count,locked_until,created_at,updated_at`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	u := &models.User{}
+	if err := row.Scan(&u.ID, &u.Email, &u.Phone, &u.FullName, &u.Role, &u.Roles, &u.Status, &u.PasswordHash,
+		&u.TokenVersion, &u.PhoneVerifiedAt, &u.FailedLoginCount, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return u, nil
 }
 
 type pgUserRepo struct {
@@ -24,43 +50,45 @@ func NewUserRepo(db *pgxpool.Pool) UserRepo {
 	return &pgUserRepo{db: db}
 }
 
-func (r *pgUserRepo) Create(u *models.User) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+func (r *pgUserRepo) Create(ctx context.Context, u *models.User) error {
+	if len(u.Roles) == 0 {
+		u.Roles = []string{u.Role}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
-	_, err := r.db.Exec(ctx, `
-		INSERT INTO users (id, email, phone, password_hash, full_name, role, status)
-		VALUES ($1,$2,$3,$4,$5,$6,$7)
-	`, u.ID, u.Email, u.Phone, u.PasswordHash, u.FullName, u.Role, u.Status)
+	_, err := conn(ctx, r.db).Exec(ctx, `
+		INSERT INTO users (id, email, phone, password_hash, full_name, role, roles, status)
+		VALUES ($1,NULLIF($2,''),NULLIF($3,''),$4,$5,$6,$7,$8)
+	`, u.ID, u.Email, u.Phone, u.PasswordHash, u.FullName, u.Role, u.Roles, u.Status)
 	return err
 }
 
 func (r *pgUserRepo) GetByEmail(email string) (*models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	u := &models.User{}
-	row := r.db.QueryRow(ctx, `SELECT id,email,phone,full_name,role,status,password_hash,created_at,updated_at FROM users WHERE email=$1`, email)
-	if err := row.Scan(&u.ID, &u.Email, &u.Phone, &u.FullName, &u.Role, &u.Status, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
-		return nil, err
-	}
-	return u, nil
+	row := conn(ctx, r.db).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE email=$1", email)
+	return scanUser(row)
 }
 
 func (r *pgUserRepo) GetByID(id string) (*models.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
-	u := &models.User{}
-	row := r.db.QueryRow(ctx, `SELECT id,email,phone,full_name,role,status,password_hash,created_at,updated_at FROM users WHERE id=$1`, id)
-	if err := row.Scan(&u.ID, &u.Email, &u.Phone, &u.FullName, &u.Role, &u.Status, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt); err != nil {
-		return nil, err
-	}
-	return u, nil
+	row := conn(ctx, r.db).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE id=$1", id)
+	return scanUser(row)
+}
+
+func (r *pgUserRepo) GetByPhone(phone string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	row := conn(ctx, r.db).QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE phone=$1", phone)
+	return scanUser(row)
 }
 
 func (r *pgUserRepo) Count() (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 	var cnt int
-	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&cnt); err != nil {
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&cnt); err != nil {
 		return 0, err
 	}
 	return cnt, nil
@@ -70,13 +98,126 @@ func (r *pgUserRepo) Update(u *models.User) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := r.db.Exec(ctx, `
+	_, err := conn(ctx, r.db).Exec(ctx, `
 		UPDATE users
 		SET full_name = $1,
-			phone = $2,
+			phone_verified_at = CASE WHEN phone IS DISTINCT FROM NULLIF($2,'') THEN NULL ELSE phone_verified_at END,
+			phone = NULLIF($2,''),
 			metadata = $3,
 			updated_at = now()
 		WHERE id = $4
 	`, u.FullName, u.Phone, u.Metadata, u.ID)
 	return err
 }
+
+// SetStatus меняет статус и отзывает все выданные access-токены
+func (r *pgUserRepo) SetStatus(ctx context.Context, id, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET status=$1, token_version=token_version+1, updated_at=now() WHERE id=$2`, status, id)
+	return err
+}
+
+// SetRoles меняет набор ролей (первая — основная) и отзывает все выданные access-токены
+func (r *pgUserRepo) SetRoles(ctx context.Context, id string, roles []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET role=$1, roles=$2, token_version=token_version+1, updated_at=now() WHERE id=$3`, roles[0], roles, id)
+	return err
+}
+
+func (r *pgUserRepo) BumpTokenVersion(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET token_version=token_version+1, updated_at=now() WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgUserRepo) MarkPhoneVerified(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET phone_verified_at=COALESCE(phone_verified_at, now()), updated_at=now() WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgUserRepo) ReleasePhone(ctx context.Context, phone string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET phone=NULL, updated_at=now() WHERE phone=$1 AND phone_verified_at IS NULL`, phone)
+	return err
+}
+
+// RegisterLoginFailure increments the failed login counter and returns the new value
+func (r *pgUserRepo) RegisterLoginFailure(id string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `UPDATE users SET failed_login_count = failed_login_count + 1 WHERE id=$1 RETURNING failed_login_count`, id).Scan(&n)
+	return n, err
+}
+
+func (r *pgUserRepo) Lock(id string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET locked_until=$1, failed_login_count=0 WHERE id=$2`, until, id)
+	return err
+}
+
+func (r *pgUserRepo) ResetLoginFailures(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE users SET failed_login_count=0, locked_until=NULL WHERE id=$1`, id)
+	return err
+}
+
+// Search — поиск для админки. filters: q (email/phone/full_name), role, status
+func (r *pgUserRepo) Search(filters map[string]string, page, perPage int) ([]*models.User, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var where []string
+	var args []interface{}
+	i := 1
+
+	if v, ok := filters["q"]; ok && v != "" {
+		where = append(where, fmt.Sprintf("(email ILIKE $%d OR phone ILIKE $%d OR full_name ILIKE $%d)", i, i, i))
+		args = append(args, "%"+v+"%")
+		i++
+	}
+	if v, ok := filters["role"]; ok && v != "" {
+		where = append(where, fmt.Sprintf("$%d = ANY(roles)", i))
+		args = append(args, v)
+		i++
+	}
+	if v, ok := filters["status"]; ok && v != "" {
+		where = append(where, fmt.Sprintf("status = $%d", i))
+		args = append(args, v)
+		i++
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, "SELECT count(*) FROM users"+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	q := fmt.Sprintf("SELECT "+userColumns+" FROM users"+cond+" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", i, i+1)
+	args = append(args, perPage, offset)
+	rows, err := conn(ctx, r.db).Query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		u.PasswordHash = ""
+		out = append(out, u)
+	}
+	return out, total, rows.Err()
+}
@@ -26,7 +26,7 @@ func NewRefreshRepo(db *pgxpool.Pool) RefreshTokenRepo {
 func (r *pgRefreshRepo) Create(ctx context.Context, t *models.RefreshToken) error {
 	_ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
-	_, err := r.db.Exec(_ctx, `
+	_, err := conn(_ctx, r.db).Exec(_ctx, `
 INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at, created_at)
 VALUES ($1,$2,$3,$4,$5)
 `, t.ID, t.UserID, t.TokenHash, t.ExpiresAt, t.CreatedAt)
@@ -37,7 +37,7 @@ func (r *pgRefreshRepo) GetByHash(ctx context.Context, hash string) (*models.Ref
 	_ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 	t := &models.RefreshToken{}
-	row := r.db.QueryRow(_ctx, `SELECT id,user_id,token_hash,expires_at,created_at FROM refresh_tokens WHERE token_hash=$1`, hash)
+	row := conn(_ctx, r.db).QueryRow(_ctx, `SELECT id,user_id,token_hash,expires_at,created_at FROM refresh_tokens WHERE token_hash=$1`, hash)
 	if err := row.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt); err != nil {
 		return nil, err
 	}
@@ -47,13 +47,13 @@ func (r *pgRefreshRepo) GetByHash(ctx context.Context, hash string) (*models.Ref
 func (r *pgRefreshRepo) DeleteByUser(ctx context.Context, userID string) error {
 	_ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
-	_, err := r.db.Exec(_ctx, `DELETE FROM refresh_tokens WHERE user_id=$1`, userID)
+	_, err := conn(_ctx, r.db).Exec(_ctx, `DELETE FROM refresh_tokens WHERE user_id=$1`, userID)
 	return err
 }
 
 func (r *pgRefreshRepo) DeleteByHash(ctx context.Context, hash string) error {
 	_ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
-	_, err := r.db.Exec(_ctx, `DELETE FROM refresh_tokens WHERE token_hash=$1`, hash)
+	_, err := conn(_ctx, r.db).Exec(_ctx, `DELETE FROM refresh_tokens WHERE token_hash=$1`, hash)
 	return err
 }
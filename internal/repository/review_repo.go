@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReviewRepo interface {
+	Create(ctx context.Context, r *models.Review) error
+	GetByID(ctx context.Context, id string) (*models.Review, error)
+	ListByOrder(ctx context.Context, orderID string) ([]*models.Review, error)
+	ListBySession(ctx context.Context, sessionID string) ([]*models.Review, error)
+	// Update changes scores/text of an unpublished review; pgx.ErrNoRows otherwise
+	Update(ctx context.Context, r *models.Review) error
+	// PublishOrder reveals all reviews of the order at once
+	PublishOrder(ctx context.Context, orderID string) error
+	PublishSession(ctx context.Context, sessionID string) error
+	// PublishDue reveals reviews of orders and sessions completed before the cutoff
+	PublishDue(ctx context.Context, completedBefore time.Time) (int64, error)
+	// ListForTarget — опубликованные отзывы о пользователе от авторов authorRole
+	ListForTarget(ctx context.Context, targetID, authorRole string, page, perPage int) ([]*models.Review, int, error)
+	// Summaries — агрегаты по пользователям; нет отзывов — нет ключа
+	Summaries(ctx context.Context, targetIDs []string, authorRole string) (map[string]*models.RatingSummary, error)
+	SetStatus(ctx context.Context, id, status string) error
+
+	// AddReport returns false if the user already reported this review
+	AddReport(ctx context.Context, rep *models.ReviewReport) (bool, error)
+	GetReport(ctx context.Context, id string) (*models.ReviewReport, error)
+	ListReports(ctx context.Context, status string, page, perPage int) ([]*models.ReviewReport, int, error)
+	// ResolveReports closes all open reports of the review
+	ResolveReports(ctx context.Context, reviewID, status, adminID string) error
+}
+
+type pgReviewRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewReviewRepo(db *pgxpool.Pool) ReviewRepo { return &pgReviewRepo{db: db} }
+
+const reviewColumns = `id, COALESCE(order_id::text, ''), session_id, author_id, target_id, author_role, rating, quality, timeliness, communication, text, status,
+	editable_until, published_at, created_at, updated_at`
+
+func scanReview(row pgx.Row) (*models.Review, error) {
+	r := &models.Review{}
+	if err := row.Scan(&r.ID, &r.OrderID, &r.SessionID, &r.AuthorID, &r.TargetID, &r.AuthorRole, &r.Rating, &r.Quality, &r.Timeliness,
+		&r.Communication, &r.Text, &r.Status, &r.EditableUntil, &r.PublishedAt, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func collectReviews(rows pgx.Rows) ([]*models.Review, error) {
+	defer rows.Close()
+	var out []*models.Review
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgReviewRepo) Create(ctx context.Context, rv *models.Review) error {
+	q := `INSERT INTO reviews (id, order_id, session_id, author_id, target_id, author_role, rating, quality, timeliness, communication, text, editable_until)
+	VALUES ($1,NULLIF($2,'')::uuid,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING status, created_at, updated_at`
+	return conn(ctx, r.db).QueryRow(ctx, q, rv.ID, rv.OrderID, rv.SessionID, rv.AuthorID, rv.TargetID, rv.AuthorRole, rv.Rating, rv.Quality, rv.Timeliness,
+		rv.Communication, rv.Text, rv.EditableUntil).Scan(&rv.Status, &rv.CreatedAt, &rv.UpdatedAt)
+}
+
+func (r *pgReviewRepo) GetByID(ctx context.Context, id string) (*models.Review, error) {
+	return scanReview(conn(ctx, r.db).QueryRow(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE id=$1`, id))
+}
+
+func (r *pgReviewRepo) ListByOrder(ctx context.Context, orderID string) ([]*models.Review, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE order_id=$1 ORDER BY created_at`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return collectReviews(rows)
+}
+
+func (r *pgReviewRepo) ListBySession(ctx context.Context, sessionID string) ([]*models.Review, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+reviewColumns+` FROM reviews WHERE session_id=$1 ORDER BY created_at`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return collectReviews(rows)
+}
+
+func (r *pgReviewRepo) Update(ctx context.Context, rv *models.Review) error {
+	err := conn(ctx, r.db).QueryRow(ctx, `UPDATE reviews SET rating=$2, quality=$3, timeliness=$4, communication=$5, text=$6, updated_at=now()
+		WHERE id=$1 AND published_at IS NULL RETURNING updated_at`,
+		rv.ID, rv.Rating, rv.Quality, rv.Timeliness, rv.Communication, rv.Text).Scan(&rv.UpdatedAt)
+	return err
+}
+
+func (r *pgReviewRepo) PublishOrder(ctx context.Context, orderID string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE reviews SET published_at=now() WHERE order_id=$1 AND published_at IS NULL`, orderID)
+	return err
+}
+
+func (r *pgReviewRepo) PublishSession(ctx context.Context, sessionID string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE reviews SET published_at=now() WHERE session_id=$1 AND published_at IS NULL`, sessionID)
+	return err
+}
+
+func (r *pgReviewRepo) PublishDue(ctx context.Context, completedBefore time.Time) (int64, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE reviews rv SET published_at=now() WHERE rv.published_at IS NULL AND (
+		EXISTS (SELECT 1 FROM orders o WHERE o.id = rv.order_id AND o.completed_at < $1) OR
+		EXISTS (SELECT 1 FROM mentoring_sessions ms WHERE ms.id = rv.session_id AND ms.completed_at < $1))`, completedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *pgReviewRepo) ListForTarget(ctx context.Context, targetID, authorRole string, page, perPage int) ([]*models.Review, int, error) {
+	const cond = ` FROM reviews WHERE target_id=$1 AND author_role=$2 AND status='published' AND published_at IS NOT NULL`
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*)`+cond, targetID, authorRole).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+reviewColumns+cond+` ORDER BY published_at DESC LIMIT $3 OFFSET $4`,
+		targetID, authorRole, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectReviews(rows)
+	return list, total, err
+}
+
+func (r *pgReviewRepo) Summaries(ctx context.Context, targetIDs []string, authorRole string) (map[string]*models.RatingSummary, error) {
+	out := map[string]*models.RatingSummary{}
+	if len(targetIDs) == 0 {
+		return out, nil
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT target_id, ROUND(AVG(rating),2)::float8, count(*), ROUND(AVG(quality),2)::float8,
+		ROUND(AVG(timeliness),2)::float8, ROUND(AVG(communication),2)::float8
+		FROM reviews WHERE target_id = ANY($1) AND author_role=$2 AND status='published' AND published_at IS NOT NULL
+		GROUP BY target_id`, targetIDs, authorRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		s := &models.RatingSummary{}
+		if err := rows.Scan(&id, &s.Average, &s.Count, &s.Quality, &s.Timeliness, &s.Communication); err != nil {
+			return nil, err
+		}
+		out[id] = s
+	}
+	return out, rows.Err()
+}
+
+func (r *pgReviewRepo) SetStatus(ctx context.Context, id, status string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE reviews SET status=$2, updated_at=now() WHERE id=$1`, id, status)
+	return err
+}
+
+func (r *pgReviewRepo) AddReport(ctx context.Context, rep *models.ReviewReport) (bool, error) {
+	err := conn(ctx, r.db).QueryRow(ctx, `INSERT INTO review_reports (id, review_id, reporter_id, reason) VALUES ($1,$2,$3,$4)
+		ON CONFLICT (review_id, reporter_id) DO NOTHING RETURNING status, created_at`,
+		rep.ID, rep.ReviewID, rep.ReporterID, rep.Reason).Scan(&rep.Status, &rep.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+const reportColumns = `id, review_id, reporter_id, reason, status, resolved_by, resolved_at, created_at`
+
+func scanReport(row pgx.Row) (*models.ReviewReport, error) {
+	rep := &models.ReviewReport{}
+	if err := row.Scan(&rep.ID, &rep.ReviewID, &rep.ReporterID, &rep.Reason, &rep.Status, &rep.ResolvedBy, &rep.ResolvedAt, &rep.CreatedAt); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+func (r *pgReviewRepo) GetReport(ctx context.Context, id string) (*models.ReviewReport, error) {
+	return scanReport(conn(ctx, r.db).QueryRow(ctx, `SELECT `+reportColumns+` FROM review_reports WHERE id=$1`, id))
+}
+
+func (r *pgReviewRepo) ListReports(ctx context.Context, status string, page, perPage int) ([]*models.ReviewReport, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM review_reports WHERE ($1='' OR status=$1)`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+reportColumns+` FROM review_reports WHERE ($1='' OR status=$1)
+		ORDER BY created_at LIMIT $2 OFFSET $3`, status, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.ReviewReport
+	for rows.Next() {
+		rep, err := scanReport(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, rep)
+	}
+	return out, total, rows.Err()
+}
+
+func (r *pgReviewRepo) ResolveReports(ctx context.Context, reviewID, status, adminID string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `UPDATE review_reports SET status=$2, resolved_by=$3, resolved_at=now()
+		WHERE review_id=$1 AND status='open'`, reviewID, status, adminID)
+	return err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SavedSearchRepo — сохранённые поиски и журнал оповещений по ним
+type SavedSearchRepo interface {
+	Create(ctx context.Context, s *models.SavedSearch) error
+	GetByID(ctx context.Context, id string) (*models.SavedSearch, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.SavedSearch, error)
+	CountByUser(ctx context.Context, userID string) (int, error)
+	Update(ctx context.Context, s *models.SavedSearch) error
+	Delete(ctx context.Context, id string) error
+
+	// Candidates — поиски, чьи category/region не противоречат заказу; instant первыми.
+	// regions — регион заказа и его родители
+	Candidates(ctx context.Context, category string, regions []string) ([]*models.SavedSearch, error)
+	// AddAlert records the order for the user once; false if already alerted.
+	// sent=false leaves it for the daily digest.
+	AddAlert(ctx context.Context, userID, orderID, searchID string, sent bool) (bool, error)
+	// DueDigests — daily-поиски с неотправленными оповещениями, дайджест по которым не слали с since
+	DueDigests(ctx context.Context, since time.Time) ([]*models.SavedSearch, error)
+	// PendingOrders — заказы (ещё опубликованные), ожидающие дайджеста поиска, до cutoff
+	PendingOrders(ctx context.Context, searchID string, cutoff time.Time) ([]string, error)
+	// MarkDigestSent marks pending alerts created up to cutoff as sent (withdrawn orders too)
+	MarkDigestSent(ctx context.Context, searchID string, cutoff time.Time) error
+}
+
+type pgSavedSearchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSavedSearchRepo(db *pgxpool.Pool) SavedSearchRepo { return &pgSavedSearchRepo{db: db} }
+
+const savedSearchColumns = `id, user_id, name, filters, channel, frequency, last_digest_at, created_at, updated_at`
+
+func scanSavedSearch(row pgx.Row) (*models.SavedSearch, error) {
+	s := &models.SavedSearch{}
+	if err := row.Scan(&s.ID, &s.UserID, &s.Name, &s.Filters, &s.Channel, &s.Frequency, &s.LastDigestAt, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func collectSavedSearches(rows pgx.Rows) ([]*models.SavedSearch, error) {
+	defer rows.Close()
+	var out []*models.SavedSearch
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgSavedSearchRepo) Create(ctx context.Context, s *models.SavedSearch) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO saved_searches (id, user_id, name, filters, channel, frequency)
+		VALUES ($1,$2,$3,$4,$5,$6) RETURNING created_at, updated_at`,
+		s.ID, s.UserID, s.Name, s.Filters, s.Channel, s.Frequency).Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *pgSavedSearchRepo) GetByID(ctx context.Context, id string) (*models.SavedSearch, error) {
+	return scanSavedSearch(conn(ctx, r.db).QueryRow(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE id=$1`, id))
+}
+
+func (r *pgSavedSearchRepo) ListByUser(ctx context.Context, userID string) ([]*models.SavedSearch, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches WHERE user_id=$1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return collectSavedSearches(rows)
+}
+
+func (r *pgSavedSearchRepo) CountByUser(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM saved_searches WHERE user_id=$1`, userID).Scan(&n)
+	return n, err
+}
+
+func (r *pgSavedSearchRepo) Update(ctx context.Context, s *models.SavedSearch) error {
+	return conn(ctx, r.db).QueryRow(ctx, `UPDATE saved_searches SET name=$2, filters=$3, channel=$4, frequency=$5, updated_at=now()
+		WHERE id=$1 RETURNING updated_at`, s.ID, s.Name, s.Filters, s.Channel, s.Frequency).Scan(&s.UpdatedAt)
+}
+
+func (r *pgSavedSearchRepo) Delete(ctx context.Context, id string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM saved_searches WHERE id=$1`, id)
+	return err
+}
+
+func (r *pgSavedSearchRepo) Candidates(ctx context.Context, category string, regions []string) ([]*models.SavedSearch, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches
+		WHERE COALESCE(filters->>'category', '') IN ('', $1) AND (COALESCE(filters->>'region', '') = '' OR filters->>'region' = ANY($2))
+		ORDER BY frequency = 'daily', created_at`, category, regions)
+	if err != nil {
+		return nil, err
+	}
+	return collectSavedSearches(rows)
+}
+
+func (r *pgSavedSearchRepo) AddAlert(ctx context.Context, userID, orderID, searchID string, sent bool) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `INSERT INTO saved_search_alerts (user_id, order_id, search_id, sent_at)
+		VALUES ($1,$2,$3, CASE WHEN $4 THEN now() END) ON CONFLICT (user_id, order_id) DO NOTHING`,
+		userID, orderID, searchID, sent)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgSavedSearchRepo) DueDigests(ctx context.Context, since time.Time) ([]*models.SavedSearch, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+savedSearchColumns+` FROM saved_searches s
+		WHERE s.frequency = 'daily' AND (s.last_digest_at IS NULL OR s.last_digest_at <= $1)
+			AND EXISTS (SELECT 1 FROM saved_search_alerts a WHERE a.search_id = s.id AND a.sent_at IS NULL)`, since)
+	if err != nil {
+		return nil, err
+	}
+	return collectSavedSearches(rows)
+}
+
+func (r *pgSavedSearchRepo) PendingOrders(ctx context.Context, searchID string, cutoff time.Time) ([]string, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT a.order_id FROM saved_search_alerts a JOIN orders o ON o.id = a.order_id
+		WHERE a.search_id=$1 AND a.sent_at IS NULL AND a.created_at <= $2 AND o.status = 'published'
+		ORDER BY a.created_at`, searchID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgSavedSearchRepo) MarkDigestSent(ctx context.Context, searchID string, cutoff time.Time) error {
+	tx, err := conn(ctx, r.db).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE saved_search_alerts SET sent_at=now() WHERE search_id=$1 AND sent_at IS NULL AND created_at <= $2`,
+		searchID, cutoff); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE saved_searches SET last_digest_at=now() WHERE id=$1`, searchID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
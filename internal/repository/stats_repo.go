@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatsRepo — агрегаты для админ-аналитики, считаются в SQL.
+// group — date_trunc unit (day|week|month), проверяется в сервисе.
+type StatsRepo interface {
+	OrdersPerPeriod(ctx context.Context, from, to time.Time, group string) ([]models.OrdersPerPeriod, error)
+	Funnel(ctx context.Context, from, to time.Time) (*models.FunnelStats, error)
+	Bids(ctx context.Context, from, to time.Time) (*models.BidStats, error)
+	Revenue(ctx context.Context, from, to time.Time, group string) ([]models.RevenueRow, error)
+	// ActiveExecutors groups executors who bid in the period by orders.<column> (region|category)
+	ActiveExecutors(ctx context.Context, from, to time.Time, column string) ([]models.ActiveExecutorsRow, error)
+}
+
+type pgStatsRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewStatsRepo(db *pgxpool.Pool) StatsRepo { return &pgStatsRepo{db: db} }
+
+func (r *pgStatsRepo) OrdersPerPeriod(ctx context.Context, from, to time.Time, group string) ([]models.OrdersPerPeriod, error) {
+	q := `SELECT period, sum(created)::int, sum(published)::int, sum(completed)::int FROM (
+		SELECT date_trunc($3, created_at) AS period, 1 AS created, 0 AS published, 0 AS completed
+			FROM orders WHERE created_at >= $1 AND created_at < $2
+		UNION ALL
+		SELECT date_trunc($3, published_at), 0, 1, 0
+			FROM orders WHERE published_at >= $1 AND published_at < $2
+		UNION ALL
+		SELECT date_trunc($3, completed_at), 0, 0, 1
+			FROM orders WHERE completed_at >= $1 AND completed_at < $2
+	) t GROUP BY period ORDER BY period`
+	rows, err := conn(ctx, r.db).Query(ctx, q, from, to, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.OrdersPerPeriod{}
+	for rows.Next() {
+		var row models.OrdersPerPeriod
+		if err := rows.Scan(&row.Period, &row.Created, &row.Published, &row.Completed); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgStatsRepo) Funnel(ctx context.Context, from, to time.Time) (*models.FunnelStats, error) {
+	f := &models.FunnelStats{}
+	q := `SELECT count(*), count(*) FILTER (WHERE executor_selected_at IS NOT NULL)
+		FROM orders WHERE published_at >= $1 AND published_at < $2`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, from, to).Scan(&f.Published, &f.ExecutorSelected); err != nil {
+		return nil, err
+	}
+	if f.Published > 0 {
+		f.Conversion = float64(f.ExecutorSelected) / float64(f.Published)
+	}
+	return f, nil
+}
+
+func (r *pgStatsRepo) Bids(ctx context.Context, from, to time.Time) (*models.BidStats, error) {
+	b := &models.BidStats{}
+	q := `WITH o AS (
+			SELECT id, published_at FROM orders WHERE published_at >= $1 AND published_at < $2
+		), per_order AS (
+			SELECT o.id, count(b.id) AS cnt, min(b.created_at) - o.published_at AS first_bid
+			FROM o LEFT JOIN bids b ON b.order_id = o.id
+			GROUP BY o.id, o.published_at
+		)
+		SELECT count(*),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY cnt), 0),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY extract(epoch FROM first_bid))
+		FROM per_order`
+	if err := conn(ctx, r.db).QueryRow(ctx, q, from, to).Scan(&b.Orders, &b.MedianBidCount, &b.MedianTimeToFirstBidSec); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *pgStatsRepo) Revenue(ctx context.Context, from, to time.Time, group string) ([]models.RevenueRow, error) {
+	q := `SELECT date_trunc($3, created_at) AS period, related_type, COALESCE(currency,'KZT'), sum(amount)::bigint, count(*)::int
+		FROM payments WHERE status='success' AND created_at >= $1 AND created_at < $2
+		GROUP BY 1, 2, 3 ORDER BY 1, 2`
+	rows, err := conn(ctx, r.db).Query(ctx, q, from, to, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.RevenueRow{}
+	for rows.Next() {
+		var row models.RevenueRow
+		if err := rows.Scan(&row.Period, &row.RelatedType, &row.Currency, &row.Amount, &row.Payments); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgStatsRepo) ActiveExecutors(ctx context.Context, from, to time.Time, column string) ([]models.ActiveExecutorsRow, error) {
+	// column приходит из белого списка сервиса (region|category)
+	q := `SELECT COALESCE(o.` + column + `, ''), count(DISTINCT b.executor_id)::int
+		FROM bids b JOIN orders o ON o.id = b.order_id
+		WHERE b.created_at >= $1 AND b.created_at < $2
+		GROUP BY 1 ORDER BY 2 DESC`
+	rows, err := conn(ctx, r.db).Query(ctx, q, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.ActiveExecutorsRow{}
+	for rows.Next() {
+		var row models.ActiveExecutorsRow
+		if err := rows.Scan(&row.Key, &row.Executors); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SubscriptionRepo — тарифы и подписки исполнителей
+type SubscriptionRepo interface {
+	ListPlans(ctx context.Context) ([]*models.SubscriptionPlan, error)
+	GetPlan(ctx context.Context, code string) (*models.SubscriptionPlan, error)
+	Get(ctx context.Context, userID string) (*models.Subscription, error)
+	// GetForUpdate — Get с блокировкой строки до конца транзакции (списание квоты ждёт)
+	GetForUpdate(ctx context.Context, userID string) (*models.Subscription, error)
+	// Save upserts the user's subscription row
+	Save(ctx context.Context, s *models.Subscription) error
+	// UseQuota takes one bid from the current period's quota; false if exhausted or no current subscription
+	UseQuota(ctx context.Context, userID string) (bool, error)
+	// Due — active с истёкшим периодом и grace с истёкшей отсрочкой
+	Due(ctx context.Context, now time.Time) ([]*models.Subscription, error)
+	// Badges — бейджи текущих подписок по пользователям
+	Badges(ctx context.Context, userIDs []string) (map[string]string, error)
+}
+
+type pgSubscriptionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSubscriptionRepo(db *pgxpool.Pool) SubscriptionRepo { return &pgSubscriptionRepo{db: db} }
+
+const planColumns = `code, name, price, bid_quota, overage_fee, badge, priority, active, sort`
+
+func scanPlan(row pgx.Row) (*models.SubscriptionPlan, error) {
+	p := &models.SubscriptionPlan{}
+	if err := row.Scan(&p.Code, &p.Name, &p.Price, &p.BidQuota, &p.OverageFee, &p.Badge, &p.Priority, &p.Active, &p.Sort); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *pgSubscriptionRepo) ListPlans(ctx context.Context) ([]*models.SubscriptionPlan, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+planColumns+` FROM subscription_plans WHERE active ORDER BY sort, code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.SubscriptionPlan
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgSubscriptionRepo) GetPlan(ctx context.Context, code string) (*models.SubscriptionPlan, error) {
+	return scanPlan(conn(ctx, r.db).QueryRow(ctx, `SELECT `+planColumns+` FROM subscription_plans WHERE code=$1`, code))
+}
+
+const subscriptionColumns = `user_id, plan_code, status, period_start, period_end, grace_until, bids_used, auto_renew,
+	next_plan_code, pending_payment_id, pending_kind, pending_plan_code, created_at, updated_at`
+
+func scanSubscription(row pgx.Row) (*models.Subscription, error) {
+	s := &models.Subscription{}
+	if err := row.Scan(&s.UserID, &s.PlanCode, &s.Status, &s.PeriodStart, &s.PeriodEnd, &s.GraceUntil, &s.BidsUsed, &s.AutoRenew,
+		&s.NextPlanCode, &s.PendingPaymentID, &s.PendingKind, &s.PendingPlanCode, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *pgSubscriptionRepo) Get(ctx context.Context, userID string) (*models.Subscription, error) {
+	return scanSubscription(conn(ctx, r.db).QueryRow(ctx, `SELECT `+subscriptionColumns+` FROM executor_subscriptions WHERE user_id=$1`, userID))
+}
+
+func (r *pgSubscriptionRepo) GetForUpdate(ctx context.Context, userID string) (*models.Subscription, error) {
+	return scanSubscription(conn(ctx, r.db).QueryRow(ctx, `SELECT `+subscriptionColumns+` FROM executor_subscriptions WHERE user_id=$1 FOR UPDATE`, userID))
+}
+
+func (r *pgSubscriptionRepo) Save(ctx context.Context, s *models.Subscription) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO executor_subscriptions (user_id, plan_code, status, period_start, period_end, grace_until,
+			bids_used, auto_renew, next_plan_code, pending_payment_id, pending_kind, pending_plan_code)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		ON CONFLICT (user_id) DO UPDATE SET plan_code=EXCLUDED.plan_code, status=EXCLUDED.status, period_start=EXCLUDED.period_start,
+			period_end=EXCLUDED.period_end, grace_until=EXCLUDED.grace_until, bids_used=EXCLUDED.bids_used, auto_renew=EXCLUDED.auto_renew,
+			next_plan_code=EXCLUDED.next_plan_code, pending_payment_id=EXCLUDED.pending_payment_id, pending_kind=EXCLUDED.pending_kind,
+			pending_plan_code=EXCLUDED.pending_plan_code, updated_at=now()
+		RETURNING created_at, updated_at`,
+		s.UserID, s.PlanCode, s.Status, s.PeriodStart, s.PeriodEnd, s.GraceUntil, s.BidsUsed, s.AutoRenew,
+		s.NextPlanCode, s.PendingPaymentID, s.PendingKind, s.PendingPlanCode).Scan(&s.CreatedAt, &s.UpdatedAt)
+}
+
+func (r *pgSubscriptionRepo) UseQuota(ctx context.Context, userID string) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `UPDATE executor_subscriptions s SET bids_used = s.bids_used + 1, updated_at=now()
+		FROM subscription_plans p
+		WHERE s.user_id=$1 AND p.code = s.plan_code AND s.status IN ('active','grace') AND s.bids_used < p.bid_quota`, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgSubscriptionRepo) Due(ctx context.Context, now time.Time) ([]*models.Subscription, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT `+subscriptionColumns+` FROM executor_subscriptions
+		WHERE (status='active' AND period_end <= $1) OR (status='grace' AND grace_until <= $1)
+		ORDER BY period_end`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgSubscriptionRepo) Badges(ctx context.Context, userIDs []string) (map[string]string, error) {
+	out := map[string]string{}
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT s.user_id, p.badge FROM executor_subscriptions s JOIN subscription_plans p ON p.code = s.plan_code
+		WHERE s.user_id = ANY($1) AND s.status IN ('active','grace') AND p.badge <> ''`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, badge string
+		if err := rows.Scan(&id, &badge); err != nil {
+			return nil, err
+		}
+		out[id] = badge
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TaxRepo — расписание ставок налогов (tax_rates)
+type TaxRepo interface {
+	Rates(ctx context.Context) ([]*models.TaxRate, error)
+	// AddRate — ErrDuplicateRate, если у кода уже есть ставка с этой даты
+	AddRate(ctx context.Context, r *models.TaxRate) error
+	// DeleteRate removes a rate that has not come into force yet (effective_from > today)
+	DeleteRate(ctx context.Context, code string, from time.Time) (bool, error)
+}
+
+var ErrDuplicateRate = errors.New("rate with this effective date already exists")
+
+type pgTaxRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxRepo(db *pgxpool.Pool) TaxRepo { return &pgTaxRepo{db: db} }
+
+func (r *pgTaxRepo) Rates(ctx context.Context) ([]*models.TaxRate, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT code, rate, effective_from, note, created_by, created_at FROM tax_rates ORDER BY code, effective_from`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.TaxRate
+	for rows.Next() {
+		t := &models.TaxRate{}
+		if err := rows.Scan(&t.Code, &t.Rate, &t.EffectiveFrom, &t.Note, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgTaxRepo) AddRate(ctx context.Context, t *models.TaxRate) error {
+	err := conn(ctx, r.db).QueryRow(ctx, `INSERT INTO tax_rates (code, rate, effective_from, note, created_by) VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT (code, effective_from) DO NOTHING RETURNING created_at`,
+		t.Code, t.Rate, t.EffectiveFrom, t.Note, t.CreatedBy).Scan(&t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDuplicateRate
+	}
+	return err
+}
+
+func (r *pgTaxRepo) DeleteRate(ctx context.Context, code string, from time.Time) (bool, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM tax_rates WHERE code=$1 AND effective_from=$2 AND effective_from > CURRENT_DATE`, code, from)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
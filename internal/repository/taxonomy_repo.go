@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TaxonomyRepo — справочники категорий и регионов (КАТО)
+type TaxonomyRepo interface {
+	// ListCategories / ListRegions return all rows, inactive included, by sort
+	ListCategories(ctx context.Context) ([]*models.Category, error)
+	ListRegions(ctx context.Context) ([]*models.Region, error)
+	CreateCategory(ctx context.Context, c *models.Category) error
+	UpdateCategory(ctx context.Context, c *models.Category) error
+	DeleteCategory(ctx context.Context, code string) error
+	CreateRegion(ctx context.Context, r *models.Region) error
+	UpdateRegion(ctx context.Context, r *models.Region) error
+	DeleteRegion(ctx context.Context, kato string) error
+	// CategoryInUse / RegionInUse — есть ли ссылки из заказов, профилей, поисков или дочерних записей
+	CategoryInUse(ctx context.Context, code string) (bool, error)
+	RegionInUse(ctx context.Context, kato string) (bool, error)
+	ListUnmapped(ctx context.Context, page, perPage int) ([]*models.TaxonomyUnmapped, int, error)
+}
+
+type pgTaxonomyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxonomyRepo(db *pgxpool.Pool) TaxonomyRepo { return &pgTaxonomyRepo{db: db} }
+
+func (r *pgTaxonomyRepo) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT code, parent_code, name_ru, name_kk, name_en, aliases, sort, active, created_at, updated_at
+		FROM categories ORDER BY sort, code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Category
+	for rows.Next() {
+		c := &models.Category{}
+		if err := rows.Scan(&c.Code, &c.ParentCode, &c.NameRu, &c.NameKk, &c.NameEn, &c.Aliases, &c.Sort, &c.Active, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgTaxonomyRepo) ListRegions(ctx context.Context) ([]*models.Region, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT kato, parent_kato, name_ru, name_kk, name_en, aliases, sort, active, created_at, updated_at
+		FROM regions ORDER BY sort, kato`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*models.Region
+	for rows.Next() {
+		g := &models.Region{}
+		if err := rows.Scan(&g.KATO, &g.ParentKATO, &g.NameRu, &g.NameKk, &g.NameEn, &g.Aliases, &g.Sort, &g.Active, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+func (r *pgTaxonomyRepo) CreateCategory(ctx context.Context, c *models.Category) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO categories (code, parent_code, name_ru, name_kk, name_en, aliases, sort, active)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING created_at, updated_at`,
+		c.Code, c.ParentCode, c.NameRu, c.NameKk, c.NameEn, c.Aliases, c.Sort, c.Active).Scan(&c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *pgTaxonomyRepo) UpdateCategory(ctx context.Context, c *models.Category) error {
+	return conn(ctx, r.db).QueryRow(ctx, `UPDATE categories SET name_ru=$2, name_kk=$3, name_en=$4, aliases=$5, sort=$6, active=$7, updated_at=now()
+		WHERE code=$1 RETURNING created_at, updated_at`,
+		c.Code, c.NameRu, c.NameKk, c.NameEn, c.Aliases, c.Sort, c.Active).Scan(&c.CreatedAt, &c.UpdatedAt)
+}
+
+func (r *pgTaxonomyRepo) DeleteCategory(ctx context.Context, code string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM categories WHERE code=$1`, code)
+	return err
+}
+
+func (r *pgTaxonomyRepo) CreateRegion(ctx context.Context, g *models.Region) error {
+	return conn(ctx, r.db).QueryRow(ctx, `INSERT INTO regions (kato, parent_kato, name_ru, name_kk, name_en, aliases, sort, active)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8) RETURNING created_at, updated_at`,
+		g.KATO, g.ParentKATO, g.NameRu, g.NameKk, g.NameEn, g.Aliases, g.Sort, g.Active).Scan(&g.CreatedAt, &g.UpdatedAt)
+}
+
+func (r *pgTaxonomyRepo) UpdateRegion(ctx context.Context, g *models.Region) error {
+	return conn(ctx, r.db).QueryRow(ctx, `UPDATE regions SET name_ru=$2, name_kk=$3, name_en=$4, aliases=$5, sort=$6, active=$7, updated_at=now()
+		WHERE kato=$1 RETURNING created_at, updated_at`,
+		g.KATO, g.NameRu, g.NameKk, g.NameEn, g.Aliases, g.Sort, g.Active).Scan(&g.CreatedAt, &g.UpdatedAt)
+}
+
+func (r *pgTaxonomyRepo) DeleteRegion(ctx context.Context, kato string) error {
+	_, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM regions WHERE kato=$1`, kato)
+	return err
+}
+
+func (r *pgTaxonomyRepo) CategoryInUse(ctx context.Context, code string) (bool, error) {
+	var used bool
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_code=$1)
+		OR EXISTS (SELECT 1 FROM orders WHERE category=$1 OR subcategory=$1)
+		OR EXISTS (SELECT 1 FROM executor_profiles WHERE $1 = ANY(specializations))
+		OR EXISTS (SELECT 1 FROM saved_searches WHERE filters->>'category' = $1)`, code).Scan(&used)
+	return used, err
+}
+
+func (r *pgTaxonomyRepo) RegionInUse(ctx context.Context, kato string) (bool, error) {
+	var used bool
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM regions WHERE parent_kato=$1)
+		OR EXISTS (SELECT 1 FROM orders WHERE region=$1)
+		OR EXISTS (SELECT 1 FROM executor_profiles WHERE $1 = ANY(regions))
+		OR EXISTS (SELECT 1 FROM saved_searches WHERE filters->>'region' = $1)`, kato).Scan(&used)
+	return used, err
+}
+
+func (r *pgTaxonomyRepo) ListUnmapped(ctx context.Context, page, perPage int) ([]*models.TaxonomyUnmapped, int, error) {
+	var total int
+	if err := conn(ctx, r.db).QueryRow(ctx, `SELECT count(*) FROM taxonomy_unmapped`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := conn(ctx, r.db).Query(ctx, `SELECT id, object_type, object_id, field, value, created_at FROM taxonomy_unmapped
+		ORDER BY field, value, id LIMIT $1 OFFSET $2`, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var out []*models.TaxonomyUnmapped
+	for rows.Next() {
+		u := &models.TaxonomyUnmapped{}
+		if err := rows.Scan(&u.ID, &u.ObjectType, &u.ObjectID, &u.Field, &u.Value, &u.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, u)
+	}
+	return out, total, rows.Err()
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/auth"
+)
+
+const impersonationTTL = 15 * time.Minute
+
+var (
+	ErrInvalidStatus     = &ServiceError{"invalid status"}
+	ErrCannotImpersonate = &ServiceError{"cannot impersonate this user"}
+	ErrBidChosen         = &ServiceError{"the chosen bid of an order cannot be hidden"}
+)
+
+var paymentStatuses = map[string]bool{
+	"initiated": true, "redirected": true, "success": true, "failed": true, "expired": true, "refunded": true,
+	"held": true, "released": true, "split": true, // order_escrow, mentoring_session
+}
+
+// AdminService — операции бэк-офиса. Каждое действие пишется в audit_logs
+// с обязательной причиной (reason) в той же транзакции, что и само изменение.
+type AdminService struct {
+	users       *UserUsecase
+	userRepo    repository.UserRepo
+	orderRepo   repository.OrderRepo
+	bidRepo     repository.BidRepo
+	paymentRepo repository.PaymentRepo
+	chatRepo    repository.ChatRepo
+	audit       repository.AuditRepo
+	events      *EventService
+	tx          repository.TxRunner
+	jwt         auth.JWTConfig
+}
+
+func NewAdminService(uc *UserUsecase, ur repository.UserRepo, or repository.OrderRepo, br repository.BidRepo, pr repository.PaymentRepo, cr repository.ChatRepo, ar repository.AuditRepo, ev *EventService, tx repository.TxRunner, jwtCfg auth.JWTConfig) *AdminService {
+	return &AdminService{users: uc, userRepo: ur, orderRepo: or, bidRepo: br, paymentRepo: pr, chatRepo: cr, audit: ar, events: ev, tx: tx, jwt: jwtCfg}
+}
+
+func (s *AdminService) log(ctx context.Context, adminID, action, objectType, objectID, reason string, extra map[string]interface{}) error {
+	payload := map[string]interface{}{"reason": reason}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	return s.audit.Add(ctx, adminID, "admin."+action, objectType, objectID, payload)
+}
+
+// --- users
+
+func (s *AdminService) SearchUsers(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.User, int, error) {
+	return s.userRepo.Search(filters, page, perPage)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, id string) (*models.User, error) {
+	return s.users.GetProfile(ctx, id)
+}
+
+// SetUserStatus — suspend (suspended), unsuspend (active), delete (deleted, soft)
+func (s *AdminService) SetUserStatus(ctx context.Context, adminID, userID, status, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	if status != "active" && status != "suspended" && status != "deleted" {
+		return ErrInvalidStatus
+	}
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.users.SetStatus(ctx, userID, status); err != nil {
+			return err
+		}
+		return s.log(ctx, adminID, "user_status", "user", userID, reason, map[string]interface{}{"old_status": u.Status, "new_status": status})
+	})
+	if err != nil {
+		return err
+	}
+	// кэш мог перечитать старый статус до commit
+	s.users.tokenState.Invalidate(userID)
+	return nil
+}
+
+// Impersonate выдаёт read-only access-токен от имени пользователя
+func (s *AdminService) Impersonate(ctx context.Context, adminID, userID, reason string) (string, error) {
+	if reason == "" {
+		return "", ErrReasonRequired
+	}
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if u.Status != "active" || u.HasRole(models.RoleAdmin) {
+		return "", ErrCannotImpersonate
+	}
+	tok, err := auth.GenerateImpersonationToken(s.jwt, u.ID, u.Role, u.TokenVersion, adminID, impersonationTTL)
+	if err != nil {
+		return "", err
+	}
+	if err := s.log(ctx, adminID, "impersonate", "user", userID, reason, nil); err != nil {
+		return "", err
+	}
+	return tok, nil
+}
+
+// --- orders
+
+// SetOrderStatus — force-cancel (cancelled) или archive (archived)
+func (s *AdminService) SetOrderStatus(ctx context.Context, adminID, orderID, status, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	if status != "cancelled" && status != "archived" {
+		return ErrInvalidStatus
+	}
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if o.Status == "disputed" {
+		return ErrOrderWrongState
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.SetStatus(ctx, orderID, status); err != nil {
+			return err
+		}
+		return s.log(ctx, adminID, "order_status", "order", orderID, reason, map[string]interface{}{"old_status": o.Status, "new_status": status})
+	})
+	if err != nil {
+		return err
+	}
+	_ = s.chatRepo.ArchiveByOrder(ctx, orderID)
+	s.events.Publish(ctx, orderParticipants(ctx, s.bidRepo, o), models.EventOrderStatusChanged, map[string]interface{}{
+		"order_id": orderID, "status": status,
+	})
+	return nil
+}
+
+// --- bids
+
+func (s *AdminService) HideBid(ctx context.Context, adminID, bidID, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	b, err := s.bidRepo.GetByID(ctx, bidID)
+	if err != nil {
+		return err
+	}
+	// выбранная ставка держит сделку (эскроу, сдача работы) — её не скрыть
+	if o, err := s.orderRepo.GetByID(ctx, b.OrderID); err != nil {
+		return err
+	} else if o.ChosenBidID != nil && *o.ChosenBidID == b.ID {
+		return ErrBidChosen
+	}
+	return s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.bidRepo.Hide(ctx, bidID); err != nil {
+			return err
+		}
+		return s.log(ctx, adminID, "bid_hide", "bid", bidID, reason, nil)
+	})
+}
+
+// --- payments
+
+func (s *AdminService) ListPayments(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Payment, int, error) {
+	return s.paymentRepo.List(ctx, filters, page, perPage)
+}
+
+func (s *AdminService) GetPayment(ctx context.Context, id string) (*models.Payment, error) {
+	return s.paymentRepo.GetByID(ctx, id)
+}
+
+// SetPaymentStatus — ручная правка «зависшего» платежа
+func (s *AdminService) SetPaymentStatus(ctx context.Context, adminID, paymentID, status, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	if !paymentStatuses[status] {
+		return ErrInvalidStatus
+	}
+	p, err := s.paymentRepo.GetByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.UpdateStatus(ctx, paymentID, status); err != nil {
+			return err
+		}
+		return s.log(ctx, adminID, "payment_status", "payment", paymentID, reason, map[string]interface{}{"old_status": p.Status, "new_status": status})
+	})
+	if err != nil {
+		return err
+	}
+	if status == "success" && p.Status != "success" && p.UserID != nil {
+		s.events.Publish(ctx, []string{*p.UserID}, models.EventPaymentSucceeded, map[string]interface{}{
+			"payment_id": p.ID, "related_type": p.RelatedType, "related_id": p.RelatedID,
+		})
+	}
+	return nil
+}
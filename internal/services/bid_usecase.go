@@ -2,25 +2,65 @@ package services
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/jackc/pgx/v5"
 )
 
+// bidFee — плата за отклик без подписки, KZT (с подпиской — overage_fee тарифа);
+// по приглашению снижается на fee_discount %
+const bidFee int64 = 500
+
 type BidService struct {
 	bidRepo     repository.BidRepo
 	paymentRepo repository.PaymentRepo
+	orderRepo   repository.OrderRepo
+	events      *EventService
+	files       *FileService
+	reviewRepo  repository.ReviewRepo
+	invitations repository.InvitationRepo
+	subs        *SubscriptionService
+	tax         *TaxService
+	tx          repository.TxRunner
 }
 
-func NewBidService(br repository.BidRepo, pr repository.PaymentRepo) *BidService {
-	return &BidService{bidRepo: br, paymentRepo: pr}
+func NewBidService(br repository.BidRepo, pr repository.PaymentRepo, or repository.OrderRepo, ev *EventService, fs *FileService, rr repository.ReviewRepo, ir repository.InvitationRepo, ss *SubscriptionService, tx *TaxService, txr repository.TxRunner) *BidService {
+	return &BidService{bidRepo: br, paymentRepo: pr, orderRepo: or, events: ev, files: fs, reviewRepo: rr, invitations: ir, subs: ss, tax: tx, tx: txr}
 }
 
 func (s *BidService) Create(ctx context.Context, b *models.Bid) error {
+	o, err := s.orderRepo.GetByID(ctx, b.OrderID)
+	if err != nil {
+		return err
+	}
+	// откликнуться можно только на заказ в ленте
+	if o.Status != "published" {
+		return ErrOrderWrongState
+	}
+	inv, err := s.invitations.GetOpen(ctx, o.ID, b.ExecutorID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if inv == nil && o.Visibility == models.OrderPrivate {
+		return ErrNotInvited
+	}
+	plan, err := s.subs.Plan(ctx, b.ExecutorID)
+	if err != nil {
+		return err
+	}
+	fee := bidFee
+	if plan != nil {
+		fee, b.Priority = plan.OverageFee, plan.Priority
+	}
+	if inv != nil {
+		fee = fee * int64(100-inv.FeeDiscount) / 100
+	}
+
 	// prepare bid
 	b.ID = uuid.NewString()
 	b.Status = "pending_payment"
@@ -28,34 +68,73 @@ func (s *BidService) Create(ctx context.Context, b *models.Bid) error {
 	b.CreatedAt = now
 	b.UpdatedAt = now
 
-	// insert bid
-	if err := s.bidRepo.Create(ctx, b); err != nil {
-		fmt.Printf("BidService.Create: bidRepo.Create error: %v\n", err)
+	atts, err := s.files.Attach(ctx, b.ExecutorID, models.FileLinkBid, b.ID, b.Attachments)
+	if err != nil {
 		return err
 	}
-	fmt.Printf("BidService.Create: bid inserted OK, id=%s\n", b.ID)
-
-	// prepare payment
-	p := &models.Payment{
-		ID:          uuid.NewString(),
-		UserID:      &b.ExecutorID,
-		RelatedType: "bid_fee",
-		RelatedID:   &b.ID,
-		Provider:    "mock",
-		Amount:      500,
-		Currency:    "KZT",
-		Status:      "initiated",
-	}
+	b.Attachments = atts
 
-	fmt.Printf("BidService.Create: about to insert payment: id=%s related_type=%s related_id=%s user=%v\n", p.ID, p.RelatedType, p.RelatedID, p.UserID)
+	// ставка, закрытие приглашения, списание квоты и платёж — вместе или никак:
+	// иначе остаётся ставка pending_payment без платежа или потраченная квота
+	paid := false
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.bidRepo.Create(ctx, b); err != nil {
+			return err
+		}
+		if inv != nil {
+			if err := s.invitations.MarkBidPlaced(ctx, inv.ID, b.ID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					// приглашение отозвали после проверки
+					return ErrNotInvited
+				}
+				return err
+			}
+		}
+		fee := fee
+		// квота тарифа расходуется раньше платы; бесплатная по приглашению ставка её не тратит
+		if fee > 0 {
+			covered, err := s.subs.UseQuota(ctx, b.ExecutorID)
+			if err != nil {
+				return err
+			}
+			if covered {
+				fee = 0
+			}
+		}
+		// бесплатная ставка (квота или приглашение с полной скидкой) сразу видна клиенту
+		if fee == 0 {
+			paid = true
+			return s.bidRepo.MarkPaid(ctx, b.ID, now)
+		}
 
-	// insert payment
-	if err := s.paymentRepo.Create(ctx, p); err != nil {
-		fmt.Printf("BidService.Create: paymentRepo.Create error: %v\n", err)
+		// prepare payment
+		p := &models.Payment{
+			ID:          uuid.NewString(),
+			UserID:      &b.ExecutorID,
+			RelatedType: "bid_fee",
+			RelatedID:   &b.ID,
+			Provider:    "mock",
+			Amount:      fee,
+			Currency:    "KZT",
+			Status:      "initiated",
+			Items:       feeItems(feeName("bid_fee"), fee),
+		}
+		if err := s.tax.Apply(ctx, p); err != nil {
+			return err
+		}
+		return s.paymentRepo.Create(ctx, p)
+	})
+	if err != nil {
+		s.files.Release(ctx, b.ExecutorID, models.FileLinkBid, b.ID)
 		return err
 	}
-	fmt.Printf("BidService.Create: payment inserted OK, id=%s\n", p.ID)
 
+	if paid {
+		b.Status, b.PaidAt, b.VisibleToClient = "paid", &now, true
+		s.events.Publish(ctx, []string{o.ClientUserID}, models.EventBidNew, map[string]interface{}{
+			"bid_id": b.ID, "order_id": o.ID, "executor_id": b.ExecutorID, "price": b.Price,
+		})
+	}
 	return nil
 }
 func (s *BidService) Pay(ctx context.Context, bidID string) error {
@@ -63,17 +142,78 @@ func (s *BidService) Pay(ctx context.Context, bidID string) error {
 	if err := s.bidRepo.MarkPaid(ctx, bidID, now); err != nil {
 		return err
 	}
+	b, err := s.bidRepo.GetByID(ctx, bidID)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, []string{b.ExecutorID}, models.EventPaymentSucceeded, map[string]interface{}{
+		"related_type": "bid_fee", "related_id": b.ID, "order_id": b.OrderID,
+	})
+	// оплаченная ставка становится видна клиенту
+	if o, err := s.orderRepo.GetByID(ctx, b.OrderID); err == nil {
+		s.events.Publish(ctx, []string{o.ClientUserID}, models.EventBidNew, map[string]interface{}{
+			"bid_id": b.ID, "order_id": o.ID, "executor_id": b.ExecutorID, "price": b.Price,
+		})
+	}
 	return nil
 }
 
 // New methods required by handler:
 
-func (s *BidService) ListByOrder(ctx context.Context, orderID string) ([]*models.Bid, error) {
-	return s.bidRepo.ListByOrder(ctx, orderID)
+// ListByOrder returns the bids with each executor's aggregated rating. Ставки
+// приватного заказа видят только те, кому виден заказ (pgx.ErrNoRows остальным)
+func (s *BidService) ListByOrder(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Bid, error) {
+	if err := s.checkOrderAccess(ctx, orderID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	list, err := s.bidRepo.ListByOrder(ctx, orderID)
+	if err != nil || len(list) == 0 {
+		return list, err
+	}
+	ids := make([]string, 0, len(list))
+	for _, b := range list {
+		ids = append(ids, b.ExecutorID)
+	}
+	ratings, err := s.reviewRepo.Summaries(ctx, ids, models.RoleClient)
+	if err != nil {
+		return nil, err
+	}
+	badges, err := s.subs.Badges(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range list {
+		b.ExecutorRating = ratings[b.ExecutorID]
+		b.ExecutorBadge = badges[b.ExecutorID]
+	}
+	return list, nil
 }
 
-func (s *BidService) GetByID(ctx context.Context, id string) (*models.Bid, error) {
-	return s.bidRepo.GetByID(ctx, id)
+func (s *BidService) GetByID(ctx context.Context, id, userID string, isAdmin bool) (*models.Bid, error) {
+	b, err := s.bidRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOrderAccess(ctx, b.OrderID, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// checkOrderAccess — как OrderService.GetByID: скрытый заказ для пользователя не существует
+func (s *BidService) checkOrderAccess(ctx context.Context, orderID, userID string, isAdmin bool) error {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil || isAdmin {
+		return err
+	}
+	ok, err := canSeeOrder(ctx, s.invitations, o, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
 func (s *BidService) Delete(ctx context.Context, id string) error {
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	chatMaxMessageLen = 4000
+	chatDefaultLimit  = 50
+	chatMaxLimit      = 200
+)
+
+var (
+	ErrChatForbidden    = &ServiceError{"no access to this conversation"}
+	ErrChatLocked       = &ServiceError{"chat is available only for a visible (paid) bid"}
+	ErrChatArchived     = &ServiceError{"conversation is archived"}
+	ErrChatEmptyMessage = &ServiceError{"message is empty or too long"}
+	ErrExecutorRequired = &ServiceError{"executor_id is required"}
+)
+
+// terminalOrderStatuses — после них переписка только для чтения
+var terminalOrderStatuses = map[string]bool{"completed": true, "cancelled": true, "archived": true}
+
+// ChatService — переписка клиента и исполнителя в рамках заказа.
+// Диалог открывается только при видимой клиенту ставке исполнителя.
+type ChatService struct {
+	chatRepo  repository.ChatRepo
+	orderRepo repository.OrderRepo
+	bidRepo   repository.BidRepo
+	audit     repository.AuditRepo
+	events    *EventService
+	files     *FileService
+}
+
+func NewChatService(cr repository.ChatRepo, or repository.OrderRepo, br repository.BidRepo, ar repository.AuditRepo, ev *EventService, fs *FileService) *ChatService {
+	return &ChatService{chatRepo: cr, orderRepo: or, bidRepo: br, audit: ar, events: ev, files: fs}
+}
+
+// Open returns (creating if needed) the conversation for the order. The client
+// must pass executorID; an executor always opens the chat for his own bid.
+func (s *ChatService) Open(ctx context.Context, orderID, userID, executorID string) (*models.Conversation, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.ClientUserID == userID {
+		if executorID == "" {
+			return nil, ErrExecutorRequired
+		}
+	} else {
+		executorID = userID
+	}
+	if terminalOrderStatuses[o.Status] {
+		return nil, ErrChatArchived
+	}
+	bids, err := s.bidRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	var bid *models.Bid
+	for _, b := range bids {
+		if b.ExecutorID == executorID && b.VisibleToClient {
+			bid = b
+			break
+		}
+	}
+	if bid == nil {
+		return nil, ErrChatLocked
+	}
+	return s.chatRepo.GetOrCreate(ctx, &models.Conversation{
+		ID:         uuid.NewString(),
+		OrderID:    orderID,
+		ClientID:   o.ClientUserID,
+		ExecutorID: executorID,
+		BidID:      bid.ID,
+	})
+}
+
+func (s *ChatService) ListMine(ctx context.Context, userID string) ([]*models.Conversation, error) {
+	return s.chatRepo.ListByUser(ctx, userID)
+}
+
+// access checks participation and bid visibility; archives the conversation
+// lazily if the order reached a terminal state.
+func (s *ChatService) access(ctx context.Context, convID, userID string) (*models.Conversation, bool, error) {
+	c, err := s.chatRepo.GetByID(ctx, convID)
+	if err != nil {
+		return nil, false, err
+	}
+	isClient := c.ClientID == userID
+	if !isClient && c.ExecutorID != userID {
+		return nil, false, ErrChatForbidden
+	}
+	b, err := s.bidRepo.GetByID(ctx, c.BidID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !b.VisibleToClient {
+		return nil, false, ErrChatLocked
+	}
+	if err := s.syncArchived(ctx, c); err != nil {
+		return nil, false, err
+	}
+	return c, isClient, nil
+}
+
+func (s *ChatService) syncArchived(ctx context.Context, c *models.Conversation) error {
+	if c.ArchivedAt != nil {
+		return nil
+	}
+	o, err := s.orderRepo.GetByID(ctx, c.OrderID)
+	if err != nil {
+		return err
+	}
+	if terminalOrderStatuses[o.Status] {
+		if err := s.chatRepo.Archive(ctx, c.ID); err != nil {
+			return err
+		}
+		now := time.Now()
+		c.ArchivedAt = &now
+	}
+	return nil
+}
+
+func (s *ChatService) Send(ctx context.Context, convID, userID, body string, attachments []string) (*models.Message, error) {
+	c, _, err := s.access(ctx, convID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c.ArchivedAt != nil {
+		return nil, ErrChatArchived
+	}
+	body = strings.TrimSpace(body)
+	if (body == "" && len(attachments) == 0) || len([]rune(body)) > chatMaxMessageLen {
+		return nil, ErrChatEmptyMessage
+	}
+	m := &models.Message{
+		ID:             uuid.NewString(),
+		ConversationID: c.ID,
+		SenderID:       userID,
+		Body:           body,
+	}
+	if m.Attachments, err = s.files.Attach(ctx, userID, models.FileLinkMessage, m.ID, attachments); err != nil {
+		return nil, err
+	}
+	if err := s.chatRepo.AddMessage(ctx, m); err != nil {
+		s.files.Release(ctx, userID, models.FileLinkMessage, m.ID)
+		return nil, err
+	}
+	// отправитель прочитал всё до своего сообщения
+	_ = s.chatRepo.MarkRead(ctx, c.ID, c.ClientID == userID, m.CreatedAt)
+
+	to := c.ClientID
+	if to == userID {
+		to = c.ExecutorID
+	}
+	s.events.Publish(ctx, []string{to}, models.EventChatMessage, map[string]interface{}{
+		"conversation_id": c.ID, "order_id": c.OrderID, "message_id": m.ID, "sender_id": userID, "body": m.Body,
+	})
+	return m, nil
+}
+
+func (s *ChatService) Messages(ctx context.Context, convID, userID string, before *time.Time, limit int) ([]*models.Message, error) {
+	c, _, err := s.access(ctx, convID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.listMessages(ctx, c, before, limit)
+}
+
+// MarkRead — read receipt: всё до текущего момента прочитано
+func (s *ChatService) MarkRead(ctx context.Context, convID, userID string) error {
+	c, isClient, err := s.access(ctx, convID, userID)
+	if err != nil {
+		return err
+	}
+	return s.chatRepo.MarkRead(ctx, c.ID, isClient, time.Now())
+}
+
+func (s *ChatService) listMessages(ctx context.Context, c *models.Conversation, before *time.Time, limit int) ([]*models.Message, error) {
+	if limit <= 0 {
+		limit = chatDefaultLimit
+	}
+	if limit > chatMaxLimit {
+		limit = chatMaxLimit
+	}
+	list, err := s.chatRepo.ListMessages(ctx, c.ID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range list {
+		// прочитано, если вторая сторона читала диалог после отправки
+		readAt := c.ClientLastReadAt
+		if m.SenderID == c.ClientID {
+			readAt = c.ExecutorLastReadAt
+		}
+		m.Read = readAt != nil && !readAt.Before(m.CreatedAt)
+	}
+	return list, nil
+}
+
+// --- admin (разбор споров); просмотр пишется в audit_logs
+
+func (s *ChatService) AdminListByOrder(ctx context.Context, adminID, orderID, reason string) ([]*models.Conversation, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	list, err := s.chatRepo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, adminID, "admin.chat_list", "order", orderID, map[string]interface{}{"reason": reason})
+	return list, nil
+}
+
+func (s *ChatService) AdminMessages(ctx context.Context, adminID, convID, reason string, before *time.Time, limit int) ([]*models.Message, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	c, err := s.chatRepo.GetByID(ctx, convID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.listMessages(ctx, c, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, adminID, "admin.chat_view", "conversation", convID, map[string]interface{}{"reason": reason, "order_id": c.OrderID})
+	return list, nil
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	disputeMaxTextLen  = 4000
+	disputeSLAInterval = 10 * time.Minute
+	disputeMaxAdmins   = 100
+)
+
+var (
+	ErrDisputeForbidden  = &ServiceError{"no access to this dispute"}
+	ErrDisputeResolved   = &ServiceError{"dispute is already resolved"}
+	ErrDisputeTextLength = &ServiceError{"text is empty or too long"}
+	ErrNotArbiter        = &ServiceError{"only the assigned arbiter can resolve the dispute"}
+	ErrArbiterNotAdmin   = &ServiceError{"arbiter must be an active admin"}
+	ErrInvalidResolution = &ServiceError{"resolution must be refund, split or release"}
+	ErrInvalidSplit      = &ServiceError{"executor_amount must be greater than 0 and less than the held amount"}
+	ErrNoEscrowHeld      = &ServiceError{"no funds are held for this order"}
+)
+
+// disputableStatuses — из каких статусов заказа можно открыть спор
+var disputableStatuses = map[string]bool{"in_progress": true, "client_review": true}
+
+// DisputeService — спор по заказу: открывает клиент или выбранный исполнитель,
+// разбирает назначенный арбитр (админ), решение двигает удержанные средства.
+// Все действия пишутся в audit_logs с префиксом dispute.
+type DisputeService struct {
+	repo        repository.DisputeRepo
+	orderRepo   repository.OrderRepo
+	bidRepo     repository.BidRepo
+	paymentRepo repository.PaymentRepo
+	chatRepo    repository.ChatRepo
+	userRepo    repository.UserRepo
+	audit       repository.AuditRepo
+	events      *EventService
+	files       *FileService
+	tx          repository.TxRunner
+	assignSLA   time.Duration
+	resolveSLA  time.Duration
+	onCompleted []func(ctx context.Context, o *models.Order)
+}
+
+func NewDisputeService(dr repository.DisputeRepo, or repository.OrderRepo, br repository.BidRepo, pr repository.PaymentRepo, cr repository.ChatRepo, ur repository.UserRepo, ar repository.AuditRepo, ev *EventService, fs *FileService, tx repository.TxRunner, assignSLA, resolveSLA time.Duration) *DisputeService {
+	return &DisputeService{repo: dr, orderRepo: or, bidRepo: br, paymentRepo: pr, chatRepo: cr, userRepo: ur, audit: ar, events: ev, files: fs, tx: tx,
+		assignSLA: assignSLA, resolveSLA: resolveSLA}
+}
+
+// OnCompleted registers a hook run when a resolution completes the order
+// (release/split). Must be called during initialization.
+func (s *DisputeService) OnCompleted(fn func(ctx context.Context, o *models.Order)) {
+	s.onCompleted = append(s.onCompleted, fn)
+}
+
+// Open — спор по заказу в работе или на проверке; заказ переходит в disputed
+func (s *DisputeService) Open(ctx context.Context, orderID, userID, reason string, evidence []string) (*models.Dispute, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !disputableStatuses[o.Status] {
+		return nil, ErrOrderWrongState
+	}
+	executorID := ""
+	if o.ChosenBidID != nil {
+		if b, err := s.bidRepo.GetByID(ctx, *o.ChosenBidID); err == nil {
+			executorID = b.ExecutorID
+		}
+	}
+	if executorID == "" || (userID != o.ClientUserID && userID != executorID) {
+		return nil, ErrDisputeForbidden
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	if len([]rune(reason)) > disputeMaxTextLen {
+		return nil, ErrDisputeTextLength
+	}
+	d := &models.Dispute{
+		ID:          uuid.NewString(),
+		OrderID:     orderID,
+		OpenedBy:    userID,
+		ClientID:    o.ClientUserID,
+		ExecutorID:  executorID,
+		PriorStatus: o.Status,
+		Reason:      reason,
+		AssignDueAt: time.Now().Add(s.assignSLA),
+	}
+	if d.Evidence, err = s.files.Attach(ctx, userID, models.FileLinkDispute, d.ID, evidence); err != nil {
+		return nil, err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, d); err != nil {
+			return err
+		}
+		// заказ мог быть принят (эскроу выплачен) после проверки статуса
+		ok, err := s.orderRepo.ChangeStatus(ctx, orderID, o.Status, "disputed")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		if err := s.orderRepo.AddHistory(ctx, userID, "open_dispute", "order", orderID, map[string]interface{}{"dispute_id": d.ID}); err != nil {
+			return err
+		}
+		return s.audit.Add(ctx, userID, "dispute.open", "dispute", d.ID, map[string]interface{}{
+			"order_id": orderID, "reason": reason, "prior_status": o.Status, "evidence": d.Evidence,
+		})
+	})
+	if err != nil {
+		s.files.Release(ctx, userID, models.FileLinkDispute, d.ID)
+		return nil, err
+	}
+
+	payload := map[string]interface{}{"dispute_id": d.ID, "order_id": orderID, "reason": reason}
+	s.events.Publish(ctx, append(s.admins(ctx), s.otherParty(d, userID)), models.EventDisputeOpened, payload)
+	s.events.Publish(ctx, []string{d.ClientID, d.ExecutorID}, models.EventOrderStatusChanged, map[string]interface{}{
+		"order_id": orderID, "status": "disputed",
+	})
+	return d, nil
+}
+
+// Get — спор видят стороны и админы
+func (s *DisputeService) Get(ctx context.Context, id, userID string, isAdmin bool) (*models.Dispute, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && d.ClientID != userID && d.ExecutorID != userID {
+		return nil, ErrDisputeForbidden
+	}
+	return d, nil
+}
+
+func (s *DisputeService) ListByOrder(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Dispute, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && o.ClientUserID != userID {
+		if o.ChosenBidID == nil {
+			return nil, ErrDisputeForbidden
+		}
+		b, err := s.bidRepo.GetByID(ctx, *o.ChosenBidID)
+		if err != nil || b.ExecutorID != userID {
+			return nil, ErrDisputeForbidden
+		}
+	}
+	list, err := s.repo.ListByOrder(ctx, orderID)
+	if list == nil {
+		list = []*models.Dispute{}
+	}
+	return list, err
+}
+
+func (s *DisputeService) Messages(ctx context.Context, id, userID string, isAdmin bool) ([]*models.DisputeMessage, error) {
+	if _, err := s.Get(ctx, id, userID, isAdmin); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListMessages(ctx, id)
+	if list == nil {
+		list = []*models.DisputeMessage{}
+	}
+	return list, err
+}
+
+// AddMessage — переписка по спору между сторонами и арбитром; после решения закрыта
+func (s *DisputeService) AddMessage(ctx context.Context, id, userID, body string, attachments []string, isAdmin bool) (*models.DisputeMessage, error) {
+	d, err := s.Get(ctx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DisputeResolved {
+		return nil, ErrDisputeResolved
+	}
+	body = strings.TrimSpace(body)
+	if (body == "" && len(attachments) == 0) || len([]rune(body)) > disputeMaxTextLen {
+		return nil, ErrDisputeTextLength
+	}
+	m := &models.DisputeMessage{
+		ID:        uuid.NewString(),
+		DisputeID: d.ID,
+		AuthorID:  userID,
+		Body:      body,
+	}
+	if m.Attachments, err = s.files.Attach(ctx, userID, models.FileLinkDisputeMessage, m.ID, attachments); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddMessage(ctx, m); err != nil {
+		s.files.Release(ctx, userID, models.FileLinkDisputeMessage, m.ID)
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, userID, "dispute.message", "dispute", d.ID, map[string]interface{}{
+		"message_id": m.ID, "attachments": m.Attachments,
+	})
+
+	var to []string
+	for _, uid := range []string{d.ClientID, d.ExecutorID} {
+		if uid != userID {
+			to = append(to, uid)
+		}
+	}
+	if d.ArbiterID != nil && *d.ArbiterID != userID {
+		to = append(to, *d.ArbiterID)
+	}
+	s.events.Publish(ctx, to, models.EventDisputeMessage, map[string]interface{}{
+		"dispute_id": d.ID, "order_id": d.OrderID, "message_id": m.ID, "author_id": userID, "body": m.Body,
+	})
+	return m, nil
+}
+
+// --- admin
+
+func (s *DisputeService) List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Dispute, int, error) {
+	return s.repo.List(ctx, filters, page, perPage)
+}
+
+// Assign назначает (или переназначает) арбитра; пустой arbiterID — сам админ.
+// Срок решения отсчитывается от назначения.
+func (s *DisputeService) Assign(ctx context.Context, adminID, id, arbiterID string) (*models.Dispute, error) {
+	if arbiterID == "" {
+		arbiterID = adminID
+	}
+	u, err := s.userRepo.GetByID(arbiterID)
+	if err != nil || u.Status != "active" || !u.HasRole(models.RoleAdmin) {
+		return nil, ErrArbiterNotAdmin
+	}
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DisputeResolved {
+		return nil, ErrDisputeResolved
+	}
+	if err := s.repo.Assign(ctx, id, arbiterID, time.Now().Add(s.resolveSLA)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDisputeResolved
+		}
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, adminID, "dispute.assign", "dispute", id, map[string]interface{}{
+		"order_id": d.OrderID, "old_arbiter_id": d.ArbiterID, "arbiter_id": arbiterID,
+	})
+	s.events.Publish(ctx, []string{d.ClientID, d.ExecutorID, arbiterID}, models.EventDisputeAssigned, map[string]interface{}{
+		"dispute_id": id, "order_id": d.OrderID, "arbiter_id": arbiterID,
+	})
+	return s.repo.GetByID(ctx, id)
+}
+
+// Resolve — решение арбитра: refund (всё клиенту, заказ отменён), release
+// (всё исполнителю) или split (executorAmount исполнителю, остаток клиенту);
+// при release/split заказ завершён.
+func (s *DisputeService) Resolve(ctx context.Context, adminID, id, resolution string, executorAmount int64, note string) (*models.Dispute, error) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, ErrReasonRequired
+	}
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == models.DisputeResolved {
+		return nil, ErrDisputeResolved
+	}
+	if d.ArbiterID == nil || *d.ArbiterID != adminID {
+		return nil, ErrNotArbiter
+	}
+	var share int64
+	orderStatus := "completed"
+	switch resolution {
+	case models.ResolutionRefund:
+		share = 0
+		orderStatus = "cancelled"
+	case models.ResolutionRelease:
+		share = -1
+	case models.ResolutionSplit:
+		if executorAmount <= 0 {
+			return nil, ErrInvalidSplit
+		}
+		share = executorAmount
+	default:
+		return nil, ErrInvalidResolution
+	}
+
+	// решение, проверка суммы (под блокировкой платежей), выплата и статус
+	// заказа — одна транзакция: повторное или параллельное решение откатится целиком
+	var toExecutor, toClient int64
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		held, err := s.paymentRepo.HeldEscrow(ctx, d.OrderID)
+		if err != nil {
+			return err
+		}
+		// без удержания решение ничего не перевело бы
+		if held == 0 {
+			return ErrNoEscrowHeld
+		}
+		if resolution == models.ResolutionSplit && executorAmount >= held {
+			return ErrInvalidSplit
+		}
+		if toExecutor, toClient, err = s.paymentRepo.SettleEscrow(ctx, d.OrderID, d.ClientID, d.ExecutorID, share); err != nil {
+			return err
+		}
+		if err := s.repo.Resolve(ctx, id, resolution, toExecutor, toClient, note); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrDisputeResolved
+			}
+			return err
+		}
+		ok, err := s.orderRepo.ChangeStatus(ctx, d.OrderID, "disputed", orderStatus)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		if err := s.orderRepo.AddHistory(ctx, adminID, "resolve_dispute", "order", d.OrderID, map[string]interface{}{
+			"dispute_id": id, "resolution": resolution,
+		}); err != nil {
+			return err
+		}
+		return s.audit.Add(ctx, adminID, "dispute.resolve", "dispute", id, map[string]interface{}{
+			"order_id": d.OrderID, "resolution": resolution, "note": note, "held": held,
+			"executor_amount": toExecutor, "client_amount": toClient, "order_status": orderStatus,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = s.chatRepo.ArchiveByOrder(ctx, d.OrderID)
+
+	parties := []string{d.ClientID, d.ExecutorID}
+	s.events.Publish(ctx, parties, models.EventDisputeResolved, map[string]interface{}{
+		"dispute_id": id, "order_id": d.OrderID, "resolution": resolution,
+		"executor_amount": toExecutor, "client_amount": toClient,
+	})
+	s.events.Publish(ctx, parties, models.EventOrderStatusChanged, map[string]interface{}{
+		"order_id": d.OrderID, "status": orderStatus,
+	})
+	if orderStatus == "completed" {
+		if o, err := s.orderRepo.GetByID(ctx, d.OrderID); err == nil {
+			for _, fn := range s.onCompleted {
+				fn(ctx, o)
+			}
+		}
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// RunSLA periodically flags disputes that missed the assignment or resolution
+// deadline and alerts the arbiter (or all admins if none). Blocks until ctx is cancelled.
+func (s *DisputeService) RunSLA(ctx context.Context) {
+	t := time.NewTicker(disputeSLAInterval)
+	defer t.Stop()
+	for {
+		s.checkSLA(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *DisputeService) checkSLA(ctx context.Context) {
+	breached, err := s.repo.MarkSLABreached(ctx, time.Now())
+	if err != nil {
+		log.Printf("disputes: sla check: %v", err)
+		return
+	}
+	for _, d := range breached {
+		due := d.AssignDueAt
+		if d.Status == models.DisputeInReview && d.ResolveDueAt != nil {
+			due = *d.ResolveDueAt
+		}
+		_ = s.audit.Add(ctx, "", "dispute.sla_breached", "dispute", d.ID, map[string]interface{}{
+			"order_id": d.OrderID, "status": d.Status, "due_at": due,
+		})
+		to := s.admins(ctx)
+		if d.ArbiterID != nil {
+			to = []string{*d.ArbiterID}
+		}
+		s.events.Publish(ctx, to, models.EventDisputeSLABreached, map[string]interface{}{
+			"dispute_id": d.ID, "order_id": d.OrderID, "status": d.Status,
+		})
+	}
+}
+
+func (s *DisputeService) otherParty(d *models.Dispute, userID string) string {
+	if userID == d.ClientID {
+		return d.ExecutorID
+	}
+	return d.ClientID
+}
+
+// admins — активные администраторы, получатели оповещений о новых спорах
+func (s *DisputeService) admins(ctx context.Context) []string {
+	list, _, err := s.userRepo.Search(map[string]string{"role": models.RoleAdmin, "status": "active"}, 1, disputeMaxAdmins)
+	if err != nil {
+		log.Printf("disputes: list admins: %v", err)
+		return nil
+	}
+	ids := make([]string, 0, len(list))
+	for _, u := range list {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
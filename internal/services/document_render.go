@@ -0,0 +1,286 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/pkg/pdf"
+)
+
+// разметка A4: поля 40pt, рабочая ширина 515pt
+const (
+	docLeft  = 40.0
+	docRight = 555.0
+	docWidth = docRight - docLeft
+)
+
+type docColumn struct {
+	title string
+	width float64
+	right bool // числа — по правому краю
+}
+
+func renderInvoice(f *pdf.Font, d *models.Document) ([]byte, error) {
+	doc := pdf.New(f)
+	doc.SetTitle("Счёт на оплату № " + d.Number)
+	y := 50.0
+
+	// платёжные реквизиты поставщика
+	doc.Rect(docLeft, y, docWidth, 58, 0.6)
+	doc.Text(docLeft+6, y+14, 9, "Бенефициар: "+d.Issuer.Name)
+	doc.Text(docLeft+6, y+26, 9, "БИН: "+d.Issuer.BIN)
+	doc.Text(docLeft+300, y+14, 9, "ИИК: "+d.Issuer.IBAN)
+	doc.Text(docLeft+460, y+14, 9, "Кбе: "+d.Issuer.Kbe)
+	doc.Text(docLeft+6, y+40, 9, "Банк бенефициара: "+d.Issuer.Bank)
+	doc.Text(docLeft+300, y+40, 9, "БИК: "+d.Issuer.BIK)
+	doc.Text(docLeft+6, y+52, 9, "Код назначения платежа: 859")
+	y += 85
+
+	doc.Bold(docLeft, y, 14, fmt.Sprintf("Счёт на оплату № %s от %s", d.Number, d.IssuedAt.Format("02.01.2006")))
+	y += 10
+	doc.Line(docLeft, y, docRight, y, 1.2)
+	y += 18
+	y = partyBlock(doc, y, "Поставщик:", d.Issuer)
+	y = partyBlock(doc, y, "Покупатель:", d.Buyer)
+	if d.PaymentID != nil {
+		doc.Text(docLeft, y, 9, "Основание: платёж "+*d.PaymentID)
+		y += 16
+	}
+
+	y = linesTable(doc, y+4, d, []docColumn{
+		{"№", 25, false}, {"Наименование", 255, false}, {"Кол-во", 45, true},
+		{"Ед.", 40, false}, {"Цена", 75, true}, {"Сумма", 75, true},
+	}, func(i int, l models.DocumentLine) []string {
+		return []string{fmt.Sprint(i + 1), l.Name, fmt.Sprint(l.Quantity), l.Unit, formatMoney(l.Price), formatMoney(l.Amount)}
+	})
+	y = totalsBlock(doc, y, d, "Всего к оплате:")
+
+	doc.Line(docLeft, y, docRight, y, 1.2)
+	y += 30
+	doc.Text(docLeft, y, 10, "Исполнитель ____________________ / "+d.Issuer.Name+" /")
+	return doc.Bytes()
+}
+
+func renderAct(f *pdf.Font, d *models.Document) ([]byte, error) {
+	doc := pdf.New(f)
+	doc.SetTitle("Акт выполненных работ № " + d.Number)
+	y := 55.0
+
+	title := "Акт выполненных работ (оказанных услуг)"
+	doc.Bold(docLeft+(docWidth-f.Width(title, 14))/2, y, 14, title)
+	y += 18
+	sub := fmt.Sprintf("№ %s от %s", d.Number, d.IssuedAt.Format("02.01.2006"))
+	doc.Text(docLeft+(docWidth-f.Width(sub, 11))/2, y, 11, sub)
+	y += 26
+
+	y = partyBlock(doc, y, "Исполнитель:", d.Issuer)
+	y = partyBlock(doc, y, "Заказчик:", d.Buyer)
+	if d.OrderID != nil {
+		doc.Text(docLeft, y, 9, "Основание: заказ "+*d.OrderID+" на платформе BuhPro")
+		y += 16
+	}
+
+	y = linesTable(doc, y+4, d, []docColumn{
+		{"№", 25, false}, {"Наименование работ (услуг)", 255, false}, {"Ед.", 40, false},
+		{"Кол-во", 45, true}, {"Цена", 75, true}, {"Стоимость", 75, true},
+	}, func(i int, l models.DocumentLine) []string {
+		return []string{fmt.Sprint(i + 1), l.Name, l.Unit, fmt.Sprint(l.Quantity), formatMoney(l.Price), formatMoney(l.Amount)}
+	})
+	y = totalsBlock(doc, y, d, "Итого к оплате:")
+
+	y += 6
+	for _, line := range doc.Wrap("Вышеперечисленные работы (услуги) выполнены полностью и в срок. Заказчик претензий по объёму, качеству и срокам оказания услуг не имеет.", 9, docWidth) {
+		doc.Text(docLeft, y, 9, line)
+		y += 12
+	}
+	y += 30
+	doc.Text(docLeft, y, 10, "Сдал (Исполнитель)")
+	doc.Text(docLeft+270, y, 10, "Принял (Заказчик)")
+	y += 24
+	doc.Text(docLeft, y, 10, "__________ / "+d.Issuer.Name+" /")
+	doc.Text(docLeft+270, y, 10, "__________ / "+d.Buyer.Name+" /")
+	y += 18
+	doc.Text(docLeft, y, 9, "М.П.")
+	doc.Text(docLeft+270, y, 9, "М.П.")
+	return doc.Bytes()
+}
+
+// partyBlock: «Поставщик: БИН / ИИН ..., наименование, адрес» с переносом
+func partyBlock(doc *pdf.Document, y float64, label string, q models.Requisites) float64 {
+	parts := []string{}
+	if q.BIN != "" {
+		parts = append(parts, "БИН / ИИН "+q.BIN)
+	}
+	parts = append(parts, q.Name)
+	if q.Address != "" {
+		parts = append(parts, q.Address)
+	}
+	doc.Bold(docLeft, y, 10, label)
+	for _, line := range doc.Wrap(strings.Join(parts, ", "), 10, docWidth-80) {
+		doc.Text(docLeft+80, y, 10, line)
+		y += 13
+	}
+	return y + 5
+}
+
+func linesTable(doc *pdf.Document, y float64, d *models.Document, cols []docColumn, cells func(int, models.DocumentLine) []string) float64 {
+	const size, lead, pad = 9.0, 12.0, 4.0
+	row := func(values []string, header bool) {
+		wrapped := make([][]string, len(cols))
+		height := 1
+		for i, c := range cols {
+			wrapped[i] = doc.Wrap(values[i], size, c.width-2*pad)
+			if len(wrapped[i]) > height {
+				height = len(wrapped[i])
+			}
+		}
+		h := float64(height)*lead + 2*pad
+		x := docLeft
+		for i, c := range cols {
+			doc.Rect(x, y, c.width, h, 0.6)
+			for j, line := range wrapped[i] {
+				ly := y + pad + lead*float64(j+1) - 3
+				switch {
+				case header:
+					doc.Bold(x+pad, ly, size, line)
+				case c.right:
+					doc.TextRight(x+c.width-pad, ly, size, line)
+				default:
+					doc.Text(x+pad, ly, size, line)
+				}
+			}
+			x += c.width
+		}
+		y += h
+	}
+	titles := make([]string, len(cols))
+	for i, c := range cols {
+		titles[i] = c.title
+	}
+	row(titles, true)
+	for i, l := range d.Lines {
+		row(cells(i, l), false)
+	}
+	return y + 16
+}
+
+func totalsBlock(doc *pdf.Document, y float64, d *models.Document, payLabel string) float64 {
+	label := docRight - 150
+	doc.Bold(label, y, 10, "Итого:")
+	doc.TextRight(docRight, y, 10, formatMoney(d.Amount))
+	y += 14
+	if d.VATRate > 0 {
+		doc.Text(label, y, 10, "Сумма без НДС:")
+		doc.TextRight(docRight, y, 10, formatMoney(d.Amount-d.VATAmount))
+		y += 14
+		doc.Text(label, y, 10, fmt.Sprintf("В том числе НДС %d%%:", d.VATRate))
+		doc.TextRight(docRight, y, 10, formatMoney(d.VATAmount))
+	} else {
+		doc.Text(label, y, 10, "Без НДС")
+	}
+	y += 22
+	doc.Text(docLeft, y, 10, fmt.Sprintf("Всего наименований %d, на сумму %s %s", len(d.Lines), formatMoney(d.Amount), d.Currency))
+	y += 14
+	for _, line := range doc.Wrap(payLabel+" "+amountInWords(d.Amount), 10, docWidth) {
+		doc.Bold(docLeft, y, 10, line)
+		y += 14
+	}
+	return y + 6
+}
+
+// formatMoney: 1250000 -> "1 250 000,00" (суммы в платформе — целые тенге)
+func formatMoney(v int64) string {
+	neg := v < 0
+	if neg {
+		v = -v
+	}
+	s := fmt.Sprint(v)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	if neg {
+		return "-" + b.String() + ",00"
+	}
+	return b.String() + ",00"
+}
+
+var (
+	wordsOnes     = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	wordsOnesFem  = []string{"", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	wordsTeens    = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать", "шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	wordsTens     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	wordsHundreds = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+	// разряды: форма для 1, 2–4, 5+ и род
+	wordsScales = []struct {
+		forms [3]string
+		fem   bool
+	}{
+		{[3]string{"", "", ""}, false},
+		{[3]string{"тысяча", "тысячи", "тысяч"}, true},
+		{[3]string{"миллион", "миллиона", "миллионов"}, false},
+		{[3]string{"миллиард", "миллиарда", "миллиардов"}, false},
+	}
+)
+
+// amountInWords — сумма прописью для счёта и АВР: «Двенадцать тысяч пятьсот тенге 00 тиын»
+func amountInWords(v int64) string {
+	if v <= 0 {
+		return "Ноль тенге 00 тиын"
+	}
+	var parts []string
+	for scale := 0; v > 0 && scale < len(wordsScales); scale++ {
+		n := int(v % 1000)
+		v /= 1000
+		if n == 0 {
+			continue
+		}
+		group := tripletWords(n, wordsScales[scale].fem)
+		if form := pluralForm(n, wordsScales[scale].forms); form != "" {
+			group = append(group, form)
+		}
+		parts = append(group, parts...)
+	}
+	s := strings.Join(parts, " ")
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:]) + " тенге 00 тиын"
+}
+
+func tripletWords(n int, fem bool) []string {
+	var w []string
+	if h := n / 100; h > 0 {
+		w = append(w, wordsHundreds[h])
+	}
+	switch t := n % 100; {
+	case t >= 10 && t < 20:
+		w = append(w, wordsTeens[t-10])
+	default:
+		if t/10 > 0 {
+			w = append(w, wordsTens[t/10])
+		}
+		if u := t % 10; u > 0 {
+			if fem {
+				w = append(w, wordsOnesFem[u])
+			} else {
+				w = append(w, wordsOnes[u])
+			}
+		}
+	}
+	return w
+}
+
+func pluralForm(n int, forms [3]string) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+		return forms[2]
+	case n%10 == 1:
+		return forms[0]
+	case n%10 >= 2 && n%10 <= 4:
+		return forms[1]
+	}
+	return forms[2]
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/pdf"
+	"github.com/BekzatS8/buhpro/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const documentLinkTTL = 5 * time.Minute
+
+var (
+	ErrDocumentForbidden = &ServiceError{"no access to this document"}
+	ErrNoInvoice         = &ServiceError{"invoices are issued only for platform fees that are not failed, expired or refunded"}
+	ErrDocumentsDisabled = &ServiceError{"PDF documents are not available on this server"}
+	ErrActNotReady       = &ServiceError{"act of completed works is issued once the order is completed"}
+	ErrActNoAmount       = &ServiceError{"order has no agreed price for the act"}
+	ErrNotPaid           = &ServiceError{"receipt is available once the payment is paid"}
+)
+
+// сборы платформы: только по ним платформа — продавец и выставляет счёт
+// (эскроу и оплата консультаций — деньги исполнителя и наставника)
+var platformFees = map[string]bool{"bid_fee": true, "order_publish": true, "subscription": true}
+
+// платежи, по которым счёт не выставляется
+var invoicelessStatuses = map[string]bool{"failed": true, "expired": true, "refunded": true}
+
+// оплаченные платежи (held/released/split — деньги в эскроу или уже распределены)
+var paidStatuses = map[string]bool{"success": true, "held": true, "released": true, "split": true}
+
+// DocumentService — закрывающие документы. Счёт на оплату выставляет
+// платформа по каждому платежу (при первом запросе), АВР — исполнитель
+// клиенту при завершении заказа. PDF хранятся в BlobStore, номера — своя
+// последовательность у каждого эмитента. Без шрифта (font == nil) новые
+// документы не выставляются.
+type DocumentService struct {
+	docs     repository.DocumentRepo
+	payments repository.PaymentRepo
+	orders   repository.OrderRepo
+	bids     repository.BidRepo
+	users    repository.UserRepo
+	store    storage.BlobStore
+	font     *pdf.Font
+	platform models.Requisites
+	tax      *TaxService
+}
+
+func NewDocumentService(dr repository.DocumentRepo, pr repository.PaymentRepo, or repository.OrderRepo, br repository.BidRepo, ur repository.UserRepo,
+	store storage.BlobStore, font *pdf.Font, platform models.Requisites, tx *TaxService) *DocumentService {
+	return &DocumentService{docs: dr, payments: pr, orders: or, bids: br, users: ur, store: store, font: font, platform: platform, tax: tx}
+}
+
+// Invoice returns the invoice of the payment, issuing it on first request
+func (s *DocumentService) Invoice(ctx context.Context, paymentID, userID string, isAdmin bool) (*models.Document, error) {
+	p, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && (p.UserID == nil || *p.UserID != userID) {
+		return nil, ErrDocumentForbidden
+	}
+	d, err := s.docs.InvoiceByPayment(ctx, p.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if invoicelessStatuses[p.Status] || !platformFees[p.RelatedType] {
+			return nil, ErrNoInvoice
+		}
+		d, err = s.issueInvoice(ctx, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.withLink(ctx, d)
+}
+
+// Receipt — квитанция об оплате с сохранённой разбивкой по НДС
+func (s *DocumentService) Receipt(ctx context.Context, paymentID, userID string, isAdmin bool) (*models.Receipt, error) {
+	p, err := s.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && (p.UserID == nil || *p.UserID != userID) {
+		return nil, ErrDocumentForbidden
+	}
+	if !paidStatuses[p.Status] || p.UserID == nil {
+		return nil, ErrNotPaid
+	}
+	payer, _ := s.party(ctx, p.OrganizationID, *p.UserID)
+	return &models.Receipt{
+		PaymentID: p.ID,
+		Status:    p.Status,
+		Subject:   s.paymentSubject(ctx, p),
+		Issuer:    s.platform,
+		Payer:     payer,
+		Currency:  p.Currency,
+		Tax:       paymentTax(p),
+		PaidAt:    p.UpdatedAt,
+	}, nil
+}
+
+// Act returns the act of completed works of the order (клиенту и исполнителю)
+func (s *DocumentService) Act(ctx context.Context, orderID, userID string, isAdmin bool) (*models.Document, error) {
+	o, executorID, err := s.participantOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	d, err := s.docs.ActByOrder(ctx, o.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if o.Status != "completed" || executorID == "" {
+			return nil, ErrActNotReady
+		}
+		d, err = s.issueAct(ctx, o, executorID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.withLink(ctx, d)
+}
+
+// OrderDocuments — документы заказа, в которых пользователь покупатель или эмитент
+func (s *DocumentService) OrderDocuments(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Document, error) {
+	o, _, err := s.participantOrder(ctx, orderID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.docs.ListByOrder(ctx, o.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := []*models.Document{}
+	for _, d := range list {
+		if !isAdmin && d.BuyerUserID != userID && (d.IssuerUserID == nil || *d.IssuerUserID != userID) {
+			continue
+		}
+		if d, err = s.withLink(ctx, d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// OrderCompleted — хук завершения заказа: АВР выставляется сразу, ошибка
+// только логируется (документ можно получить позже через Act)
+func (s *DocumentService) OrderCompleted(ctx context.Context, o *models.Order) {
+	executorID := ""
+	if o.ChosenBidID != nil {
+		if b, err := s.bids.GetByID(ctx, *o.ChosenBidID); err == nil {
+			executorID = b.ExecutorID
+		}
+	}
+	if executorID == "" {
+		return
+	}
+	if _, err := s.issueAct(ctx, o, executorID); err != nil && !errors.Is(err, ErrActNoAmount) && !errors.Is(err, ErrDocumentsDisabled) {
+		log.Printf("documents: act for order %s: %v", o.ID, err)
+	}
+}
+
+func (s *DocumentService) participantOrder(ctx context.Context, orderID, userID string, isAdmin bool) (*models.Order, string, error) {
+	o, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	executorID := ""
+	if o.ChosenBidID != nil {
+		if b, err := s.bids.GetByID(ctx, *o.ChosenBidID); err == nil {
+			executorID = b.ExecutorID
+		}
+	}
+	if !isAdmin && o.ClientUserID != userID && (executorID == "" || executorID != userID) {
+		return nil, "", ErrDocumentForbidden
+	}
+	return o, executorID, nil
+}
+
+func (s *DocumentService) issueInvoice(ctx context.Context, p *models.Payment) (*models.Document, error) {
+	if p.UserID == nil || !platformFees[p.RelatedType] {
+		return nil, ErrNoInvoice
+	}
+	buyer, _ := s.party(ctx, p.OrganizationID, *p.UserID)
+	d := &models.Document{
+		ID:          uuid.NewString(),
+		Kind:        models.DocumentInvoice,
+		IssuerKey:   models.IssuerPlatform,
+		BuyerUserID: *p.UserID,
+		Issuer:      s.platform,
+		Buyer:       buyer,
+		PaymentID:   &p.ID,
+		OrderID:     s.paymentOrder(ctx, p),
+		Lines:       []models.DocumentLine{{Name: s.paymentSubject(ctx, p), Quantity: 1, Unit: "усл.", Price: p.Amount, Amount: p.Amount}},
+		Amount:      p.Amount,
+		VATRate:     p.VATRate,
+		VATAmount:   p.VATAmount,
+		Currency:    p.Currency,
+		IssuedAt:    time.Now().In(kzTime),
+	}
+	return s.issue(ctx, d, renderInvoice)
+}
+
+func (s *DocumentService) issueAct(ctx context.Context, o *models.Order, executorID string) (*models.Document, error) {
+	amount, err := s.docs.PaidOut(ctx, o.ID, executorID)
+	if err != nil {
+		return nil, err
+	}
+	// без эскроу (расчёт напрямую) — по цене выбранной ставки
+	if amount == 0 && o.ChosenBidID != nil {
+		if b, err := s.bids.GetByID(ctx, *o.ChosenBidID); err == nil && b.Price != nil {
+			amount = *b.Price
+		}
+	}
+	if amount <= 0 {
+		return nil, ErrActNoAmount
+	}
+	issuer, vatPayer := s.party(ctx, nil, executorID)
+	orgID := &o.OrgID
+	if o.OrgID == "" {
+		orgID = nil
+	}
+	buyer, buyerVAT := s.party(ctx, orgID, o.ClientUserID)
+	done := time.Now()
+	if o.CompletedAt != nil {
+		done = *o.CompletedAt
+	}
+	// ставка — на дату оборота (завершения заказа)
+	vat, err := s.tax.Compute(ctx, amount, vatPayer, buyerVAT, done)
+	if err != nil {
+		return nil, err
+	}
+	currency := o.Currency
+	if currency == "" {
+		currency = "KZT"
+	}
+	d := &models.Document{
+		ID:           uuid.NewString(),
+		Kind:         models.DocumentAct,
+		IssuerKey:    executorID,
+		IssuerUserID: &executorID,
+		BuyerUserID:  o.ClientUserID,
+		Issuer:       issuer,
+		Buyer:        buyer,
+		OrderID:      &o.ID,
+		Lines: []models.DocumentLine{{
+			Name:     fmt.Sprintf("Услуги по заказу «%s», выполнены %s", o.Title, done.In(kzTime).Format("02.01.2006")),
+			Quantity: 1, Unit: "усл.", Price: amount, Amount: amount,
+		}},
+		Amount:    amount,
+		VATRate:   vat.Rate,
+		VATAmount: vat.VAT,
+		Currency:  currency,
+		IssuedAt:  time.Now().In(kzTime),
+	}
+	return s.issue(ctx, d, renderAct)
+}
+
+// issue numbers the document, renders and uploads the PDF; если документ
+// успели выставить параллельно — возвращается существующий
+func (s *DocumentService) issue(ctx context.Context, d *models.Document, render func(*pdf.Font, *models.Document) ([]byte, error)) (*models.Document, error) {
+	if s.font == nil {
+		return nil, ErrDocumentsDisabled
+	}
+	err := s.docs.Issue(ctx, d, func(d *models.Document) error {
+		body, err := render(s.font, d)
+		if err != nil {
+			return err
+		}
+		d.StorageKey = fmt.Sprintf("documents/%s/%d/%s.pdf", d.Kind, d.IssuedAt.Year(), d.ID)
+		d.Size = int64(len(body))
+		return s.store.Put(ctx, d.StorageKey, "application/pdf", body)
+	})
+	if err == nil {
+		return d, nil
+	}
+	var existing *models.Document
+	var gerr error
+	if d.Kind == models.DocumentInvoice {
+		existing, gerr = s.docs.InvoiceByPayment(ctx, *d.PaymentID)
+	} else {
+		existing, gerr = s.docs.ActByOrder(ctx, *d.OrderID)
+	}
+	if gerr == nil {
+		return existing, nil
+	}
+	return nil, err
+}
+
+func (s *DocumentService) withLink(ctx context.Context, d *models.Document) (*models.Document, error) {
+	name := fmt.Sprintf("%s-%s.pdf", d.Kind, d.Number)
+	u, err := s.store.PresignGet(ctx, d.StorageKey, name, documentLinkTTL)
+	if err != nil {
+		return nil, err
+	}
+	d.DownloadURL = u
+	return d, nil
+}
+
+// party — реквизиты стороны: организация платежа/заказа, иначе организация
+// пользователя, иначе физлицо (ФИО). Второе значение — плательщик НДС.
+func (s *DocumentService) party(ctx context.Context, orgID *string, userID string) (models.Requisites, bool) {
+	if orgID != nil && *orgID != "" {
+		if q, vat, err := s.docs.Organization(ctx, *orgID); err == nil {
+			return *q, vat
+		}
+	}
+	if q, vat, err := s.docs.UserOrganization(ctx, userID); err == nil {
+		return *q, vat
+	}
+	if u, err := s.users.GetByID(userID); err == nil {
+		name := u.FullName
+		if name == "" {
+			name = u.Email
+		}
+		return models.Requisites{Name: name}, false
+	}
+	return models.Requisites{}, false
+}
+
+// paymentOrder — заказ, к которому относится платёж (для списка документов заказа)
+func (s *DocumentService) paymentOrder(ctx context.Context, p *models.Payment) *string {
+	if p.RelatedID == nil {
+		return nil
+	}
+	switch p.RelatedType {
+	case "order_publish", "order_escrow":
+		return p.RelatedID
+	case "bid_fee":
+		if b, err := s.bids.GetByID(ctx, *p.RelatedID); err == nil {
+			return &b.OrderID
+		}
+	}
+	return nil
+}
+
+// paymentSubject — наименование услуги в строке счёта
+func (s *DocumentService) paymentSubject(ctx context.Context, p *models.Payment) string {
+	title := func(orderID string) string {
+		if o, err := s.orders.GetByID(ctx, orderID); err == nil {
+			return "«" + o.Title + "»"
+		}
+		return ""
+	}
+	switch p.RelatedType {
+	case "order_publish":
+		if p.RelatedID != nil {
+			return "Размещение заказа " + title(*p.RelatedID) + " на платформе BuhPro"
+		}
+	case "order_escrow":
+		if p.RelatedID != nil {
+			return "Оплата заказа " + title(*p.RelatedID) + " (безопасная сделка)"
+		}
+	case "bid_fee":
+		if id := s.paymentOrder(ctx, p); id != nil {
+			return "Отклик на заказ " + title(*id) + " на платформе BuhPro"
+		}
+	case "subscription":
+		if plan, ok := p.Items["plan_code"].(string); ok {
+			return "Подписка BuhPro, тариф " + plan + ", 1 месяц"
+		}
+	case "mentoring_session":
+		if m, ok := p.Items["minutes"].(float64); ok {
+			return fmt.Sprintf("Консультация наставника, %d мин", int(m))
+		}
+		return "Консультация наставника"
+	}
+	return "Услуги платформы BuhPro"
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/esf"
+	"github.com/BekzatS8/buhpro/pkg/storage"
+	"github.com/BekzatS8/buhpro/pkg/tax"
+	"github.com/google/uuid"
+)
+
+const maxESFPeriod = 366 * 24 * time.Hour
+
+var (
+	ErrESFPeriod          = &ServiceError{"from and to must be dates (YYYY-MM-DD), from <= to, at most a year apart"}
+	ErrESFNothingToExport = &ServiceError{"no unexported organization payments for platform fees in this period"}
+	ErrESFConcurrent      = &ServiceError{"some payments were exported by a parallel request, retry"}
+)
+
+// ESFRejectedError — ни один платёж периода не прошёл проверку схемы
+type ESFRejectedError struct {
+	Skipped []models.ESFSkipped
+}
+
+func (e *ESFRejectedError) Error() string {
+	return "no payment passed ESF schema validation"
+}
+
+// ESFService — выгрузка ЭСФ для ИС ЭСФ по сборам платформы (ставки,
+// публикации), оплаченным организациями. Каждый платёж выгружается один раз;
+// не прошедшие проверку XSD пропускаются с причинами и ждут следующей выгрузки.
+type ESFService struct {
+	repo     repository.ESFRepo
+	docs     repository.DocumentRepo
+	users    repository.UserRepo
+	audit    repository.AuditRepo
+	store    storage.BlobStore
+	platform models.Requisites
+}
+
+func NewESFService(er repository.ESFRepo, dr repository.DocumentRepo, ur repository.UserRepo, ar repository.AuditRepo, store storage.BlobStore,
+	platform models.Requisites) *ESFService {
+	return &ESFService{repo: er, docs: dr, users: ur, audit: ar, store: store, platform: platform}
+}
+
+// Export builds, validates and stores the XML for payments created between
+// from and to (YYYY-MM-DD, inclusive)
+func (s *ESFService) Export(ctx context.Context, adminID, from, to string) (*models.ESFExport, error) {
+	start, err1 := time.ParseInLocation("2006-01-02", from, kzTime)
+	end, err2 := time.ParseInLocation("2006-01-02", to, kzTime)
+	if err1 != nil || err2 != nil || end.Before(start) || end.Sub(start) > maxESFPeriod {
+		return nil, ErrESFPeriod
+	}
+	payments, err := s.repo.Candidates(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, ErrESFNothingToExport
+	}
+	operator := ""
+	if u, err := s.users.GetByID(adminID); err == nil {
+		operator = u.FullName
+	}
+
+	now := time.Now().In(kzTime)
+	e := &models.ESFExport{
+		ID:         uuid.NewString(),
+		PeriodFrom: start,
+		PeriodTo:   end,
+		CreatedBy:  adminID,
+		Skipped:    []models.ESFSkipped{},
+		CreatedAt:  now,
+	}
+	// каждый счёт проверяется отдельно, чтобы один платёж без БИН не блокировал остальные
+	var valid []*models.Payment
+	for _, p := range payments {
+		inv, err := s.invoice(ctx, p, "ESF-0000-00000", operator, now)
+		if err == nil {
+			var doc []byte
+			if doc, err = esf.Marshal([]*esf.Invoice{inv}); err == nil {
+				err = esf.Validate(doc)
+			}
+		}
+		if err != nil {
+			e.Skipped = append(e.Skipped, models.ESFSkipped{PaymentID: p.ID, Errors: violations(err)})
+			continue
+		}
+		valid = append(valid, p)
+		e.Items = append(e.Items, models.ESFExportItem{PaymentID: p.ID, Amount: p.Amount})
+		e.Total += p.Amount
+	}
+	if len(valid) == 0 {
+		return nil, &ESFRejectedError{Skipped: e.Skipped}
+	}
+	e.Invoices = len(valid)
+
+	err = s.repo.Create(ctx, e, func(e *models.ESFExport) error {
+		invoices := make([]*esf.Invoice, len(valid))
+		for i, p := range valid {
+			inv, err := s.invoice(ctx, p, e.Items[i].Num, operator, now)
+			if err != nil {
+				return err
+			}
+			invoices[i] = inv
+		}
+		doc, err := esf.Marshal(invoices)
+		if err != nil {
+			return err
+		}
+		if err := esf.Validate(doc); err != nil {
+			return err
+		}
+		e.StorageKey = fmt.Sprintf("esf/%d/%s.xml", now.Year(), e.ID)
+		e.Size = int64(len(doc))
+		return s.store.Put(ctx, e.StorageKey, "application/xml", doc)
+	})
+	if errors.Is(err, repository.ErrAlreadyExported) {
+		return nil, ErrESFConcurrent
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, adminID, "admin.esf_export", "esf_export", e.ID, map[string]interface{}{
+		"from": from, "to": to, "invoices": e.Invoices, "total": e.Total, "skipped": len(e.Skipped),
+	})
+	return s.withLink(ctx, e)
+}
+
+func (s *ESFService) Get(ctx context.Context, id string) (*models.ESFExport, error) {
+	e, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withLink(ctx, e)
+}
+
+func (s *ESFService) List(ctx context.Context, page, perPage int) ([]*models.ESFExport, int, error) {
+	return s.repo.List(ctx, page, perPage)
+}
+
+func (s *ESFService) withLink(ctx context.Context, e *models.ESFExport) (*models.ESFExport, error) {
+	name := fmt.Sprintf("esf-%s-%s.xml", e.PeriodFrom.Format("20060102"), e.PeriodTo.Format("20060102"))
+	u, err := s.store.PresignGet(ctx, e.StorageKey, name, documentLinkTTL)
+	if err != nil {
+		return nil, err
+	}
+	e.DownloadURL = u
+	return e, nil
+}
+
+// invoice maps a payment to an ESF invoice: seller — платформа, customer —
+// организация платежа, строки — из payments.items
+func (s *ESFService) invoice(ctx context.Context, p *models.Payment, num, operator string, now time.Time) (*esf.Invoice, error) {
+	buyer, _, err := s.docs.Organization(ctx, *p.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("organization %s: %w", *p.OrganizationID, err)
+	}
+	lines, ok := itemLines(p)
+	if !ok {
+		return nil, errors.New("payments.items lines do not add up to the payment amount")
+	}
+	// ставка — сохранённая в платеже на дату оборота
+	products := make([]*esf.Product, 0, len(lines))
+	for _, l := range lines {
+		vat := tax.Included(l.Amount, p.VATRate)
+		pr := &esf.Product{
+			Description:      l.Name,
+			UnitNomenclature: l.Unit,
+			Quantity:         int64(l.Quantity),
+			UnitPrice:        esf.Tenge(l.Price),
+			PriceWithoutTax:  esf.Tenge(vat.Net),
+			NdsAmount:        esf.Tenge(vat.VAT),
+			PriceWithTax:     esf.Tenge(l.Amount),
+			TurnoverSize:     esf.Tenge(vat.Net),
+		}
+		if p.VATRate > 0 {
+			rate := p.VATRate
+			pr.NdsRate = &rate
+		}
+		products = append(products, pr)
+	}
+	ps := esf.ProductSet{CurrencyCode: p.Currency, Products: products}
+	ps.Totals()
+	return &esf.Invoice{
+		Date:             now.Format(esf.DateLayout),
+		InvoiceType:      esf.InvoiceOrdinary,
+		Num:              num,
+		OperatorFullname: operator,
+		TurnoverDate:     p.CreatedAt.In(kzTime).Format(esf.DateLayout),
+		Seller: esf.Seller{
+			TIN: s.platform.BIN, Name: s.platform.Name, Address: s.platform.Address,
+			Bank: s.platform.Bank, BIK: s.platform.BIK, IIK: s.platform.IBAN, Kbe: s.platform.Kbe,
+		},
+		Customer:   esf.Customer{TIN: buyer.BIN, Name: buyer.Name, Address: buyer.Address, CountryCode: "KZ"},
+		ProductSet: ps,
+	}, nil
+}
+
+func violations(err error) []string {
+	var ve *esf.ValidationError
+	if errors.As(err, &ve) {
+		out := make([]string, len(ve.Violations))
+		for i, v := range ve.Violations {
+			// путь внутри одиночного контейнера проверки не нужен финансисту
+			out[i] = strings.TrimPrefix(v, "/invoiceContainer/invoiceSet[1]/invoice[1]/")
+		}
+		return out
+	}
+	return []string{err.Error()}
+}
+
+// feeItems — строки платежа за сбор платформы (payments.items.lines)
+func feeItems(name string, amount int64) map[string]interface{} {
+	return map[string]interface{}{"lines": []interface{}{
+		map[string]interface{}{"name": name, "quantity": 1, "unit": "усл.", "unit_price": amount},
+	}}
+}
+
+// itemLines reads payments.items.lines; без строк — одна строка на всю сумму
+// с наименованием по типу платежа. false — строки не сходятся с суммой платежа.
+func itemLines(p *models.Payment) ([]models.DocumentLine, bool) {
+	raw, _ := p.Items["lines"].([]interface{})
+	if len(raw) == 0 {
+		return []models.DocumentLine{{Name: feeName(p.RelatedType), Quantity: 1, Unit: "усл.", Price: p.Amount, Amount: p.Amount}}, true
+	}
+	var lines []models.DocumentLine
+	var sum int64
+	for _, r := range raw {
+		m, _ := r.(map[string]interface{})
+		name, _ := m["name"].(string)
+		unit, _ := m["unit"].(string)
+		qty, _ := m["quantity"].(float64)
+		price, _ := m["unit_price"].(float64)
+		if name == "" || qty <= 0 {
+			return nil, false
+		}
+		if unit == "" {
+			unit = "усл."
+		}
+		l := models.DocumentLine{Name: name, Quantity: int(qty), Unit: unit, Price: int64(price), Amount: int64(qty) * int64(price)}
+		sum += l.Amount
+		lines = append(lines, l)
+	}
+	return lines, sum == p.Amount
+}
+
+func feeName(relatedType string) string {
+	switch relatedType {
+	case "bid_fee":
+		return "Отклик на заказ на платформе BuhPro"
+	case "order_publish":
+		return "Размещение заказа на платформе BuhPro"
+	}
+	return "Услуги платформы BuhPro"
+}
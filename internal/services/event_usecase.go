@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+)
+
+const (
+	eventReplayWindow = 24 * time.Hour
+	eventReplayLimit  = 500
+)
+
+// EventService публикует события для real-time канала (/events).
+// Ошибка публикации не должна ломать бизнес-операцию, поэтому она только логируется.
+type EventService struct {
+	repo     repository.EventRepo
+	handlers []func(ctx context.Context, e *models.Event)
+}
+
+func NewEventService(r repository.EventRepo) *EventService {
+	return &EventService{repo: r}
+}
+
+// Subscribe registers an in-process handler called for every stored event
+// (e.g. notifications). Must be called during initialization.
+func (s *EventService) Subscribe(fn func(ctx context.Context, e *models.Event)) {
+	s.handlers = append(s.handlers, fn)
+}
+
+// Publish stores one event per recipient; empty and duplicate ids are skipped.
+func (s *EventService) Publish(ctx context.Context, userIDs []string, eventType string, payload interface{}) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("events: marshal %s: %v", eventType, err)
+		return
+	}
+	seen := make(map[string]bool, len(userIDs))
+	for _, uid := range userIDs {
+		if uid == "" || seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		e := &models.Event{UserID: uid, Type: eventType, Payload: b}
+		if err := s.repo.Create(ctx, e); err != nil {
+			log.Printf("events: publish %s to %s: %v", eventType, uid, err)
+			continue
+		}
+		for _, h := range s.handlers {
+			h(ctx, e)
+		}
+	}
+}
+
+// Replay returns events missed after lastID (Last-Event-ID) within the replay window.
+func (s *EventService) Replay(ctx context.Context, userID string, lastID int64) ([]*models.Event, error) {
+	if lastID <= 0 {
+		return nil, nil
+	}
+	return s.repo.ListForUserSince(ctx, userID, lastID, time.Now().Add(-eventReplayWindow), eventReplayLimit)
+}
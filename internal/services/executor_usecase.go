@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	executorMaxBioLen   = 4000
+	executorMaxTags     = 20
+	executorMaxTagLen   = 128
+	executorMaxYears    = 60
+	portfolioMaxTitle   = 200
+	portfolioMaxDescLen = 4000
+)
+
+var (
+	ErrNotExecutor          = &ServiceError{"executor role is required"}
+	ErrProfileInvalid       = &ServiceError{"profile: bio, specializations, regions or experience out of limits"}
+	ErrUnknownSoftware      = &ServiceError{"unknown software skill"}
+	ErrWorkMode             = &ServiceError{"work_mode must be any, online or offline"}
+	ErrCertificateKind      = &ServiceError{"kind must be one of CAP, CIPA, DipIFR, ACCA, other"}
+	ErrCertificateProof     = &ServiceError{"file_id with the certificate scan is required"}
+	ErrCertificateDate      = &ServiceError{"issued_at must be YYYY-MM-DD"}
+	ErrCertificateForbidden = &ServiceError{"not your certificate"}
+	ErrPortfolioInvalid     = &ServiceError{"portfolio case: title is required, title/description too long"}
+	ErrPortfolioForbidden   = &ServiceError{"not your portfolio case"}
+)
+
+type ExecutorProfileInput struct {
+	Bio             string   `json:"bio"`
+	Specializations []string `json:"specializations"`
+	Regions         []string `json:"regions"`
+	Software        []string `json:"software"`
+	ExperienceYears int      `json:"experience_years"`
+	PriceFrom       *int64   `json:"price_from"`
+	Available       *bool    `json:"available"` // nil = true
+	WorkMode        string   `json:"work_mode"` // any (default) | online | offline
+}
+
+type CertificateInput struct {
+	Kind     string `json:"kind"`
+	Title    string `json:"title"`
+	Number   string `json:"number"`
+	IssuedAt string `json:"issued_at"` // YYYY-MM-DD
+	FileID   string `json:"file_id"`
+}
+
+type PortfolioInput struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Category    string   `json:"category"`
+	Year        *int     `json:"year"`
+	Files       []string `json:"files"`
+}
+
+// ExecutorCard — публичная карточка исполнителя: без контактов,
+// только подтверждённые сертификаты
+type ExecutorCard struct {
+	ID           string                  `json:"id"`
+	FullName     string                  `json:"full_name"`
+	MemberSince  time.Time               `json:"member_since"`
+	Profile      *models.ExecutorProfile `json:"profile"`
+	Certificates []*models.Certificate   `json:"certificates"`
+	Portfolio    []*models.PortfolioCase `json:"portfolio"`
+	Rating       *models.RatingSummary   `json:"rating"`
+	Badge        string                  `json:"badge,omitempty"` // тариф подписки
+}
+
+// ExecutorService — профиль исполнителя, сертификаты (проверяет админ) и портфолио
+type ExecutorService struct {
+	repo       repository.ExecutorRepo
+	userRepo   repository.UserRepo
+	reviewRepo repository.ReviewRepo
+	audit      repository.AuditRepo
+	events     *EventService
+	files      *FileService
+	taxonomy   *TaxonomyService
+	subs       *SubscriptionService
+	onSaved    []func(ctx context.Context, userID string)
+}
+
+func NewExecutorService(er repository.ExecutorRepo, ur repository.UserRepo, rr repository.ReviewRepo, ar repository.AuditRepo, ev *EventService, fs *FileService, ts *TaxonomyService, ss *SubscriptionService) *ExecutorService {
+	return &ExecutorService{repo: er, userRepo: ur, reviewRepo: rr, audit: ar, events: ev, files: fs, taxonomy: ts, subs: ss}
+}
+
+// OnProfileSaved registers a hook run after an executor saves the profile
+// (e.g. recomputing recommendations). Must be called during initialization.
+func (s *ExecutorService) OnProfileSaved(fn func(ctx context.Context, userID string)) {
+	s.onSaved = append(s.onSaved, fn)
+}
+
+// Profile returns the user's profile, an empty one if not filled yet
+func (s *ExecutorService) Profile(ctx context.Context, userID string) (*models.ExecutorProfile, error) {
+	p, err := s.repo.GetProfile(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &models.ExecutorProfile{UserID: userID, Specializations: []string{}, Regions: []string{}, Software: []string{},
+			Available: true, WorkMode: models.WorkModeAny}, nil
+	}
+	return p, err
+}
+
+func (s *ExecutorService) SaveProfile(ctx context.Context, userID string, in ExecutorProfileInput) (*models.ExecutorProfile, error) {
+	if err := s.requireExecutor(userID); err != nil {
+		return nil, err
+	}
+	p := &models.ExecutorProfile{
+		UserID:          userID,
+		Bio:             strings.TrimSpace(in.Bio),
+		Specializations: normalizeTags(in.Specializations),
+		Regions:         normalizeTags(in.Regions),
+		Software:        normalizeTags(in.Software),
+		ExperienceYears: in.ExperienceYears,
+		PriceFrom:       in.PriceFrom,
+		Available:       in.Available == nil || *in.Available,
+		WorkMode:        in.WorkMode,
+	}
+	switch p.WorkMode {
+	case "":
+		p.WorkMode = models.WorkModeAny
+	case models.WorkModeAny, models.WorkModeOnline, models.WorkModeOffline:
+	default:
+		return nil, ErrWorkMode
+	}
+	if p.PriceFrom != nil && *p.PriceFrom < 0 {
+		return nil, ErrProfileInvalid
+	}
+	if len([]rune(p.Bio)) > executorMaxBioLen || p.ExperienceYears < 0 || p.ExperienceYears > executorMaxYears ||
+		len(p.Specializations) > executorMaxTags || len(p.Regions) > executorMaxTags || !tagsFit(p.Specializations) || !tagsFit(p.Regions) {
+		return nil, ErrProfileInvalid
+	}
+	for _, sw := range p.Software {
+		if !models.ExecutorSoftware[sw] {
+			return nil, ErrUnknownSoftware
+		}
+	}
+	var err error
+	if p.Specializations, err = s.taxonomy.Categories(ctx, p.Specializations); err != nil {
+		return nil, err
+	}
+	if p.Regions, err = s.taxonomy.Regions(ctx, p.Regions); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveProfile(ctx, p); err != nil {
+		return nil, err
+	}
+	for _, fn := range s.onSaved {
+		fn(ctx, userID)
+	}
+	return p, nil
+}
+
+// Public — карточка для клиентов; pgx.ErrNoRows, если пользователь не активный исполнитель
+func (s *ExecutorService) Public(ctx context.Context, id string) (*ExecutorCard, error) {
+	u, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != "active" || !u.HasRole(models.RoleExecutor) {
+		return nil, pgx.ErrNoRows
+	}
+	card := &ExecutorCard{ID: u.ID, FullName: u.FullName, MemberSince: u.CreatedAt}
+	if card.Profile, err = s.Profile(ctx, id); err != nil {
+		return nil, err
+	}
+	certs, err := s.repo.ListCertificates(ctx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	card.Certificates = []*models.Certificate{}
+	for _, c := range certs {
+		// скан и служебные поля проверки — только владельцу и админу
+		card.Certificates = append(card.Certificates, &models.Certificate{
+			ID: c.ID, UserID: c.UserID, Kind: c.Kind, Title: c.Title, Number: c.Number, IssuedAt: c.IssuedAt,
+			Status: c.Status, VerifiedAt: c.VerifiedAt, CreatedAt: c.CreatedAt,
+		})
+	}
+	if card.Portfolio, err = s.Portfolio(ctx, id); err != nil {
+		return nil, err
+	}
+	ratings, err := s.reviewRepo.Summaries(ctx, []string{id}, models.RoleClient)
+	if err != nil {
+		return nil, err
+	}
+	card.Rating = ratings[id]
+	badges, err := s.subs.Badges(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	card.Badge = badges[id]
+	return card, nil
+}
+
+// Search — каталог исполнителей (фильтры см. ExecutorRepo.Search), сортировка по рейтингу
+func (s *ExecutorService) Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.ExecutorSummary, int, error) {
+	s.taxonomy.ResolveFilters(ctx, filters, "specialization", "region")
+	list, total, err := s.repo.Search(ctx, filters, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		return []*models.ExecutorSummary{}, total, nil
+	}
+	ids := make([]string, 0, len(list))
+	for _, e := range list {
+		ids = append(ids, e.ID)
+	}
+	ratings, err := s.reviewRepo.Summaries(ctx, ids, models.RoleClient)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, e := range list {
+		e.Rating = ratings[e.ID]
+	}
+	return list, total, nil
+}
+
+// --- certificates
+
+func (s *ExecutorService) Certificates(ctx context.Context, userID string) ([]*models.Certificate, error) {
+	list, err := s.repo.ListCertificates(ctx, userID, false)
+	if list == nil {
+		list = []*models.Certificate{}
+	}
+	return list, err
+}
+
+// AddCertificate — сертификат со сканом уходит на проверку (pending)
+func (s *ExecutorService) AddCertificate(ctx context.Context, userID string, in CertificateInput) (*models.Certificate, error) {
+	if err := s.requireExecutor(userID); err != nil {
+		return nil, err
+	}
+	if !models.CertificateKinds[in.Kind] {
+		return nil, ErrCertificateKind
+	}
+	if strings.TrimSpace(in.FileID) == "" {
+		return nil, ErrCertificateProof
+	}
+	c := &models.Certificate{
+		ID:     uuid.NewString(),
+		UserID: userID,
+		Kind:   in.Kind,
+		Title:  strings.TrimSpace(in.Title),
+		Number: strings.TrimSpace(in.Number),
+	}
+	if in.IssuedAt != "" {
+		t, err := time.Parse("2006-01-02", in.IssuedAt)
+		if err != nil {
+			return nil, ErrCertificateDate
+		}
+		c.IssuedAt = &t
+	}
+	ids, err := s.files.Attach(ctx, userID, models.FileLinkCertificate, c.ID, []string{in.FileID})
+	if err != nil {
+		return nil, err
+	}
+	c.FileID = &ids[0]
+	if err := s.repo.CreateCertificate(ctx, c); err != nil {
+		s.files.Release(ctx, userID, models.FileLinkCertificate, c.ID)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *ExecutorService) DeleteCertificate(ctx context.Context, userID, id string) error {
+	c, err := s.repo.GetCertificate(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.UserID != userID {
+		return ErrCertificateForbidden
+	}
+	s.files.Release(ctx, userID, models.FileLinkCertificate, c.ID)
+	return s.repo.DeleteCertificate(ctx, id)
+}
+
+// --- portfolio
+
+func (s *ExecutorService) Portfolio(ctx context.Context, userID string) ([]*models.PortfolioCase, error) {
+	list, err := s.repo.ListPortfolio(ctx, userID)
+	if list == nil {
+		list = []*models.PortfolioCase{}
+	}
+	return list, err
+}
+
+func (s *ExecutorService) AddPortfolio(ctx context.Context, userID string, in PortfolioInput) (*models.PortfolioCase, error) {
+	if err := s.requireExecutor(userID); err != nil {
+		return nil, err
+	}
+	p := &models.PortfolioCase{ID: uuid.NewString(), UserID: userID}
+	if err := applyPortfolioInput(p, in); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.Files, err = s.files.Attach(ctx, userID, models.FileLinkPortfolio, p.ID, in.Files); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePortfolio(ctx, p); err != nil {
+		s.files.Release(ctx, userID, models.FileLinkPortfolio, p.ID)
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *ExecutorService) UpdatePortfolio(ctx context.Context, userID, id string, in PortfolioInput) (*models.PortfolioCase, error) {
+	p, err := s.repo.GetPortfolio(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, ErrPortfolioForbidden
+	}
+	if err := applyPortfolioInput(p, in); err != nil {
+		return nil, err
+	}
+	if p.Files, err = s.files.Attach(ctx, userID, models.FileLinkPortfolio, p.ID, in.Files); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdatePortfolio(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *ExecutorService) DeletePortfolio(ctx context.Context, userID, id string) error {
+	p, err := s.repo.GetPortfolio(ctx, id)
+	if err != nil {
+		return err
+	}
+	if p.UserID != userID {
+		return ErrPortfolioForbidden
+	}
+	s.files.Release(ctx, userID, models.FileLinkPortfolio, p.ID)
+	return s.repo.DeletePortfolio(ctx, id)
+}
+
+func applyPortfolioInput(p *models.PortfolioCase, in PortfolioInput) error {
+	p.Title = strings.TrimSpace(in.Title)
+	p.Description = strings.TrimSpace(in.Description)
+	p.Category = strings.TrimSpace(in.Category)
+	p.Year = in.Year
+	if p.Title == "" || len([]rune(p.Title)) > portfolioMaxTitle || len([]rune(p.Description)) > portfolioMaxDescLen ||
+		len([]rune(p.Category)) > executorMaxTagLen {
+		return ErrPortfolioInvalid
+	}
+	return nil
+}
+
+// --- admin
+
+func (s *ExecutorService) CertificatesByStatus(ctx context.Context, status string, page, perPage int) ([]*models.Certificate, int, error) {
+	return s.repo.ListCertificatesByStatus(ctx, status, page, perPage)
+}
+
+// ReviewCertificate — админ подтверждает или отклоняет сертификат (с причиной)
+func (s *ExecutorService) ReviewCertificate(ctx context.Context, adminID, id string, verified bool, reason string) (*models.Certificate, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	c, err := s.repo.GetCertificate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	status, action := models.CertificateVerified, "certificate_verify"
+	var rejectReason *string
+	if !verified {
+		status, action = models.CertificateRejected, "certificate_reject"
+		rejectReason = &reason
+	}
+	if err := s.repo.SetCertificateStatus(ctx, id, status, adminID, rejectReason); err != nil {
+		return nil, err
+	}
+	if err := s.audit.Add(ctx, adminID, "admin."+action, "certificate", id, map[string]interface{}{
+		"reason": reason, "user_id": c.UserID, "kind": c.Kind, "old_status": c.Status,
+	}); err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, []string{c.UserID}, models.EventCertificateReviewed, map[string]interface{}{
+		"certificate_id": id, "kind": c.Kind, "verified": verified, "reason": reason,
+	})
+	return s.repo.GetCertificate(ctx, id)
+}
+
+func (s *ExecutorService) requireExecutor(userID string) error {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !u.HasRole(models.RoleExecutor) {
+		return ErrNotExecutor
+	}
+	return nil
+}
+
+// normalizeTags trims, drops empty values and duplicates; never nil
+func normalizeTags(in []string) []string {
+	out := make([]string, 0, len(in))
+	seen := map[string]bool{}
+	for _, v := range in {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+func tagsFit(tags []string) bool {
+	for _, t := range tags {
+		if len([]rune(t)) > executorMaxTagLen {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/scanner"
+	"github.com/BekzatS8/buhpro/pkg/storage"
+)
+
+const (
+	scanBatch        = 10
+	scanLease        = 5 * time.Minute // после этого «зависшая» проверка берётся повторно
+	scanPollInterval = 15 * time.Second
+	scanFileTimeout  = 2 * time.Minute
+	scanMaxAttempts  = 5 // после стольких сбоев сканера файл отклоняется (fail closed)
+)
+
+// FileScanService — асинхронная проверка загруженных файлов: сигнатура
+// (magic bytes) против заявленного mime_type, затем антивирус. Ошибки сканера
+// оставляют файл в pending_scan (fail closed) до следующей попытки.
+type FileScanService struct {
+	repo    repository.FileRepo
+	store   storage.BlobStore
+	scanner scanner.Scanner
+	audit   repository.AuditRepo
+	events  *EventService
+	wake    <-chan struct{}
+}
+
+func NewFileScanService(fr repository.FileRepo, store storage.BlobStore, sc scanner.Scanner, ar repository.AuditRepo, ev *EventService, fs *FileService) *FileScanService {
+	return &FileScanService{repo: fr, store: store, scanner: sc, audit: ar, events: ev, wake: fs.scanWake}
+}
+
+// Run blocks until ctx is cancelled
+func (s *FileScanService) Run(ctx context.Context) {
+	t := time.NewTicker(scanPollInterval)
+	defer t.Stop()
+	for {
+		s.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *FileScanService) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		files, err := s.repo.ClaimForScan(ctx, scanBatch, scanLease)
+		if err != nil {
+			log.Printf("scan: claim: %v", err)
+			return
+		}
+		if len(files) == 0 {
+			return
+		}
+		for _, f := range files {
+			// попытка засчитывается при захвате: сюда попадает и файл, на котором
+			// процесс падал, не дойдя до ошибки
+			if f.ScanAttempts > scanMaxAttempts {
+				if err := s.reject(ctx, f, models.FileStatusRejected, "scan failed repeatedly"); err != nil {
+					log.Printf("scan: file %s: %v", f.ID, err)
+				}
+				continue
+			}
+			if err := s.scanOne(ctx, f); err != nil {
+				log.Printf("scan: file %s (attempt %d): %v", f.ID, f.ScanAttempts, err)
+				if f.ScanAttempts >= scanMaxAttempts && ctx.Err() == nil {
+					if err := s.reject(ctx, f, models.FileStatusRejected, "scan failed repeatedly"); err != nil {
+						log.Printf("scan: file %s: %v", f.ID, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *FileScanService) scanOne(ctx context.Context, f *models.File) error {
+	ctx, cancel := context.WithTimeout(ctx, scanFileTimeout)
+	defer cancel()
+
+	obj, err := s.store.Read(ctx, f.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	// хэшируем ровно те байты, что видит сканер
+	h := sha256.New()
+	rc := io.TeeReader(obj, h)
+
+	head := make([]byte, scanner.HeadSize)
+	n, err := io.ReadFull(rc, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	head = head[:n]
+	if !scanner.MatchesMIME(head, f.MimeType) {
+		return s.reject(ctx, f, models.FileStatusRejected, "content does not match "+f.MimeType)
+	}
+
+	res, err := s.scanner.Scan(ctx, io.MultiReader(bytes.NewReader(head), rc))
+	if err != nil {
+		return err
+	}
+	// сканер мог не дочитать поток — добираем остаток для хэша
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != f.Checksum {
+		return s.reject(ctx, f, models.FileStatusRejected, "content does not match checksum")
+	}
+	if !res.Clean {
+		return s.reject(ctx, f, models.FileStatusQuarantined, res.Signature)
+	}
+	return s.repo.SetScanResult(ctx, f.ID, models.FileStatusClean, "")
+}
+
+// reject: карантин или отказ — фиксируем в audit_logs и уведомляем загрузившего
+func (s *FileScanService) reject(ctx context.Context, f *models.File, status, reason string) error {
+	if err := s.repo.SetScanResult(ctx, f.ID, status, reason); err != nil {
+		return err
+	}
+	eventType := models.EventFileQuarantined
+	if status == models.FileStatusRejected {
+		eventType = models.EventFileRejected
+	}
+	_ = s.audit.Add(ctx, "", eventType, "file", f.ID, map[string]interface{}{
+		"owner_id": f.OwnerID, "name": f.Name, "mime_type": f.MimeType, "reason": reason,
+	})
+	s.events.Publish(ctx, []string{f.OwnerID}, eventType, map[string]interface{}{
+		"file_id": f.ID, "name": f.Name, "reason": reason,
+	})
+	return nil
+}
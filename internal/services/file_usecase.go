@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	fileUploadURLTTL   = 15 * time.Minute
+	fileDownloadURLTTL = 5 * time.Minute
+	fileVerifyLease    = 10 * time.Minute // зависшую проверку (падение инстанса) можно повторить
+	maxAttachments     = 10
+	maxFileNameLen     = 255
+)
+
+var (
+	ErrFileTooLarge        = &ServiceError{"file is too large"}
+	ErrFileTypeNotAllowed  = &ServiceError{"file type is not allowed"}
+	ErrFileBadChecksum     = &ServiceError{"checksum_sha256 must be a hex SHA-256"}
+	ErrFileBadName         = &ServiceError{"file name is required"}
+	ErrFileForbidden       = &ServiceError{"no access to this file"}
+	ErrFileNotUploaded     = &ServiceError{"file content is not uploaded"}
+	ErrFileMismatch        = &ServiceError{"uploaded content does not match declared size or checksum"}
+	ErrFileInUse           = &ServiceError{"file is attached and cannot be deleted"}
+	ErrInvalidAttachments  = &ServiceError{"attachments must be ids of your uploaded, unattached files"}
+	ErrTooManyAttachments  = &ServiceError{"too many attachments"}
+	ErrFileAlreadyUploaded = &ServiceError{"file is already uploaded"}
+	ErrFileNotClean        = &ServiceError{"file is not available: awaiting scan, quarantined or rejected"}
+)
+
+var allowedMimeTypes = map[string]bool{
+	"application/pdf":    true,
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/webp":         true,
+	"text/plain":         true,
+	"text/csv":           true,
+	"application/xml":    true,
+	"text/xml":           true,
+	"application/zip":    true,
+	"application/msword": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.ms-excel": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": true,
+}
+
+var sha256Hex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// FileUploadInput — заявка на загрузку; OrganizationID привязывает файл
+// к организации владельца (документы верификации)
+type FileUploadInput struct {
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	MimeType       string `json:"mime_type"`
+	Checksum       string `json:"checksum_sha256"`
+	OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// FileService — метаданные файлов и presigned-ссылки. Содержимое идёт
+// напрямую в BlobStore, API проверяет результат в Complete.
+type FileService struct {
+	repo      repository.FileRepo
+	store     storage.BlobStore
+	orderRepo repository.OrderRepo
+	bidRepo   repository.BidRepo
+	chatRepo  repository.ChatRepo
+	userRepo  repository.UserRepo
+	delivRepo repository.DeliverableRepo
+	dispRepo  repository.DisputeRepo
+	invRepo   repository.InvitationRepo
+	maxSize   int64
+	scanWake  chan struct{} // будит FileScanService после Complete
+}
+
+func NewFileService(fr repository.FileRepo, store storage.BlobStore, or repository.OrderRepo, br repository.BidRepo, cr repository.ChatRepo, ur repository.UserRepo, dr repository.DeliverableRepo, dsr repository.DisputeRepo, ir repository.InvitationRepo, maxSize int64) *FileService {
+	return &FileService{repo: fr, store: store, orderRepo: or, bidRepo: br, chatRepo: cr, userRepo: ur, delivRepo: dr, dispRepo: dsr, invRepo: ir, maxSize: maxSize, scanWake: make(chan struct{}, 1)}
+}
+
+func (s *FileService) MaxSize() int64 { return s.maxSize }
+
+func (s *FileService) CreateUpload(ctx context.Context, ownerID string, in FileUploadInput) (*models.File, *storage.UploadURL, error) {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(in.Name, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		return nil, nil, ErrFileBadName
+	}
+	if r := []rune(name); len(r) > maxFileNameLen {
+		name = string(r[:maxFileNameLen])
+	}
+	if in.Size <= 0 || in.Size > s.maxSize {
+		return nil, nil, ErrFileTooLarge
+	}
+	mt, _, err := mime.ParseMediaType(in.MimeType)
+	if err != nil || !allowedMimeTypes[mt] {
+		return nil, nil, ErrFileTypeNotAllowed
+	}
+	sum := strings.ToLower(in.Checksum)
+	if !sha256Hex.MatchString(sum) {
+		return nil, nil, ErrFileBadChecksum
+	}
+
+	f := &models.File{
+		ID:       uuid.NewString(),
+		OwnerID:  ownerID,
+		Name:     name,
+		Size:     in.Size,
+		MimeType: mt,
+		Checksum: sum,
+		Status:   models.FileStatusPending,
+	}
+	if in.OrganizationID != "" {
+		owner, err := s.repo.OrganizationOwner(ctx, in.OrganizationID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if owner != ownerID {
+			return nil, nil, ErrFileForbidden
+		}
+		lt, lid := models.FileLinkOrganization, in.OrganizationID
+		f.LinkedType, f.LinkedID = &lt, &lid
+	}
+	// ключ не содержит имени файла: имя отдаётся только в Content-Disposition
+	f.StorageKey = fmt.Sprintf("%s/%s", time.Now().UTC().Format("2006/01"), f.ID)
+
+	up, err := s.store.PresignPut(ctx, uploadKey(f), f.MimeType, fileUploadURLTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.repo.Create(ctx, f); err != nil {
+		return nil, nil, err
+	}
+	return f, up, nil
+}
+
+// uploadKey — куда клиент грузит по presigned PUT; постоянный StorageKey
+// пишет только сервер (Complete), поэтому подменить проверенное содержимое
+// повторным PUT по ещё живой ссылке нельзя. Брошенные объекты под uploads/
+// чистит lifecycle-правило бакета (срок больше fileUploadURLTTL).
+func uploadKey(f *models.File) string {
+	return "uploads/" + f.StorageKey
+}
+
+// Complete copies the uploaded object to its permanent key, hashing the content
+// server-side against the declared size/checksum, and queues it for scanning;
+// the file is downloadable only once it is clean.
+func (s *FileService) Complete(ctx context.Context, id, userID string) (*models.File, error) {
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f.OwnerID != userID {
+		return nil, ErrFileForbidden
+	}
+	if f.Status != models.FileStatusPending && f.Status != models.FileStatusVerifying {
+		return nil, ErrFileAlreadyUploaded
+	}
+	ok, err := s.repo.BeginVerify(ctx, f.ID, fileVerifyLease)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrFileAlreadyUploaded
+	}
+	if err := s.verify(ctx, f); err != nil {
+		_ = s.repo.ResetPending(ctx, f.ID)
+		return nil, err
+	}
+	_ = s.store.Delete(ctx, uploadKey(f))
+	if err := s.repo.MarkUploaded(ctx, f.ID); err != nil {
+		return nil, err
+	}
+	select {
+	case s.scanWake <- struct{}{}:
+	default:
+	}
+	return s.repo.GetByID(ctx, f.ID)
+}
+
+func (s *FileService) verify(ctx context.Context, f *models.File) error {
+	src := uploadKey(f)
+	info, err := s.store.Stat(ctx, src)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrFileNotUploaded
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size != f.Size {
+		_ = s.store.Delete(ctx, src)
+		return ErrFileMismatch
+	}
+	got, err := s.store.Copy(ctx, src, f.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return ErrFileNotUploaded
+	}
+	if err != nil {
+		return err
+	}
+	if got.Size != f.Size || got.SHA256 != f.Checksum {
+		_ = s.store.Delete(ctx, f.StorageKey)
+		_ = s.store.Delete(ctx, src)
+		return ErrFileMismatch
+	}
+	return nil
+}
+
+func (s *FileService) Get(ctx context.Context, id, userID string) (*models.File, error) {
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canAccess(ctx, f, userID) {
+		return nil, ErrFileForbidden
+	}
+	return f, nil
+}
+
+// DownloadURL returns a short-lived presigned GET link
+func (s *FileService) DownloadURL(ctx context.Context, id, userID string) (string, time.Time, error) {
+	f, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	switch f.Status {
+	case models.FileStatusClean:
+	case models.FileStatusPending, models.FileStatusVerifying:
+		return "", time.Time{}, ErrFileNotUploaded
+	default:
+		return "", time.Time{}, ErrFileNotClean
+	}
+	u, err := s.store.PresignGet(ctx, f.StorageKey, f.Name, fileDownloadURLTTL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return u, time.Now().Add(fileDownloadURLTTL), nil
+}
+
+func (s *FileService) Delete(ctx context.Context, id, userID string) error {
+	f, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if f.OwnerID != userID {
+		return ErrFileForbidden
+	}
+	if f.LinkedID != nil && (f.LinkedType == nil || *f.LinkedType != models.FileLinkOrganization) {
+		return ErrFileInUse
+	}
+	// карантин хранится для разбора инцидента
+	if f.Status == models.FileStatusQuarantined {
+		return ErrFileForbidden
+	}
+	if err := s.store.Delete(ctx, f.StorageKey); err != nil {
+		return err
+	}
+	_ = s.store.Delete(ctx, uploadKey(f))
+	return s.repo.Delete(ctx, f.ID)
+}
+
+// Attach makes ids the attachment set of the object and returns the
+// normalized list (deduplicated, never nil) to store in its attachments field.
+func (s *FileService) Attach(ctx context.Context, ownerID, linkedType, linkedID string, ids []string) ([]string, error) {
+	out := make([]string, 0, len(ids))
+	seen := map[string]bool{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if seen[id] {
+			continue
+		}
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, ErrInvalidAttachments
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	if len(out) > maxAttachments {
+		return nil, ErrTooManyAttachments
+	}
+	if err := s.repo.SetLinks(ctx, ownerID, linkedType, linkedID, out); err != nil {
+		if errors.Is(err, repository.ErrFilesNotAttachable) {
+			return nil, ErrInvalidAttachments
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// Release detaches all files of an object that failed to be created
+func (s *FileService) Release(ctx context.Context, ownerID, linkedType, linkedID string) {
+	_ = s.repo.SetLinks(ctx, ownerID, linkedType, linkedID, nil)
+}
+
+// canAccess: владелец, админ или участник объекта, к которому привязан файл
+func (s *FileService) canAccess(ctx context.Context, f *models.File, userID string) bool {
+	if f.OwnerID == userID {
+		return true
+	}
+	if u, err := s.userRepo.GetByID(userID); err == nil && u.HasRole(models.RoleAdmin) {
+		return true
+	}
+	if f.LinkedType == nil || f.LinkedID == nil {
+		return false
+	}
+	switch *f.LinkedType {
+	case models.FileLinkOrder:
+		o, err := s.orderRepo.GetByID(ctx, *f.LinkedID)
+		if err != nil {
+			return false
+		}
+		if o.ClientUserID == userID {
+			return true
+		}
+		// опубликованный заказ видят все исполнители, приватный — только приглашённые
+		if o.Status == "published" {
+			if ok, err := canSeeOrder(ctx, s.invRepo, o, userID); err == nil && ok {
+				return true
+			}
+		}
+		bids, err := s.bidRepo.ListByOrder(ctx, o.ID)
+		if err != nil {
+			return false
+		}
+		for _, b := range bids {
+			if b.ExecutorID == userID {
+				return true
+			}
+		}
+	case models.FileLinkBid:
+		b, err := s.bidRepo.GetByID(ctx, *f.LinkedID)
+		if err != nil {
+			return false
+		}
+		if b.ExecutorID == userID {
+			return true
+		}
+		o, err := s.orderRepo.GetByID(ctx, b.OrderID)
+		return err == nil && o.ClientUserID == userID && b.VisibleToClient
+	case models.FileLinkMessage:
+		m, err := s.chatRepo.GetMessage(ctx, *f.LinkedID)
+		if err != nil {
+			return false
+		}
+		c, err := s.chatRepo.GetByID(ctx, m.ConversationID)
+		return err == nil && (c.ClientID == userID || c.ExecutorID == userID)
+	case models.FileLinkOrganization:
+		owner, err := s.repo.OrganizationOwner(ctx, *f.LinkedID)
+		return err == nil && owner == userID
+	case models.FileLinkDeliverable:
+		d, err := s.delivRepo.GetByID(ctx, *f.LinkedID)
+		if err != nil {
+			return false
+		}
+		o, err := s.orderRepo.GetByID(ctx, d.OrderID)
+		return err == nil && o.ClientUserID == userID
+	case models.FileLinkDispute:
+		d, err := s.dispRepo.GetByID(ctx, *f.LinkedID)
+		return err == nil && (d.ClientID == userID || d.ExecutorID == userID)
+	case models.FileLinkDisputeMessage:
+		m, err := s.dispRepo.GetMessage(ctx, *f.LinkedID)
+		if err != nil {
+			return false
+		}
+		d, err := s.dispRepo.GetByID(ctx, m.DisputeID)
+		return err == nil && (d.ClientID == userID || d.ExecutorID == userID)
+	case models.FileLinkPortfolio:
+		// портфолио публично; скан сертификата — только владельцу и админу
+		return true
+	}
+	return false
+}
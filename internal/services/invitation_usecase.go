@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const invitationMaxMessageLen = 2000
+
+var (
+	ErrInvitationForbidden = &ServiceError{"no access to this invitation"}
+	ErrInviteeNotExecutor  = &ServiceError{"only an active executor can be invited"}
+	ErrAlreadyInvited      = &ServiceError{"executor is already invited to this order"}
+	ErrInvitationClosed    = &ServiceError{"invitation is no longer open"}
+	ErrInvitationMessage   = &ServiceError{"invitation message is too long"}
+	ErrNotInvited          = &ServiceError{"this order accepts bids by invitation only"}
+)
+
+type InvitationInput struct {
+	ExecutorID string `json:"executor_id" binding:"required"`
+	Message    string `json:"message"`
+}
+
+// InvitationService — клиент приглашает исполнителя к опубликованному заказу
+// (в том числе приватному, которого нет в ленте). Приглашённый откликается со
+// скидкой feeDiscount % на плату за ставку (100 — бесплатно).
+type InvitationService struct {
+	repo        repository.InvitationRepo
+	orderRepo   repository.OrderRepo
+	userRepo    repository.UserRepo
+	events      *EventService
+	feeDiscount int
+}
+
+func NewInvitationService(ir repository.InvitationRepo, or repository.OrderRepo, ur repository.UserRepo, ev *EventService, feeDiscount int) *InvitationService {
+	if feeDiscount < 0 {
+		feeDiscount = 0
+	}
+	if feeDiscount > 100 {
+		feeDiscount = 100
+	}
+	return &InvitationService{repo: ir, orderRepo: or, userRepo: ur, events: ev, feeDiscount: feeDiscount}
+}
+
+func (s *InvitationService) Invite(ctx context.Context, orderID, clientID string, in InvitationInput) (*models.Invitation, error) {
+	in.Message = strings.TrimSpace(in.Message)
+	if len([]rune(in.Message)) > invitationMaxMessageLen {
+		return nil, ErrInvitationMessage
+	}
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.ClientUserID != clientID {
+		return nil, ErrInvitationForbidden
+	}
+	if o.Status != "published" {
+		return nil, ErrOrderWrongState
+	}
+	if in.ExecutorID == clientID {
+		return nil, ErrInviteeNotExecutor
+	}
+	u, err := s.userRepo.GetByID(in.ExecutorID)
+	if err != nil || u.Status != "active" || !u.HasRole(models.RoleExecutor) {
+		return nil, ErrInviteeNotExecutor
+	}
+	inv := &models.Invitation{
+		ID:          uuid.NewString(),
+		OrderID:     orderID,
+		ClientID:    clientID,
+		ExecutorID:  in.ExecutorID,
+		Message:     in.Message,
+		FeeDiscount: s.feeDiscount,
+	}
+	if err := s.repo.Create(ctx, inv); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlreadyInvited
+		}
+		return nil, err
+	}
+	s.events.Publish(ctx, []string{inv.ExecutorID}, models.EventInvitationReceived, map[string]interface{}{
+		"invitation_id": inv.ID, "order_id": orderID, "title": o.Title, "fee_discount": inv.FeeDiscount,
+	})
+	return inv, nil
+}
+
+func (s *InvitationService) ListByOrder(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Invitation, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && o.ClientUserID != userID {
+		return nil, ErrInvitationForbidden
+	}
+	list, err := s.repo.ListByOrder(ctx, orderID)
+	if list == nil {
+		list = []*models.Invitation{}
+	}
+	return list, err
+}
+
+// Mine — входящие приглашения исполнителя вместе с заказами
+func (s *InvitationService) Mine(ctx context.Context, executorID, status string, page, perPage int) ([]*models.Invitation, int, error) {
+	list, total, err := s.repo.ListForExecutor(ctx, executorID, status, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, inv := range list {
+		if o, err := s.orderRepo.GetByID(ctx, inv.OrderID); err == nil {
+			inv.Order = o
+		}
+	}
+	if list == nil {
+		list = []*models.Invitation{}
+	}
+	return list, total, nil
+}
+
+// Respond — исполнитель принимает (pending) или отклоняет (pending/accepted) приглашение
+func (s *InvitationService) Respond(ctx context.Context, id, executorID string, accept bool) (*models.Invitation, error) {
+	inv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv.ExecutorID != executorID {
+		return nil, ErrInvitationForbidden
+	}
+	status, from := models.InvitationDeclined, []string{models.InvitationPending, models.InvitationAccepted}
+	if accept {
+		status, from = models.InvitationAccepted, []string{models.InvitationPending}
+	}
+	if err := s.repo.SetStatus(ctx, id, status, from...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationClosed
+		}
+		return nil, err
+	}
+	payload := map[string]interface{}{"invitation_id": id, "order_id": inv.OrderID, "executor_id": executorID, "accepted": accept}
+	if o, err := s.orderRepo.GetByID(ctx, inv.OrderID); err == nil {
+		payload["title"] = o.Title
+	}
+	s.events.Publish(ctx, []string{inv.ClientID}, models.EventInvitationAnswered, payload)
+	return s.repo.GetByID(ctx, id)
+}
+
+// Revoke — клиент отзывает приглашение, пока по нему не сделана ставка
+func (s *InvitationService) Revoke(ctx context.Context, id, clientID string) error {
+	inv, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inv.ClientID != clientID {
+		return ErrInvitationForbidden
+	}
+	if err := s.repo.SetStatus(ctx, id, models.InvitationRevoked, models.InvitationPending, models.InvitationAccepted); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvitationClosed
+		}
+		return err
+	}
+	return nil
+}
+
+// canSeeOrder: публичный заказ видят все, приватный — клиент и приглашённые исполнители
+// (админа проверяет вызывающий)
+func canSeeOrder(ctx context.Context, invitations repository.InvitationRepo, o *models.Order, userID string) (bool, error) {
+	if o.Visibility != models.OrderPrivate || o.ClientUserID == userID {
+		return true, nil
+	}
+	if userID == "" {
+		return false, nil
+	}
+	return invitations.Invited(ctx, o.ID, userID)
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+)
+
+const (
+	matchMinScore      = 30 // ниже — заказ не рекомендуется
+	matchRescanLimit   = 500
+	matchDigestBatch   = 1000
+	matchDigestTitles  = 3
+	matchDigestMinimum = time.Hour
+
+	matchPointsSpecialization = 40
+	matchPointsRegion         = 25
+	matchPointsOnline         = 15
+	matchPointsHistoryEach    = 5
+	matchPointsHistoryMax     = 15
+	matchPointsBudget         = 10
+	matchPointsBudgetHistory  = 10
+)
+
+// MatchService — рекомендации заказов исполнителям. Оценка считается один раз
+// при публикации заказа (для всех подходящих исполнителей) и при сохранении
+// профиля (для всех опубликованных заказов, в фоне — RunRescans); дайджест
+// рассылается периодически.
+type MatchService struct {
+	repo         repository.MatchRepo
+	orderRepo    repository.OrderRepo
+	executorRepo repository.ExecutorRepo
+	events       *EventService
+	digestEvery  time.Duration
+
+	mu         sync.Mutex
+	rescan     map[string]struct{} // исполнители, ждущие пересчёта
+	rescanWake chan struct{}
+}
+
+func NewMatchService(mr repository.MatchRepo, or repository.OrderRepo, er repository.ExecutorRepo, ev *EventService, digestEvery time.Duration) *MatchService {
+	return &MatchService{repo: mr, orderRepo: or, executorRepo: er, events: ev, digestEvery: digestEvery,
+		rescan: map[string]struct{}{}, rescanWake: make(chan struct{}, 1)}
+}
+
+// OrderPublished — хук OrderService: оценивает новый заказ для кандидатов
+func (s *MatchService) OrderPublished(ctx context.Context, o *models.Order) {
+	if o.Visibility == models.OrderPrivate {
+		return
+	}
+	candidates, err := s.repo.Candidates(ctx, o)
+	if err != nil {
+		log.Printf("matching: candidates for order %s: %v", o.ID, err)
+		return
+	}
+	ids := make([]string, 0, len(candidates))
+	for _, p := range candidates {
+		ids = append(ids, p.UserID)
+	}
+	history, err := s.repo.History(ctx, ids)
+	if err != nil {
+		log.Printf("matching: history for order %s: %v", o.ID, err)
+		return
+	}
+	for _, p := range candidates {
+		s.save(ctx, o, p, history[p.UserID])
+	}
+}
+
+// ProfileSaved — хук ExecutorService: ставит пересчёт по опубликованным
+// заказам в очередь; повторные сохранения до пересчёта схлопываются
+func (s *MatchService) ProfileSaved(_ context.Context, executorID string) {
+	s.mu.Lock()
+	s.rescan[executorID] = struct{}{}
+	s.mu.Unlock()
+	select {
+	case s.rescanWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunRescans recomputes matches for executors queued by ProfileSaved.
+// Blocks until ctx is cancelled.
+func (s *MatchService) RunRescans(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.rescanWake:
+		}
+		for ctx.Err() == nil {
+			id, ok := s.nextRescan()
+			if !ok {
+				break
+			}
+			s.rescanProfile(ctx, id)
+		}
+	}
+}
+
+func (s *MatchService) nextRescan() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.rescan {
+		delete(s.rescan, id)
+		return id, true
+	}
+	return "", false
+}
+
+func (s *MatchService) rescanProfile(ctx context.Context, executorID string) {
+	p, err := s.executorRepo.GetProfile(ctx, executorID)
+	if err != nil {
+		log.Printf("matching: profile %s: %v", executorID, err)
+		return
+	}
+	history, err := s.repo.History(ctx, []string{executorID})
+	if err != nil {
+		log.Printf("matching: history %s: %v", executorID, err)
+		return
+	}
+	orders, _, err := s.orderRepo.List(ctx, map[string]string{"status": "published", "visibility": models.OrderPublic}, 1, matchRescanLimit)
+	if err != nil {
+		log.Printf("matching: published orders: %v", err)
+		return
+	}
+	keep := []string{}
+	for _, o := range orders {
+		if o.ClientUserID != executorID && s.save(ctx, o, p, history[executorID]) {
+			keep = append(keep, o.ID)
+		}
+	}
+	if err := s.repo.Prune(ctx, executorID, keep); err != nil {
+		log.Printf("matching: prune %s: %v", executorID, err)
+	}
+}
+
+// save stores the match if it scores high enough; reports whether it did
+func (s *MatchService) save(ctx context.Context, o *models.Order, p *models.ExecutorProfile, h *models.ExecutorHistory) bool {
+	score, reasons := scoreMatch(o, p, h)
+	if score < matchMinScore {
+		return false
+	}
+	m := &models.OrderMatch{OrderID: o.ID, ExecutorID: p.UserID, Score: score, Reasons: reasons}
+	if err := s.repo.Save(ctx, m); err != nil {
+		log.Printf("matching: save %s/%s: %v", o.ID, p.UserID, err)
+		return false
+	}
+	return true
+}
+
+// scoreMatch оценивает заказ для исполнителя (0–100) и объясняет оценку
+func scoreMatch(o *models.Order, p *models.ExecutorProfile, h *models.ExecutorHistory) (int, []models.MatchReason) {
+	if !p.Available || (!o.ModeOnline && p.WorkMode == models.WorkModeOnline) {
+		return 0, nil
+	}
+	// выше ставки "от" исполнителя бюджет не дотягивает — не рекомендуем
+	top := o.BudgetMax
+	if top == nil {
+		top = o.BudgetMin
+	}
+	if top != nil && p.PriceFrom != nil && *top < *p.PriceFrom {
+		return 0, nil
+	}
+	score := 0
+	reasons := []models.MatchReason{}
+	add := func(code, value string, points int, text string) {
+		score += points
+		reasons = append(reasons, models.MatchReason{Code: code, Value: value, Points: points, Text: text})
+	}
+	if o.Category != "" && containsTag(p.Specializations, o.Category) {
+		add(models.MatchSpecialization, o.Category, matchPointsSpecialization, "matches your specialization "+o.Category)
+	}
+	if o.Region != "" && containsTag(p.Regions, o.Region) {
+		add(models.MatchRegion, o.Region, matchPointsRegion, "in your region "+o.Region)
+	} else if o.ModeOnline && p.WorkMode != models.WorkModeOffline {
+		add(models.MatchOnline, "", matchPointsOnline, "can be done remotely")
+	}
+	if h != nil {
+		if n := h.WonByCategory[o.Category]; n > 0 && o.Category != "" {
+			pts := n * matchPointsHistoryEach
+			if pts > matchPointsHistoryMax {
+				pts = matchPointsHistoryMax
+			}
+			add(models.MatchHistory, o.Category, pts, fmt.Sprintf("you were chosen for %d order(s) in %s", n, o.Category))
+		}
+		if top != nil && h.AvgPrice > 0 && *top >= h.AvgPrice {
+			add(models.MatchBudget, fmt.Sprint(*top), matchPointsBudgetHistory,
+				fmt.Sprintf("budget up to %d ₸ is in line with your past orders (avg %d ₸)", *top, h.AvgPrice))
+		}
+	}
+	if top != nil && p.PriceFrom != nil {
+		add(models.MatchBudget, fmt.Sprint(*top), matchPointsBudget, fmt.Sprintf("budget up to %d ₸ fits your rate from %d ₸", *top, *p.PriceFrom))
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score, reasons
+}
+
+func containsTag(tags []string, v string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Recommended — лента рекомендованных заказов исполнителя, лучшие сверху
+func (s *MatchService) Recommended(ctx context.Context, executorID string, page, perPage int) ([]*models.OrderMatch, int, error) {
+	list, total, err := s.repo.ListForExecutor(ctx, executorID, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, m := range list {
+		if o, err := s.orderRepo.GetByID(ctx, m.OrderID); err == nil {
+			m.Order = o
+		}
+	}
+	if list == nil {
+		list = []*models.OrderMatch{}
+	}
+	return list, total, nil
+}
+
+// RunDigest periodically sends each executor a digest of new recommended
+// orders. Blocks until ctx is cancelled.
+func (s *MatchService) RunDigest(ctx context.Context) {
+	if s.digestEvery <= 0 {
+		return
+	}
+	every := s.digestEvery
+	if every < matchDigestMinimum {
+		every = matchDigestMinimum
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		s.sendDigests(ctx)
+	}
+}
+
+func (s *MatchService) sendDigests(ctx context.Context) {
+	pending, err := s.repo.PendingDigest(ctx, matchDigestBatch)
+	if err != nil {
+		log.Printf("matching: digest: %v", err)
+		return
+	}
+	byExecutor := map[string][]*models.OrderMatch{}
+	var order []string
+	for _, m := range pending {
+		if _, ok := byExecutor[m.ExecutorID]; !ok {
+			order = append(order, m.ExecutorID)
+		}
+		byExecutor[m.ExecutorID] = append(byExecutor[m.ExecutorID], m)
+	}
+	for _, executorID := range order {
+		matches := byExecutor[executorID]
+		ids := make([]string, 0, len(matches))
+		var titles []string
+		for _, m := range matches {
+			ids = append(ids, m.OrderID)
+			if len(titles) < matchDigestTitles {
+				if o, err := s.orderRepo.GetByID(ctx, m.OrderID); err == nil {
+					titles = append(titles, "«"+o.Title+"»")
+				}
+			}
+		}
+		if err := s.repo.MarkNotified(ctx, executorID, ids); err != nil {
+			log.Printf("matching: digest mark %s: %v", executorID, err)
+			continue
+		}
+		s.events.Publish(ctx, []string{executorID}, models.EventOrdersMatched, map[string]interface{}{
+			"count": len(ids), "order_ids": ids, "titles": strings.Join(titles, ", "),
+		})
+	}
+}
@@ -0,0 +1,623 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	coachMaxHeadlineLen    = 200
+	sessionMaxTopicLen     = 500
+	sessionMinDuration     = 30 * time.Minute
+	sessionMaxDuration     = 4 * time.Hour
+	slotMaxAhead           = 90 * 24 * time.Hour
+	sessionPaymentHold     = 30 * time.Minute // неоплаченная запись держит окно столько
+	sessionMaxReschedules  = 2
+	sessionLateRefundShare = 50 // % цены, возвращаемый при поздней отмене подопечным
+	mentoringTick          = 5 * time.Minute
+)
+
+// kzTime — время в уведомлениях (Казахстан — UTC+5)
+var kzTime = time.FixedZone("UTC+5", 5*60*60)
+
+var (
+	ErrNotCoach            = &ServiceError{"coach role is required"}
+	ErrCoachProfileInvalid = &ServiceError{"coach profile: hourly_rate must be positive; headline, bio, topics or experience out of limits"}
+	ErrCoachLanguage       = &ServiceError{"languages must be ru, kk or en"}
+	ErrSlotInvalid         = &ServiceError{"slot must start in the future (within 90 days) and last from 30 minutes to 4 hours"}
+	ErrSlotOverlap         = &ServiceError{"slot overlaps another slot"}
+	ErrSlotUnavailable     = &ServiceError{"slot is no longer available"}
+	ErrSlotBooked          = &ServiceError{"booked slot can not be deleted; cancel the session instead"}
+	ErrOwnSlot             = &ServiceError{"you can not book your own slot"}
+	ErrSlotMismatch        = &ServiceError{"new slot must belong to the same coach and have the same duration"}
+	ErrSessionForbidden    = &ServiceError{"not a participant of this session"}
+	ErrSessionWrongState   = &ServiceError{"action is not allowed in the current session status"}
+	ErrRescheduleClosed    = &ServiceError{"session can be rescheduled only before the free cancellation deadline"}
+	ErrRescheduleLimit     = &ServiceError{"session was rescheduled too many times"}
+	ErrSessionTopic        = &ServiceError{"topic is too long"}
+)
+
+type CoachProfileInput struct {
+	Headline        string   `json:"headline"`
+	Bio             string   `json:"bio"`
+	Topics          []string `json:"topics"`
+	Languages       []string `json:"languages"`
+	ExperienceYears int      `json:"experience_years"`
+	HourlyRate      int64    `json:"hourly_rate"`
+	Active          *bool    `json:"active"` // nil = true
+}
+
+type SlotInput struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+type BookingInput struct {
+	SlotID string `json:"slot_id" binding:"required"`
+	Topic  string `json:"topic" binding:"required"`
+}
+
+// CoachCard — публичная карточка наставника со свободными окнами
+type CoachCard struct {
+	ID          string                `json:"id"`
+	FullName    string                `json:"full_name"`
+	MemberSince time.Time             `json:"member_since"`
+	Profile     *models.CoachProfile  `json:"profile"`
+	Rating      *models.RatingSummary `json:"rating"`
+	Slots       []*models.CoachSlot   `json:"slots"`
+}
+
+// SessionCheckout — запись на консультацию и платёж, который нужно провести
+type SessionCheckout struct {
+	Session *models.MentoringSession `json:"session"`
+	Payment *models.Payment          `json:"payment"`
+}
+
+// MentoringService — консультации наставников (роль coach). Подопечный
+// бронирует свободное окно и оплачивает его; деньги удерживаются на платеже
+// до конца сессии и уходят наставнику, при отмене возвращаются по правилам:
+// наставник — всегда полностью, подопечный — полностью не позже чем за
+// cancelWindow до начала, позже — sessionLateRefundShare%. Перенос — на другое
+// окно того же наставника той же длины, до того же срока.
+type MentoringService struct {
+	repo         repository.MentoringRepo
+	paymentRepo  repository.PaymentRepo
+	userRepo     repository.UserRepo
+	reviewRepo   repository.ReviewRepo
+	events       *EventService
+	taxonomy     *TaxonomyService
+	tax          *TaxService
+	tx           repository.TxRunner
+	cancelWindow time.Duration
+}
+
+func NewMentoringService(mr repository.MentoringRepo, pr repository.PaymentRepo, ur repository.UserRepo, rr repository.ReviewRepo, ev *EventService, ts *TaxonomyService, tx *TaxService, txr repository.TxRunner, cancelWindow time.Duration) *MentoringService {
+	return &MentoringService{repo: mr, paymentRepo: pr, userRepo: ur, reviewRepo: rr, events: ev, taxonomy: ts, tax: tx, tx: txr, cancelWindow: cancelWindow}
+}
+
+func (s *MentoringService) requireCoach(userID string) error {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !u.HasRole(models.RoleCoach) {
+		return ErrNotCoach
+	}
+	return nil
+}
+
+// --- профиль и каталог
+
+// Profile — профиль наставника; pgx.ErrNoRows, если ещё не заполнен
+func (s *MentoringService) Profile(ctx context.Context, userID string) (*models.CoachProfile, error) {
+	return s.repo.GetProfile(ctx, userID)
+}
+
+func (s *MentoringService) SaveProfile(ctx context.Context, userID string, in CoachProfileInput) (*models.CoachProfile, error) {
+	if err := s.requireCoach(userID); err != nil {
+		return nil, err
+	}
+	p := &models.CoachProfile{
+		UserID:          userID,
+		Headline:        strings.TrimSpace(in.Headline),
+		Bio:             strings.TrimSpace(in.Bio),
+		Topics:          normalizeTags(in.Topics),
+		Languages:       normalizeTags(in.Languages),
+		ExperienceYears: in.ExperienceYears,
+		HourlyRate:      in.HourlyRate,
+		Active:          in.Active == nil || *in.Active,
+	}
+	if p.HourlyRate <= 0 || len([]rune(p.Headline)) > coachMaxHeadlineLen || len([]rune(p.Bio)) > executorMaxBioLen ||
+		p.ExperienceYears < 0 || p.ExperienceYears > executorMaxYears || len(p.Topics) > executorMaxTags || !tagsFit(p.Topics) {
+		return nil, ErrCoachProfileInvalid
+	}
+	for _, l := range p.Languages {
+		if !notificationLocales[l] {
+			return nil, ErrCoachLanguage
+		}
+	}
+	var err error
+	if p.Topics, err = s.taxonomy.Categories(ctx, p.Topics); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SaveProfile(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *MentoringService) Search(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.CoachSummary, int, error) {
+	s.taxonomy.ResolveFilters(ctx, filters, "topic", "")
+	list, total, err := s.repo.Search(ctx, filters, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	if list == nil {
+		return []*models.CoachSummary{}, total, nil
+	}
+	ids := make([]string, 0, len(list))
+	for _, c := range list {
+		ids = append(ids, c.ID)
+	}
+	ratings, err := s.reviewRepo.Summaries(ctx, ids, models.ReviewAuthorMentee)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, c := range list {
+		c.Rating = ratings[c.ID]
+	}
+	return list, total, nil
+}
+
+// Public — карточка наставника; pgx.ErrNoRows, если он не активен
+func (s *MentoringService) Public(ctx context.Context, id string) (*CoachCard, error) {
+	u, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != "active" || !u.HasRole(models.RoleCoach) {
+		return nil, pgx.ErrNoRows
+	}
+	p, err := s.repo.GetProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Active {
+		return nil, pgx.ErrNoRows
+	}
+	card := &CoachCard{ID: u.ID, FullName: u.FullName, MemberSince: u.CreatedAt, Profile: p}
+	if card.Slots, err = s.slots(ctx, id, true); err != nil {
+		return nil, err
+	}
+	ratings, err := s.reviewRepo.Summaries(ctx, []string{id}, models.ReviewAuthorMentee)
+	if err != nil {
+		return nil, err
+	}
+	card.Rating = ratings[id]
+	return card, nil
+}
+
+// --- окна
+
+// Slots — будущие окна наставника, включая забронированные
+func (s *MentoringService) Slots(ctx context.Context, coachID string) ([]*models.CoachSlot, error) {
+	return s.slots(ctx, coachID, false)
+}
+
+func (s *MentoringService) slots(ctx context.Context, coachID string, onlyOpen bool) ([]*models.CoachSlot, error) {
+	list, err := s.repo.ListSlots(ctx, coachID, time.Now(), onlyOpen)
+	if list == nil {
+		list = []*models.CoachSlot{}
+	}
+	return list, err
+}
+
+func (s *MentoringService) AddSlot(ctx context.Context, coachID string, in SlotInput) (*models.CoachSlot, error) {
+	if err := s.requireCoach(coachID); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetProfile(ctx, coachID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCoachProfileInvalid
+		}
+		return nil, err
+	}
+	start, end := in.StartsAt.Truncate(time.Minute), in.EndsAt.Truncate(time.Minute)
+	d := end.Sub(start)
+	if !start.After(time.Now()) || start.After(time.Now().Add(slotMaxAhead)) || d < sessionMinDuration || d > sessionMaxDuration {
+		return nil, ErrSlotInvalid
+	}
+	slot := &models.CoachSlot{ID: uuid.NewString(), CoachID: coachID, StartsAt: start, EndsAt: end}
+	ok, err := s.repo.AddSlot(ctx, slot)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSlotOverlap
+	}
+	return slot, nil
+}
+
+func (s *MentoringService) DeleteSlot(ctx context.Context, coachID, id string) error {
+	slot, err := s.repo.GetSlot(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slot.CoachID != coachID {
+		return pgx.ErrNoRows
+	}
+	ok, err := s.repo.DeleteSlot(ctx, id, coachID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSlotBooked
+	}
+	return nil
+}
+
+// --- консультации
+
+// Book — запись на окно; окно держится sessionPaymentHold до оплаты
+func (s *MentoringService) Book(ctx context.Context, menteeID string, in BookingInput) (*SessionCheckout, error) {
+	in.Topic = strings.TrimSpace(in.Topic)
+	if len([]rune(in.Topic)) > sessionMaxTopicLen {
+		return nil, ErrSessionTopic
+	}
+	slot, err := s.repo.GetSlot(ctx, in.SlotID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSlotUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if slot.CoachID == menteeID {
+		return nil, ErrOwnSlot
+	}
+	p, err := s.repo.GetProfile(ctx, slot.CoachID)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Active {
+		return nil, ErrSlotUnavailable
+	}
+	ok, err := s.repo.BookSlot(ctx, slot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSlotUnavailable
+	}
+	ms := &models.MentoringSession{
+		ID:       uuid.NewString(),
+		CoachID:  slot.CoachID,
+		MenteeID: menteeID,
+		SlotID:   &slot.ID,
+		StartsAt: slot.StartsAt,
+		EndsAt:   slot.EndsAt,
+		Topic:    in.Topic,
+		Price:    sessionPrice(p.HourlyRate, slot.EndsAt.Sub(slot.StartsAt)),
+		Status:   models.SessionPendingPayment,
+	}
+	expires := time.Now().Add(sessionPaymentHold)
+	pay := &models.Payment{
+		ID:          uuid.NewString(),
+		UserID:      &menteeID,
+		RelatedType: "mentoring_session",
+		RelatedID:   &ms.ID,
+		Provider:    "mock",
+		Amount:      ms.Price,
+		Currency:    "KZT",
+		Status:      "initiated",
+		ExpiresAt:   &expires,
+		Items:       map[string]interface{}{"coach_id": ms.CoachID, "starts_at": ms.StartsAt, "minutes": int(slot.EndsAt.Sub(slot.StartsAt) / time.Minute)},
+	}
+	err = s.tax.Apply(ctx, pay)
+	if err == nil {
+		err = s.paymentRepo.Create(ctx, pay)
+	}
+	if err != nil {
+		s.releaseSlot(ctx, slot.ID)
+		return nil, err
+	}
+	ms.PaymentID = &pay.ID
+	if err := s.repo.CreateSession(ctx, ms); err != nil {
+		s.releaseSlot(ctx, slot.ID)
+		return nil, err
+	}
+	return &SessionCheckout{Session: ms, Payment: pay}, nil
+}
+
+// sessionPrice — почасовая ставка за длительность, с округлением вверх
+func sessionPrice(hourlyRate int64, d time.Duration) int64 {
+	minutes := int64(d / time.Minute)
+	return (hourlyRate*minutes + 59) / 60
+}
+
+// Pay — подтверждение оплаты (mock, как BidService.Pay); деньги удерживаются до конца сессии
+func (s *MentoringService) Pay(ctx context.Context, id, menteeID string) (*models.MentoringSession, error) {
+	ms, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ms.MenteeID != menteeID {
+		return nil, ErrSessionForbidden
+	}
+	if ms.Status != models.SessionPendingPayment || ms.PaymentID == nil {
+		return nil, ErrSessionWrongState
+	}
+	ms.Status = models.SessionScheduled
+	// запись подтверждается только вместе с удержанием платежа
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateSession(ctx, ms, models.SessionPendingPayment); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSessionWrongState
+			}
+			return err
+		}
+		ok, err := s.paymentRepo.ChangeStatus(ctx, *ms.PaymentID, "initiated", "held")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrSessionWrongState
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, []string{ms.MenteeID}, models.EventPaymentSucceeded, map[string]interface{}{
+		"payment_id": *ms.PaymentID, "related_type": "mentoring_session", "related_id": ms.ID,
+	})
+	s.notify(ctx, ms, []string{ms.CoachID, ms.MenteeID}, models.EventSessionBooked, nil)
+	return ms, nil
+}
+
+func (s *MentoringService) Get(ctx context.Context, id, userID string, isAdmin bool) (*models.MentoringSession, error) {
+	ms, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && ms.CoachID != userID && ms.MenteeID != userID {
+		return nil, ErrSessionForbidden
+	}
+	return ms, nil
+}
+
+// Mine — консультации пользователя для кабинета; as: coach | mentee | "" (все)
+func (s *MentoringService) Mine(ctx context.Context, userID, as, status string, page, perPage int) ([]*models.MentoringSession, int, error) {
+	list, total, err := s.repo.ListSessions(ctx, userID, as, status, page, perPage)
+	if list == nil {
+		list = []*models.MentoringSession{}
+	}
+	return list, total, err
+}
+
+func (s *MentoringService) Cancel(ctx context.Context, id, userID, reason string) (*models.MentoringSession, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	ms, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ms.CoachID != userID && ms.MenteeID != userID {
+		return nil, ErrSessionForbidden
+	}
+	from := ms.Status
+	switch {
+	case from == models.SessionPendingPayment && userID == ms.MenteeID:
+	case from == models.SessionScheduled && time.Now().Before(ms.StartsAt):
+	default:
+		return nil, ErrSessionWrongState
+	}
+	ms.Status, ms.CancelledBy, ms.CancelReason = models.SessionCancelled, &userID, &reason
+	// наставник отменяет — полный возврат, окно снимается; подопечный — по сроку
+	coachShare := int64(0)
+	if userID == ms.MenteeID && time.Until(ms.StartsAt) < s.cancelWindow {
+		coachShare = ms.Price * (100 - sessionLateRefundShare) / 100
+	}
+	// статус и расчёт по удержанному платежу — одна транзакция: отмена без
+	// возврата (или возврат без отмены) не остаётся
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateSession(ctx, ms, from); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrSessionWrongState
+			}
+			return err
+		}
+		if from == models.SessionPendingPayment {
+			return s.expirePayment(ctx, ms)
+		}
+		_, refunded, err := s.paymentRepo.SettleHeld(ctx, "mentoring_session", ms.ID, ms.MenteeID, ms.CoachID, coachShare)
+		if err != nil {
+			return err
+		}
+		ms.Refunded = refunded
+		return s.repo.UpdateSession(ctx, ms, models.SessionCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if from == models.SessionPendingPayment {
+		if ms.SlotID != nil {
+			s.releaseSlot(ctx, *ms.SlotID)
+		}
+		return ms, nil
+	}
+	if ms.SlotID != nil {
+		if userID == ms.CoachID {
+			s.releaseSlot(ctx, *ms.SlotID)
+			if _, err := s.repo.DeleteSlot(ctx, *ms.SlotID, ms.CoachID); err != nil {
+				log.Printf("mentoring: delete slot %s: %v", *ms.SlotID, err)
+			}
+		} else if time.Now().Before(ms.StartsAt) {
+			s.releaseSlot(ctx, *ms.SlotID)
+		}
+	}
+	s.notify(ctx, ms, []string{ms.CoachID, ms.MenteeID}, models.EventSessionCancelled, map[string]interface{}{
+		"refunded": ms.Refunded, "reason": reason,
+	})
+	return ms, nil
+}
+
+// Reschedule переносит оплаченную консультацию на другое окно того же наставника
+func (s *MentoringService) Reschedule(ctx context.Context, id, userID, slotID string) (*models.MentoringSession, error) {
+	ms, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ms.CoachID != userID && ms.MenteeID != userID {
+		return nil, ErrSessionForbidden
+	}
+	if ms.Status != models.SessionScheduled {
+		return nil, ErrSessionWrongState
+	}
+	if time.Until(ms.StartsAt) < s.cancelWindow {
+		return nil, ErrRescheduleClosed
+	}
+	if ms.Reschedules >= sessionMaxReschedules {
+		return nil, ErrRescheduleLimit
+	}
+	slot, err := s.repo.GetSlot(ctx, slotID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSlotUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if slot.CoachID != ms.CoachID || slot.EndsAt.Sub(slot.StartsAt) != ms.EndsAt.Sub(ms.StartsAt) {
+		return nil, ErrSlotMismatch
+	}
+	ok, err := s.repo.BookSlot(ctx, slot.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSlotUnavailable
+	}
+	old := ms.SlotID
+	ms.SlotID, ms.StartsAt, ms.EndsAt = &slot.ID, slot.StartsAt, slot.EndsAt
+	ms.Reschedules++
+	if err := s.repo.UpdateSession(ctx, ms, models.SessionScheduled); err != nil {
+		s.releaseSlot(ctx, slot.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionWrongState
+		}
+		return nil, err
+	}
+	if old != nil {
+		s.releaseSlot(ctx, *old)
+	}
+	other := ms.CoachID
+	if userID == ms.CoachID {
+		other = ms.MenteeID
+	}
+	s.notify(ctx, ms, []string{other}, models.EventSessionRescheduled, nil)
+	return ms, nil
+}
+
+// RunSessions periodically drops unpaid bookings and completes finished
+// sessions, paying the coach. Blocks until ctx is cancelled.
+func (s *MentoringService) RunSessions(ctx context.Context) {
+	t := time.NewTicker(mentoringTick)
+	defer t.Stop()
+	for {
+		s.dropUnpaid(ctx)
+		s.completeFinished(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *MentoringService) dropUnpaid(ctx context.Context) {
+	list, err := s.repo.Unpaid(ctx, time.Now().Add(-sessionPaymentHold))
+	if err != nil {
+		log.Printf("mentoring: unpaid: %v", err)
+		return
+	}
+	for _, ms := range list {
+		ms.Status = models.SessionCancelled
+		err := s.tx.InTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.UpdateSession(ctx, ms, models.SessionPendingPayment); err != nil {
+				return err
+			}
+			return s.expirePayment(ctx, ms)
+		})
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("mentoring: drop unpaid %s: %v", ms.ID, err)
+			}
+			continue
+		}
+		if ms.SlotID != nil {
+			s.releaseSlot(ctx, *ms.SlotID)
+		}
+	}
+}
+
+func (s *MentoringService) completeFinished(ctx context.Context) {
+	list, err := s.repo.Finished(ctx, time.Now())
+	if err != nil {
+		log.Printf("mentoring: finished: %v", err)
+		return
+	}
+	for _, ms := range list {
+		now := time.Now()
+		ms.Status, ms.CompletedAt = models.SessionCompleted, &now
+		// без выплаты сессия остаётся scheduled и завершится на следующем тике
+		err := s.tx.InTx(ctx, func(ctx context.Context) error {
+			if err := s.repo.UpdateSession(ctx, ms, models.SessionScheduled); err != nil {
+				return err
+			}
+			_, _, err := s.paymentRepo.SettleHeld(ctx, "mentoring_session", ms.ID, ms.MenteeID, ms.CoachID, -1)
+			return err
+		})
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("mentoring: complete %s: %v", ms.ID, err)
+			}
+			continue
+		}
+		s.notify(ctx, ms, []string{ms.CoachID, ms.MenteeID}, models.EventSessionCompleted, nil)
+	}
+}
+
+// expirePayment отменяет неоплаченный платёж записи (проведённый не трогает)
+func (s *MentoringService) expirePayment(ctx context.Context, ms *models.MentoringSession) error {
+	if ms.PaymentID == nil {
+		return nil
+	}
+	_, err := s.paymentRepo.ChangeStatus(ctx, *ms.PaymentID, "initiated", "expired")
+	return err
+}
+
+func (s *MentoringService) releaseSlot(ctx context.Context, slotID string) {
+	if err := s.repo.ReleaseSlot(ctx, slotID); err != nil {
+		log.Printf("mentoring: release slot %s: %v", slotID, err)
+	}
+}
+
+func (s *MentoringService) notify(ctx context.Context, ms *models.MentoringSession, to []string, eventType string, extra map[string]interface{}) {
+	payload := map[string]interface{}{
+		"session_id": ms.ID, "topic": ms.Topic, "starts_at": ms.StartsAt.In(kzTime).Format("02.01.2006 15:04"),
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	s.events.Publish(ctx, to, eventType, payload)
+}
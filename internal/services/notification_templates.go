@@ -0,0 +1,204 @@
+package services
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+)
+
+const defaultLocale = "ru"
+
+var notificationLocales = map[string]bool{"ru": true, "kk": true, "en": true}
+
+type notificationTemplate struct {
+	Title string
+	Body  string
+}
+
+// notificationTemplates[type][locale]; поля payload события доступны как {{.order_id}} и т.п.
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	models.EventBidNew: {
+		"ru": {"Новый отклик на заказ", "На ваш заказ {{.order_id}} поступил новый отклик. Цена: {{.price}} ₸."},
+		"kk": {"Тапсырысқа жаңа ұсыныс", "Сіздің {{.order_id}} тапсырысыңызға жаңа ұсыныс түсті. Бағасы: {{.price}} ₸."},
+		"en": {"New bid on your order", "Your order {{.order_id}} received a new bid. Price: {{.price}} KZT."},
+	},
+	models.EventBidWon: {
+		"ru": {"Ваш отклик выбран", "Клиент выбрал вас исполнителем заказа {{.order_id}}."},
+		"kk": {"Сіздің ұсынысыңыз таңдалды", "Клиент сізді {{.order_id}} тапсырысының орындаушысы етіп таңдады."},
+		"en": {"Your bid won", "The client selected you as the executor for order {{.order_id}}."},
+	},
+	models.EventBidLost: {
+		"ru": {"Выбран другой исполнитель", "По заказу {{.order_id}} клиент выбрал другого исполнителя."},
+		"kk": {"Басқа орындаушы таңдалды", "{{.order_id}} тапсырысы бойынша клиент басқа орындаушыны таңдады."},
+		"en": {"Another executor was selected", "The client selected another executor for order {{.order_id}}."},
+	},
+	models.EventOrderStatusChanged: {
+		"ru": {"Статус заказа изменён", "Заказ {{.order_id}}: {{.status_label}}."},
+		"kk": {"Тапсырыс мәртебесі өзгерді", "{{.order_id}} тапсырысы: {{.status_label}}."},
+		"en": {"Order status changed", "Order {{.order_id}}: {{.status_label}}."},
+	},
+	models.EventPaymentSucceeded: {
+		"ru": {"Оплата прошла", "Платёж ({{.related_type}}) успешно проведён."},
+		"kk": {"Төлем өтті", "Төлем ({{.related_type}}) сәтті жүргізілді."},
+		"en": {"Payment succeeded", "Your payment ({{.related_type}}) was successful."},
+	},
+	models.EventFileQuarantined: {
+		"ru": {"Файл помещён в карантин", "В файле «{{.name}}» обнаружено вредоносное содержимое ({{.reason}}). Файл недоступен для скачивания."},
+		"kk": {"Файл карантинге орналастырылды", "«{{.name}}» файлынан зиянды мазмұн табылды ({{.reason}}). Файлды жүктеп алу мүмкін емес."},
+		"en": {"File quarantined", "Malicious content was detected in \"{{.name}}\" ({{.reason}}). The file cannot be downloaded."},
+	},
+	models.EventFileRejected: {
+		"ru": {"Файл отклонён", "Содержимое файла «{{.name}}» не соответствует его типу. Загрузите файл заново."},
+		"kk": {"Файл қабылданбады", "«{{.name}}» файлының мазмұны оның түріне сәйкес келмейді. Файлды қайта жүктеңіз."},
+		"en": {"File rejected", "The content of \"{{.name}}\" does not match its declared type. Please upload it again."},
+	},
+	models.EventDisputeOpened: {
+		"ru": {"Открыт спор по заказу", "По заказу {{.order_id}} открыт спор. Причина: {{.reason}}"},
+		"kk": {"Тапсырыс бойынша дау ашылды", "{{.order_id}} тапсырысы бойынша дау ашылды. Себебі: {{.reason}}"},
+		"en": {"Dispute opened", "A dispute was opened on order {{.order_id}}. Reason: {{.reason}}"},
+	},
+	models.EventDisputeAssigned: {
+		"ru": {"Назначен арбитр", "Спор по заказу {{.order_id}} передан арбитру на рассмотрение."},
+		"kk": {"Төреші тағайындалды", "{{.order_id}} тапсырысы бойынша дау төрешіге қарауға берілді."},
+		"en": {"Arbiter assigned", "The dispute on order {{.order_id}} has been assigned to an arbiter."},
+	},
+	models.EventDisputeResolved: {
+		"ru": {"Спор разрешён", "Спор по заказу {{.order_id}} разрешён: {{.resolution_label}}. Исполнителю: {{.executor_amount}} ₸, клиенту: {{.client_amount}} ₸."},
+		"kk": {"Дау шешілді", "{{.order_id}} тапсырысы бойынша дау шешілді: {{.resolution_label}}. Орындаушыға: {{.executor_amount}} ₸, клиентке: {{.client_amount}} ₸."},
+		"en": {"Dispute resolved", "The dispute on order {{.order_id}} was resolved: {{.resolution_label}}. Executor: {{.executor_amount}} KZT, client: {{.client_amount}} KZT."},
+	},
+	models.EventDisputeSLABreached: {
+		"ru": {"Просрочен срок по спору", "Спор {{.dispute_id}} по заказу {{.order_id}} не обработан в срок."},
+		"kk": {"Дау бойынша мерзім өтіп кетті", "{{.order_id}} тапсырысы бойынша {{.dispute_id}} дауы мерзімінде қаралмады."},
+		"en": {"Dispute SLA breached", "Dispute {{.dispute_id}} on order {{.order_id}} was not handled in time."},
+	},
+	models.EventCertificateReviewed: {
+		"ru": {"Проверка сертификата", "Сертификат {{.kind}} {{if .verified}}подтверждён{{else}}отклонён: {{.reason}}{{end}}."},
+		"kk": {"Сертификатты тексеру", "{{.kind}} сертификаты {{if .verified}}расталды{{else}}қабылданбады: {{.reason}}{{end}}."},
+		"en": {"Certificate review", "Your {{.kind}} certificate was {{if .verified}}verified{{else}}rejected: {{.reason}}{{end}}."},
+	},
+	models.EventReviewReceived: {
+		"ru": {"Новый отзыв", "Вам оставили отзыв по {{if .session_id}}консультации{{else}}заказу {{.order_id}}{{end}}.{{if not .published}} Он станет виден после вашего отзыва или по окончании срока.{{end}}"},
+		"kk": {"Жаңа пікір", "{{if .session_id}}Кеңес{{else}}{{.order_id}} тапсырысы{{end}} бойынша сізге пікір қалдырылды.{{if not .published}} Ол сіздің пікіріңізден кейін немесе мерзім біткенде көрінеді.{{end}}"},
+		"en": {"New review", "You received a review for {{if .session_id}}a mentoring session{{else}}order {{.order_id}}{{end}}.{{if not .published}} It becomes visible once you leave yours or the review period ends.{{end}}"},
+	},
+	models.EventInvitationReceived: {
+		"ru": {"Приглашение к заказу", "Клиент приглашает вас в заказ «{{.title}}».{{if .fee_discount}} Скидка на отклик: {{.fee_discount}}%.{{end}}"},
+		"kk": {"Тапсырысқа шақыру", "Клиент сізді «{{.title}}» тапсырысына шақырады.{{if .fee_discount}} Ұсынысқа жеңілдік: {{.fee_discount}}%.{{end}}"},
+		"en": {"Order invitation", "A client invited you to the order \"{{.title}}\".{{if .fee_discount}} Bid fee discount: {{.fee_discount}}%.{{end}}"},
+	},
+	models.EventInvitationAnswered: {
+		"ru": {"Ответ на приглашение", "Исполнитель {{if .accepted}}принял{{else}}отклонил{{end}} приглашение к заказу «{{.title}}»."},
+		"kk": {"Шақыруға жауап", "Орындаушы «{{.title}}» тапсырысына шақыруды {{if .accepted}}қабылдады{{else}}қабылдамады{{end}}."},
+		"en": {"Invitation answered", "The executor {{if .accepted}}accepted{{else}}declined{{end}} your invitation to the order \"{{.title}}\"."},
+	},
+	models.EventOrdersMatched: {
+		"ru": {"Подходящие заказы", "Новых заказов по вашему профилю: {{.count}}. {{.titles}}"},
+		"kk": {"Сізге сай тапсырыстар", "Профиліңізге сай жаңа тапсырыстар: {{.count}}. {{.titles}}"},
+		"en": {"Recommended orders", "New orders matching your profile: {{.count}}. {{.titles}}"},
+	},
+	models.EventSavedSearchMatch: {
+		"ru": {"Новый заказ: {{.name}}", "По сохранённому поиску «{{.name}}» опубликован заказ «{{.title}}»."},
+		"kk": {"Жаңа тапсырыс: {{.name}}", "«{{.name}}» сақталған іздеуі бойынша «{{.title}}» тапсырысы жарияланды."},
+		"en": {"New order: {{.name}}", "A new order \"{{.title}}\" matches your saved search \"{{.name}}\"."},
+	},
+	models.EventSavedSearchDigest: {
+		"ru": {"Дайджест: {{.name}}", "За сутки по поиску «{{.name}}» новых заказов: {{.count}}. {{.titles}}"},
+		"kk": {"Дайджест: {{.name}}", "Тәулік ішінде «{{.name}}» іздеуі бойынша жаңа тапсырыстар: {{.count}}. {{.titles}}"},
+		"en": {"Daily digest: {{.name}}", "New orders for your saved search \"{{.name}}\" today: {{.count}}. {{.titles}}"},
+	},
+	models.EventSubscriptionActive: {
+		"ru": {"Подписка {{.plan}} активна", "Тариф {{.plan}} действует до {{.period_end}}. Ставок в квоте: {{.bid_quota}}."},
+		"kk": {"{{.plan}} жазылымы белсенді", "{{.plan}} тарифі {{.period_end}} дейін жарамды. Квотадағы өтінімдер: {{.bid_quota}}."},
+		"en": {"{{.plan}} subscription is active", "Your {{.plan}} plan is valid until {{.period_end}}. Bids in quota: {{.bid_quota}}."},
+	},
+	models.EventSubscriptionDue: {
+		"ru": {"Оплатите продление {{.plan}}", "Период подписки закончился. Оплатите {{.amount}} ₸ до {{.grace_until}}, иначе подписка будет отключена."},
+		"kk": {"{{.plan}} ұзартуын төлеңіз", "Жазылым мерзімі аяқталды. {{.grace_until}} дейін {{.amount}} ₸ төлеңіз, әйтпесе жазылым өшіріледі."},
+		"en": {"Renew your {{.plan}} plan", "Your subscription period has ended. Pay {{.amount}} KZT by {{.grace_until}} or the subscription will be switched off."},
+	},
+	models.EventSubscriptionExpired: {
+		"ru": {"Подписка {{.plan}} закончилась", "Ставки снова оплачиваются по обычному тарифу."},
+		"kk": {"{{.plan}} жазылымы аяқталды", "Өтінімдер қайтадан әдеттегі тариф бойынша төленеді."},
+		"en": {"{{.plan}} subscription has ended", "Bids are charged at the standard fee again."},
+	},
+	models.EventSessionBooked: {
+		"ru": {"Консультация назначена", "Консультация «{{.topic}}» состоится {{.starts_at}}."},
+		"kk": {"Кеңес белгіленді", "«{{.topic}}» кеңесі {{.starts_at}} өтеді."},
+		"en": {"Mentoring session booked", "The session \"{{.topic}}\" is scheduled for {{.starts_at}}."},
+	},
+	models.EventSessionRescheduled: {
+		"ru": {"Консультация перенесена", "Консультация «{{.topic}}» перенесена на {{.starts_at}}."},
+		"kk": {"Кеңес ауыстырылды", "«{{.topic}}» кеңесі {{.starts_at}} уақытына ауыстырылды."},
+		"en": {"Mentoring session rescheduled", "The session \"{{.topic}}\" was moved to {{.starts_at}}."},
+	},
+	models.EventSessionCancelled: {
+		"ru": {"Консультация отменена", "Консультация «{{.topic}}» ({{.starts_at}}) отменена.{{if .refunded}} Возврат: {{.refunded}} ₸.{{end}}"},
+		"kk": {"Кеңес тоқтатылды", "«{{.topic}}» кеңесі ({{.starts_at}}) тоқтатылды.{{if .refunded}} Қайтарым: {{.refunded}} ₸.{{end}}"},
+		"en": {"Mentoring session cancelled", "The session \"{{.topic}}\" ({{.starts_at}}) was cancelled.{{if .refunded}} Refund: {{.refunded}} KZT.{{end}}"},
+	},
+	models.EventSessionCompleted: {
+		"ru": {"Консультация завершена", "Консультация «{{.topic}}» завершена. Оставьте отзыв о второй стороне."},
+		"kk": {"Кеңес аяқталды", "«{{.topic}}» кеңесі аяқталды. Екінші тарап туралы пікір қалдырыңыз."},
+		"en": {"Mentoring session completed", "The session \"{{.topic}}\" is over. Please leave a review."},
+	},
+}
+
+var disputeResolutionLabels = map[string]map[string]string{
+	"ru": {"refund": "возврат клиенту", "split": "частичная выплата", "release": "выплата исполнителю"},
+	"kk": {"refund": "клиентке қайтару", "split": "ішінара төлем", "release": "орындаушыға төлем"},
+	"en": {"refund": "refund to the client", "split": "partial split", "release": "release to the executor"},
+}
+
+var orderStatusLabels = map[string]map[string]string{
+	"ru": {
+		"executor_selected": "исполнитель выбран", "in_progress": "в работе", "client_review": "на проверке у клиента",
+		"completed": "завершён", "cancelled": "отменён", "archived": "в архиве", "disputed": "открыт спор",
+	},
+	"kk": {
+		"executor_selected": "орындаушы таңдалды", "in_progress": "орындалуда", "client_review": "клиенттің тексеруінде",
+		"completed": "аяқталды", "cancelled": "болдырылмады", "archived": "мұрағатта", "disputed": "дау ашылды",
+	},
+	"en": {
+		"executor_selected": "executor selected", "in_progress": "in progress", "client_review": "awaiting client review",
+		"completed": "completed", "cancelled": "cancelled", "archived": "archived", "disputed": "disputed",
+	},
+}
+
+// renderNotification returns localized title/body; ok=false if the event type has no template
+func renderNotification(eventType, locale string, data map[string]interface{}) (string, string, bool) {
+	byLocale, ok := notificationTemplates[eventType]
+	if !ok {
+		return "", "", false
+	}
+	if !notificationLocales[locale] {
+		locale = defaultLocale
+	}
+	if st, ok := data["status"].(string); ok {
+		data["status_label"] = st
+		if l, ok := orderStatusLabels[locale][st]; ok {
+			data["status_label"] = l
+		}
+	}
+	if r, ok := data["resolution"].(string); ok {
+		data["resolution_label"] = r
+		if l, ok := disputeResolutionLabels[locale][r]; ok {
+			data["resolution_label"] = l
+		}
+	}
+	t := byLocale[locale]
+	return execTemplate(t.Title, data), execTemplate(t.Body, data), true
+}
+
+func execTemplate(text string, data map[string]interface{}) string {
+	t, err := template.New("n").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return text
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return text
+	}
+	return strings.ReplaceAll(b.String(), "<no value>", "")
+}
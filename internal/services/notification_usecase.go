@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/email"
+	"github.com/BekzatS8/buhpro/pkg/sms"
+	"github.com/google/uuid"
+)
+
+const notificationDeliveryTimeout = 30 * time.Second
+
+var ErrInvalidLocale = &ServiceError{"locale must be one of ru, kk, en"}
+
+// NotificationService превращает доменные события в уведомления: запись во
+// входящие (in-app) всегда, email/SMS — по настройкам пользователя.
+type NotificationService struct {
+	repo     repository.NotificationRepo
+	userRepo repository.UserRepo
+	email    email.EmailSender
+	sms      sms.SMSSender
+}
+
+func NewNotificationService(nr repository.NotificationRepo, ur repository.UserRepo, es email.EmailSender, ss sms.SMSSender) *NotificationService {
+	return &NotificationService{repo: nr, userRepo: ur, email: es, sms: ss}
+}
+
+// HandleEvent — подписчик EventService
+func (s *NotificationService) HandleEvent(ctx context.Context, e *models.Event) {
+	if _, ok := notificationTemplates[e.Type]; !ok {
+		return
+	}
+	prefs, err := s.repo.GetPreferences(ctx, e.UserID)
+	if err != nil {
+		log.Printf("notifications: preferences for %s: %v", e.UserID, err)
+		return
+	}
+	data := map[string]interface{}{}
+	_ = json.Unmarshal(e.Payload, &data)
+	title, body, _ := renderNotification(e.Type, prefs.Locale, data)
+
+	n := &models.Notification{
+		ID:      uuid.NewString(),
+		UserID:  e.UserID,
+		Type:    e.Type,
+		Title:   title,
+		Body:    body,
+		Payload: e.Payload,
+	}
+	if err := s.repo.Create(ctx, n); err != nil {
+		log.Printf("notifications: create for %s: %v", e.UserID, err)
+	}
+
+	for _, t := range prefs.DisabledTypes {
+		if t == e.Type {
+			return
+		}
+	}
+	if prefs.EmailEnabled || prefs.SMSEnabled {
+		// внешние каналы — вне запроса, чтобы не задерживать ответ
+		go s.deliver(prefs, title, body)
+	}
+}
+
+func (s *NotificationService) deliver(prefs *models.NotificationPreferences, title, body string) {
+	ctx, cancel := context.WithTimeout(context.Background(), notificationDeliveryTimeout)
+	defer cancel()
+	u, err := s.userRepo.GetByID(prefs.UserID)
+	if err != nil || u.Status != "active" {
+		return
+	}
+	if prefs.EmailEnabled && u.Email != "" {
+		if err := s.email.Send(ctx, u.Email, title, body); err != nil {
+			log.Printf("notifications: email to %s: %v", u.ID, err)
+		}
+	}
+	if prefs.SMSEnabled && u.Phone != "" && u.PhoneVerifiedAt != nil {
+		if err := s.sms.Send(ctx, u.Phone, title+". "+body); err != nil {
+			log.Printf("notifications: sms to %s: %v", u.ID, err)
+		}
+	}
+}
+
+// Email renders the event template in the user's locale and sends it by email
+// regardless of preferences — for alerts the user explicitly asked to get by email.
+func (s *NotificationService) Email(ctx context.Context, userID, eventType string, data map[string]interface{}) error {
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+	title, body, ok := renderNotification(eventType, prefs.Locale, data)
+	if !ok {
+		return nil
+	}
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil || u.Status != "active" || u.Email == "" {
+		return err
+	}
+	return s.email.Send(ctx, u.Email, title, body)
+}
+
+func (s *NotificationService) List(ctx context.Context, userID string, unreadOnly bool, page, perPage int) ([]*models.Notification, int, error) {
+	return s.repo.List(ctx, userID, unreadOnly, page, perPage)
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID string) error {
+	return s.repo.MarkRead(ctx, id, userID)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) error {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+func (s *NotificationService) Preferences(ctx context.Context, userID string) (*models.NotificationPreferences, error) {
+	return s.repo.GetPreferences(ctx, userID)
+}
+
+func (s *NotificationService) SavePreferences(ctx context.Context, p *models.NotificationPreferences) error {
+	if !notificationLocales[p.Locale] {
+		return ErrInvalidLocale
+	}
+	return s.repo.SavePreferences(ctx, p)
+}
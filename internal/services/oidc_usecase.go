@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/oidc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// OIDCStateTTL — сколько живёт state (и cookie с ним в браузере)
+const OIDCStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider    = &ServiceError{"unknown identity provider"}
+	ErrOIDCStateInvalid   = &ServiceError{"invalid or expired login state"}
+	ErrIdentityLinked     = &ServiceError{"this external account is linked to another user"}
+	ErrIdentityEmailTaken = &ServiceError{"email already registered: sign in and link the provider from your profile"}
+	ErrLastLoginMethod    = &ServiceError{"cannot unlink the only login method"}
+)
+
+// OIDCResult — итог callback: либо выданы токены (вход), либо привязан логин (link)
+type OIDCResult struct {
+	AccessToken  string               `json:"access_token,omitempty"`
+	RefreshToken string               `json:"refresh_token,omitempty"`
+	Created      bool                 `json:"created,omitempty"`
+	Linked       bool                 `json:"linked,omitempty"`
+	Identity     *models.UserIdentity `json:"identity,omitempty"`
+}
+
+// OIDCService — вход через внешних провайдеров (authorization code + PKCE)
+type OIDCService struct {
+	identities repository.IdentityRepo
+	users      *UserUsecase
+	providers  map[string]*oidc.Provider
+}
+
+func NewOIDCService(ir repository.IdentityRepo, uc *UserUsecase, providers []*oidc.Provider) *OIDCService {
+	m := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		m[p.Name()] = p
+	}
+	return &OIDCService{identities: ir, users: uc, providers: m}
+}
+
+func (s *OIDCService) Providers() []string {
+	out := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		out = append(out, name)
+	}
+	return out
+}
+
+// Start returns the provider authorization URL and the state. linkUserID != ""
+// means the resulting identity is linked to that (logged-in) user instead of
+// logging in. The caller binds the state to the browser (HttpOnly cookie):
+// Callback accepts it only from the browser that started the flow.
+func (s *OIDCService) Start(ctx context.Context, provider, linkUserID string) (string, string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+	st := &models.OIDCState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(OIDCStateTTL),
+	}
+	if linkUserID != "" {
+		st.LinkUserID = &linkUserID
+	}
+	if err := s.identities.SaveState(ctx, st); err != nil {
+		return "", "", err
+	}
+	u, err := p.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		return "", "", err
+	}
+	return u, state, nil
+}
+
+// Callback exchanges the code, verifies the id_token and logs in, provisions or links.
+// browserState — state из cookie браузера: без совпадения чужой flow (login CSRF,
+// привязка своего IdP к чужому аккаунту) завершить нельзя.
+func (s *OIDCService) Callback(ctx context.Context, provider, state, browserState, code string) (*OIDCResult, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrOIDCStateInvalid
+	}
+	st, err := s.identities.TakeState(ctx, state)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOIDCStateInvalid
+		}
+		return nil, err
+	}
+	if st.Provider != provider {
+		return nil, ErrOIDCStateInvalid
+	}
+	tok, err := p.Exchange(ctx, code, st.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.VerifyIDToken(ctx, tok.IDToken, st.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	email := ""
+	if claims.EmailVerified {
+		email = claims.Email
+	}
+
+	existing, err := s.identities.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// linking from profile
+	if st.LinkUserID != nil {
+		if existing == nil {
+			ident := &models.UserIdentity{ID: uuid.NewString(), UserID: *st.LinkUserID, Provider: provider, Subject: claims.Subject, Email: email}
+			err := s.identities.Create(ctx, ident)
+			if err == nil {
+				_ = s.users.audit.Add(ctx, *st.LinkUserID, "identity_linked", "user", *st.LinkUserID, map[string]interface{}{"provider": provider})
+				return &OIDCResult{Linked: true, Identity: ident}, nil
+			}
+			if !errors.Is(err, repository.ErrDuplicateIdentity) {
+				return nil, err
+			}
+			// параллельный callback успел привязать этот subject
+			if existing, err = s.identities.GetByProviderSubject(ctx, provider, claims.Subject); err != nil {
+				return nil, err
+			}
+		}
+		if existing.UserID != *st.LinkUserID {
+			return nil, ErrIdentityLinked
+		}
+		return &OIDCResult{Linked: true, Identity: existing}, nil
+	}
+
+	if existing != nil {
+		return s.login(ctx, existing)
+	}
+
+	// first login — provision an account: пользователь и привязка создаются
+	// вместе, иначе сбой привязки оставил бы аккаунт без способа входа
+	if email != "" {
+		if u, _ := s.users.repo.GetByEmail(email); u != nil {
+			return nil, ErrIdentityEmailTaken
+		}
+	}
+	u := &models.User{
+		ID:       uuid.NewString(),
+		Email:    email,
+		FullName: claims.Name,
+		Role:     "client",
+		Status:   "active",
+	}
+	ident := &models.UserIdentity{ID: uuid.NewString(), UserID: u.ID, Provider: provider, Subject: claims.Subject, Email: email}
+	err = s.users.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.users.repo.Create(ctx, u); err != nil {
+			return err
+		}
+		return s.identities.Create(ctx, ident)
+	})
+	if errors.Is(err, repository.ErrDuplicateIdentity) {
+		// параллельный первый вход тем же subject создал аккаунт раньше: входим в него
+		if existing, err = s.identities.GetByProviderSubject(ctx, provider, claims.Subject); err != nil {
+			return nil, err
+		}
+		return s.login(ctx, existing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	access, refresh, err := s.users.issueTokens(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCResult{AccessToken: access, RefreshToken: refresh, Created: true, Identity: ident}, nil
+}
+
+// login issues tokens for the user an identity is linked to
+func (s *OIDCService) login(ctx context.Context, ident *models.UserIdentity) (*OIDCResult, error) {
+	u, err := s.users.repo.GetByID(ident.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Status != "active" {
+		return nil, ErrUserInactive
+	}
+	_ = s.identities.TouchLogin(ctx, ident.ID)
+	access, refresh, err := s.users.issueTokens(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCResult{AccessToken: access, RefreshToken: refresh, Identity: ident}, nil
+}
+
+func (s *OIDCService) ListIdentities(ctx context.Context, userID string) ([]*models.UserIdentity, error) {
+	return s.identities.ListByUser(ctx, userID)
+}
+
+// Unlink removes a linked identity unless it is the user's only way to sign in
+func (s *OIDCService) Unlink(ctx context.Context, userID, identityID string) error {
+	list, err := s.identities.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, i := range list {
+		if i.ID == identityID {
+			found = true
+		}
+	}
+	if !found {
+		return pgx.ErrNoRows
+	}
+	u, err := s.users.repo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	hasOther := len(list) > 1 || u.PasswordHash != "" || u.PhoneVerifiedAt != nil
+	if !hasOther {
+		return ErrLastLoginMethod
+	}
+	if err := s.identities.Delete(ctx, userID, identityID); err != nil {
+		return err
+	}
+	_ = s.users.audit.Add(ctx, userID, "identity_unlinked", "user", userID, map[string]interface{}{"identity_id": identityID})
+	return nil
+}
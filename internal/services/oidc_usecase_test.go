@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/auth"
+	"github.com/BekzatS8/buhpro/pkg/oidc"
+	"github.com/BekzatS8/buhpro/pkg/oidc/oidcstub"
+	"github.com/jackc/pgx/v5"
+)
+
+// in-memory репозитории: ровно то, что нужно OIDC flow
+
+type memIdentities struct {
+	mu     sync.Mutex
+	idents []*models.UserIdentity
+	states map[string]*models.OIDCState
+	// beforeCreate имитирует параллельный callback между проверкой и вставкой
+	beforeCreate func()
+}
+
+func (m *memIdentities) Create(_ context.Context, i *models.UserIdentity) error {
+	if m.beforeCreate != nil {
+		m.beforeCreate()
+		m.beforeCreate = nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.idents {
+		if e.Provider == i.Provider && e.Subject == i.Subject {
+			return repository.ErrDuplicateIdentity
+		}
+	}
+	m.idents = append(m.idents, i)
+	return nil
+}
+
+func (m *memIdentities) GetByProviderSubject(_ context.Context, provider, subject string) (*models.UserIdentity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, i := range m.idents {
+		if i.Provider == provider && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (m *memIdentities) ListByUser(_ context.Context, userID string) ([]*models.UserIdentity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []*models.UserIdentity
+	for _, i := range m.idents {
+		if i.UserID == userID {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func (m *memIdentities) Delete(context.Context, string, string) error { return nil }
+func (m *memIdentities) TouchLogin(context.Context, string) error     { return nil }
+
+func (m *memIdentities) SaveState(_ context.Context, s *models.OIDCState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[s.State] = s
+	return nil
+}
+
+func (m *memIdentities) TakeState(_ context.Context, state string) (*models.OIDCState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[state]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	delete(m.states, state)
+	return s, nil
+}
+
+type memUsers struct {
+	repository.UserRepo
+	mu    sync.Mutex
+	users map[string]*models.User
+}
+
+func (m *memUsers) Create(_ context.Context, u *models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users[u.ID] = u
+	return nil
+}
+
+func (m *memUsers) GetByID(id string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.users[id]; ok {
+		return u, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (m *memUsers) GetByEmail(email string) (*models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+type memRefresh struct{ repository.RefreshTokenRepo }
+
+func (memRefresh) Create(context.Context, *models.RefreshToken) error { return nil }
+
+type memTx struct{}
+
+func (memTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+
+type memAudit struct{}
+
+func (memAudit) Add(context.Context, string, string, string, string, map[string]interface{}) error {
+	return nil
+}
+
+type oidcFixture struct {
+	svc    *OIDCService
+	users  *memUsers
+	idents *memIdentities
+}
+
+func newOIDCFixture(t *testing.T) *oidcFixture {
+	t.Helper()
+	is, err := oidcstub.NewIssuer("buhpro-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(is.Close)
+	p := oidc.NewProvider(oidc.Config{
+		Name:        "stub",
+		Issuer:      is.URL,
+		ClientID:    is.ClientID,
+		RedirectURL: "http://localhost/api/v1/auth/oidc/stub/callback",
+	})
+	f := &oidcFixture{
+		users:  &memUsers{users: make(map[string]*models.User)},
+		idents: &memIdentities{states: make(map[string]*models.OIDCState)},
+	}
+	jwtCfg := auth.JWTConfig{Secret: "test-secret", Issuer: "buhpro", Audience: "buhpro", AccessTTLMinutes: 15, RefreshTTLDays: 30}
+	uc := NewUserUsecase(f.users, memRefresh{}, nil, memAudit{}, jwtCfg, nil, memTx{})
+	f.svc = NewOIDCService(f.idents, uc, []*oidc.Provider{p})
+	return f
+}
+
+// authorize проходит authorize endpoint stub-провайдера за пользователя subject
+// и возвращает code и state из redirect на callback
+func authorize(t *testing.T, authURL, subject string) (string, string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("login_hint", subject)
+	u.RawQuery = q.Encode()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+	cb, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cb.Query().Get("code"), cb.Query().Get("state")
+}
+
+func TestOIDCFirstLoginProvisionsAccount(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, cookie, err := f.svc.Start(ctx, "stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := authorize(t, authURL, "alice")
+	if state != cookie {
+		t.Fatalf("state in redirect %q, cookie %q", state, cookie)
+	}
+	res, err := f.svc.Callback(ctx, "stub", state, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Created || res.AccessToken == "" || res.RefreshToken == "" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if len(f.users.users) != 1 {
+		t.Fatalf("users: %d", len(f.users.users))
+	}
+
+	// второй вход тем же subject — без нового аккаунта
+	authURL, cookie, _ = f.svc.Start(ctx, "stub", "")
+	code, state = authorize(t, authURL, "alice")
+	res, err = f.svc.Callback(ctx, "stub", state, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created || res.AccessToken == "" || len(f.users.users) != 1 {
+		t.Fatalf("repeat login: %+v, users %d", res, len(f.users.users))
+	}
+}
+
+// login CSRF: атакующий начинает flow у себя и подсовывает жертве ссылку на
+// callback со своим code/state — у жертвы нет cookie с этим state
+func TestOIDCCallbackRejectsForeignState(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, _, err := f.svc.Start(ctx, "stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := authorize(t, authURL, "mallory")
+
+	_, victimCookie, _ := f.svc.Start(ctx, "stub", "")
+	for name, browserState := range map[string]string{"no cookie": "", "other flow": victimCookie} {
+		if _, err := f.svc.Callback(ctx, "stub", state, browserState, code); !errors.Is(err, ErrOIDCStateInvalid) {
+			t.Fatalf("%s: want ErrOIDCStateInvalid, got %v", name, err)
+		}
+	}
+	if len(f.users.users) != 0 {
+		t.Fatalf("account provisioned from a foreign state")
+	}
+}
+
+// link hijack: атакующий начал привязку к своему аккаунту, жертва завершает
+// её своим IdP-логином — привязка не должна состояться
+func TestOIDCLinkRequiresSameBrowser(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	for _, id := range []string{"attacker", "victim"} {
+		_ = f.users.Create(context.Background(), &models.User{ID: id, Email: id + "@example.com", Role: "client", Status: "active"})
+	}
+
+	authURL, _, err := f.svc.Start(ctx, "stub", "attacker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := authorize(t, authURL, "victim-idp")
+	if _, err := f.svc.Callback(ctx, "stub", state, "", code); !errors.Is(err, ErrOIDCStateInvalid) {
+		t.Fatalf("want ErrOIDCStateInvalid, got %v", err)
+	}
+	if list, _ := f.idents.ListByUser(ctx, "attacker"); len(list) != 0 {
+		t.Fatalf("identity linked to the attacker")
+	}
+
+	// тот же браузер — привязка проходит
+	authURL, cookie, _ := f.svc.Start(ctx, "stub", "victim")
+	code, state = authorize(t, authURL, "victim-idp")
+	res, err := f.svc.Callback(ctx, "stub", state, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Linked || res.Identity.UserID != "victim" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestOIDCStateIsOneTime(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+
+	authURL, cookie, _ := f.svc.Start(ctx, "stub", "")
+	code, state := authorize(t, authURL, "bob")
+	if _, err := f.svc.Callback(ctx, "stub", state, cookie, code); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.Callback(ctx, "stub", state, cookie, code); !errors.Is(err, ErrOIDCStateInvalid) {
+		t.Fatalf("replay: want ErrOIDCStateInvalid, got %v", err)
+	}
+}
+
+// два первых входа одним subject: проигравший callback входит в аккаунт победителя
+func TestOIDCConcurrentFirstLogin(t *testing.T) {
+	f := newOIDCFixture(t)
+	ctx := context.Background()
+	_ = f.users.Create(ctx, &models.User{ID: "winner", Role: "client", Status: "active"})
+
+	authURL, cookie, _ := f.svc.Start(ctx, "stub", "")
+	code, state := authorize(t, authURL, "carol")
+	f.idents.beforeCreate = func() {
+		f.idents.idents = append(f.idents.idents, &models.UserIdentity{ID: "ident-1", UserID: "winner", Provider: "stub", Subject: "carol"})
+	}
+	res, err := f.svc.Callback(ctx, "stub", state, cookie, code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created || res.AccessToken == "" || res.Identity.UserID != "winner" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const autoAcceptInterval = time.Hour
+
+var (
+	ErrOrderWrongState      = &ServiceError{"action not allowed in current order state"}
+	ErrNotOrderExecutor     = &ServiceError{"only the selected executor can do this"}
+	ErrNotOrderClient       = &ServiceError{"only the order client can do this"}
+	ErrNoPublishPayment     = &ServiceError{"no pending publish payment for this order"}
+	ErrSummaryRequired      = &ServiceError{"summary is required"}
+	ErrCommentRequired      = &ServiceError{"comment is required"}
+	ErrNoPendingDeliverable = &ServiceError{"no submitted deliverable to review"}
+)
+
+// chosenExecutor returns the executor of the selected bid ("" if none)
+func (s *OrderService) chosenExecutor(ctx context.Context, o *models.Order) string {
+	if o.ChosenBidID == nil {
+		return ""
+	}
+	b, err := s.bidRepo.GetByID(ctx, *o.ChosenBidID)
+	if err != nil {
+		return ""
+	}
+	return b.ExecutorID
+}
+
+// Complete — исполнитель сдаёт работу: описание + файлы, новая версия сдачи,
+// заказ переходит в client_review
+func (s *OrderService) Complete(ctx context.Context, orderID, actorID, summary string, fileIDs []string) (*models.Deliverable, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != "in_progress" {
+		return nil, ErrOrderWrongState
+	}
+	if s.chosenExecutor(ctx, o) != actorID {
+		return nil, ErrNotOrderExecutor
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return nil, ErrSummaryRequired
+	}
+	d := &models.Deliverable{
+		ID:         uuid.NewString(),
+		OrderID:    orderID,
+		ExecutorID: actorID,
+		Summary:    summary,
+	}
+	if d.Files, err = s.files.Attach(ctx, actorID, models.FileLinkDeliverable, d.ID, fileIDs); err != nil {
+		return nil, err
+	}
+	// сдача и client_review — одна транзакция; заказ мог уйти в спор после проверки статуса
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.deliverableRepo.Create(ctx, d); err != nil {
+			return err
+		}
+		ok, err := s.orderRepo.ChangeStatus(ctx, orderID, "in_progress", "client_review")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		return nil
+	})
+	if err != nil {
+		s.files.Release(ctx, actorID, models.FileLinkDeliverable, d.ID)
+		return nil, err
+	}
+	_ = s.orderRepo.AddHistory(ctx, actorID, "complete_order", "order", orderID, map[string]interface{}{"deliverable_id": d.ID, "version": d.Version})
+	s.notifyStatus(ctx, orderID, "client_review")
+	return d, nil
+}
+
+// Accept — клиент принимает последнюю сдачу: заказ completed, удержанные средства уходят исполнителю
+func (s *OrderService) Accept(ctx context.Context, orderID, actorID string) error {
+	o, d, err := s.pendingReview(ctx, orderID, actorID)
+	if err != nil {
+		return err
+	}
+	return s.accept(ctx, o, d, &actorID)
+}
+
+// RequestChanges — клиент возвращает работу на доработку с комментарием
+func (s *OrderService) RequestChanges(ctx context.Context, orderID, actorID, comment string) error {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return ErrCommentRequired
+	}
+	_, d, err := s.pendingReview(ctx, orderID, actorID)
+	if err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.deliverableRepo.Review(ctx, d.ID, models.DeliverableChangesRequested, comment, &actorID, false); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNoPendingDeliverable
+			}
+			return err
+		}
+		ok, err := s.orderRepo.ChangeStatus(ctx, orderID, "client_review", "in_progress")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_ = s.orderRepo.AddHistory(ctx, actorID, "request_changes", "order", orderID, map[string]interface{}{"deliverable_id": d.ID, "comment": comment})
+	s.notifyStatus(ctx, orderID, "in_progress")
+	return nil
+}
+
+// Deliverables — история сдач; видна клиенту, выбранному исполнителю и админу
+func (s *OrderService) Deliverables(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Deliverable, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin && o.ClientUserID != userID && s.chosenExecutor(ctx, o) != userID {
+		return nil, ErrNotOrderClient
+	}
+	return s.deliverableRepo.ListByOrder(ctx, orderID)
+}
+
+func (s *OrderService) pendingReview(ctx context.Context, orderID, actorID string) (*models.Order, *models.Deliverable, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.ClientUserID != actorID {
+		return nil, nil, ErrNotOrderClient
+	}
+	if o.Status != "client_review" {
+		return nil, nil, ErrOrderWrongState
+	}
+	d, err := s.deliverableRepo.GetLatest(ctx, orderID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && d.Status != models.DeliverableSubmitted) {
+		return nil, nil, ErrNoPendingDeliverable
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return o, d, nil
+}
+
+// accept: reviewerID == nil — автоприёмка по истечении срока. Приёмка сдачи,
+// выплата эскроу и completed — одна транзакция: при сбое выплаты заказ
+// остаётся в client_review и приёмку можно повторить.
+func (s *OrderService) accept(ctx context.Context, o *models.Order, d *models.Deliverable, reviewerID *string) error {
+	actor := ""
+	if reviewerID != nil {
+		actor = *reviewerID
+	}
+	err := s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.deliverableRepo.Review(ctx, d.ID, models.DeliverableAccepted, "", reviewerID, reviewerID == nil); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNoPendingDeliverable
+			}
+			return err
+		}
+		// заказ мог уйти в спор после проверки статуса
+		ok, err := s.orderRepo.ChangeStatus(ctx, o.ID, "client_review", "completed")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		released, err := s.paymentRepo.ReleaseEscrow(ctx, o.ID, d.ExecutorID)
+		if err != nil {
+			return err
+		}
+		return s.orderRepo.AddHistory(ctx, actor, "accept_order", "order", o.ID, map[string]interface{}{
+			"deliverable_id": d.ID, "version": d.Version, "auto": reviewerID == nil, "released": released,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	_ = s.chatRepo.ArchiveByOrder(ctx, o.ID)
+	s.notifyStatus(ctx, o.ID, "completed")
+	if o, err := s.orderRepo.GetByID(ctx, o.ID); err == nil {
+		for _, fn := range s.onCompleted {
+			fn(ctx, o)
+		}
+	}
+	return nil
+}
+
+// RunAutoAccept periodically accepts deliverables left without a client
+// response for longer than autoAccept. Blocks until ctx is cancelled.
+func (s *OrderService) RunAutoAccept(ctx context.Context) {
+	if s.autoAccept <= 0 {
+		return
+	}
+	t := time.NewTicker(autoAcceptInterval)
+	defer t.Stop()
+	for {
+		s.autoAcceptDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *OrderService) autoAcceptDue(ctx context.Context) {
+	due, err := s.deliverableRepo.ListDueForAutoAccept(ctx, time.Now().Add(-s.autoAccept), 100)
+	if err != nil {
+		log.Printf("orders: auto-accept: %v", err)
+		return
+	}
+	for _, d := range due {
+		o, err := s.orderRepo.GetByID(ctx, d.OrderID)
+		if err != nil || o.Status != "client_review" {
+			continue
+		}
+		// ErrNoPendingDeliverable / ErrOrderWrongState — другой инстанс или спор успели раньше
+		if err := s.accept(ctx, o, d, nil); err != nil && !errors.Is(err, ErrNoPendingDeliverable) && !errors.Is(err, ErrOrderWrongState) {
+			log.Printf("orders: auto-accept %s: %v", o.ID, err)
+		}
+	}
+}
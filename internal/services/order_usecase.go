@@ -7,31 +7,88 @@ import (
 	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/BekzatS8/buhpro/internal/repository"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type OrderService struct {
-	orderRepo   repository.OrderRepo
-	paymentRepo repository.PaymentRepo
+	orderRepo       repository.OrderRepo
+	paymentRepo     repository.PaymentRepo
+	bidRepo         repository.BidRepo
+	chatRepo        repository.ChatRepo
+	deliverableRepo repository.DeliverableRepo
+	invitations     repository.InvitationRepo
+	events          *EventService
+	files           *FileService
+	taxonomy        *TaxonomyService
+	tax             *TaxService
+	tx              repository.TxRunner
+	autoAccept      time.Duration // client_review без ответа дольше — работа принимается автоматически
+	onPublished     []func(ctx context.Context, o *models.Order)
+	onCompleted     []func(ctx context.Context, o *models.Order)
 }
 
-func NewOrderService(or repository.OrderRepo, pr repository.PaymentRepo) *OrderService {
-	return &OrderService{orderRepo: or, paymentRepo: pr}
+func NewOrderService(or repository.OrderRepo, pr repository.PaymentRepo, br repository.BidRepo, cr repository.ChatRepo, dr repository.DeliverableRepo, ir repository.InvitationRepo, ev *EventService, fs *FileService, ts *TaxonomyService, tx *TaxService, txr repository.TxRunner, autoAccept time.Duration) *OrderService {
+	return &OrderService{orderRepo: or, paymentRepo: pr, bidRepo: br, chatRepo: cr, deliverableRepo: dr, invitations: ir, events: ev, files: fs, taxonomy: ts, tax: tx, tx: txr, autoAccept: autoAccept}
+}
+
+// OnPublished registers a hook run after an order is published (matching,
+// saved-search alerts). Must be called during initialization.
+func (s *OrderService) OnPublished(fn func(ctx context.Context, o *models.Order)) {
+	s.onPublished = append(s.onPublished, fn)
+}
+
+// OnCompleted registers a hook run after the client (or auto-accept) accepts
+// the work (closing documents). Must be called during initialization.
+func (s *OrderService) OnCompleted(fn func(ctx context.Context, o *models.Order)) {
+	s.onCompleted = append(s.onCompleted, fn)
 }
 
 func (s *OrderService) Create(ctx context.Context, o *models.Order) error {
 	o.ID = uuid.NewString()
 	o.Status = "draft"
+	if err := normalizeVisibility(o); err != nil {
+		return err
+	}
+	if err := s.taxonomy.NormalizeOrder(ctx, o, nil); err != nil {
+		return err
+	}
 	now := time.Now()
 	o.CreatedAt = now
 	o.UpdatedAt = now
-	return s.orderRepo.Create(ctx, o)
+	atts, err := s.files.Attach(ctx, o.ClientUserID, models.FileLinkOrder, o.ID, o.Attachments)
+	if err != nil {
+		return err
+	}
+	o.Attachments = atts
+	if err := s.orderRepo.Create(ctx, o); err != nil {
+		s.files.Release(ctx, o.ClientUserID, models.FileLinkOrder, o.ID)
+		return err
+	}
+	return nil
 }
 
-func (s *OrderService) GetByID(ctx context.Context, id string) (*models.Order, error) {
-	return s.orderRepo.GetByID(ctx, id)
+// GetByID hides a private order from everyone but its client, invited
+// executors and admins: для остальных он не существует (pgx.ErrNoRows)
+func (s *OrderService) GetByID(ctx context.Context, id, userID string, isAdmin bool) (*models.Order, error) {
+	o, err := s.orderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		return o, nil
+	}
+	ok, err := canSeeOrder(ctx, s.invitations, o, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return o, nil
 }
 
 func (s *OrderService) List(ctx context.Context, filters map[string]string, page, perPage int) ([]*models.Order, int, error) {
+	s.taxonomy.ResolveFilters(ctx, filters, "category", "region")
 	return s.orderRepo.List(ctx, filters, page, perPage)
 }
 
@@ -44,6 +101,20 @@ func (s *OrderService) Update(ctx context.Context, o *models.Order) error {
 	if orig.Status != "draft" && orig.Status != "pending_payment" {
 		return ErrOrderImmutable
 	}
+	if o.Visibility == "" {
+		o.Visibility = orig.Visibility
+	}
+	if err := normalizeVisibility(o); err != nil {
+		return err
+	}
+	if err := s.taxonomy.NormalizeOrder(ctx, o, orig); err != nil {
+		return err
+	}
+	atts, err := s.files.Attach(ctx, orig.ClientUserID, models.FileLinkOrder, o.ID, o.Attachments)
+	if err != nil {
+		return err
+	}
+	o.Attachments = atts
 	return s.orderRepo.Update(ctx, o)
 }
 
@@ -61,14 +132,32 @@ func (s *OrderService) Delete(ctx context.Context, id string) error {
 var (
 	ErrOrderImmutable    = &ServiceError{"order not editable in current state"}
 	ErrOrderCannotDelete = &ServiceError{"order cannot be deleted in current state"}
+	ErrOrderVisibility   = &ServiceError{"visibility must be public or private"}
+	ErrNotOrderParty     = &ServiceError{"only the order client or the selected executor can do this"}
+	ErrNoEscrowAmount    = &ServiceError{"the selected bid has no price to hold in escrow"}
 )
 
+func normalizeVisibility(o *models.Order) error {
+	switch o.Visibility {
+	case "":
+		o.Visibility = models.OrderPublic
+	case models.OrderPublic, models.OrderPrivate:
+	default:
+		return ErrOrderVisibility
+	}
+	return nil
+}
+
 type ServiceError struct{ Msg string }
 
 func (e *ServiceError) Error() string { return e.Msg }
 
 // Publish: create payment record and set order to PENDING_PAYMENT
 func (s *OrderService) Publish(ctx context.Context, orderID string, payerID string, amount int64) (*models.Payment, error) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
 	// create payment
 	p := &models.Payment{
 		ID:          uuid.NewString(),
@@ -79,39 +168,194 @@ func (s *OrderService) Publish(ctx context.Context, orderID string, payerID stri
 		Amount:      amount,
 		Currency:    "KZT",
 		Status:      "initiated",
+		Items:       feeItems(feeName("order_publish"), amount),
 	}
-	if err := s.paymentRepo.Create(ctx, p); err != nil {
+	// публикацию от имени организации оплачивает организация (для счёта и ЭСФ)
+	if o.OrgID != "" {
+		p.OrganizationID = &o.OrgID
+	}
+	if err := s.tax.Apply(ctx, p); err != nil {
 		return nil, err
 	}
-	// set order pending payment
-	if err := s.orderRepo.SetStatus(ctx, orderID, "pending_payment"); err != nil {
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := s.paymentRepo.Create(ctx, p); err != nil {
+			return err
+		}
+		// set order pending payment
+		return s.orderRepo.SetStatus(ctx, orderID, "pending_payment")
+	})
+	if err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
+// Pay — подтверждение оплаты публикации (mock, как BidService.Pay): платёж
+// order_publish из Publish и статус заказа меняются в одной транзакции, затем
+// заказ попадает в ленту и запускаются хуки публикации
+func (s *OrderService) Pay(ctx context.Context, orderID, actorID string) error {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if o.ClientUserID != actorID {
+		return ErrNotOrderClient
+	}
+	if o.Status != "pending_payment" {
+		return ErrOrderWrongState
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		list, _, err := s.paymentRepo.List(ctx, map[string]string{
+			"related_type": "order_publish", "related_id": orderID, "status": "initiated",
+		}, 1, 1)
+		if err != nil {
+			return err
+		}
+		if len(list) == 0 {
+			return ErrNoPublishPayment
+		}
+		ok, err := s.paymentRepo.ChangeStatus(ctx, list[0].ID, "initiated", "success")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNoPublishPayment
+		}
+		if ok, err = s.orderRepo.ChangeStatus(ctx, orderID, "pending_payment", "published"); err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, []string{o.ClientUserID}, models.EventPaymentSucceeded, map[string]interface{}{
+		"related_type": "order_publish", "related_id": o.ID, "order_id": o.ID,
+	})
+	if o, err = s.orderRepo.GetByID(ctx, orderID); err != nil {
+		return err
+	}
+	for _, fn := range s.onPublished {
+		fn(ctx, o)
+	}
+	return nil
+}
+
 func (s *OrderService) SelectExecutor(ctx context.Context, orderID, bidID, actorID string) error {
 	if err := s.orderRepo.SelectExecutor(ctx, orderID, bidID); err != nil {
 		return err
 	}
 	// audit
 	_ = s.orderRepo.AddHistory(ctx, actorID, "select_executor", "order", orderID, map[string]interface{}{"bid_id": bidID})
-	return nil
-}
 
-func (s *OrderService) Start(ctx context.Context, orderID string) error {
-	// minimal checks (in real: validate actor and chosen bid)
-	return s.orderRepo.SetStatus(ctx, orderID, "in_progress")
+	// победителю — bid.won, остальным участникам — bid.lost
+	if bids, err := s.bidRepo.ListByOrder(ctx, orderID); err == nil {
+		for _, b := range bids {
+			typ := models.EventBidLost
+			if b.ID == bidID {
+				typ = models.EventBidWon
+			}
+			s.events.Publish(ctx, []string{b.ExecutorID}, typ, map[string]interface{}{"bid_id": b.ID, "order_id": orderID})
+		}
+	}
+	s.notifyStatus(ctx, orderID, "executor_selected")
+	return nil
 }
 
-func (s *OrderService) Complete(ctx context.Context, orderID, actorID string) error {
-	_ = s.orderRepo.SetStatus(ctx, orderID, "client_review")
-	_ = s.orderRepo.AddHistory(ctx, actorID, "complete_order", "order", orderID, nil)
+// Start — клиент или выбранный исполнитель начинают работу: цена выбранной
+// ставки удерживается (order_escrow, held) в той же транзакции, что и
+// in_progress. Оплата mock, как в Pay: средства клиента считаются списанными.
+func (s *OrderService) Start(ctx context.Context, orderID, actorID string) error {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if o.Status != "executor_selected" || o.ChosenBidID == nil {
+		return ErrOrderWrongState
+	}
+	b, err := s.bidRepo.GetByID(ctx, *o.ChosenBidID)
+	if err != nil {
+		return err
+	}
+	if actorID != o.ClientUserID && actorID != b.ExecutorID {
+		return ErrNotOrderParty
+	}
+	if b.Price == nil || *b.Price <= 0 {
+		return ErrNoEscrowAmount
+	}
+	p := &models.Payment{
+		ID:          uuid.NewString(),
+		UserID:      &o.ClientUserID,
+		RelatedType: "order_escrow",
+		RelatedID:   &orderID,
+		Provider:    "mock",
+		Amount:      *b.Price,
+		Currency:    "KZT",
+		Status:      "held",
+		Items:       map[string]interface{}{"bid_id": b.ID, "executor_id": b.ExecutorID},
+	}
+	if o.OrgID != "" {
+		p.OrganizationID = &o.OrgID
+	}
+	if err := s.tax.Apply(ctx, p); err != nil {
+		return err
+	}
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		ok, err := s.orderRepo.ChangeStatus(ctx, orderID, "executor_selected", "in_progress")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrOrderWrongState
+		}
+		if err := s.paymentRepo.Create(ctx, p); err != nil {
+			return err
+		}
+		return s.orderRepo.AddHistory(ctx, actorID, "start_order", "order", orderID, map[string]interface{}{"escrow_payment_id": p.ID, "amount": p.Amount})
+	})
+	if err != nil {
+		return err
+	}
+	s.notifyStatus(ctx, orderID, "in_progress")
 	return nil
 }
 
 func (s *OrderService) Cancel(ctx context.Context, orderID, actorID string) error {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	// средства по спорному заказу распределяет только арбитр
+	if o.Status == "disputed" {
+		return ErrOrderWrongState
+	}
 	_ = s.orderRepo.SetStatus(ctx, orderID, "cancelled")
+	_ = s.chatRepo.ArchiveByOrder(ctx, orderID)
 	_ = s.orderRepo.AddHistory(ctx, actorID, "cancel_order", "order", orderID, nil)
+	s.notifyStatus(ctx, orderID, "cancelled")
 	return nil
 }
+
+// notifyStatus sends order.status_changed to the client and the chosen executor
+func (s *OrderService) notifyStatus(ctx context.Context, orderID, status string) {
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return
+	}
+	s.events.Publish(ctx, orderParticipants(ctx, s.bidRepo, o), models.EventOrderStatusChanged, map[string]interface{}{
+		"order_id": o.ID, "status": status,
+	})
+}
+
+func orderParticipants(ctx context.Context, br repository.BidRepo, o *models.Order) []string {
+	ids := []string{o.ClientUserID}
+	if o.ChosenBidID != nil {
+		if b, err := br.GetByID(ctx, *o.ChosenBidID); err == nil {
+			ids = append(ids, b.ExecutorID)
+		}
+	}
+	return ids
+}
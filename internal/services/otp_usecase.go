@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/auth"
+	"github.com/BekzatS8/buhpro/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	otpDigits         = 6
+	otpMaxAttempts    = 5
+	otpResendInterval = time.Minute
+	otpPhonePerHour   = 5
+	otpIPPerHour      = 20
+)
+
+var (
+	ErrInvalidPhone  = &ServiceError{"invalid phone number"}
+	ErrOTPThrottled  = &ServiceError{"too many code requests, try again later"}
+	ErrOTPInvalid    = &ServiceError{"invalid or expired code"}
+	ErrOTPNoAttempts = &ServiceError{"too many attempts, request a new code"}
+)
+
+// OTPService — вход по номеру телефона через одноразовый SMS-код
+type OTPService struct {
+	otpRepo repository.OTPRepo
+	users   *UserUsecase
+	sender  sms.SMSSender
+	secret  string
+	ttl     time.Duration
+}
+
+func NewOTPService(or repository.OTPRepo, uc *UserUsecase, sender sms.SMSSender, secret string, ttl time.Duration) *OTPService {
+	return &OTPService{otpRepo: or, users: uc, sender: sender, secret: secret, ttl: ttl}
+}
+
+// NormalizePhone приводит номер к виду +7XXXXXXXXXX (8XXXXXXXXXX → +7XXXXXXXXXX)
+func NormalizePhone(phone string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	digits := b.String()
+	if len(digits) == 11 && digits[0] == '8' {
+		digits = "7" + digits[1:]
+	}
+	if len(digits) < 10 || len(digits) > 15 {
+		return "", ErrInvalidPhone
+	}
+	return "+" + digits, nil
+}
+
+// RequestCode генерирует код, сохраняет его хэш и отправляет SMS
+func (s *OTPService) RequestCode(ctx context.Context, phone, ip string) error {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if n, err := s.otpRepo.CountByPhoneSince(ctx, phone, now.Add(-otpResendInterval)); err != nil {
+		return err
+	} else if n > 0 {
+		return ErrOTPThrottled
+	}
+	if n, err := s.otpRepo.CountByPhoneSince(ctx, phone, now.Add(-time.Hour)); err != nil {
+		return err
+	} else if n >= otpPhonePerHour {
+		return ErrOTPThrottled
+	}
+	if ip != "" {
+		if n, err := s.otpRepo.CountByIPSince(ctx, ip, now.Add(-time.Hour)); err != nil {
+			return err
+		} else if n >= otpIPPerHour {
+			return ErrOTPThrottled
+		}
+	}
+
+	code, err := auth.GenerateOTP(otpDigits)
+	if err != nil {
+		return err
+	}
+	c := &models.OTPCode{
+		ID:        uuid.NewString(),
+		Phone:     phone,
+		CodeHash:  auth.HashOTP(s.secret, phone, code),
+		RequestIP: ip,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err := s.otpRepo.Create(ctx, c); err != nil {
+		return err
+	}
+	return s.sender.Send(ctx, phone, fmt.Sprintf("BuhPro: код входа %s. Никому его не сообщайте.", code))
+}
+
+// Verify проверяет код и входит в аккаунт с этим подтверждённым номером; при
+// первом входе создаёт аккаунт (role=client) с подтверждённым телефоном.
+// Возвращает access и refresh токены.
+func (s *OTPService) Verify(ctx context.Context, phone, code string) (string, string, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return "", "", err
+	}
+	c, err := s.otpRepo.GetActive(ctx, phone)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrOTPInvalid
+		}
+		return "", "", err
+	}
+	// попытка списывается до сравнения одним UPDATE: параллельные подборы не обходят лимит
+	ok, err := s.otpRepo.UseAttempt(ctx, c.ID, otpMaxAttempts)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return "", "", ErrOTPNoAttempts
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashOTP(s.secret, phone, code)), []byte(c.CodeHash)) != 1 {
+		return "", "", ErrOTPInvalid
+	}
+	// один код — один вход: погасить успевает только один из параллельных запросов
+	if ok, err := s.otpRepo.Consume(ctx, c.ID); err != nil {
+		return "", "", err
+	} else if !ok {
+		return "", "", ErrOTPInvalid
+	}
+
+	u, err := s.users.repo.GetByPhone(phone)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", "", err
+	}
+	if u != nil && u.PhoneVerifiedAt != nil {
+		if u.Status != "active" {
+			return "", "", ErrUserInactive
+		}
+		return s.users.issueTokens(ctx, u)
+	}
+	// номер, указанный без подтверждения, не доказывает владение аккаунтом:
+	// владелец номера получает свой аккаунт, а неподтверждённый номер снимается
+	prev := u
+	u = &models.User{
+		ID:     uuid.NewString(),
+		Phone:  phone,
+		Role:   "client",
+		Status: "active",
+	}
+	err = s.users.tx.InTx(ctx, func(ctx context.Context) error {
+		if prev != nil {
+			if err := s.users.repo.ReleasePhone(ctx, phone); err != nil {
+				return err
+			}
+		}
+		if err := s.users.repo.Create(ctx, u); err != nil {
+			return err
+		}
+		return s.users.repo.MarkPhoneVerified(ctx, u.ID)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if prev != nil {
+		_ = s.users.audit.Add(ctx, u.ID, "phone_released", "user", prev.ID, nil)
+	}
+	return s.users.issueTokens(ctx, u)
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	reviewMaxTextLen      = 2000
+	reviewPublishInterval = time.Hour
+)
+
+var (
+	ErrReviewNotAllowed    = &ServiceError{"reviews can be left only by order parties within the review period after completion"}
+	ErrReviewExists        = &ServiceError{"you have already reviewed this order"}
+	ErrSessionReviewDenied = &ServiceError{"reviews can be left only by session participants within the review period after it ends"}
+	ErrReviewScores        = &ServiceError{"rating, quality, timeliness and communication must be between 1 and 5"}
+	ErrReviewTextTooLong   = &ServiceError{"review text is too long"}
+	ErrReviewLocked        = &ServiceError{"review can no longer be edited"}
+	ErrReviewForbidden     = &ServiceError{"no access to this review"}
+	ErrInvalidModeration   = &ServiceError{"action must be hide or dismiss"}
+)
+
+// ReviewInput — оценки 1–5 и текст отзыва
+type ReviewInput struct {
+	Rating        int    `json:"rating"`
+	Quality       int    `json:"quality"`
+	Timeliness    int    `json:"timeliness"`
+	Communication int    `json:"communication"`
+	Text          string `json:"text"`
+}
+
+func (in *ReviewInput) validate() error {
+	for _, v := range []int{in.Rating, in.Quality, in.Timeliness, in.Communication} {
+		if v < 1 || v > 5 {
+			return ErrReviewScores
+		}
+	}
+	in.Text = strings.TrimSpace(in.Text)
+	if len([]rune(in.Text)) > reviewMaxTextLen {
+		return ErrReviewTextTooLong
+	}
+	return nil
+}
+
+// UserRating — рейтинг пользователя в обеих ролях; nil — отзывов нет
+type UserRating struct {
+	AsExecutor *models.RatingSummary `json:"as_executor"`
+	AsClient   *models.RatingSummary `json:"as_client"`
+	AsCoach    *models.RatingSummary `json:"as_coach,omitempty"`
+}
+
+// ReviewService — взаимные отзывы по завершённому заказу или консультации.
+// Отзывы скрыты до тех пор, пока обе стороны не оставят свой или не истечёт
+// срок (window); до публикации автор может править отзыв в течение editWindow.
+type ReviewService struct {
+	repo       repository.ReviewRepo
+	orderRepo  repository.OrderRepo
+	bidRepo    repository.BidRepo
+	mentoring  repository.MentoringRepo
+	audit      repository.AuditRepo
+	events     *EventService
+	window     time.Duration
+	editWindow time.Duration
+}
+
+func NewReviewService(rr repository.ReviewRepo, or repository.OrderRepo, br repository.BidRepo, mr repository.MentoringRepo, ar repository.AuditRepo, ev *EventService, window, editWindow time.Duration) *ReviewService {
+	return &ReviewService{repo: rr, orderRepo: or, bidRepo: br, mentoring: mr, audit: ar, events: ev, window: window, editWindow: editWindow}
+}
+
+func (s *ReviewService) Create(ctx context.Context, orderID, authorID string, in ReviewInput) (*models.Review, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	o, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status != "completed" || o.CompletedAt == nil || time.Since(*o.CompletedAt) > s.window || o.ChosenBidID == nil {
+		return nil, ErrReviewNotAllowed
+	}
+	b, err := s.bidRepo.GetByID(ctx, *o.ChosenBidID)
+	if err != nil {
+		return nil, err
+	}
+	r := &models.Review{
+		ID:            uuid.NewString(),
+		OrderID:       orderID,
+		AuthorID:      authorID,
+		Rating:        in.Rating,
+		Quality:       in.Quality,
+		Timeliness:    in.Timeliness,
+		Communication: in.Communication,
+		Text:          in.Text,
+		EditableUntil: time.Now().Add(s.editWindow),
+	}
+	switch authorID {
+	case o.ClientUserID:
+		r.AuthorRole, r.TargetID = models.RoleClient, b.ExecutorID
+	case b.ExecutorID:
+		r.AuthorRole, r.TargetID = models.RoleExecutor, o.ClientUserID
+	default:
+		return nil, ErrReviewNotAllowed
+	}
+	existing, err := s.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.AuthorID == authorID {
+			return nil, ErrReviewExists
+		}
+	}
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	// вторая сторона уже оставила отзыв — публикуем оба одновременно
+	if len(existing) > 0 {
+		if err := s.repo.PublishOrder(ctx, orderID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		r.PublishedAt = &now
+	}
+	s.events.Publish(ctx, []string{r.TargetID}, models.EventReviewReceived, map[string]interface{}{
+		"order_id": orderID, "review_id": r.ID, "published": r.PublishedAt != nil,
+	})
+	return r, nil
+}
+
+// CreateForSession — отзыв участника консультации о второй стороне; правила
+// публикации те же, что у заказов
+func (s *ReviewService) CreateForSession(ctx context.Context, sessionID, authorID string, in ReviewInput) (*models.Review, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	ms, err := s.mentoring.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if ms.Status != models.SessionCompleted || ms.CompletedAt == nil || time.Since(*ms.CompletedAt) > s.window {
+		return nil, ErrSessionReviewDenied
+	}
+	r := &models.Review{
+		ID:            uuid.NewString(),
+		SessionID:     &sessionID,
+		AuthorID:      authorID,
+		Rating:        in.Rating,
+		Quality:       in.Quality,
+		Timeliness:    in.Timeliness,
+		Communication: in.Communication,
+		Text:          in.Text,
+		EditableUntil: time.Now().Add(s.editWindow),
+	}
+	switch authorID {
+	case ms.MenteeID:
+		r.AuthorRole, r.TargetID = models.ReviewAuthorMentee, ms.CoachID
+	case ms.CoachID:
+		r.AuthorRole, r.TargetID = models.RoleCoach, ms.MenteeID
+	default:
+		return nil, ErrSessionReviewDenied
+	}
+	existing, err := s.repo.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.AuthorID == authorID {
+			return nil, ErrReviewExists
+		}
+	}
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		if err := s.repo.PublishSession(ctx, sessionID); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		r.PublishedAt = &now
+	}
+	s.events.Publish(ctx, []string{r.TargetID}, models.EventReviewReceived, map[string]interface{}{
+		"session_id": sessionID, "review_id": r.ID, "published": r.PublishedAt != nil,
+	})
+	return r, nil
+}
+
+func (s *ReviewService) Update(ctx context.Context, id, authorID string, in ReviewInput) (*models.Review, error) {
+	if err := in.validate(); err != nil {
+		return nil, err
+	}
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.AuthorID != authorID {
+		return nil, ErrReviewForbidden
+	}
+	if r.PublishedAt != nil || time.Now().After(r.EditableUntil) {
+		return nil, ErrReviewLocked
+	}
+	r.Rating, r.Quality, r.Timeliness, r.Communication, r.Text = in.Rating, in.Quality, in.Timeliness, in.Communication, in.Text
+	if err := s.repo.Update(ctx, r); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewLocked
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListByOrder — стороне заказа виден свой отзыв и опубликованный отзыв второй стороны
+func (s *ReviewService) ListByOrder(ctx context.Context, orderID, userID string, isAdmin bool) ([]*models.Review, error) {
+	list, err := s.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return visibleReviews(list, userID, isAdmin), nil
+}
+
+func (s *ReviewService) ListBySession(ctx context.Context, sessionID, userID string, isAdmin bool) ([]*models.Review, error) {
+	list, err := s.repo.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return visibleReviews(list, userID, isAdmin), nil
+}
+
+func visibleReviews(list []*models.Review, userID string, isAdmin bool) []*models.Review {
+	out := []*models.Review{}
+	for _, r := range list {
+		if isAdmin || r.AuthorID == userID || (r.TargetID == userID && r.PublishedAt != nil && r.Status == models.ReviewPublished) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ListForUser — публичные отзывы о пользователе в роли role (executor|client|coach)
+func (s *ReviewService) ListForUser(ctx context.Context, userID, role string, page, perPage int) ([]*models.Review, int, error) {
+	list, total, err := s.repo.ListForTarget(ctx, userID, reviewAuthorRole(role), page, perPage)
+	if list == nil {
+		list = []*models.Review{}
+	}
+	return list, total, err
+}
+
+func (s *ReviewService) Rating(ctx context.Context, userID string) (*UserRating, error) {
+	asExecutor, err := s.repo.Summaries(ctx, []string{userID}, models.RoleClient)
+	if err != nil {
+		return nil, err
+	}
+	asClient, err := s.repo.Summaries(ctx, []string{userID}, models.RoleExecutor)
+	if err != nil {
+		return nil, err
+	}
+	asCoach, err := s.repo.Summaries(ctx, []string{userID}, models.ReviewAuthorMentee)
+	if err != nil {
+		return nil, err
+	}
+	return &UserRating{AsExecutor: asExecutor[userID], AsClient: asClient[userID], AsCoach: asCoach[userID]}, nil
+}
+
+// reviewAuthorRole: отзывы об исполнителе пишут клиенты и наоборот,
+// о наставнике — подопечные
+func reviewAuthorRole(targetRole string) string {
+	switch targetRole {
+	case models.RoleClient:
+		return models.RoleExecutor
+	case models.RoleCoach:
+		return models.ReviewAuthorMentee
+	}
+	return models.RoleClient
+}
+
+// Report — жалоба на опубликованный отзыв; уходит в очередь модерации
+func (s *ReviewService) Report(ctx context.Context, id, reporterID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if r.PublishedAt == nil || r.Status != models.ReviewPublished {
+		return pgx.ErrNoRows
+	}
+	_, err = s.repo.AddReport(ctx, &models.ReviewReport{ID: uuid.NewString(), ReviewID: id, ReporterID: reporterID, Reason: reason})
+	return err
+}
+
+// --- admin (модерация)
+
+func (s *ReviewService) Reports(ctx context.Context, status string, page, perPage int) ([]*models.ReviewReport, int, error) {
+	list, total, err := s.repo.ListReports(ctx, status, page, perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, rep := range list {
+		if r, err := s.repo.GetByID(ctx, rep.ReviewID); err == nil {
+			rep.Review = r
+		}
+	}
+	return list, total, nil
+}
+
+// Moderate: hide скрывает отзыв (из рейтинга тоже), dismiss оставляет как есть;
+// закрывает все открытые жалобы на этот отзыв
+func (s *ReviewService) Moderate(ctx context.Context, adminID, reportID, action, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	rep, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	status := models.ReportDismissed
+	switch action {
+	case "hide":
+		status = models.ReportUpheld
+		if err := s.repo.SetStatus(ctx, rep.ReviewID, models.ReviewHidden); err != nil {
+			return err
+		}
+	case "dismiss":
+	default:
+		return ErrInvalidModeration
+	}
+	if err := s.repo.ResolveReports(ctx, rep.ReviewID, status, adminID); err != nil {
+		return err
+	}
+	return s.audit.Add(ctx, adminID, "admin.review_"+action, "review", rep.ReviewID, map[string]interface{}{
+		"reason": reason, "report_id": reportID,
+	})
+}
+
+// RunPublisher periodically reveals reviews whose review period has ended.
+// Blocks until ctx is cancelled.
+func (s *ReviewService) RunPublisher(ctx context.Context) {
+	t := time.NewTicker(reviewPublishInterval)
+	defer t.Stop()
+	for {
+		if _, err := s.repo.PublishDue(ctx, time.Now().Add(-s.window)); err != nil {
+			log.Printf("reviews: publish due: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	savedSearchMaxPerUser = 20
+	savedSearchMaxName    = 200
+	savedSearchDigestTick = time.Hour
+	savedSearchDigestGap  = 24 * time.Hour
+	savedSearchTitles     = 5
+)
+
+var (
+	ErrSavedSearchInvalid   = &ServiceError{"saved search: name is required and at least one of category, region, min_budget, max_budget"}
+	ErrSavedSearchFilter    = &ServiceError{"unknown filter or invalid budget value"}
+	ErrSavedSearchDelivery  = &ServiceError{"channel must be in_app or email, frequency instant or daily"}
+	ErrSavedSearchLimit     = &ServiceError{"too many saved searches"}
+	ErrSavedSearchForbidden = &ServiceError{"not your saved search"}
+)
+
+type SavedSearchInput struct {
+	Name      string            `json:"name"`
+	Filters   map[string]string `json:"filters"`   // category, region, min_budget, max_budget — как в GET /orders
+	Channel   string            `json:"channel"`   // in_app (default) | email
+	Frequency string            `json:"frequency"` // instant (default) | daily
+}
+
+// SavedSearchService — подписки на новые заказы. При публикации заказа
+// подходящим поискам отправляется оповещение (сразу или в ежедневном
+// дайджесте); один заказ попадает к пользователю только один раз.
+type SavedSearchService struct {
+	repo          repository.SavedSearchRepo
+	orderRepo     repository.OrderRepo
+	notifications *NotificationService
+	events        *EventService
+	taxonomy      *TaxonomyService
+}
+
+func NewSavedSearchService(sr repository.SavedSearchRepo, or repository.OrderRepo, ns *NotificationService, ev *EventService, ts *TaxonomyService) *SavedSearchService {
+	return &SavedSearchService{repo: sr, orderRepo: or, notifications: ns, events: ev, taxonomy: ts}
+}
+
+func (s *SavedSearchService) List(ctx context.Context, userID string) ([]*models.SavedSearch, error) {
+	list, err := s.repo.ListByUser(ctx, userID)
+	if list == nil {
+		list = []*models.SavedSearch{}
+	}
+	return list, err
+}
+
+func (s *SavedSearchService) Create(ctx context.Context, userID string, in SavedSearchInput) (*models.SavedSearch, error) {
+	n, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= savedSearchMaxPerUser {
+		return nil, ErrSavedSearchLimit
+	}
+	ss := &models.SavedSearch{ID: uuid.NewString(), UserID: userID}
+	if err := applySavedSearchInput(ss, in); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFilters(ctx, ss); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, ss); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (s *SavedSearchService) Update(ctx context.Context, userID, id string, in SavedSearchInput) (*models.SavedSearch, error) {
+	ss, err := s.own(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySavedSearchInput(ss, in); err != nil {
+		return nil, err
+	}
+	if err := s.resolveFilters(ctx, ss); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, ss); err != nil {
+		return nil, err
+	}
+	return ss, nil
+}
+
+func (s *SavedSearchService) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.own(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *SavedSearchService) own(ctx context.Context, userID, id string) (*models.SavedSearch, error) {
+	ss, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ss.UserID != userID {
+		return nil, ErrSavedSearchForbidden
+	}
+	return ss, nil
+}
+
+func applySavedSearchInput(ss *models.SavedSearch, in SavedSearchInput) error {
+	ss.Name = strings.TrimSpace(in.Name)
+	ss.Filters = map[string]string{}
+	for k, v := range in.Filters {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !models.SavedSearchFilters[k] {
+			return ErrSavedSearchFilter
+		}
+		if k == "min_budget" || k == "max_budget" {
+			if n, err := strconv.ParseInt(v, 10, 64); err != nil || n < 0 {
+				return ErrSavedSearchFilter
+			}
+		}
+		ss.Filters[k] = v
+	}
+	if ss.Name == "" || len([]rune(ss.Name)) > savedSearchMaxName || len(ss.Filters) == 0 {
+		return ErrSavedSearchInvalid
+	}
+	ss.Channel, ss.Frequency = in.Channel, in.Frequency
+	if ss.Channel == "" {
+		ss.Channel = models.AlertChannelInApp
+	}
+	if ss.Frequency == "" {
+		ss.Frequency = models.AlertInstant
+	}
+	if (ss.Channel != models.AlertChannelInApp && ss.Channel != models.AlertChannelEmail) ||
+		(ss.Frequency != models.AlertInstant && ss.Frequency != models.AlertDaily) {
+		return ErrSavedSearchDelivery
+	}
+	return nil
+}
+
+// resolveFilters приводит category/region к кодам справочника
+func (s *SavedSearchService) resolveFilters(ctx context.Context, ss *models.SavedSearch) error {
+	if v, ok := ss.Filters["category"]; ok {
+		code, err := s.taxonomy.Category(ctx, v)
+		if err != nil {
+			return err
+		}
+		ss.Filters["category"] = code
+	}
+	if v, ok := ss.Filters["region"]; ok {
+		kato, err := s.taxonomy.Region(ctx, v)
+		if err != nil {
+			return err
+		}
+		ss.Filters["region"] = kato
+	}
+	return nil
+}
+
+// savedSearchMatches повторяет условия OrderRepo.List для этих фильтров;
+// regions — регион заказа и его родители по КАТО
+func savedSearchMatches(o *models.Order, regions []string, filters map[string]string) bool {
+	for k, v := range filters {
+		switch k {
+		case "category":
+			if o.Category != v {
+				return false
+			}
+		case "region":
+			if !containsTag(regions, v) {
+				return false
+			}
+		case "min_budget":
+			n, _ := strconv.ParseInt(v, 10, 64)
+			if o.BudgetMin == nil || *o.BudgetMin < n {
+				return false
+			}
+		case "max_budget":
+			n, _ := strconv.ParseInt(v, 10, 64)
+			if o.BudgetMax == nil || *o.BudgetMax > n {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// OrderPublished — хук OrderService: оповещения по подходящим поискам
+func (s *SavedSearchService) OrderPublished(ctx context.Context, o *models.Order) {
+	if o.Visibility == models.OrderPrivate {
+		return
+	}
+	regions := s.taxonomy.RegionPath(ctx, o.Region)
+	candidates, err := s.repo.Candidates(ctx, o.Category, regions)
+	if err != nil {
+		log.Printf("saved searches: candidates for order %s: %v", o.ID, err)
+		return
+	}
+	for _, ss := range candidates {
+		if ss.UserID == o.ClientUserID || !savedSearchMatches(o, regions, ss.Filters) {
+			continue
+		}
+		instant := ss.Frequency == models.AlertInstant
+		added, err := s.repo.AddAlert(ctx, ss.UserID, o.ID, ss.ID, instant)
+		if err != nil {
+			log.Printf("saved searches: alert %s/%s: %v", ss.ID, o.ID, err)
+			continue
+		}
+		if added && instant {
+			s.deliver(ctx, ss, models.EventSavedSearchMatch, map[string]interface{}{
+				"search_id": ss.ID, "name": ss.Name, "order_id": o.ID, "title": o.Title,
+			})
+		}
+	}
+}
+
+func (s *SavedSearchService) deliver(ctx context.Context, ss *models.SavedSearch, eventType string, data map[string]interface{}) {
+	if ss.Channel == models.AlertChannelEmail {
+		if err := s.notifications.Email(ctx, ss.UserID, eventType, data); err != nil {
+			log.Printf("saved searches: email to %s: %v", ss.UserID, err)
+		}
+		return
+	}
+	s.events.Publish(ctx, []string{ss.UserID}, eventType, data)
+}
+
+// RunDigest sends daily digests for saved searches with frequency=daily.
+// Blocks until ctx is cancelled.
+func (s *SavedSearchService) RunDigest(ctx context.Context) {
+	t := time.NewTicker(savedSearchDigestTick)
+	defer t.Stop()
+	for {
+		s.sendDigests(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *SavedSearchService) sendDigests(ctx context.Context) {
+	now := time.Now()
+	due, err := s.repo.DueDigests(ctx, now.Add(-savedSearchDigestGap))
+	if err != nil {
+		log.Printf("saved searches: digests: %v", err)
+		return
+	}
+	for _, ss := range due {
+		ids, err := s.repo.PendingOrders(ctx, ss.ID, now)
+		if err != nil {
+			log.Printf("saved searches: pending %s: %v", ss.ID, err)
+			continue
+		}
+		if err := s.repo.MarkDigestSent(ctx, ss.ID, now); err != nil {
+			log.Printf("saved searches: mark digest %s: %v", ss.ID, err)
+			continue
+		}
+		if len(ids) == 0 {
+			continue // заказы сняты с публикации
+		}
+		var titles []string
+		for _, id := range ids {
+			if len(titles) == savedSearchTitles {
+				break
+			}
+			if o, err := s.orderRepo.GetByID(ctx, id); err == nil {
+				titles = append(titles, "«"+o.Title+"»")
+			}
+		}
+		s.deliver(ctx, ss, models.EventSavedSearchDigest, map[string]interface{}{
+			"search_id": ss.ID, "name": ss.Name, "count": len(ids), "order_ids": ids, "titles": strings.Join(titles, ", "),
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+)
+
+const statsMaxRange = 366 * 24 * time.Hour
+
+var (
+	ErrInvalidRange = &ServiceError{"invalid date range"}
+	ErrInvalidGroup = &ServiceError{"group must be day, week or month"}
+	ErrInvalidBy    = &ServiceError{"by must be region or category"}
+)
+
+// StatsService — аналитика маркетплейса для админки
+type StatsService struct {
+	repo repository.StatsRepo
+}
+
+func NewStatsService(r repository.StatsRepo) *StatsService {
+	return &StatsService{repo: r}
+}
+
+// Range validates [from, to) and grouping; zero values default to the last 30 days by day
+func (s *StatsService) Range(from, to time.Time, group string) (models.StatsRange, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if group == "" {
+		group = "day"
+	}
+	if group != "day" && group != "week" && group != "month" {
+		return models.StatsRange{}, ErrInvalidGroup
+	}
+	if !from.Before(to) || to.Sub(from) > statsMaxRange {
+		return models.StatsRange{}, ErrInvalidRange
+	}
+	return models.StatsRange{From: from, To: to, Group: group}, nil
+}
+
+func (s *StatsService) Orders(ctx context.Context, rg models.StatsRange) ([]models.OrdersPerPeriod, error) {
+	return s.repo.OrdersPerPeriod(ctx, rg.From, rg.To, rg.Group)
+}
+
+func (s *StatsService) Funnel(ctx context.Context, rg models.StatsRange) (*models.FunnelStats, error) {
+	return s.repo.Funnel(ctx, rg.From, rg.To)
+}
+
+func (s *StatsService) Bids(ctx context.Context, rg models.StatsRange) (*models.BidStats, error) {
+	return s.repo.Bids(ctx, rg.From, rg.To)
+}
+
+func (s *StatsService) Revenue(ctx context.Context, rg models.StatsRange) ([]models.RevenueRow, error) {
+	return s.repo.Revenue(ctx, rg.From, rg.To, rg.Group)
+}
+
+func (s *StatsService) ActiveExecutors(ctx context.Context, rg models.StatsRange, by string) ([]models.ActiveExecutorsRow, error) {
+	if by == "" {
+		by = "region"
+	}
+	if by != "region" && by != "category" {
+		return nil, ErrInvalidBy
+	}
+	return s.repo.ActiveExecutors(ctx, rg.From, rg.To, by)
+}
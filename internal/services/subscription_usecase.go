@@ -0,0 +1,396 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const subscriptionTick = time.Hour
+
+var (
+	ErrPlanUnknown          = &ServiceError{"unknown subscription plan"}
+	ErrAlreadySubscribed    = &ServiceError{"already subscribed to this plan"}
+	ErrSubscriptionGrace    = &ServiceError{"pay the pending renewal before changing the plan"}
+	ErrNoPendingPayment     = &ServiceError{"no subscription payment awaiting confirmation"}
+	ErrSubscriptionInactive = &ServiceError{"no active subscription"}
+)
+
+// SubscriptionCheckout — подписка и платёж, который нужно провести (nil, если платить нечего)
+type SubscriptionCheckout struct {
+	Subscription *models.Subscription `json:"subscription"`
+	Payment      *models.Payment      `json:"payment,omitempty"`
+}
+
+// SubscriptionService — тарифы исполнителей. Подписка оплачивается помесячно
+// через payments (related_type=subscription); продление создаёт платёж в конце
+// периода, до оплаты подписка в grace и условия тарифа сохраняются. Повышение
+// тарифа — сразу, с доплатой за остаток периода; понижение — со следующего.
+type SubscriptionService struct {
+	repo        repository.SubscriptionRepo
+	paymentRepo repository.PaymentRepo
+	userRepo    repository.UserRepo
+	events      *EventService
+	tax         *TaxService
+	tx          repository.TxRunner
+	grace       time.Duration
+}
+
+func NewSubscriptionService(sr repository.SubscriptionRepo, pr repository.PaymentRepo, ur repository.UserRepo, ev *EventService, tx *TaxService, txr repository.TxRunner, grace time.Duration) *SubscriptionService {
+	return &SubscriptionService{repo: sr, paymentRepo: pr, userRepo: ur, events: ev, tax: tx, tx: txr, grace: grace}
+}
+
+func (s *SubscriptionService) Plans(ctx context.Context) ([]*models.SubscriptionPlan, error) {
+	list, err := s.repo.ListPlans(ctx)
+	if list == nil {
+		list = []*models.SubscriptionPlan{}
+	}
+	return list, err
+}
+
+// Mine — подписка пользователя с тарифом; pgx.ErrNoRows, если её не было
+func (s *SubscriptionService) Mine(ctx context.Context, userID string) (*models.Subscription, error) {
+	sub, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Plan, err = s.repo.GetPlan(ctx, sub.PlanCode); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Plan — тариф текущей (active/grace) подписки или nil
+func (s *SubscriptionService) Plan(ctx context.Context, userID string) (*models.SubscriptionPlan, error) {
+	sub, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil || !sub.Current() {
+		return nil, err
+	}
+	return s.repo.GetPlan(ctx, sub.PlanCode)
+}
+
+// UseQuota списывает ставку из квоты текущего периода; false — квоты нет
+func (s *SubscriptionService) UseQuota(ctx context.Context, userID string) (bool, error) {
+	return s.repo.UseQuota(ctx, userID)
+}
+
+// Badges — бейджи тарифов пользователей с текущей подпиской
+func (s *SubscriptionService) Badges(ctx context.Context, userIDs []string) (map[string]string, error) {
+	return s.repo.Badges(ctx, userIDs)
+}
+
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID, planCode string) (*SubscriptionCheckout, error) {
+	u, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.HasRole(models.RoleExecutor) {
+		return nil, ErrNotExecutor
+	}
+	plan, err := s.repo.GetPlan(ctx, planCode)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !plan.Active) {
+		return nil, ErrPlanUnknown
+	}
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		sub, err = &models.Subscription{UserID: userID, AutoRenew: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sub.Current() {
+		return s.change(ctx, sub, plan)
+	}
+
+	// новая подписка (или возобновление истёкшей) — полный месяц
+	s.dropPending(ctx, sub)
+	p, err := s.charge(ctx, userID, plan.Price, models.SubscriptionPayNew, plan.Code)
+	if err != nil {
+		return nil, err
+	}
+	sub.PlanCode, sub.Status, sub.AutoRenew, sub.NextPlanCode = plan.Code, models.SubscriptionPending, true, nil
+	setPending(sub, p.ID, models.SubscriptionPayNew, plan.Code)
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	sub.Plan = plan
+	return &SubscriptionCheckout{Subscription: sub, Payment: p}, nil
+}
+
+// change — смена тарифа действующей подписки
+func (s *SubscriptionService) change(ctx context.Context, sub *models.Subscription, plan *models.SubscriptionPlan) (*SubscriptionCheckout, error) {
+	if sub.Status == models.SubscriptionGrace {
+		return nil, ErrSubscriptionGrace
+	}
+	cur, err := s.repo.GetPlan(ctx, sub.PlanCode)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Code == cur.Code {
+		if sub.NextPlanCode == nil && sub.PendingKind == nil {
+			return nil, ErrAlreadySubscribed
+		}
+		// отмена запланированного понижения или неоплаченного повышения
+		s.dropPending(ctx, sub)
+		sub.NextPlanCode = nil
+		if err := s.repo.Save(ctx, sub); err != nil {
+			return nil, err
+		}
+		sub.Plan = cur
+		return &SubscriptionCheckout{Subscription: sub}, nil
+	}
+	s.dropPending(ctx, sub)
+	if plan.Price <= cur.Price {
+		sub.NextPlanCode = &plan.Code
+		if err := s.repo.Save(ctx, sub); err != nil {
+			return nil, err
+		}
+		sub.Plan = cur
+		return &SubscriptionCheckout{Subscription: sub}, nil
+	}
+
+	// повышение: доплата разницы за оставшуюся часть периода
+	sub.NextPlanCode = nil
+	amount := prorate(plan.Price-cur.Price, time.Now(), *sub.PeriodStart, *sub.PeriodEnd)
+	if amount == 0 {
+		sub.PlanCode = plan.Code
+		if err := s.repo.Save(ctx, sub); err != nil {
+			return nil, err
+		}
+		sub.Plan = plan
+		return &SubscriptionCheckout{Subscription: sub}, nil
+	}
+	p, err := s.charge(ctx, sub.UserID, amount, models.SubscriptionPayUpgrade, plan.Code)
+	if err != nil {
+		return nil, err
+	}
+	setPending(sub, p.ID, models.SubscriptionPayUpgrade, plan.Code)
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	sub.Plan = cur
+	return &SubscriptionCheckout{Subscription: sub, Payment: p}, nil
+}
+
+// prorate — доля суммы за остаток периода [start, end), с округлением вверх
+func prorate(amount int64, now, start, end time.Time) int64 {
+	total := int64(end.Sub(start) / time.Second)
+	left := int64(end.Sub(now) / time.Second)
+	if total <= 0 || left <= 0 {
+		return 0
+	}
+	if left > total {
+		left = total
+	}
+	return (amount*left + total - 1) / total
+}
+
+func setPending(sub *models.Subscription, paymentID, kind, planCode string) {
+	sub.PendingPaymentID, sub.PendingKind, sub.PendingPlanCode = &paymentID, &kind, &planCode
+}
+
+// dropPending отменяет неоплаченный платёж подписки (уже проведённый Pay не трогает)
+func (s *SubscriptionService) dropPending(ctx context.Context, sub *models.Subscription) {
+	if sub.PendingPaymentID != nil {
+		if _, err := s.paymentRepo.ChangeStatus(ctx, *sub.PendingPaymentID, "initiated", "expired"); err != nil {
+			log.Printf("subscriptions: expire payment %s: %v", *sub.PendingPaymentID, err)
+		}
+	}
+	sub.PendingPaymentID, sub.PendingKind, sub.PendingPlanCode = nil, nil, nil
+}
+
+func (s *SubscriptionService) charge(ctx context.Context, userID string, amount int64, kind, planCode string) (*models.Payment, error) {
+	p := &models.Payment{
+		ID:          uuid.NewString(),
+		UserID:      &userID,
+		RelatedType: "subscription",
+		RelatedID:   &userID,
+		Provider:    "mock",
+		Amount:      amount,
+		Currency:    "KZT",
+		Status:      "initiated",
+		Items:       map[string]interface{}{"kind": kind, "plan_code": planCode},
+	}
+	if err := s.tax.Apply(ctx, p); err != nil {
+		return nil, err
+	}
+	if err := s.paymentRepo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Pay — подтверждение оплаты (mock, как BidService.Pay) ожидающего платежа подписки
+func (s *SubscriptionService) Pay(ctx context.Context, userID string) (*models.Subscription, error) {
+	sub, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoPendingPayment
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sub.PendingPaymentID == nil {
+		return nil, ErrNoPendingPayment
+	}
+	p, err := s.paymentRepo.GetByID(ctx, *sub.PendingPaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != "initiated" {
+		return nil, ErrNoPendingPayment
+	}
+	plan, err := s.repo.GetPlan(ctx, *sub.PendingPlanCode)
+	if err != nil {
+		return nil, err
+	}
+	kind := *sub.PendingKind
+	// платёж проводится только из initiated и вместе с подпиской: повторный или
+	// параллельный Pay не продлит период дважды. Подписка перечитывается под
+	// блокировкой: ставка, списавшая квоту после чтения выше, не откатится
+	err = s.tx.InTx(ctx, func(ctx context.Context) error {
+		ok, err := s.paymentRepo.ChangeStatus(ctx, p.ID, "initiated", "success")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrNoPendingPayment
+		}
+		if sub, err = s.repo.GetForUpdate(ctx, userID); err != nil {
+			return err
+		}
+		if sub.PendingPaymentID == nil || *sub.PendingPaymentID != p.ID {
+			return ErrNoPendingPayment
+		}
+		now := time.Now()
+		switch kind {
+		case models.SubscriptionPayNew, models.SubscriptionPayRenewal:
+			// продление продолжает период без разрыва, даже если оплачено в grace
+			start := now
+			if kind == models.SubscriptionPayRenewal && sub.PeriodEnd != nil && sub.PeriodEnd.AddDate(0, 1, 0).After(now) {
+				start = *sub.PeriodEnd
+			}
+			end := start.AddDate(0, 1, 0)
+			sub.PeriodStart, sub.PeriodEnd, sub.GraceUntil = &start, &end, nil
+			sub.Status, sub.BidsUsed, sub.NextPlanCode = models.SubscriptionActive, 0, nil
+		}
+		sub.PlanCode = plan.Code
+		sub.PendingPaymentID, sub.PendingKind, sub.PendingPlanCode = nil, nil, nil
+		return s.repo.Save(ctx, sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sub.Plan = plan
+	s.events.Publish(ctx, []string{userID}, models.EventPaymentSucceeded, map[string]interface{}{
+		"payment_id": p.ID, "related_type": "subscription", "related_id": userID,
+	})
+	s.events.Publish(ctx, []string{userID}, models.EventSubscriptionActive, map[string]interface{}{
+		"plan": plan.Name, "plan_code": plan.Code, "kind": kind, "bid_quota": plan.BidQuota,
+		"period_end": sub.PeriodEnd.Format("2006-01-02"),
+	})
+	return sub, nil
+}
+
+// SetAutoRenew — отказ от продления (подписка доработает до конца периода) или возврат к нему
+func (s *SubscriptionService) SetAutoRenew(ctx context.Context, userID string, on bool) (*models.Subscription, error) {
+	sub, err := s.Mine(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrSubscriptionInactive
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Current() {
+		return nil, ErrSubscriptionInactive
+	}
+	sub.AutoRenew = on
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RunRenewals periodically renews or expires subscriptions whose period
+// ended. Blocks until ctx is cancelled.
+func (s *SubscriptionService) RunRenewals(ctx context.Context) {
+	t := time.NewTicker(subscriptionTick)
+	defer t.Stop()
+	for {
+		s.processDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *SubscriptionService) processDue(ctx context.Context) {
+	due, err := s.repo.Due(ctx, time.Now())
+	if err != nil {
+		log.Printf("subscriptions: due: %v", err)
+		return
+	}
+	for _, sub := range due {
+		if err := s.advance(ctx, sub); err != nil {
+			log.Printf("subscriptions: %s: %v", sub.UserID, err)
+		}
+	}
+}
+
+// advance: закончившийся период -> платёж за продление и grace (или истечение);
+// закончившийся grace -> истечение
+func (s *SubscriptionService) advance(ctx context.Context, sub *models.Subscription) error {
+	cur, err := s.repo.GetPlan(ctx, sub.PlanCode)
+	if err != nil {
+		return err
+	}
+	s.dropPending(ctx, sub)
+	if sub.Status == models.SubscriptionActive && sub.AutoRenew {
+		code := sub.PlanCode
+		if sub.NextPlanCode != nil {
+			code = *sub.NextPlanCode
+		}
+		plan, err := s.repo.GetPlan(ctx, code)
+		if err != nil {
+			return err
+		}
+		if plan.Active {
+			p, err := s.charge(ctx, sub.UserID, plan.Price, models.SubscriptionPayRenewal, plan.Code)
+			if err != nil {
+				return err
+			}
+			until := sub.PeriodEnd.Add(s.grace)
+			sub.Status, sub.GraceUntil = models.SubscriptionGrace, &until
+			setPending(sub, p.ID, models.SubscriptionPayRenewal, plan.Code)
+			if err := s.repo.Save(ctx, sub); err != nil {
+				return err
+			}
+			s.events.Publish(ctx, []string{sub.UserID}, models.EventSubscriptionDue, map[string]interface{}{
+				"plan": plan.Name, "plan_code": plan.Code, "amount": plan.Price, "payment_id": p.ID,
+				"grace_until": until.Format("2006-01-02"),
+			})
+			return nil
+		}
+	}
+	sub.Status, sub.GraceUntil, sub.NextPlanCode = models.SubscriptionExpired, nil, nil
+	if err := s.repo.Save(ctx, sub); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, []string{sub.UserID}, models.EventSubscriptionExpired, map[string]interface{}{
+		"plan": cur.Name, "plan_code": cur.Code,
+	})
+	return nil
+}
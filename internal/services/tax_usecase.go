@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/BekzatS8/buhpro/pkg/tax"
+)
+
+var (
+	ErrTaxRateInput  = &ServiceError{"code must be vat, rate 0..99, effective_from YYYY-MM-DD"}
+	ErrTaxRatePast   = &ServiceError{"a rate change may only take effect from tomorrow or later"}
+	ErrTaxRateExists = &ServiceError{"a rate with this effective date already exists"}
+	ErrTaxQuoteInput = &ServiceError{"amount must be positive, basis gross or net, on YYYY-MM-DD"}
+)
+
+// TaxService — НДС по расписанию ставок (tax_rates). НДС начисляется, если
+// эмитент зарегистрирован по НДС: платформа — только по своим сборам
+// (platformFees), исполнитель — по цене заказа (АВР). Суммы платежей и
+// бюджеты — с НДС.
+type TaxService struct {
+	repo        repository.TaxRepo
+	docs        repository.DocumentRepo
+	orders      repository.OrderRepo
+	bids        repository.BidRepo
+	audit       repository.AuditRepo
+	platformVAT bool
+}
+
+func NewTaxService(tr repository.TaxRepo, dr repository.DocumentRepo, or repository.OrderRepo, br repository.BidRepo, ar repository.AuditRepo, platformVAT bool) *TaxService {
+	return &TaxService{repo: tr, docs: dr, orders: or, bids: br, audit: ar, platformVAT: platformVAT}
+}
+
+// TaxRateInput — новая ставка (смена ставки с даты)
+type TaxRateInput struct {
+	Code          string `json:"code"`
+	Rate          int    `json:"rate"`
+	EffectiveFrom string `json:"effective_from"` // YYYY-MM-DD
+	Note          string `json:"note"`
+}
+
+// TaxQuoteInput — калькулятор: сумма с НДС (gross) или без (net)
+type TaxQuoteInput struct {
+	Amount    int64  `form:"amount"`
+	Basis     string `form:"basis"` // gross (по умолчанию) | net
+	IssuerVAT bool   `form:"issuer_vat"`
+	PayerVAT  bool   `form:"payer_vat"`
+	On        string `form:"on"` // YYYY-MM-DD, по умолчанию сегодня
+}
+
+func (s *TaxService) Rates(ctx context.Context) ([]*models.TaxRate, error) {
+	return s.repo.Rates(ctx)
+}
+
+func (s *TaxService) AddRate(ctx context.Context, adminID string, in TaxRateInput) (*models.TaxRate, error) {
+	from, err := time.ParseInLocation("2006-01-02", in.EffectiveFrom, kzTime)
+	if err != nil || in.Code != tax.VAT || in.Rate < 0 || in.Rate > 99 {
+		return nil, ErrTaxRateInput
+	}
+	// задним числом нельзя: по прошедшим датам уже посчитаны платежи и выставлены документы
+	if !from.After(today()) {
+		return nil, ErrTaxRatePast
+	}
+	r := &models.TaxRate{Code: in.Code, Rate: in.Rate, EffectiveFrom: from, Note: in.Note, CreatedBy: &adminID}
+	if err := s.repo.AddRate(ctx, r); err != nil {
+		if errors.Is(err, repository.ErrDuplicateRate) {
+			return nil, ErrTaxRateExists
+		}
+		return nil, err
+	}
+	_ = s.audit.Add(ctx, adminID, "admin.tax_rate_add", "tax_rate", "", map[string]interface{}{
+		"code": r.Code, "rate": r.Rate, "effective_from": in.EffectiveFrom,
+	})
+	return r, nil
+}
+
+// DeleteRate cancels a scheduled rate change that has not come into force
+func (s *TaxService) DeleteRate(ctx context.Context, adminID, code, from string) error {
+	day, err := time.ParseInLocation("2006-01-02", from, kzTime)
+	if err != nil {
+		return ErrTaxRateInput
+	}
+	ok, err := s.repo.DeleteRate(ctx, code, day)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTaxRatePast
+	}
+	_ = s.audit.Add(ctx, adminID, "admin.tax_rate_delete", "tax_rate", "", map[string]interface{}{"code": code, "effective_from": from})
+	return nil
+}
+
+// VATRate — ставка НДС на дату at (0, если ставок на эту дату нет)
+func (s *TaxService) VATRate(ctx context.Context, at time.Time) (int, error) {
+	rates, err := s.repo.Rates(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sched := make(tax.Schedule, len(rates))
+	for i, r := range rates {
+		sched[i] = tax.Rate{Code: r.Code, Percent: r.Rate, From: r.EffectiveFrom}
+	}
+	// ставка — по календарной дате в Казахстане
+	day := at.In(kzTime)
+	rate, _ := sched.At(tax.VAT, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC))
+	return rate, nil
+}
+
+// Compute splits a gross amount: НДС по ставке на дату at, если эмитент плательщик НДС
+func (s *TaxService) Compute(ctx context.Context, gross int64, issuerVAT, payerVAT bool, at time.Time) (models.TaxBreakdown, error) {
+	rate := 0
+	if issuerVAT {
+		var err error
+		if rate, err = s.VATRate(ctx, at); err != nil {
+			return models.TaxBreakdown{}, err
+		}
+	}
+	return breakdown(tax.Included(gross, rate), issuerVAT, payerVAT, at), nil
+}
+
+// Quote — калькулятор НДС для произвольной суммы
+func (s *TaxService) Quote(ctx context.Context, in TaxQuoteInput) (models.TaxBreakdown, error) {
+	at := time.Now()
+	if in.On != "" {
+		var err error
+		if at, err = time.ParseInLocation("2006-01-02", in.On, kzTime); err != nil {
+			return models.TaxBreakdown{}, ErrTaxQuoteInput
+		}
+	}
+	if in.Amount <= 0 || (in.Basis != "" && in.Basis != "gross" && in.Basis != "net") {
+		return models.TaxBreakdown{}, ErrTaxQuoteInput
+	}
+	if in.Basis != "net" {
+		return s.Compute(ctx, in.Amount, in.IssuerVAT, in.PayerVAT, at)
+	}
+	rate := 0
+	if in.IssuerVAT {
+		var err error
+		if rate, err = s.VATRate(ctx, at); err != nil {
+			return models.TaxBreakdown{}, err
+		}
+	}
+	return breakdown(tax.Added(in.Amount, rate), in.IssuerVAT, in.PayerVAT, at), nil
+}
+
+// VATPayer — зарегистрирована ли по НДС организация orgID, иначе организация
+// пользователя; физлицо — нет
+func (s *TaxService) VATPayer(ctx context.Context, orgID *string, userID string) bool {
+	if orgID != nil && *orgID != "" {
+		if _, vat, err := s.docs.Organization(ctx, *orgID); err == nil {
+			return vat
+		}
+	}
+	if _, vat, err := s.docs.UserOrganization(ctx, userID); err == nil {
+		return vat
+	}
+	return false
+}
+
+// Apply fills the VAT breakdown of a new payment, ставка — на сегодня. НДС
+// платформы — только в её сборах (она выставляет счёт); оплата сессии
+// ментора идёт ментору, платформа в ней не эмитент — без НДС
+func (s *TaxService) Apply(ctx context.Context, p *models.Payment) error {
+	payerVAT := false
+	if p.UserID != nil {
+		payerVAT = s.VATPayer(ctx, p.OrganizationID, *p.UserID)
+	}
+	issuerVAT := s.platformVAT && platformFees[p.RelatedType]
+	b, err := s.Compute(ctx, p.Amount, issuerVAT, payerVAT, time.Now())
+	if err != nil {
+		return err
+	}
+	p.NetAmount, p.VATAmount, p.VATRate, p.PayerVAT = b.Net, b.VAT, b.Rate, payerVAT
+	return nil
+}
+
+// OrderQuote — бюджет заказа с НДС и без (для исполнителя-плательщика НДС и нет)
+// и цена выбранной ставки с НДС выбранного исполнителя
+func (s *TaxService) OrderQuote(ctx context.Context, orderID string) (*models.OrderQuote, error) {
+	o, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	q := &models.OrderQuote{OrderID: o.ID, Currency: o.Currency, PayerVAT: s.VATPayer(ctx, &o.OrgID, o.ClientUserID)}
+	if q.Currency == "" {
+		q.Currency = "KZT"
+	}
+	budget := func(v *int64) (*models.BudgetQuote, error) {
+		if v == nil {
+			return nil, nil
+		}
+		with, err := s.Compute(ctx, *v, true, q.PayerVAT, now)
+		if err != nil {
+			return nil, err
+		}
+		without, _ := s.Compute(ctx, *v, false, q.PayerVAT, now)
+		return &models.BudgetQuote{VATExecutor: with, NoVATExecutor: without}, nil
+	}
+	if q.BudgetMin, err = budget(o.BudgetMin); err != nil {
+		return nil, err
+	}
+	if q.BudgetMax, err = budget(o.BudgetMax); err != nil {
+		return nil, err
+	}
+	if o.ChosenBidID != nil {
+		if b, err := s.bids.GetByID(ctx, *o.ChosenBidID); err == nil && b.Price != nil {
+			cb, err := s.Compute(ctx, *b.Price, s.VATPayer(ctx, nil, b.ExecutorID), q.PayerVAT, now)
+			if err != nil {
+				return nil, err
+			}
+			q.ChosenBid = &cb
+		}
+	}
+	return q, nil
+}
+
+// paymentTax — сохранённая разбивка платежа
+func paymentTax(p *models.Payment) models.TaxBreakdown {
+	return breakdown(tax.Breakdown{Net: p.NetAmount, VAT: p.VATAmount, Gross: p.Amount, Rate: p.VATRate}, p.VATRate > 0, p.PayerVAT, p.CreatedAt)
+}
+
+func breakdown(b tax.Breakdown, issuerVAT, payerVAT bool, at time.Time) models.TaxBreakdown {
+	out := models.TaxBreakdown{
+		Net: b.Net, VAT: b.VAT, Gross: b.Gross, Rate: b.Rate,
+		IssuerVAT: issuerVAT, PayerVAT: payerVAT, PayerCost: b.Gross,
+		On: at.In(kzTime).Format("2006-01-02"),
+	}
+	if payerVAT {
+		out.Deductible = b.VAT
+		out.PayerCost = b.Net
+	}
+	return out
+}
+
+func today() time.Time {
+	now := time.Now().In(kzTime)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, kzTime)
+}
@@ -0,0 +1,521 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	taxonomyMaxAliases  = 20
+	taxonomyMaxNameLen  = 200
+	taxonomyMaxCodeLen  = 64
+	taxonomyDefaultTTL  = 5 * time.Minute
+	taxonomyAliasMaxLen = 128
+)
+
+var (
+	ErrUnknownCategory    = &ServiceError{"unknown or inactive category"}
+	ErrUnknownSubcategory = &ServiceError{"unknown or inactive subcategory for this category"}
+	ErrUnknownRegion      = &ServiceError{"unknown or inactive region (KATO code or name)"}
+	ErrTaxonomyInvalid    = &ServiceError{"names ru/kk/en are required; names and aliases within limits"}
+	ErrCategoryCode       = &ServiceError{"code must be lowercase latin letters, digits, '_' or '.'"}
+	ErrKATOCode           = &ServiceError{"kato must be 9 digits"}
+	ErrTaxonomyParent     = &ServiceError{"unknown parent (subcategories are one level deep)"}
+	ErrTaxonomyExists     = &ServiceError{"code already exists"}
+	ErrTaxonomyInUse      = &ServiceError{"still referenced: deactivate instead of deleting"}
+
+	categoryCodeRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.]*$`)
+	katoRe         = regexp.MustCompile(`^[0-9]{9}$`)
+)
+
+// TaxonomyInput — создание/изменение категории или региона.
+// Code и Parent учитываются только при создании.
+type TaxonomyInput struct {
+	Code    string   `json:"code"`   // код категории или КАТО
+	Parent  string   `json:"parent"` // parent_code / parent_kato
+	NameRu  string   `json:"name_ru"`
+	NameKk  string   `json:"name_kk"`
+	NameEn  string   `json:"name_en"`
+	Aliases []string `json:"aliases"`
+	Sort    int      `json:"sort"`
+	Active  *bool    `json:"active"` // nil = true
+}
+
+type taxonomySnapshot struct {
+	categories []*models.Category // по sort
+	regions    []*models.Region
+	byCode     map[string]*models.Category
+	byKATO     map[string]*models.Region
+	loadedAt   time.Time
+}
+
+// TaxonomyService — справочники категорий и регионов. Значения заказов,
+// профилей и сохранённых поисков приводятся к кодам справочника: принимается
+// код или любое из названий/alias без учёта регистра. Справочник кэшируется
+// на ttl; изменения через этот процесс сбрасывают кэш сразу.
+type TaxonomyService struct {
+	repo  repository.TaxonomyRepo
+	audit repository.AuditRepo
+	ttl   time.Duration
+
+	mu   sync.Mutex
+	snap *taxonomySnapshot
+}
+
+func NewTaxonomyService(tr repository.TaxonomyRepo, ar repository.AuditRepo) *TaxonomyService {
+	return &TaxonomyService{repo: tr, audit: ar, ttl: taxonomyDefaultTTL}
+}
+
+func (s *TaxonomyService) load(ctx context.Context) (*taxonomySnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snap != nil && time.Since(s.snap.loadedAt) < s.ttl {
+		return s.snap, nil
+	}
+	cats, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	regs, err := s.repo.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snap := &taxonomySnapshot{categories: cats, regions: regs, byCode: map[string]*models.Category{}, byKATO: map[string]*models.Region{}, loadedAt: time.Now()}
+	for _, c := range cats {
+		snap.byCode[c.Code] = c
+	}
+	for _, g := range regs {
+		snap.byKATO[g.KATO] = g
+	}
+	s.snap = snap
+	return snap, nil
+}
+
+func (s *TaxonomyService) invalidate() {
+	s.mu.Lock()
+	s.snap = nil
+	s.mu.Unlock()
+}
+
+// taxonomyKey — ключ сравнения: регистр, пробелы по краям и е/ё не важны
+func taxonomyKey(v string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(v)), "ё", "е")
+}
+
+func taxonomyMatches(key, code, ru, kk, en string, aliases []string) bool {
+	for _, v := range append([]string{code, ru, kk, en}, aliases...) {
+		if taxonomyKey(v) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// findCategory ищет среди детей parent ("" — верхний уровень); сначала по коду
+func (t *taxonomySnapshot) findCategory(v, parent string, inactive bool) *models.Category {
+	if c := t.byCode[strings.TrimSpace(v)]; c != nil && parentCode(c) == parent && (c.Active || inactive) {
+		return c
+	}
+	key := taxonomyKey(v)
+	for _, c := range t.categories {
+		if parentCode(c) == parent && (c.Active || inactive) && taxonomyMatches(key, c.Code, c.NameRu, c.NameKk, c.NameEn, c.Aliases) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (t *taxonomySnapshot) findRegion(v string, inactive bool) *models.Region {
+	if g := t.byKATO[strings.TrimSpace(v)]; g != nil && (g.Active || inactive) {
+		return g
+	}
+	key := taxonomyKey(v)
+	for _, g := range t.regions {
+		if (g.Active || inactive) && taxonomyMatches(key, g.KATO, g.NameRu, g.NameKk, g.NameEn, g.Aliases) {
+			return g
+		}
+	}
+	return nil
+}
+
+func parentCode(c *models.Category) string {
+	if c.ParentCode == nil {
+		return ""
+	}
+	return *c.ParentCode
+}
+
+// Category resolves an active top-level category to its code
+func (s *TaxonomyService) Category(ctx context.Context, v string) (string, error) {
+	t, err := s.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	c := t.findCategory(v, "", false)
+	if c == nil {
+		return "", ErrUnknownCategory
+	}
+	return c.Code, nil
+}
+
+// Region resolves an active region to its KATO code
+func (s *TaxonomyService) Region(ctx context.Context, v string) (string, error) {
+	t, err := s.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	g := t.findRegion(v, false)
+	if g == nil {
+		return "", ErrUnknownRegion
+	}
+	return g.KATO, nil
+}
+
+// NormalizeOrder приводит category/subcategory/region заказа к кодам.
+// Пустые значения допустимы; значения, уже сохранённые в orig, принимаются
+// даже после деактивации.
+func (s *TaxonomyService) NormalizeOrder(ctx context.Context, o, orig *models.Order) error {
+	t, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	var was models.Order
+	if orig != nil {
+		was = *orig
+	}
+	o.Category, o.Subcategory, o.Region = strings.TrimSpace(o.Category), strings.TrimSpace(o.Subcategory), strings.TrimSpace(o.Region)
+	if o.Category != "" && o.Category != was.Category {
+		c := t.findCategory(o.Category, "", false)
+		if c == nil {
+			return ErrUnknownCategory
+		}
+		o.Category = c.Code
+	}
+	if o.Subcategory != "" && (o.Subcategory != was.Subcategory || o.Category != was.Category) {
+		c := t.findCategory(o.Subcategory, o.Category, false)
+		if o.Category == "" || c == nil {
+			return ErrUnknownSubcategory
+		}
+		o.Subcategory = c.Code
+	}
+	if o.Region != "" && o.Region != was.Region {
+		g := t.findRegion(o.Region, false)
+		if g == nil {
+			return ErrUnknownRegion
+		}
+		o.Region = g.KATO
+	}
+	return nil
+}
+
+// Categories / Regions приводят теги профиля к кодам (верхний уровень категорий)
+func (s *TaxonomyService) Categories(ctx context.Context, vals []string) ([]string, error) {
+	return s.resolveAll(ctx, vals, s.Category)
+}
+
+func (s *TaxonomyService) Regions(ctx context.Context, vals []string) ([]string, error) {
+	return s.resolveAll(ctx, vals, s.Region)
+}
+
+func (s *TaxonomyService) resolveAll(ctx context.Context, vals []string, resolve func(context.Context, string) (string, error)) ([]string, error) {
+	out := make([]string, 0, len(vals))
+	seen := map[string]bool{}
+	for _, v := range vals {
+		code, err := resolve(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[code] {
+			seen[code] = true
+			out = append(out, code)
+		}
+	}
+	return out, nil
+}
+
+// ResolveFilters — для фильтров ленты и каталога: названия заменяются
+// кодами (включая неактивные), нераспознанные значения остаются как есть
+func (s *TaxonomyService) ResolveFilters(ctx context.Context, filters map[string]string, categoryKey, regionKey string) {
+	t, err := s.load(ctx)
+	if err != nil {
+		return
+	}
+	if v := filters[categoryKey]; v != "" {
+		if c := t.findCategory(v, "", true); c != nil {
+			filters[categoryKey] = c.Code
+		}
+	}
+	if v := filters[regionKey]; v != "" {
+		if g := t.findRegion(v, true); g != nil {
+			filters[regionKey] = g.KATO
+		}
+	}
+}
+
+// RegionPath — КАТО региона и всех его родителей (для фильтра "регион с вложенными")
+func (s *TaxonomyService) RegionPath(ctx context.Context, kato string) []string {
+	if kato == "" {
+		return nil
+	}
+	path := []string{kato}
+	t, err := s.load(ctx)
+	if err != nil {
+		return path
+	}
+	for g := t.byKATO[kato]; g != nil && g.ParentKATO != nil && len(path) <= len(t.regions); g = t.byKATO[*g.ParentKATO] {
+		path = append(path, *g.ParentKATO)
+	}
+	return path
+}
+
+// Tree — справочник деревьями; all=false скрывает неактивные ветки
+func (s *TaxonomyService) Tree(ctx context.Context, all bool) (*models.Taxonomy, error) {
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.Taxonomy{Categories: []*models.Category{}, Regions: []*models.Region{}}
+	cats := map[string]*models.Category{}
+	for _, c := range t.categories {
+		if c.Active || all {
+			cp := *c
+			cp.Children = nil
+			cats[c.Code] = &cp
+		}
+	}
+	for _, c := range t.categories {
+		cp := cats[c.Code]
+		if cp == nil {
+			continue
+		}
+		if c.ParentCode == nil {
+			out.Categories = append(out.Categories, cp)
+		} else if p := cats[*c.ParentCode]; p != nil {
+			p.Children = append(p.Children, cp)
+		}
+	}
+	regs := map[string]*models.Region{}
+	for _, g := range t.regions {
+		if g.Active || all {
+			cp := *g
+			cp.Children = nil
+			regs[g.KATO] = &cp
+		}
+	}
+	for _, g := range t.regions {
+		cp := regs[g.KATO]
+		if cp == nil {
+			continue
+		}
+		if g.ParentKATO == nil {
+			out.Regions = append(out.Regions, cp)
+		} else if p := regs[*g.ParentKATO]; p != nil {
+			p.Children = append(p.Children, cp)
+		}
+	}
+	return out, nil
+}
+
+func (s *TaxonomyService) Unmapped(ctx context.Context, page, perPage int) ([]*models.TaxonomyUnmapped, int, error) {
+	list, total, err := s.repo.ListUnmapped(ctx, page, perPage)
+	if list == nil {
+		list = []*models.TaxonomyUnmapped{}
+	}
+	return list, total, err
+}
+
+// applyTaxonomyNames проверяет названия и alias; active по умолчанию true
+func applyTaxonomyNames(in TaxonomyInput) (ru, kk, en string, aliases []string, active bool, err error) {
+	ru, kk, en = strings.TrimSpace(in.NameRu), strings.TrimSpace(in.NameKk), strings.TrimSpace(in.NameEn)
+	for _, n := range []string{ru, kk, en} {
+		if n == "" || len([]rune(n)) > taxonomyMaxNameLen {
+			return "", "", "", nil, false, ErrTaxonomyInvalid
+		}
+	}
+	aliases = []string{}
+	seen := map[string]bool{}
+	for _, a := range in.Aliases {
+		a = strings.TrimSpace(a)
+		if a == "" || seen[taxonomyKey(a)] {
+			continue
+		}
+		if len([]rune(a)) > taxonomyAliasMaxLen {
+			return "", "", "", nil, false, ErrTaxonomyInvalid
+		}
+		seen[taxonomyKey(a)] = true
+		aliases = append(aliases, a)
+	}
+	if len(aliases) > taxonomyMaxAliases {
+		return "", "", "", nil, false, ErrTaxonomyInvalid
+	}
+	return ru, kk, en, aliases, in.Active == nil || *in.Active, nil
+}
+
+func (s *TaxonomyService) CreateCategory(ctx context.Context, adminID string, in TaxonomyInput) (*models.Category, error) {
+	code := strings.TrimSpace(in.Code)
+	if len(code) > taxonomyMaxCodeLen || !categoryCodeRe.MatchString(code) {
+		return nil, ErrCategoryCode
+	}
+	ru, kk, en, aliases, active, err := applyTaxonomyNames(in)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.byCode[code] != nil {
+		return nil, ErrTaxonomyExists
+	}
+	c := &models.Category{Code: code, NameRu: ru, NameKk: kk, NameEn: en, Aliases: aliases, Sort: in.Sort, Active: active}
+	if parent := strings.TrimSpace(in.Parent); parent != "" {
+		p := t.byCode[parent]
+		if p == nil || p.ParentCode != nil {
+			return nil, ErrTaxonomyParent
+		}
+		c.ParentCode = &p.Code
+	}
+	if err := s.repo.CreateCategory(ctx, c); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.category_create", "category", "", map[string]interface{}{"code": c.Code, "parent_code": c.ParentCode})
+	return c, nil
+}
+
+func (s *TaxonomyService) UpdateCategory(ctx context.Context, adminID, code string, in TaxonomyInput) (*models.Category, error) {
+	ru, kk, en, aliases, active, err := applyTaxonomyNames(in)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur := t.byCode[code]
+	if cur == nil {
+		return nil, pgx.ErrNoRows
+	}
+	c := *cur
+	c.NameRu, c.NameKk, c.NameEn, c.Aliases, c.Sort, c.Active = ru, kk, en, aliases, in.Sort, active
+	if err := s.repo.UpdateCategory(ctx, &c); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.category_update", "category", "", map[string]interface{}{"code": c.Code, "active": c.Active})
+	return &c, nil
+}
+
+func (s *TaxonomyService) DeleteCategory(ctx context.Context, adminID, code string) error {
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if t.byCode[code] == nil {
+		return pgx.ErrNoRows
+	}
+	used, err := s.repo.CategoryInUse(ctx, code)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrTaxonomyInUse
+	}
+	if err := s.repo.DeleteCategory(ctx, code); err != nil {
+		return err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.category_delete", "category", "", map[string]interface{}{"code": code})
+	return nil
+}
+
+func (s *TaxonomyService) CreateRegion(ctx context.Context, adminID string, in TaxonomyInput) (*models.Region, error) {
+	kato := strings.TrimSpace(in.Code)
+	if !katoRe.MatchString(kato) {
+		return nil, ErrKATOCode
+	}
+	ru, kk, en, aliases, active, err := applyTaxonomyNames(in)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.byKATO[kato] != nil {
+		return nil, ErrTaxonomyExists
+	}
+	g := &models.Region{KATO: kato, NameRu: ru, NameKk: kk, NameEn: en, Aliases: aliases, Sort: in.Sort, Active: active}
+	if parent := strings.TrimSpace(in.Parent); parent != "" {
+		p := t.byKATO[parent]
+		if p == nil {
+			return nil, ErrTaxonomyParent
+		}
+		g.ParentKATO = &p.KATO
+	}
+	if err := s.repo.CreateRegion(ctx, g); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.region_create", "region", "", map[string]interface{}{"kato": g.KATO, "parent_kato": g.ParentKATO})
+	return g, nil
+}
+
+func (s *TaxonomyService) UpdateRegion(ctx context.Context, adminID, kato string, in TaxonomyInput) (*models.Region, error) {
+	ru, kk, en, aliases, active, err := applyTaxonomyNames(in)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur := t.byKATO[kato]
+	if cur == nil {
+		return nil, pgx.ErrNoRows
+	}
+	g := *cur
+	g.NameRu, g.NameKk, g.NameEn, g.Aliases, g.Sort, g.Active = ru, kk, en, aliases, in.Sort, active
+	if err := s.repo.UpdateRegion(ctx, &g); err != nil {
+		return nil, err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.region_update", "region", "", map[string]interface{}{"kato": g.KATO, "active": g.Active})
+	return &g, nil
+}
+
+func (s *TaxonomyService) DeleteRegion(ctx context.Context, adminID, kato string) error {
+	s.invalidate()
+	t, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	if t.byKATO[kato] == nil {
+		return pgx.ErrNoRows
+	}
+	used, err := s.repo.RegionInUse(ctx, kato)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrTaxonomyInUse
+	}
+	if err := s.repo.DeleteRegion(ctx, kato); err != nil {
+		return err
+	}
+	s.invalidate()
+	_ = s.audit.Add(ctx, adminID, "admin.region_delete", "region", "", map[string]interface{}{"kato": kato})
+	return nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/repository"
+)
+
+var (
+	ErrTokenRevoked = errors.New("token revoked")
+	ErrUserInactive = errors.New("user is not active")
+)
+
+type tokenStateEntry struct {
+	role     string
+	roles    []string
+	status   string
+	version  int
+	loadedAt time.Time
+}
+
+// TokenStateCache проверяет, что access-токен не отозван: статус пользователя
+// должен быть active, а claim "ver" — совпадать с users.token_version.
+// Состояние кэшируется на ttl; изменения, сделанные через этот процесс,
+// сбрасывают кэш сразу (Invalidate), остальные инстансы увидят их через ttl.
+type TokenStateCache struct {
+	repo repository.UserRepo
+	ttl  time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]tokenStateEntry
+}
+
+func NewTokenStateCache(r repository.UserRepo, ttl time.Duration) *TokenStateCache {
+	return &TokenStateCache{repo: r, ttl: ttl, entries: make(map[string]tokenStateEntry)}
+}
+
+// Validate returns the current primary role and role set of the user if the token version is still valid.
+func (c *TokenStateCache) Validate(ctx context.Context, userID string, tokenVersion int) (string, []string, error) {
+	e, err := c.load(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if e.status != "active" {
+		return "", nil, ErrUserInactive
+	}
+	if e.version != tokenVersion {
+		return "", nil, ErrTokenRevoked
+	}
+	return e.role, e.roles, nil
+}
+
+// Invalidate drops cached state so the next request re-reads it from the DB.
+func (c *TokenStateCache) Invalidate(userID string) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+func (c *TokenStateCache) load(userID string) (tokenStateEntry, error) {
+	c.mu.RLock()
+	e, ok := c.entries[userID]
+	c.mu.RUnlock()
+	if ok && time.Since(e.loadedAt) < c.ttl {
+		return e, nil
+	}
+
+	u, err := c.repo.GetByID(userID)
+	if err != nil {
+		return tokenStateEntry{}, err
+	}
+	e = tokenStateEntry{role: u.Role, roles: u.Roles, status: u.Status, version: u.TokenVersion, loadedAt: time.Now()}
+	c.mu.Lock()
+	c.entries[userID] = e
+	c.mu.Unlock()
+	return e, nil
+}
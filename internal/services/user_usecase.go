@@ -9,18 +9,22 @@ import (
 	"github.com/BekzatS8/buhpro/internal/repository"
 	"github.com/BekzatS8/buhpro/pkg/auth"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type UserUsecase struct {
-	repo        repository.UserRepo
-	refreshRepo repository.RefreshTokenRepo
-	jwtSecret   string
-	jwtTTL      int
-	refreshTTL  int // days
+	repo          repository.UserRepo
+	refreshRepo   repository.RefreshTokenRepo
+	loginFailures repository.LoginFailureRepo
+	audit         repository.AuditRepo
+	jwt           auth.JWTConfig
+	refreshTTL    int // days
+	tokenState    *TokenStateCache
+	tx            repository.TxRunner
 }
 
-func NewUserUsecase(r repository.UserRepo, rr repository.RefreshTokenRepo, jwtSecret string, jwtTTL int, refreshTTLDays int) *UserUsecase {
-	return &UserUsecase{repo: r, refreshRepo: rr, jwtSecret: jwtSecret, jwtTTL: jwtTTL, refreshTTL: refreshTTLDays}
+func NewUserUsecase(r repository.UserRepo, rr repository.RefreshTokenRepo, lf repository.LoginFailureRepo, ar repository.AuditRepo, jwtCfg auth.JWTConfig, ts *TokenStateCache, tx repository.TxRunner) *UserUsecase {
+	return &UserUsecase{repo: r, refreshRepo: rr, loginFailures: lf, audit: ar, jwt: jwtCfg, refreshTTL: jwtCfg.RefreshTTLDays, tokenState: ts, tx: tx}
 }
 
 // UserUpdate — DTO для обновления профиля
@@ -30,11 +34,27 @@ type UserUpdate struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
-// Register now returns access and refresh tokens
-func (uc *UserUsecase) Register(email, phone, fullName, password string, role string) (string, string, error) {
+// Register now returns access and refresh tokens.
+// Only self-service roles (client, executor) may be requested; the first one is primary.
+func (uc *UserUsecase) Register(email, phone, fullName, password string, roles []string) (string, string, error) {
+	roles, err := normalizeRoles(roles)
+	if err != nil {
+		return "", "", err
+	}
+	for _, r := range roles {
+		if !models.IsSelfServiceRole(r) {
+			return "", "", ErrRoleNotAllowed
+		}
+	}
 	if u, _ := uc.repo.GetByEmail(email); u != nil {
 		return "", "", errors.New("email already registered")
 	}
+	// номер хранится нормализованным и неподтверждённым: входа по SMS он не даёт
+	if phone != "" {
+		if phone, err = NormalizePhone(phone); err != nil {
+			return "", "", err
+		}
+	}
 	hash, err := auth.HashPassword(password)
 	if err != nil {
 		return "", "", err
@@ -44,59 +64,133 @@ func (uc *UserUsecase) Register(email, phone, fullName, password string, role st
 		Email:        email,
 		Phone:        phone,
 		FullName:     fullName,
-		Role:         role,
+		Role:         roles[0],
+		Roles:        roles,
 		Status:       "active",
 		PasswordHash: hash,
 	}
-	if err := uc.repo.Create(user); err != nil {
+	if err := uc.repo.Create(context.Background(), user); err != nil {
 		return "", "", err
 	}
-	// generate tokens
-	access, refresh, err := auth.GenerateTokens(uc.jwtSecret, user.ID, user.Role, uc.jwtTTL, uc.refreshTTL)
+	return uc.issueTokens(context.Background(), user)
+}
+
+// issueTokens generates an access/refresh pair and stores the refresh hash
+func (uc *UserUsecase) issueTokens(ctx context.Context, u *models.User) (string, string, error) {
+	access, refresh, err := auth.GenerateTokens(uc.jwt, u.ID, u.Role, u.TokenVersion)
 	if err != nil {
 		return "", "", err
 	}
 	// store refresh token (hashed)
 	rt := &models.RefreshToken{
 		ID:        uuid.NewString(),
-		UserID:    user.ID,
+		UserID:    u.ID,
 		TokenHash: auth.HashToken(refresh),
 		ExpiresAt: time.Now().Add(time.Hour * 24 * time.Duration(uc.refreshTTL)),
 		CreatedAt: time.Now(),
 	}
-	if err := uc.refreshRepo.Create(context.Background(), rt); err != nil {
+	if err := uc.refreshRepo.Create(ctx, rt); err != nil {
 		// return error to be safe
 		return "", "", err
 	}
 	return access, refresh, nil
 }
 
-// Login returns access and refresh tokens and saves refresh
-func (uc *UserUsecase) Login(email, password string) (string, string, error) {
-	u, err := uc.repo.GetByEmail(email)
+const (
+	loginMaxFailures   = 5                // неудачных попыток до блокировки аккаунта
+	loginLockout       = 15 * time.Minute // длительность блокировки
+	loginIPWindow      = 15 * time.Minute
+	loginIPMaxFailures = 30
+	loginMaxDelay      = 4 * time.Second
+)
+
+var (
+	// ErrInvalidCredentials — единый ответ: не раскрываем, существует ли email и заблокирован ли аккаунт
+	ErrInvalidCredentials = &ServiceError{"invalid credentials"}
+	ErrTooManyAttempts    = &ServiceError{"too many failed login attempts, try again later"}
+)
+
+// dummyHash сравнивается при неизвестном email, чтобы время ответа не выдавало наличие аккаунта
+var dummyHash, _ = auth.HashPassword("buhpro-dummy-password")
+
+// Login returns access and refresh tokens and saves refresh.
+// Failures are counted per account and per IP; responses slow down progressively
+// and the account is locked for loginLockout after loginMaxFailures in a row.
+func (uc *UserUsecase) Login(ctx context.Context, email, password, ip string) (string, string, error) {
+	ipFailures, err := uc.loginFailures.CountByIPSince(ctx, ip, time.Now().Add(-loginIPWindow))
 	if err != nil {
 		return "", "", err
 	}
-	if err := auth.CheckPassword(u.PasswordHash, password); err != nil {
-		return "", "", errors.New("invalid credentials")
+	if ipFailures >= loginIPMaxFailures {
+		return "", "", ErrTooManyAttempts
 	}
-	access, refresh, err := auth.GenerateTokens(uc.jwtSecret, u.ID, u.Role, uc.jwtTTL, uc.refreshTTL)
+	delay(ctx, ipFailures)
+
+	u, err := uc.repo.GetByEmail(email)
 	if err != nil {
-		return "", "", err
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return "", "", err
+		}
+		_ = auth.CheckPassword(dummyHash, password)
+		_ = uc.loginFailures.Record(ctx, email, ip)
+		return "", "", ErrInvalidCredentials
+	}
+	if u.LockedUntil != nil && u.LockedUntil.After(time.Now()) {
+		_ = uc.loginFailures.Record(ctx, email, ip)
+		return "", "", ErrInvalidCredentials
+	}
+	delay(ctx, u.FailedLoginCount)
+	if err := auth.CheckPassword(u.PasswordHash, password); err != nil {
+		_ = uc.loginFailures.Record(ctx, email, ip)
+		n, err := uc.repo.RegisterLoginFailure(u.ID)
+		if err == nil && n >= loginMaxFailures {
+			until := time.Now().Add(loginLockout)
+			if err := uc.repo.Lock(u.ID, until); err == nil {
+				_ = uc.audit.Add(ctx, "", "account_locked", "user", u.ID, map[string]interface{}{
+					"ip": ip, "failures": n, "locked_until": until,
+				})
+			}
+		}
+		return "", "", ErrInvalidCredentials
+	}
+	if u.Status != "active" {
+		return "", "", ErrInvalidCredentials
+	}
+	if u.FailedLoginCount > 0 || u.LockedUntil != nil {
+		_ = uc.repo.ResetLoginFailures(u.ID)
 	}
 	// delete previous refresh tokens for this user (optional)
-	_ = uc.refreshRepo.DeleteByUser(context.Background(), u.ID)
-	rt := &models.RefreshToken{
-		ID:        uuid.NewString(),
-		UserID:    u.ID,
-		TokenHash: auth.HashToken(refresh),
-		ExpiresAt: time.Now().Add(time.Hour * 24 * time.Duration(uc.refreshTTL)),
-		CreatedAt: time.Now(),
+	_ = uc.refreshRepo.DeleteByUser(ctx, u.ID)
+	return uc.issueTokens(ctx, u)
+}
+
+// delay — прогрессивная задержка: 0, 0, 250ms, 500ms, 1s ... до loginMaxDelay
+func delay(ctx context.Context, failures int) {
+	if failures < 2 {
+		return
 	}
-	if err := uc.refreshRepo.Create(context.Background(), rt); err != nil {
-		return "", "", err
+	d := 250 * time.Millisecond << uint(failures-2)
+	if d > loginMaxDelay || d <= 0 {
+		d = loginMaxDelay
 	}
-	return access, refresh, nil
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
+
+// UnlockAccount снимает блокировку входа (действие администратора)
+func (uc *UserUsecase) UnlockAccount(ctx context.Context, adminID, userID, reason string) error {
+	if reason == "" {
+		return ErrReasonRequired
+	}
+	if _, err := uc.repo.GetByID(userID); err != nil {
+		return err
+	}
+	if err := uc.repo.ResetLoginFailures(userID); err != nil {
+		return err
+	}
+	return uc.audit.Add(ctx, adminID, "account_unlocked", "user", userID, map[string]interface{}{"reason": reason})
 }
 
 func (uc *UserUsecase) RepoCount() (int, error) {
@@ -104,11 +198,7 @@ func (uc *UserUsecase) RepoCount() (int, error) {
 }
 
 func (uc *UserUsecase) RefreshTokens(ctx context.Context, refreshToken string) (string, string, error) {
-	claims, err := auth.ParseRefreshToken(uc.jwtSecret, refreshToken)
-	if err != nil {
-		return "", "", err
-	}
-	// check stored hash
+	// refresh token is opaque: look it up by hash
 	hash := auth.HashToken(refreshToken)
 	stored, err := uc.refreshRepo.GetByHash(ctx, hash)
 	if err != nil {
@@ -117,8 +207,17 @@ func (uc *UserUsecase) RefreshTokens(ctx context.Context, refreshToken string) (
 	if stored.ExpiresAt.Before(time.Now()) {
 		return "", "", errors.New("refresh token expired")
 	}
+	// current role/status/version — from DB, so changes apply on refresh too
+	u, err := uc.repo.GetByID(stored.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Status != "active" {
+		_ = uc.refreshRepo.DeleteByUser(ctx, u.ID)
+		return "", "", ErrUserInactive
+	}
 	// generate new tokens
-	access, newRefresh, err := auth.GenerateTokens(uc.jwtSecret, claims.UserID, claims.Role, uc.jwtTTL, uc.refreshTTL)
+	access, newRefresh, err := auth.GenerateTokens(uc.jwt, u.ID, u.Role, u.TokenVersion)
 	if err != nil {
 		return "", "", err
 	}
@@ -128,7 +227,7 @@ func (uc *UserUsecase) RefreshTokens(ctx context.Context, refreshToken string) (
 	}
 	rt := &models.RefreshToken{
 		ID:        uuid.NewString(),
-		UserID:    claims.UserID,
+		UserID:    u.ID,
 		TokenHash: auth.HashToken(newRefresh),
 		ExpiresAt: time.Now().Add(time.Hour * 24 * time.Duration(uc.refreshTTL)),
 		CreatedAt: time.Now(),
@@ -139,9 +238,105 @@ func (uc *UserUsecase) RefreshTokens(ctx context.Context, refreshToken string) (
 	return access, newRefresh, nil
 }
 
-// Logout removes all refresh tokens for a user (or a single token if needed)
+// Logout removes all refresh tokens for a user and revokes issued access tokens
 func (uc *UserUsecase) Logout(ctx context.Context, userID string) error {
-	return uc.refreshRepo.DeleteByUser(ctx, userID)
+	if err := uc.refreshRepo.DeleteByUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := uc.repo.BumpTokenVersion(userID); err != nil {
+		return err
+	}
+	uc.tokenState.Invalidate(userID)
+	return nil
+}
+
+// SetStatus (active|suspended|deleted) — сессии пользователя отзываются сразу
+func (uc *UserUsecase) SetStatus(ctx context.Context, userID, status string) error {
+	if err := uc.repo.SetStatus(ctx, userID, status); err != nil {
+		return err
+	}
+	uc.tokenState.Invalidate(userID)
+	if status != "active" {
+		return uc.refreshRepo.DeleteByUser(ctx, userID)
+	}
+	return nil
+}
+
+var (
+	ErrInvalidRole    = &ServiceError{"invalid role"}
+	ErrRoleNotAllowed = &ServiceError{"role cannot be self-assigned"}
+	ErrReasonRequired = &ServiceError{"reason is required"}
+)
+
+// normalizeRoles validates and de-duplicates roles, keeping the order (first = primary)
+func normalizeRoles(roles []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range roles {
+		if !models.ValidRole(r) {
+			return nil, ErrInvalidRole
+		}
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrInvalidRole
+	}
+	return out, nil
+}
+
+// ChangeRoles — смена ролей администратором; новые роли действуют сразу,
+// старые access-токены отзываются, изменение пишется в audit_logs.
+func (uc *UserUsecase) ChangeRoles(ctx context.Context, adminID, userID string, roles []string, reason string) (*models.User, error) {
+	if reason == "" {
+		return nil, ErrReasonRequired
+	}
+	roles, err := normalizeRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	u, err := uc.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	err = uc.tx.InTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.SetRoles(ctx, userID, roles); err != nil {
+			return err
+		}
+		return uc.audit.Add(ctx, adminID, "roles_changed", "user", userID, map[string]interface{}{
+			"old_role": u.Role, "old_roles": u.Roles, "new_roles": roles, "reason": reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.tokenState.Invalidate(userID)
+	u.Role, u.Roles = roles[0], roles
+	u.PasswordHash = ""
+	return u, nil
+}
+
+// AddSelfRole — клиент может стать исполнителем и наоборот (только self-service роли)
+func (uc *UserUsecase) AddSelfRole(ctx context.Context, userID, role string) (*models.User, error) {
+	if !models.IsSelfServiceRole(role) {
+		return nil, ErrRoleNotAllowed
+	}
+	u, err := uc.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.HasRole(role) {
+		roles := append(append([]string{}, u.Roles...), role)
+		if err := uc.repo.SetRoles(ctx, userID, roles); err != nil {
+			return nil, err
+		}
+		uc.tokenState.Invalidate(userID)
+		u.Roles = roles
+	}
+	u.PasswordHash = ""
+	return u, nil
 }
 
 // GetProfile and UpdateProfile
@@ -163,7 +358,16 @@ func (uc *UserUsecase) UpdateProfile(ctx context.Context, userID string, upd Use
 		u.FullName = *upd.FullName
 	}
 	if upd.Phone != nil {
-		u.Phone = *upd.Phone
+		phone := *upd.Phone
+		if phone != "" {
+			if phone, err = NormalizePhone(phone); err != nil {
+				return nil, err
+			}
+		}
+		// новый номер нужно подтвердить заново (repo.Update сбрасывает phone_verified_at)
+		if phone != u.Phone {
+			u.Phone, u.PhoneVerifiedAt = phone, nil
+		}
 	}
 	if upd.Metadata != nil {
 		u.Metadata = upd.Metadata
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminHandler — бэк-офис; все маршруты под /admin и RequireRole(admin)
+type AdminHandler struct {
+	svc *services.AdminService
+}
+
+func NewAdminHandler(s *services.AdminService) *AdminHandler { return &AdminHandler{svc: s} }
+
+type reasonReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type statusReasonReq struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func adminID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	s, _ := uid.(string)
+	return s
+}
+
+func pageParams(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	per, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if per < 1 || per > 100 {
+		per = 20
+	}
+	return page, per
+}
+
+func (h *AdminHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// --- users
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	filters := map[string]string{
+		"q":      c.Query("q"),
+		"role":   c.Query("role"),
+		"status": c.Query("status"),
+	}
+	page, per := pageParams(c)
+	list, total, err := h.svc.SearchUsers(c.Request.Context(), filters, page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	u, err := h.svc.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+func (h *AdminHandler) setUserStatus(c *gin.Context, status string) {
+	var req reasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.SetUserStatus(c.Request.Context(), adminID(c), c.Param("id"), status, req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "status": status})
+}
+
+func (h *AdminHandler) SuspendUser(c *gin.Context)   { h.setUserStatus(c, "suspended") }
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) { h.setUserStatus(c, "active") }
+func (h *AdminHandler) DeleteUser(c *gin.Context)    { h.setUserStatus(c, "deleted") }
+
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	var req reasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tok, err := h.svc.Impersonate(c.Request.Context(), adminID(c), c.Param("id"), req.Reason)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"access_token": tok, "read_only": true})
+}
+
+// --- orders
+
+func (h *AdminHandler) setOrderStatus(c *gin.Context, status string) {
+	var req reasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.SetOrderStatus(c.Request.Context(), adminID(c), c.Param("id"), status, req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "status": status})
+}
+
+func (h *AdminHandler) CancelOrder(c *gin.Context)  { h.setOrderStatus(c, "cancelled") }
+func (h *AdminHandler) ArchiveOrder(c *gin.Context) { h.setOrderStatus(c, "archived") }
+
+// --- bids
+
+func (h *AdminHandler) HideBid(c *gin.Context) {
+	var req reasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.HideBid(c.Request.Context(), adminID(c), c.Param("id"), req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// --- payments
+
+func (h *AdminHandler) ListPayments(c *gin.Context) {
+	filters := map[string]string{
+		"status":       c.Query("status"),
+		"user_id":      c.Query("user_id"),
+		"related_type": c.Query("related_type"),
+		"related_id":   c.Query("related_id"),
+		"provider":     c.Query("provider"),
+		"from":         c.Query("from"),
+		"to":           c.Query("to"),
+	}
+	page, per := pageParams(c)
+	list, total, err := h.svc.ListPayments(c.Request.Context(), filters, page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *AdminHandler) GetPayment(c *gin.Context) {
+	p, err := h.svc.GetPayment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *AdminHandler) SetPaymentStatus(c *gin.Context) {
+	var req statusReasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.SetPaymentStatus(c.Request.Context(), adminID(c), c.Param("id"), req.Status, req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "status": req.Status})
+}
@@ -1,13 +1,15 @@
 package http
 
 import (
-	"fmt"
+	"errors"
 	"github.com/google/uuid"
 	"net/http"
 
+	"github.com/BekzatS8/buhpro/internal/middleware"
 	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/BekzatS8/buhpro/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type BidHandler struct {
@@ -44,22 +46,26 @@ func (h *BidHandler) CreateBid(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id in context"})
 		return
 	}
-	fmt.Printf("CreateBid: executorID from ctx = %s\n", executorID)
 
 	var req models.Bid
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	fmt.Printf("CreateBid body: %+v\n", req)
 
 	req.OrderID = orderID
 	req.ExecutorID = executorID
 
-	// call service and log exact error if any
 	if err := h.svc.Create(c.Request.Context(), &req); err != nil {
-		// very important: print full error to stdout so we can see DB error text
-		fmt.Printf("ERROR: BidService.Create failed: %v\n", err)
+		if errors.Is(err, services.ErrOrderWrongState) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		var se *services.ServiceError
+		if errors.As(err, &se) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -68,7 +74,11 @@ func (h *BidHandler) CreateBid(c *gin.Context) {
 
 func (h *BidHandler) ListByOrder(c *gin.Context) {
 	orderID := c.Param("id")
-	list, err := h.svc.ListByOrder(c.Request.Context(), orderID)
+	list, err := h.svc.ListByOrder(c.Request.Context(), orderID, currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -78,11 +88,15 @@ func (h *BidHandler) ListByOrder(c *gin.Context) {
 
 func (h *BidHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
-	b, err := h.svc.GetByID(c.Request.Context(), id)
-	if err != nil {
+	b, err := h.svc.GetByID(c.Request.Context(), id, currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if errors.Is(err, pgx.ErrNoRows) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, b)
 }
 
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type ChatHandler struct {
+	svc *services.ChatService
+}
+
+func NewChatHandler(s *services.ChatService) *ChatHandler { return &ChatHandler{svc: s} }
+
+func currentUserID(c *gin.Context) string {
+	uid, _ := c.Get("user_id")
+	s, _ := uid.(string)
+	return s
+}
+
+func (h *ChatHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrChatForbidden), errors.Is(err, services.ErrChatLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrChatArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		var se *services.ServiceError
+		if errors.As(err, &se) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// cursor reads ?before=RFC3339&limit=N
+func cursor(c *gin.Context) (*time.Time, int) {
+	var before *time.Time
+	if v := c.Query("before"); v != "" {
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			before = &t
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return before, limit
+}
+
+type openChatReq struct {
+	ExecutorID string `json:"executor_id"`
+}
+
+// Open — POST /orders/:id/conversations
+func (h *ChatHandler) Open(c *gin.Context) {
+	var req openChatReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	conv, err := h.svc.Open(c.Request.Context(), c.Param("id"), currentUserID(c), req.ExecutorID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conv)
+}
+
+func (h *ChatHandler) ListMine(c *gin.Context) {
+	list, err := h.svc.ListMine(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ChatHandler) Messages(c *gin.Context) {
+	before, limit := cursor(c)
+	list, err := h.svc.Messages(c.Request.Context(), c.Param("id"), currentUserID(c), before, limit)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+type sendMessageReq struct {
+	Body        string   `json:"body"`
+	Attachments []string `json:"attachments,omitempty"` // file ids
+}
+
+func (h *ChatHandler) Send(c *gin.Context) {
+	var req sendMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	m, err := h.svc.Send(c.Request.Context(), c.Param("id"), currentUserID(c), req.Body, req.Attachments)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+func (h *ChatHandler) MarkRead(c *gin.Context) {
+	if err := h.svc.MarkRead(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+// AdminListByOrder — GET /admin/orders/:id/conversations?reason=
+func (h *ChatHandler) AdminListByOrder(c *gin.Context) {
+	list, err := h.svc.AdminListByOrder(c.Request.Context(), currentUserID(c), c.Param("id"), c.Query("reason"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// AdminMessages — GET /admin/conversations/:id/messages?reason=
+func (h *ChatHandler) AdminMessages(c *gin.Context) {
+	before, limit := cursor(c)
+	list, err := h.svc.AdminMessages(c.Request.Context(), currentUserID(c), c.Param("id"), c.Query("reason"), before, limit)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
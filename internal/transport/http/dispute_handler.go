@@ -0,0 +1,155 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// DisputeHandler — споры по заказам: стороны и арбитр (/admin/disputes)
+type DisputeHandler struct {
+	svc *services.DisputeService
+}
+
+func NewDisputeHandler(s *services.DisputeService) *DisputeHandler { return &DisputeHandler{svc: s} }
+
+type openDisputeReq struct {
+	Reason   string   `json:"reason" binding:"required"`
+	Evidence []string `json:"evidence"` // file ids
+}
+
+type disputeMessageReq struct {
+	Body        string   `json:"body"`
+	Attachments []string `json:"attachments"`
+}
+
+type assignDisputeReq struct {
+	ArbiterID string `json:"arbiter_id"` // пусто — назначить себя
+}
+
+type resolveDisputeReq struct {
+	Resolution     string `json:"resolution" binding:"required"` // refund | split | release
+	ExecutorAmount int64  `json:"executor_amount"`               // только для split
+	Note           string `json:"note" binding:"required"`
+}
+
+func (h *DisputeHandler) Open(c *gin.Context) {
+	var req openDisputeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := h.svc.Open(c.Request.Context(), c.Param("id"), currentUserID(c), req.Reason, req.Evidence)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+func (h *DisputeHandler) ListByOrder(c *gin.Context) {
+	list, err := h.svc.ListByOrder(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *DisputeHandler) Get(c *gin.Context) {
+	d, err := h.svc.Get(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *DisputeHandler) Messages(c *gin.Context) {
+	list, err := h.svc.Messages(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *DisputeHandler) AddMessage(c *gin.Context) {
+	var req disputeMessageReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	m, err := h.svc.AddMessage(c.Request.Context(), c.Param("id"), currentUserID(c), req.Body, req.Attachments,
+		middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, m)
+}
+
+// --- admin
+
+// AdminList: ?status=open|in_review|resolved&arbiter_id=&overdue=true
+func (h *DisputeHandler) AdminList(c *gin.Context) {
+	filters := map[string]string{
+		"status":     c.Query("status"),
+		"arbiter_id": c.Query("arbiter_id"),
+		"overdue":    c.Query("overdue"),
+	}
+	page, per := pageParams(c)
+	list, total, err := h.svc.List(c.Request.Context(), filters, page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *DisputeHandler) Assign(c *gin.Context) {
+	var req assignDisputeReq
+	// тело необязательно
+	_ = c.ShouldBindJSON(&req)
+	d, err := h.svc.Assign(c.Request.Context(), adminID(c), c.Param("id"), req.ArbiterID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *DisputeHandler) Resolve(c *gin.Context) {
+	var req resolveDisputeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := h.svc.Resolve(c.Request.Context(), adminID(c), c.Param("id"), req.Resolution, req.ExecutorAmount, req.Note)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *DisputeHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrDisputeForbidden), errors.Is(err, services.ErrNotArbiter):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOrderWrongState), errors.Is(err, services.ErrDisputeResolved), errors.Is(err, services.ErrNoEscrowHeld):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
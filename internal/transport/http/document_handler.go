@@ -0,0 +1,82 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// DocumentHandler — счета на оплату (/payments/:id/invoice), квитанции и АВР
+// (/orders/:id/act); ?redirect=true — сразу на PDF
+type DocumentHandler struct {
+	svc *services.DocumentService
+}
+
+func NewDocumentHandler(s *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{svc: s}
+}
+
+func (h *DocumentHandler) Invoice(c *gin.Context) {
+	d, err := h.svc.Invoice(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	h.respond(c, d, err)
+}
+
+func (h *DocumentHandler) Act(c *gin.Context) {
+	d, err := h.svc.Act(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	h.respond(c, d, err)
+}
+
+// Receipt — квитанция об оплаченном платеже с разбивкой по НДС
+func (h *DocumentHandler) Receipt(c *gin.Context) {
+	r, err := h.svc.Receipt(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+func (h *DocumentHandler) ListByOrder(c *gin.Context) {
+	list, err := h.svc.OrderDocuments(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *DocumentHandler) respond(c *gin.Context, d *models.Document, err error) {
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, d.DownloadURL)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+func (h *DocumentHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrDocumentsDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDocumentForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoInvoice), errors.Is(err, services.ErrActNotReady), errors.Is(err, services.ErrActNoAmount),
+		errors.Is(err, services.ErrNotPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ESFHandler — выгрузки ЭСФ для бухгалтерии (/admin/esf/exports)
+type ESFHandler struct {
+	svc *services.ESFService
+}
+
+func NewESFHandler(s *services.ESFService) *ESFHandler {
+	return &ESFHandler{svc: s}
+}
+
+type esfExportRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+func (h *ESFHandler) Export(c *gin.Context) {
+	var in esfExportRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	e, err := h.svc.Export(c.Request.Context(), adminID(c), in.From, in.To)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, e)
+}
+
+func (h *ESFHandler) List(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.List(c.Request.Context(), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *ESFHandler) Get(c *gin.Context) {
+	e, err := h.svc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, e.DownloadURL)
+		return
+	}
+	c.JSON(http.StatusOK, e)
+}
+
+func (h *ESFHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	var rejected *services.ESFRejectedError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.As(err, &rejected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "skipped": rejected.Skipped})
+	case errors.Is(err, services.ErrESFNothingToExport), errors.Is(err, services.ErrESFConcurrent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
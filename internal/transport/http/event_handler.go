@@ -0,0 +1,84 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/realtime"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const sseHeartbeat = 25 * time.Second
+
+// EventHandler — Server-Sent Events поток событий текущего пользователя
+type EventHandler struct {
+	svc *services.EventService
+	hub *realtime.Hub
+}
+
+func NewEventHandler(s *services.EventService, hub *realtime.Hub) *EventHandler {
+	return &EventHandler{svc: s, hub: hub}
+}
+
+// Stream — GET /events. Resume: заголовок Last-Event-ID (или ?last_event_id).
+func (h *EventHandler) Stream(c *gin.Context) {
+	uid := currentUserID(c)
+	lastID, _ := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if lastID == 0 {
+		lastID, _ = strconv.ParseInt(c.Query("last_event_id"), 10, 64)
+	}
+
+	// подписываемся до replay, чтобы не потерять события между ними
+	ch, unsubscribe := h.hub.Subscribe(uid)
+	defer unsubscribe()
+
+	missed, err := h.svc.Replay(c.Request.Context(), uid, lastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	for _, e := range missed {
+		writeEvent(w, e)
+		lastID = e.ID
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(sseHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if e.ID <= lastID {
+				continue
+			}
+			writeEvent(w, e)
+			lastID = e.ID
+			w.Flush()
+		case <-ticker.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, e *models.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Payload)
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExecutorHandler — публичный профиль исполнителя, редактирование своего
+// профиля (/users/me/...) и проверка сертификатов админом
+type ExecutorHandler struct {
+	svc *services.ExecutorService
+}
+
+func NewExecutorHandler(s *services.ExecutorService) *ExecutorHandler {
+	return &ExecutorHandler{svc: s}
+}
+
+type reviewCertificateReq struct {
+	Verified *bool  `json:"verified" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+// Search — каталог: ?q=&specialization=&region=&software=&min_rating=&price_min=&price_max=&certified=&available=
+func (h *ExecutorHandler) Search(c *gin.Context) {
+	filters := map[string]string{}
+	for _, k := range []string{"q", "specialization", "region", "software", "min_rating", "price_min", "price_max", "certified", "available"} {
+		filters[k] = c.Query(k)
+	}
+	page, per := pageParams(c)
+	list, total, err := h.svc.Search(c.Request.Context(), filters, page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *ExecutorHandler) Public(c *gin.Context) {
+	card, err := h.svc.Public(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *ExecutorHandler) MyProfile(c *gin.Context) {
+	p, err := h.svc.Profile(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ExecutorHandler) SaveProfile(c *gin.Context) {
+	var in services.ExecutorProfileInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.SaveProfile(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ExecutorHandler) MyCertificates(c *gin.Context) {
+	list, err := h.svc.Certificates(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ExecutorHandler) AddCertificate(c *gin.Context) {
+	var in services.CertificateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert, err := h.svc.AddCertificate(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, cert)
+}
+
+func (h *ExecutorHandler) DeleteCertificate(c *gin.Context) {
+	if err := h.svc.DeleteCertificate(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ExecutorHandler) MyPortfolio(c *gin.Context) {
+	list, err := h.svc.Portfolio(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ExecutorHandler) AddPortfolio(c *gin.Context) {
+	var in services.PortfolioInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.AddPortfolio(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func (h *ExecutorHandler) UpdatePortfolio(c *gin.Context) {
+	var in services.PortfolioInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.UpdatePortfolio(c.Request.Context(), currentUserID(c), c.Param("id"), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ExecutorHandler) DeletePortfolio(c *gin.Context) {
+	if err := h.svc.DeletePortfolio(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// --- admin
+
+// Certificates: ?status=pending|verified|rejected (по умолчанию pending)
+func (h *ExecutorHandler) Certificates(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.CertificatesByStatus(c.Request.Context(), c.DefaultQuery("status", models.CertificatePending), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *ExecutorHandler) ReviewCertificate(c *gin.Context) {
+	var req reviewCertificateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert, err := h.svc.ReviewCertificate(c.Request.Context(), adminID(c), c.Param("id"), *req.Verified, req.Reason)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cert)
+}
+
+func (h *ExecutorHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrNotExecutor), errors.Is(err, services.ErrCertificateForbidden), errors.Is(err, services.ErrPortfolioForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
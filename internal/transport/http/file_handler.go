@@ -0,0 +1,148 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/BekzatS8/buhpro/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type FileHandler struct {
+	svc   *services.FileService
+	local *storage.LocalStore // nil, если используется S3
+}
+
+func NewFileHandler(s *services.FileService, local *storage.LocalStore) *FileHandler {
+	return &FileHandler{svc: s, local: local}
+}
+
+func (h *FileHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrFileForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFileNotClean):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFileTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// CreateUpload — POST /files: метаданные + presigned PUT
+func (h *FileHandler) CreateUpload(c *gin.Context) {
+	var req services.FileUploadInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	f, up, err := h.svc.CreateUpload(c.Request.Context(), currentUserID(c), req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"file": f, "upload": up})
+}
+
+// Complete — POST /files/:id/complete после загрузки по ссылке
+func (h *FileHandler) Complete(c *gin.Context) {
+	f, err := h.svc.Complete(c.Request.Context(), c.Param("id"), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+func (h *FileHandler) Get(c *gin.Context) {
+	f, err := h.svc.Get(c.Request.Context(), c.Param("id"), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, f)
+}
+
+// Download — GET /files/:id/download; ?redirect=true отвечает 302
+func (h *FileHandler) Download(c *gin.Context) {
+	u, exp, err := h.svc.DownloadURL(c.Request.Context(), c.Param("id"), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if c.Query("redirect") == "true" {
+		c.Redirect(http.StatusFound, u)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": u, "expires_at": exp})
+}
+
+func (h *FileHandler) Delete(c *gin.Context) {
+	if err := h.svc.Delete(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// --- локальный backend: приём и отдача содержимого по подписанным ссылкам
+
+func blobKey(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("key"), "/")
+}
+
+func (h *FileHandler) LocalPut(c *gin.Context) {
+	key := blobKey(c)
+	if err := h.local.Verify(http.MethodPut, key, c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Request.ContentLength > h.svc.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": storage.ErrTooLarge.Error()})
+		return
+	}
+	if err := h.local.Save(key, c.Request.Body, h.svc.MaxSize()); err != nil {
+		if errors.Is(err, storage.ErrTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+func (h *FileHandler) LocalGet(c *gin.Context) {
+	key := blobKey(c)
+	q := c.Request.URL.Query()
+	if err := h.local.Verify(http.MethodGet, key, q); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	f, err := h.local.Open(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	defer f.Close()
+	if name := q.Get("name"); name != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	_, _ = io.Copy(c.Writer, f)
+}
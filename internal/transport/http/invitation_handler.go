@@ -0,0 +1,92 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// InvitationHandler — приглашения исполнителей к заказам
+type InvitationHandler struct {
+	svc *services.InvitationService
+}
+
+func NewInvitationHandler(s *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{svc: s}
+}
+
+func (h *InvitationHandler) Invite(c *gin.Context) {
+	var in services.InvitationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inv, err := h.svc.Invite(c.Request.Context(), c.Param("id"), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+func (h *InvitationHandler) ListByOrder(c *gin.Context) {
+	list, err := h.svc.ListByOrder(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Mine — входящие приглашения: ?status=pending|accepted|declined|revoked|bid_placed
+func (h *InvitationHandler) Mine(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.Mine(c.Request.Context(), currentUserID(c), c.Query("status"), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *InvitationHandler) Accept(c *gin.Context) { h.respond(c, true) }
+
+func (h *InvitationHandler) Decline(c *gin.Context) { h.respond(c, false) }
+
+func (h *InvitationHandler) respond(c *gin.Context, accept bool) {
+	inv, err := h.svc.Respond(c.Request.Context(), c.Param("id"), currentUserID(c), accept)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	if err := h.svc.Revoke(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *InvitationHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrInvitationForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyInvited), errors.Is(err, services.ErrInvitationClosed), errors.Is(err, services.ErrOrderWrongState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// MatchHandler — рекомендованные исполнителю заказы с причинами совпадения
+type MatchHandler struct {
+	svc *services.MatchService
+}
+
+func NewMatchHandler(s *services.MatchService) *MatchHandler { return &MatchHandler{svc: s} }
+
+func (h *MatchHandler) Recommended(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.Recommended(c.Request.Context(), currentUserID(c), page, per)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
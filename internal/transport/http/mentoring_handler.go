@@ -0,0 +1,196 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// MentoringHandler — наставники (/coaches), их профиль и окна
+// (/users/me/coach-*) и консультации (/sessions)
+type MentoringHandler struct {
+	svc *services.MentoringService
+}
+
+func NewMentoringHandler(s *services.MentoringService) *MentoringHandler {
+	return &MentoringHandler{svc: s}
+}
+
+type cancelSessionReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type rescheduleSessionReq struct {
+	SlotID string `json:"slot_id" binding:"required"`
+}
+
+// Search — каталог: ?q=&topic=&language=&rate_max=&min_rating=&available=true
+func (h *MentoringHandler) Search(c *gin.Context) {
+	filters := map[string]string{}
+	for _, k := range []string{"q", "topic", "language", "rate_max", "min_rating", "available"} {
+		filters[k] = c.Query(k)
+	}
+	page, per := pageParams(c)
+	list, total, err := h.svc.Search(c.Request.Context(), filters, page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *MentoringHandler) Public(c *gin.Context) {
+	card, err := h.svc.Public(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *MentoringHandler) MyProfile(c *gin.Context) {
+	p, err := h.svc.Profile(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *MentoringHandler) SaveProfile(c *gin.Context) {
+	var in services.CoachProfileInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.SaveProfile(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *MentoringHandler) MySlots(c *gin.Context) {
+	list, err := h.svc.Slots(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *MentoringHandler) AddSlot(c *gin.Context) {
+	var in services.SlotInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	slot, err := h.svc.AddSlot(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, slot)
+}
+
+func (h *MentoringHandler) DeleteSlot(c *gin.Context) {
+	if err := h.svc.DeleteSlot(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MySessions — консультации в кабинете: ?as=coach|mentee&status=
+func (h *MentoringHandler) MySessions(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.Mine(c.Request.Context(), currentUserID(c), c.Query("as"), c.Query("status"), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *MentoringHandler) Book(c *gin.Context) {
+	var in services.BookingInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	out, err := h.svc.Book(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+func (h *MentoringHandler) Get(c *gin.Context) {
+	ms, err := h.svc.Get(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ms)
+}
+
+func (h *MentoringHandler) Pay(c *gin.Context) {
+	ms, err := h.svc.Pay(c.Request.Context(), c.Param("id"), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ms)
+}
+
+func (h *MentoringHandler) Cancel(c *gin.Context) {
+	var req cancelSessionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ms, err := h.svc.Cancel(c.Request.Context(), c.Param("id"), currentUserID(c), req.Reason)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ms)
+}
+
+func (h *MentoringHandler) Reschedule(c *gin.Context) {
+	var req rescheduleSessionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ms, err := h.svc.Reschedule(c.Request.Context(), c.Param("id"), currentUserID(c), req.SlotID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ms)
+}
+
+func (h *MentoringHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrNotCoach), errors.Is(err, services.ErrSessionForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSlotOverlap), errors.Is(err, services.ErrSlotUnavailable), errors.Is(err, services.ErrSlotBooked),
+		errors.Is(err, services.ErrSessionWrongState), errors.Is(err, services.ErrRescheduleClosed), errors.Is(err, services.ErrRescheduleLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type NotificationHandler struct {
+	svc *services.NotificationService
+}
+
+func NewNotificationHandler(s *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: s}
+}
+
+// List — GET /notifications?unread=true&page=&per_page=
+func (h *NotificationHandler) List(c *gin.Context) {
+	uid := currentUserID(c)
+	page, per := pageParams(c)
+	list, total, err := h.svc.List(c.Request.Context(), uid, c.Query("unread") == "true", page, per)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	unread, err := h.svc.UnreadCount(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "unread": unread, "page": page, "per_page": per})
+}
+
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	n, err := h.svc.UnreadCount(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": n})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	if err := h.svc.MarkRead(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if err := h.svc.MarkAllRead(c.Request.Context(), currentUserID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	p, err := h.svc.Preferences(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+type preferencesReq struct {
+	Locale        string   `json:"locale"`
+	EmailEnabled  *bool    `json:"email_enabled"`
+	SMSEnabled    *bool    `json:"sms_enabled"`
+	DisabledTypes []string `json:"disabled_types"`
+}
+
+// SavePreferences — PUT /notifications/preferences; отсутствующие поля не меняются
+func (h *NotificationHandler) SavePreferences(c *gin.Context) {
+	var req preferencesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.svc.Preferences(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Locale != "" {
+		p.Locale = req.Locale
+	}
+	if req.EmailEnabled != nil {
+		p.EmailEnabled = *req.EmailEnabled
+	}
+	if req.SMSEnabled != nil {
+		p.SMSEnabled = *req.SMSEnabled
+	}
+	if req.DisabledTypes != nil {
+		p.DisabledTypes = req.DisabledTypes
+	}
+	if err := h.svc.SavePreferences(c.Request.Context(), p); err != nil {
+		var se *services.ServiceError
+		if errors.As(err, &se) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// oidcStateCookie — state текущего OIDC flow; HttpOnly + SameSite=Lax (приходит
+// на top-level redirect с IdP), только на путь callback
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+)
+
+type OIDCHandler struct {
+	svc *services.OIDCService
+}
+
+func NewOIDCHandler(s *services.OIDCService) *OIDCHandler { return &OIDCHandler{svc: s} }
+
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.svc.Providers()})
+}
+
+// Start redirects the browser to the provider login page
+func (h *OIDCHandler) Start(c *gin.Context) {
+	u, state, err := h.svc.Start(c.Request.Context(), c.Param("provider"), "")
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	h.setState(c, state, int(services.OIDCStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, u)
+}
+
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": e, "error_description": c.Query("error_description")})
+		return
+	}
+	browserState, _ := c.Cookie(oidcStateCookie)
+	h.setState(c, "", -1)
+	res, err := h.svc.Callback(c.Request.Context(), c.Param("provider"), c.Query("state"), browserState, c.Query("code"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *OIDCHandler) ListMine(c *gin.Context) {
+	uid, _ := c.Get("user_id")
+	list, err := h.svc.ListIdentities(c.Request.Context(), uid.(string))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Link returns the provider URL; after login there the identity is linked to the
+// current user. Запрос делается из того же браузера (credentials: include) — он
+// получает cookie со state, иначе callback отклонит привязку.
+func (h *OIDCHandler) Link(c *gin.Context) {
+	uid, _ := c.Get("user_id")
+	u, state, err := h.svc.Start(c.Request.Context(), c.Param("provider"), uid.(string))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	h.setState(c, state, int(services.OIDCStateTTL.Seconds()))
+	c.JSON(http.StatusOK, gin.H{"auth_url": u})
+}
+
+func (h *OIDCHandler) setState(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcCookiePath, "", secure, true)
+}
+
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	uid, _ := c.Get("user_id")
+	if err := h.svc.Unlink(c.Request.Context(), uid.(string), c.Param("id")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OIDCHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityLinked), errors.Is(err, services.ErrIdentityEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUserInactive):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "external login failed"})
+	}
+}
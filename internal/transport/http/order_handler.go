@@ -1,12 +1,15 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/BekzatS8/buhpro/internal/middleware"
 	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/BekzatS8/buhpro/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 // structure depends on your wiring
@@ -24,9 +27,13 @@ func (h *OrderHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.DELETE("/:id", h.Delete)
 
 	rg.POST("/:id/publish", h.Publish)
+	rg.POST("/:id/pay", h.Pay)
 	rg.POST("/:id/select-executor", h.SelectExecutor)
 	rg.POST("/:id/start", h.Start)
 	rg.POST("/:id/complete", h.Complete)
+	rg.POST("/:id/accept", h.Accept)
+	rg.POST("/:id/request-changes", h.RequestChanges)
+	rg.GET("/:id/deliverables", h.Deliverables)
 	rg.POST("/:id/cancel", h.Cancel)
 	rg.GET("/:id/history", h.History)
 }
@@ -42,6 +49,11 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		req.ClientUserID = uid.(string)
 	}
 	if err := h.svc.Create(c.Request.Context(), &req); err != nil {
+		var se *services.ServiceError
+		if errors.As(err, &se) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
@@ -55,6 +67,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 		"region":     c.Query("region"),
 		"min_budget": c.Query("min_budget"),
 		"max_budget": c.Query("max_budget"),
+		"visibility": models.OrderPublic, // приватные заказы видят только приглашённые
 	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	per, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
@@ -68,7 +81,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 
 func (h *OrderHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
-	o, err := h.svc.GetByID(c.Request.Context(), id)
+	o, err := h.svc.GetByID(c.Request.Context(), id, currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
 	if err != nil {
 		c.JSON(404, gin.H{"error": "not found"})
 		return
@@ -121,6 +134,15 @@ func (h *OrderHandler) Publish(c *gin.Context) {
 	c.JSON(201, p)
 }
 
+// Pay — mock-подтверждение оплаты публикации
+func (h *OrderHandler) Pay(c *gin.Context) {
+	if err := h.svc.Pay(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
 type selectReq struct {
 	BidID string `json:"bid_id" binding:"required"`
 }
@@ -144,27 +166,83 @@ func (h *OrderHandler) SelectExecutor(c *gin.Context) {
 }
 
 func (h *OrderHandler) Start(c *gin.Context) {
-	id := c.Param("id")
-	if err := h.svc.Start(c.Request.Context(), id); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+	if err := h.svc.Start(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
 		return
 	}
 	c.Status(200)
 }
 
+type completeReq struct {
+	Summary string   `json:"summary" binding:"required"`
+	Files   []string `json:"files"` // file ids
+}
+
+// Complete — исполнитель сдаёт работу (новая версия deliverable)
 func (h *OrderHandler) Complete(c *gin.Context) {
-	id := c.Param("id")
-	actor := ""
-	if uid, ok := c.Get("user_id"); ok {
-		actor = uid.(string)
+	var req completeReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	d, err := h.svc.Complete(c.Request.Context(), c.Param("id"), currentUserID(c), req.Summary, req.Files)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, d)
+}
+
+func (h *OrderHandler) Accept(c *gin.Context) {
+	if err := h.svc.Accept(c.Request.Context(), c.Param("id"), currentUserID(c)); err != nil {
+		h.fail(c, err)
+		return
 	}
-	if err := h.svc.Complete(c.Request.Context(), id, actor); err != nil {
+	c.Status(200)
+}
+
+type requestChangesReq struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+func (h *OrderHandler) RequestChanges(c *gin.Context) {
+	var req requestChangesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	if err := h.svc.RequestChanges(c.Request.Context(), c.Param("id"), currentUserID(c), req.Comment); err != nil {
+		h.fail(c, err)
+		return
+	}
 	c.Status(200)
 }
 
+func (h *OrderHandler) Deliverables(c *gin.Context) {
+	list, err := h.svc.Deliverables(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+func (h *OrderHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrNotOrderClient), errors.Is(err, services.ErrNotOrderExecutor), errors.Is(err, services.ErrNotOrderParty):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOrderWrongState), errors.Is(err, services.ErrNoPendingDeliverable), errors.Is(err, services.ErrNoPublishPayment):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *OrderHandler) Cancel(c *gin.Context) {
 	id := c.Param("id")
 	actor := ""
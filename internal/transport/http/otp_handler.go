@@ -0,0 +1,67 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OTPHandler struct {
+	svc *services.OTPService
+}
+
+func NewOTPHandler(s *services.OTPService) *OTPHandler { return &OTPHandler{svc: s} }
+
+type otpRequestReq struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+func (h *OTPHandler) Request(c *gin.Context) {
+	var req otpRequestReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.RequestCode(c.Request.Context(), req.Phone, c.ClientIP()); err != nil {
+		switch {
+		case errors.Is(err, services.ErrOTPThrottled):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"ok": true})
+}
+
+type otpVerifyReq struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required"`
+}
+
+func (h *OTPHandler) Verify(c *gin.Context) {
+	var req otpVerifyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	access, refresh, err := h.svc.Verify(c.Request.Context(), req.Phone, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPhone):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOTPNoAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOTPInvalid), errors.Is(err, services.ErrUserInactive):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"access_token": access, "refresh_token": refresh})
+}
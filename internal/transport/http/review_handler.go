@@ -0,0 +1,162 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// ReviewHandler — отзывы по заказам и консультациям, публичный рейтинг и модерация жалоб
+type ReviewHandler struct {
+	svc *services.ReviewService
+}
+
+func NewReviewHandler(s *services.ReviewService) *ReviewHandler { return &ReviewHandler{svc: s} }
+
+type reportReviewReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type moderateReviewReq struct {
+	Action string `json:"action" binding:"required"` // hide | dismiss
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (h *ReviewHandler) Create(c *gin.Context) {
+	var in services.ReviewInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.Create(c.Request.Context(), c.Param("id"), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+func (h *ReviewHandler) ListByOrder(c *gin.Context) {
+	list, err := h.svc.ListByOrder(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReviewHandler) CreateForSession(c *gin.Context) {
+	var in services.ReviewInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.CreateForSession(c.Request.Context(), c.Param("id"), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+func (h *ReviewHandler) ListBySession(c *gin.Context) {
+	list, err := h.svc.ListBySession(c.Request.Context(), c.Param("id"), currentUserID(c), middleware.HasAnyRole(c, models.RoleAdmin))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReviewHandler) Update(c *gin.Context) {
+	var in services.ReviewInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.Update(c.Request.Context(), c.Param("id"), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+func (h *ReviewHandler) Report(c *gin.Context) {
+	var req reportReviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.Report(c.Request.Context(), c.Param("id"), currentUserID(c), req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"ok": true})
+}
+
+// ForUser — публичные отзывы: ?role=executor|client|coach (по умолчанию executor)
+func (h *ReviewHandler) ForUser(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.ListForUser(c.Request.Context(), c.Param("id"), c.DefaultQuery("role", models.RoleExecutor), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *ReviewHandler) Rating(c *gin.Context) {
+	r, err := h.svc.Rating(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// --- admin
+
+func (h *ReviewHandler) Reports(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.Reports(c.Request.Context(), c.DefaultQuery("status", models.ReportOpen), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *ReviewHandler) Moderate(c *gin.Context) {
+	var req moderateReviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.Moderate(c.Request.Context(), adminID(c), c.Param("id"), req.Action, req.Reason); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *ReviewHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrReviewForbidden), errors.Is(err, services.ErrReviewNotAllowed), errors.Is(err, services.ErrSessionReviewDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReviewExists), errors.Is(err, services.ErrReviewLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
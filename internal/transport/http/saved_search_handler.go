@@ -0,0 +1,78 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// SavedSearchHandler — сохранённые поиски заказов (/users/me/saved-searches)
+type SavedSearchHandler struct {
+	svc *services.SavedSearchService
+}
+
+func NewSavedSearchHandler(s *services.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{svc: s}
+}
+
+func (h *SavedSearchHandler) List(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *SavedSearchHandler) Create(c *gin.Context) {
+	var in services.SavedSearchInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ss, err := h.svc.Create(c.Request.Context(), currentUserID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, ss)
+}
+
+func (h *SavedSearchHandler) Update(c *gin.Context) {
+	var in services.SavedSearchInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ss, err := h.svc.Update(c.Request.Context(), currentUserID(c), c.Param("id"), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ss)
+}
+
+func (h *SavedSearchHandler) Delete(c *gin.Context) {
+	if err := h.svc.Delete(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SavedSearchHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrSavedSearchForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler — /admin/stats/*; query: from, to (RFC3339 or YYYY-MM-DD), group=day|week|month
+type StatsHandler struct {
+	svc *services.StatsService
+}
+
+func NewStatsHandler(s *services.StatsService) *StatsHandler { return &StatsHandler{svc: s} }
+
+func parseDate(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+func (h *StatsHandler) rangeParams(c *gin.Context) (models.StatsRange, bool) {
+	from, err := parseDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return models.StatsRange{}, false
+	}
+	to, err := parseDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return models.StatsRange{}, false
+	}
+	rg, err := h.svc.Range(from, to, c.Query("group"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return models.StatsRange{}, false
+	}
+	return rg, true
+}
+
+func (h *StatsHandler) Orders(c *gin.Context) {
+	rg, ok := h.rangeParams(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.Orders(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"range": rg, "data": rows})
+}
+
+func (h *StatsHandler) Funnel(c *gin.Context) {
+	rg, ok := h.rangeParams(c)
+	if !ok {
+		return
+	}
+	f, err := h.svc.Funnel(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"range": rg, "data": f})
+}
+
+func (h *StatsHandler) Bids(c *gin.Context) {
+	rg, ok := h.rangeParams(c)
+	if !ok {
+		return
+	}
+	b, err := h.svc.Bids(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"range": rg, "data": b})
+}
+
+func (h *StatsHandler) Revenue(c *gin.Context) {
+	rg, ok := h.rangeParams(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.Revenue(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"range": rg, "data": rows})
+}
+
+func (h *StatsHandler) ActiveExecutors(c *gin.Context) {
+	rg, ok := h.rangeParams(c)
+	if !ok {
+		return
+	}
+	rows, err := h.svc.ActiveExecutors(c.Request.Context(), rg, c.Query("by"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"range": rg, "by": c.DefaultQuery("by", "region"), "data": rows})
+}
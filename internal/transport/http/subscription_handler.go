@@ -0,0 +1,98 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// SubscriptionHandler — тарифы исполнителей (/subscription-plans) и подписка
+// текущего пользователя (/users/me/subscription)
+type SubscriptionHandler struct {
+	svc *services.SubscriptionService
+}
+
+func NewSubscriptionHandler(s *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: s}
+}
+
+func (h *SubscriptionHandler) Plans(c *gin.Context) {
+	list, err := h.svc.Plans(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *SubscriptionHandler) Mine(c *gin.Context) {
+	sub, err := h.svc.Mine(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// Subscribe — новая подписка или смена тарифа; в ответе платёж к оплате
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	var in struct {
+		PlanCode string `json:"plan_code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	out, err := h.svc.Subscribe(c.Request.Context(), currentUserID(c), in.PlanCode)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// Pay — подтверждение ожидающего платежа (mock-провайдер)
+func (h *SubscriptionHandler) Pay(c *gin.Context) {
+	sub, err := h.svc.Pay(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *SubscriptionHandler) Update(c *gin.Context) {
+	var in struct {
+		AutoRenew *bool `json:"auto_renew" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := h.svc.SetAutoRenew(c.Request.Context(), currentUserID(c), *in.AutoRenew)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *SubscriptionHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrNotExecutor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadySubscribed), errors.Is(err, services.ErrSubscriptionGrace),
+		errors.Is(err, services.ErrNoPendingPayment):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// TaxHandler — ставки НДС, калькулятор (/tax) и расчёт бюджета заказа (/orders/:id/quote)
+type TaxHandler struct {
+	svc *services.TaxService
+}
+
+func NewTaxHandler(s *services.TaxService) *TaxHandler {
+	return &TaxHandler{svc: s}
+}
+
+func (h *TaxHandler) Rates(c *gin.Context) {
+	list, err := h.svc.Rates(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Quote: ?amount=112000&basis=gross|net&issuer_vat=true&payer_vat=false&on=2026-01-01
+func (h *TaxHandler) Quote(c *gin.Context) {
+	var in services.TaxQuoteInput
+	if err := c.ShouldBindQuery(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	b, err := h.svc.Quote(c.Request.Context(), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, b)
+}
+
+func (h *TaxHandler) OrderQuote(c *gin.Context) {
+	q, err := h.svc.OrderQuote(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+func (h *TaxHandler) AddRate(c *gin.Context) {
+	var in services.TaxRateInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.AddRate(c.Request.Context(), adminID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+func (h *TaxHandler) DeleteRate(c *gin.Context) {
+	if err := h.svc.DeleteRate(c.Request.Context(), adminID(c), c.Param("code"), c.Param("from")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TaxHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrTaxRateExists), errors.Is(err, services.ErrTaxRatePast):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
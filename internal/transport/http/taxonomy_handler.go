@@ -0,0 +1,131 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BekzatS8/buhpro/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// TaxonomyHandler — справочники категорий и регионов: публичное чтение
+// (/taxonomy) и управление под /admin/taxonomy
+type TaxonomyHandler struct {
+	svc *services.TaxonomyService
+}
+
+func NewTaxonomyHandler(s *services.TaxonomyService) *TaxonomyHandler {
+	return &TaxonomyHandler{svc: s}
+}
+
+// Get — активные категории и регионы деревьями
+func (h *TaxonomyHandler) Get(c *gin.Context) { h.tree(c, false) }
+
+// AdminGet — вместе с неактивными
+func (h *TaxonomyHandler) AdminGet(c *gin.Context) { h.tree(c, true) }
+
+func (h *TaxonomyHandler) tree(c *gin.Context, all bool) {
+	t, err := h.svc.Tree(c.Request.Context(), all)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+func (h *TaxonomyHandler) Unmapped(c *gin.Context) {
+	page, per := pageParams(c)
+	list, total, err := h.svc.Unmapped(c.Request.Context(), page, per)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": total, "page": page, "per_page": per})
+}
+
+func (h *TaxonomyHandler) CreateCategory(c *gin.Context) {
+	var in services.TaxonomyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cat, err := h.svc.CreateCategory(c.Request.Context(), adminID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, cat)
+}
+
+func (h *TaxonomyHandler) UpdateCategory(c *gin.Context) {
+	var in services.TaxonomyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cat, err := h.svc.UpdateCategory(c.Request.Context(), adminID(c), c.Param("code"), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cat)
+}
+
+func (h *TaxonomyHandler) DeleteCategory(c *gin.Context) {
+	if err := h.svc.DeleteCategory(c.Request.Context(), adminID(c), c.Param("code")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TaxonomyHandler) CreateRegion(c *gin.Context) {
+	var in services.TaxonomyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.CreateRegion(c.Request.Context(), adminID(c), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, r)
+}
+
+func (h *TaxonomyHandler) UpdateRegion(c *gin.Context) {
+	var in services.TaxonomyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	r, err := h.svc.UpdateRegion(c.Request.Context(), adminID(c), c.Param("kato"), in)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+func (h *TaxonomyHandler) DeleteRegion(c *gin.Context) {
+	if err := h.svc.DeleteRegion(c.Request.Context(), adminID(c), c.Param("kato")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TaxonomyHandler) fail(c *gin.Context, err error) {
+	var se *services.ServiceError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, services.ErrTaxonomyExists), errors.Is(err, services.ErrTaxonomyInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &se):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
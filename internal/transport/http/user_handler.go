@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/BekzatS8/buhpro/internal/models"
 	"github.com/BekzatS8/buhpro/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -26,11 +28,12 @@ func (h *UserHandler) RegisterRoutes(rg *gin.RouterGroup) {
 
 // Requests
 type registerReq struct {
-	Email    string `json:"email" binding:"required,email"`
-	Phone    string `json:"phone"`
-	FullName string `json:"full_name"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role"`
+	Email    string   `json:"email" binding:"required,email"`
+	Phone    string   `json:"phone"`
+	FullName string   `json:"full_name"`
+	Password string   `json:"password" binding:"required,min=6"`
+	Role     string   `json:"role" binding:"omitempty,oneof=client executor"`
+	Roles    []string `json:"roles" binding:"omitempty,dive,oneof=client executor"` // client+executor at once
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
@@ -39,10 +42,14 @@ func (h *UserHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if req.Role == "" {
-		req.Role = "executor"
+	roles := req.Roles
+	if req.Role != "" {
+		roles = append([]string{req.Role}, roles...)
+	}
+	if len(roles) == 0 {
+		roles = []string{models.RoleExecutor}
 	}
-	access, refresh, err := h.uc.Register(req.Email, req.Phone, req.FullName, req.Password, req.Role)
+	access, refresh, err := h.uc.Register(req.Email, req.Phone, req.FullName, req.Password, roles)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -61,9 +68,16 @@ func (h *UserHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	access, refresh, err := h.uc.Login(req.Email, req.Password)
+	access, refresh, err := h.uc.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, services.ErrTooManyAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal error"})
+		}
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"access_token": access, "refresh_token": refresh})
@@ -167,9 +181,67 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 	}
 
 	u, err := h.uc.UpdateProfile(c.Request.Context(), uid, upd)
+	if errors.Is(err, services.ErrInvalidPhone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, u)
 }
+
+// Unlock — снятие блокировки входа администратором
+func (h *UserHandler) Unlock(c *gin.Context) {
+	var req reasonReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.uc.UnlockAccount(c.Request.Context(), adminID(c), c.Param("id"), req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type addRoleReq struct {
+	Role string `json:"role" binding:"required,oneof=client executor"`
+}
+
+// AddMyRole — добавить себе вторую self-service роль (client ⇄ executor)
+func (h *UserHandler) AddMyRole(c *gin.Context) {
+	var req addRoleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	uid, _ := c.Get("user_id")
+	u, err := h.uc.AddSelfRole(c.Request.Context(), uid.(string), req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+type setRolesReq struct {
+	Roles  []string `json:"roles" binding:"required,min=1,dive,oneof=client executor coach admin"`
+	Reason string   `json:"reason" binding:"required"`
+}
+
+// SetRoles — смена ролей администратором (пишется в audit_logs)
+func (h *UserHandler) SetRoles(c *gin.Context) {
+	var req setRolesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	u, err := h.uc.ChangeRoles(c.Request.Context(), adminID(c), c.Param("id"), req.Roles, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
@@ -1,51 +1,273 @@
 package router
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
+	"github.com/BekzatS8/buhpro/internal/realtime"
 	"github.com/BekzatS8/buhpro/internal/repository"
 	"github.com/BekzatS8/buhpro/internal/services"
 	httpHandlers "github.com/BekzatS8/buhpro/internal/transport/http"
+	"github.com/BekzatS8/buhpro/pkg/auth"
 	"github.com/BekzatS8/buhpro/pkg/config"
+	"github.com/BekzatS8/buhpro/pkg/email"
+	"github.com/BekzatS8/buhpro/pkg/oidc"
+	"github.com/BekzatS8/buhpro/pkg/pdf"
+	"github.com/BekzatS8/buhpro/pkg/scanner"
+	"github.com/BekzatS8/buhpro/pkg/sms"
+	"github.com/BekzatS8/buhpro/pkg/storage"
 )
 
 // AppDeps carries minimal app dependencies (передаём в InitAndRegister)
 type AppDeps struct {
 	DB  *pgxpool.Pool
 	Cfg *config.Config
+	// Ctx ограничивает жизнь фоновых воркеров (отменяется при остановке сервера)
+	Ctx context.Context
 }
 
 // InitAndRegister — создаёт репозитории/сервисы/хендлеры и регистрирует роуты.
 // Здесь — вся инициализация, а регистрация чисто вызывает RegisterRoutes (routes.go).
 func InitAndRegister(deps *AppDeps, r *gin.Engine) {
+	ctx := deps.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// repos
 	userRepo := repository.NewUserRepo(deps.DB)
 	refreshRepo := repository.NewRefreshRepo(deps.DB)
 	orderRepo := repository.NewOrderRepo(deps.DB)
 	bidRepo := repository.NewBidRepo(deps.DB)
 	paymentRepo := repository.NewPaymentRepo(deps.DB)
+	otpRepo := repository.NewOTPRepo(deps.DB)
+	loginFailureRepo := repository.NewLoginFailureRepo(deps.DB)
+	auditRepo := repository.NewAuditRepo(deps.DB)
+	txRunner := repository.NewTxRunner(deps.DB)
+	identityRepo := repository.NewIdentityRepo(deps.DB)
+	statsRepo := repository.NewStatsRepo(deps.DB)
+	chatRepo := repository.NewChatRepo(deps.DB)
+	eventRepo := repository.NewEventRepo(deps.DB)
+	notificationRepo := repository.NewNotificationRepo(deps.DB)
+	fileRepo := repository.NewFileRepo(deps.DB)
+	deliverableRepo := repository.NewDeliverableRepo(deps.DB)
+	disputeRepo := repository.NewDisputeRepo(deps.DB)
+	reviewRepo := repository.NewReviewRepo(deps.DB)
+	executorRepo := repository.NewExecutorRepo(deps.DB)
+	invitationRepo := repository.NewInvitationRepo(deps.DB)
+	matchRepo := repository.NewMatchRepo(deps.DB)
+	savedSearchRepo := repository.NewSavedSearchRepo(deps.DB)
+	taxonomyRepo := repository.NewTaxonomyRepo(deps.DB)
+	subscriptionRepo := repository.NewSubscriptionRepo(deps.DB)
+	mentoringRepo := repository.NewMentoringRepo(deps.DB)
+	documentRepo := repository.NewDocumentRepo(deps.DB)
+	esfRepo := repository.NewESFRepo(deps.DB)
+	taxRepo := repository.NewTaxRepo(deps.DB)
+
+	jwtCfg := auth.JWTConfig{
+		Secret:           deps.Cfg.JWTSecret,
+		Issuer:           deps.Cfg.JWTIssuer,
+		Audience:         deps.Cfg.JWTAudience,
+		AccessTTLMinutes: deps.Cfg.JTTTLMin,
+		RefreshTTLDays:   deps.Cfg.RefreshTTLDays,
+	}
+	tokenState := services.NewTokenStateCache(userRepo, time.Duration(deps.Cfg.AuthCacheTTLSec)*time.Second)
+
+	// real-time: LISTEN/NOTIFY -> hub -> SSE подписчики
+	hub := realtime.NewHub()
+	go realtime.NewListener(deps.DB, eventRepo, hub).Run(ctx)
+	go func() {
+		<-ctx.Done()
+		hub.Close()
+	}()
+
+	// file storage
+	blobStore, localStore := blobStore(deps.Cfg)
 
 	// usecases / services
-	userUC := services.NewUserUsecase(userRepo, refreshRepo, deps.Cfg.JWTSecret, deps.Cfg.JTTTLMin, deps.Cfg.RefreshTTLDays)
-	orderSvc := services.NewOrderService(orderRepo, paymentRepo)
-	bidSvc := services.NewBidService(bidRepo, paymentRepo)
+	eventSvc := services.NewEventService(eventRepo)
+	smsSender := sms.NewLogSender(deps.Cfg.SMSLogFile)
+	notificationSvc := services.NewNotificationService(notificationRepo, userRepo, emailSender(deps.Cfg), smsSender)
+	eventSvc.Subscribe(notificationSvc.HandleEvent)
+	fileSvc := services.NewFileService(fileRepo, blobStore, orderRepo, bidRepo, chatRepo, userRepo, deliverableRepo, disputeRepo, invitationRepo, int64(deps.Cfg.FileMaxSizeMB)<<20)
+	go services.NewFileScanService(fileRepo, blobStore, fileScanner(deps.Cfg), auditRepo, eventSvc, fileSvc).Run(ctx)
+	userUC := services.NewUserUsecase(userRepo, refreshRepo, loginFailureRepo, auditRepo, jwtCfg, tokenState, txRunner)
+	otpSvc := services.NewOTPService(otpRepo, userUC, smsSender, deps.Cfg.JWTSecret, time.Duration(deps.Cfg.OTPTTLSec)*time.Second)
+	oidcSvc := services.NewOIDCService(identityRepo, userUC, oidcProviders(deps.Cfg))
+	taxonomySvc := services.NewTaxonomyService(taxonomyRepo, auditRepo)
+	taxSvc := services.NewTaxService(taxRepo, documentRepo, orderRepo, bidRepo, auditRepo, deps.Cfg.PlatformVAT)
+	orderSvc := services.NewOrderService(orderRepo, paymentRepo, bidRepo, chatRepo, deliverableRepo, invitationRepo, eventSvc, fileSvc, taxonomySvc, taxSvc, txRunner,
+		time.Duration(deps.Cfg.AutoAcceptDays)*24*time.Hour)
+	go orderSvc.RunAutoAccept(ctx)
+	adminSvc := services.NewAdminService(userUC, userRepo, orderRepo, bidRepo, paymentRepo, chatRepo, auditRepo, eventSvc, txRunner, jwtCfg)
+	statsSvc := services.NewStatsService(statsRepo)
+	chatSvc := services.NewChatService(chatRepo, orderRepo, bidRepo, auditRepo, eventSvc, fileSvc)
+	subscriptionSvc := services.NewSubscriptionService(subscriptionRepo, paymentRepo, userRepo, eventSvc, taxSvc, txRunner,
+		time.Duration(deps.Cfg.GraceDays)*24*time.Hour)
+	go subscriptionSvc.RunRenewals(ctx)
+	bidSvc := services.NewBidService(bidRepo, paymentRepo, orderRepo, eventSvc, fileSvc, reviewRepo, invitationRepo, subscriptionSvc, taxSvc, txRunner)
+	disputeSvc := services.NewDisputeService(disputeRepo, orderRepo, bidRepo, paymentRepo, chatRepo, userRepo, auditRepo, eventSvc, fileSvc, txRunner,
+		time.Duration(deps.Cfg.AssignSLAHours)*time.Hour, time.Duration(deps.Cfg.ResolveSLAHours)*time.Hour)
+	go disputeSvc.RunSLA(ctx)
+	reviewSvc := services.NewReviewService(reviewRepo, orderRepo, bidRepo, mentoringRepo, auditRepo, eventSvc,
+		time.Duration(deps.Cfg.ReviewDays)*24*time.Hour, time.Duration(deps.Cfg.ReviewEditHours)*time.Hour)
+	go reviewSvc.RunPublisher(ctx)
+	executorSvc := services.NewExecutorService(executorRepo, userRepo, reviewRepo, auditRepo, eventSvc, fileSvc, taxonomySvc, subscriptionSvc)
+	invitationSvc := services.NewInvitationService(invitationRepo, orderRepo, userRepo, eventSvc, deps.Cfg.InviteDiscount)
+	matchSvc := services.NewMatchService(matchRepo, orderRepo, executorRepo, eventSvc, time.Duration(deps.Cfg.DigestHours)*time.Hour)
+	orderSvc.OnPublished(matchSvc.OrderPublished)
+	executorSvc.OnProfileSaved(matchSvc.ProfileSaved)
+	go matchSvc.RunDigest(ctx)
+	go matchSvc.RunRescans(ctx)
+	savedSearchSvc := services.NewSavedSearchService(savedSearchRepo, orderRepo, notificationSvc, eventSvc, taxonomySvc)
+	orderSvc.OnPublished(savedSearchSvc.OrderPublished)
+	go savedSearchSvc.RunDigest(ctx)
+	mentoringSvc := services.NewMentoringService(mentoringRepo, paymentRepo, userRepo, reviewRepo, eventSvc, taxonomySvc, taxSvc, txRunner,
+		time.Duration(deps.Cfg.CancelHours)*time.Hour)
+	go mentoringSvc.RunSessions(ctx)
+	platform := models.Requisites{
+		Name: deps.Cfg.PlatformName, BIN: deps.Cfg.PlatformBIN, Address: deps.Cfg.PlatformAddress,
+		IBAN: deps.Cfg.PlatformIBAN, Bank: deps.Cfg.PlatformBank, BIK: deps.Cfg.PlatformBIK, Kbe: deps.Cfg.PlatformKbe,
+	}
+	documentSvc := services.NewDocumentService(documentRepo, paymentRepo, orderRepo, bidRepo, userRepo, blobStore, pdfFont(deps.Cfg),
+		platform, taxSvc)
+	esfSvc := services.NewESFService(esfRepo, documentRepo, userRepo, auditRepo, blobStore, platform)
+	orderSvc.OnCompleted(documentSvc.OrderCompleted)
+	disputeSvc.OnCompleted(documentSvc.OrderCompleted)
 
 	// handlers (готовые для передачи в routes.go)
 	userHandler := httpHandlers.NewUserHandler(userUC)
+	otpHandler := httpHandlers.NewOTPHandler(otpSvc)
+	oidcHandler := httpHandlers.NewOIDCHandler(oidcSvc)
+	adminHandler := httpHandlers.NewAdminHandler(adminSvc)
+	statsHandler := httpHandlers.NewStatsHandler(statsSvc)
+	chatHandler := httpHandlers.NewChatHandler(chatSvc)
 	orderHandler := httpHandlers.NewOrderHandler(orderSvc)
 	bidHandler := httpHandlers.NewBidHandler(bidSvc)
+	eventHandler := httpHandlers.NewEventHandler(eventSvc, hub)
+	notificationHandler := httpHandlers.NewNotificationHandler(notificationSvc)
+	fileHandler := httpHandlers.NewFileHandler(fileSvc, localStore)
+	disputeHandler := httpHandlers.NewDisputeHandler(disputeSvc)
+	reviewHandler := httpHandlers.NewReviewHandler(reviewSvc)
+	executorHandler := httpHandlers.NewExecutorHandler(executorSvc)
+	invitationHandler := httpHandlers.NewInvitationHandler(invitationSvc)
+	matchHandler := httpHandlers.NewMatchHandler(matchSvc)
+	savedSearchHandler := httpHandlers.NewSavedSearchHandler(savedSearchSvc)
+	taxonomyHandler := httpHandlers.NewTaxonomyHandler(taxonomySvc)
+	subscriptionHandler := httpHandlers.NewSubscriptionHandler(subscriptionSvc)
+	mentoringHandler := httpHandlers.NewMentoringHandler(mentoringSvc)
+	documentHandler := httpHandlers.NewDocumentHandler(documentSvc)
+	esfHandler := httpHandlers.NewESFHandler(esfSvc)
+	taxHandler := httpHandlers.NewTaxHandler(taxSvc)
 
 	// middleware
-	authMw := middleware.AuthMiddleware(deps.Cfg.JWTSecret)
+	authMw := middleware.AuthMiddleware(jwtCfg, tokenState)
 
 	// собираем RouteDeps и регистрируем маршруты
 	routeDeps := &RouteDeps{
-		UserHandler:  userHandler,
-		OrderHandler: orderHandler,
-		BidHandler:   bidHandler,
-		AuthMW:       authMw,
+		UserHandler:         userHandler,
+		OTPHandler:          otpHandler,
+		OIDCHandler:         oidcHandler,
+		AdminHandler:        adminHandler,
+		StatsHandler:        statsHandler,
+		ChatHandler:         chatHandler,
+		OrderHandler:        orderHandler,
+		BidHandler:          bidHandler,
+		EventHandler:        eventHandler,
+		NotificationHandler: notificationHandler,
+		FileHandler:         fileHandler,
+		DisputeHandler:      disputeHandler,
+		ReviewHandler:       reviewHandler,
+		ExecutorHandler:     executorHandler,
+		InvitationHandler:   invitationHandler,
+		MatchHandler:        matchHandler,
+		SavedSearchHandler:  savedSearchHandler,
+		TaxonomyHandler:     taxonomyHandler,
+		SubscriptionHandler: subscriptionHandler,
+		MentoringHandler:    mentoringHandler,
+		DocumentHandler:     documentHandler,
+		ESFHandler:          esfHandler,
+		TaxHandler:          taxHandler,
+		LocalBlobs:          localStore != nil,
+		AuthMW:              authMw,
 	}
 	RegisterRoutes(r, routeDeps)
 }
+
+// blobStore: S3/MinIO or local disk; the local store is also returned so its
+// signed URLs can be served by the API itself
+func blobStore(cfg *config.Config) (storage.BlobStore, *storage.LocalStore) {
+	if cfg.StorageBackend == "s3" {
+		s, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return s, nil
+	}
+	s, err := storage.NewLocalStore(cfg.StorageLocalDir, cfg.StorageBaseURL, cfg.JWTSecret)
+	if err != nil {
+		panic(err)
+	}
+	return s, s
+}
+
+// pdfFont: font for closing documents; без него сервер работает, но новые
+// счета и акты не выставляются (уже выставленные доступны)
+func pdfFont(cfg *config.Config) *pdf.Font {
+	f, err := pdf.LoadFont(cfg.PDFFontFile)
+	if err != nil {
+		log.Printf("PDF_FONT_FILE %s: %v; invoices and acts are disabled", cfg.PDFFontFile, err)
+		return nil
+	}
+	return f
+}
+
+// fileScanner: clamd in production, EICAR-only stub for development
+func fileScanner(cfg *config.Config) scanner.Scanner {
+	if cfg.Scanner == "stub" {
+		log.Println("SCANNER=stub: uploaded files are NOT virus-scanned (development only)")
+		return scanner.NewStubScanner()
+	}
+	return scanner.NewClamdScanner(cfg.ClamdAddr, 30*time.Second)
+}
+
+// emailSender: SMTP if configured, otherwise local log stand-in
+func emailSender(cfg *config.Config) email.EmailSender {
+	if cfg.SMTPHost == "" {
+		return email.NewLogSender(cfg.EmailLogFile)
+	}
+	return email.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom)
+}
+
+// oidcProviders builds configured external providers (+ in-process stub in dev,
+// only in binaries built with -tags oidcstub)
+func oidcProviders(cfg *config.Config) []*oidc.Provider {
+	var out []*oidc.Provider
+	for _, p := range cfg.OIDCProviders {
+		out = append(out, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+		}))
+	}
+	if cfg.OIDCStub {
+		if sp := stubProvider(cfg); sp != nil {
+			out = append(out, sp)
+		}
+	}
+	return out
+}
@@ -0,0 +1,16 @@
+//go:build !oidcstub
+
+package router
+
+import (
+	"log"
+
+	"github.com/BekzatS8/buhpro/pkg/config"
+	"github.com/BekzatS8/buhpro/pkg/oidc"
+)
+
+// stubProvider: в обычной сборке stub-issuer (httptest) не линкуется
+func stubProvider(*config.Config) *oidc.Provider {
+	log.Println("OIDC_STUB=true ignored: build with -tags oidcstub to enable the stub issuer")
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build oidcstub
+
+package router
+
+import (
+	"github.com/BekzatS8/buhpro/pkg/config"
+	"github.com/BekzatS8/buhpro/pkg/oidc"
+	"github.com/BekzatS8/buhpro/pkg/oidc/oidcstub"
+)
+
+// stubProvider — in-process issuer "stub" для локальной разработки
+func stubProvider(cfg *config.Config) *oidc.Provider {
+	is, err := oidcstub.NewIssuer("buhpro-stub")
+	if err != nil {
+		panic(err)
+	}
+	return oidc.NewProvider(oidc.Config{
+		Name:        "stub",
+		Issuer:      is.URL,
+		ClientID:    is.ClientID,
+		RedirectURL: "http://localhost" + cfg.AppAddr + "/api/v1/auth/oidc/stub/callback",
+	})
+}
@@ -1,14 +1,37 @@
 package router
 
 import (
+	"github.com/BekzatS8/buhpro/internal/middleware"
+	"github.com/BekzatS8/buhpro/internal/models"
 	httpHandlers "github.com/BekzatS8/buhpro/internal/transport/http"
 	"github.com/gin-gonic/gin"
 )
 
 type RouteDeps struct {
-	UserHandler  *httpHandlers.UserHandler
-	OrderHandler *httpHandlers.OrderHandler
-	BidHandler   *httpHandlers.BidHandler
+	UserHandler         *httpHandlers.UserHandler
+	OTPHandler          *httpHandlers.OTPHandler
+	OIDCHandler         *httpHandlers.OIDCHandler
+	AdminHandler        *httpHandlers.AdminHandler
+	StatsHandler        *httpHandlers.StatsHandler
+	ChatHandler         *httpHandlers.ChatHandler
+	OrderHandler        *httpHandlers.OrderHandler
+	BidHandler          *httpHandlers.BidHandler
+	EventHandler        *httpHandlers.EventHandler
+	NotificationHandler *httpHandlers.NotificationHandler
+	FileHandler         *httpHandlers.FileHandler
+	DisputeHandler      *httpHandlers.DisputeHandler
+	ReviewHandler       *httpHandlers.ReviewHandler
+	ExecutorHandler     *httpHandlers.ExecutorHandler
+	InvitationHandler   *httpHandlers.InvitationHandler
+	MatchHandler        *httpHandlers.MatchHandler
+	SavedSearchHandler  *httpHandlers.SavedSearchHandler
+	TaxonomyHandler     *httpHandlers.TaxonomyHandler
+	SubscriptionHandler *httpHandlers.SubscriptionHandler
+	MentoringHandler    *httpHandlers.MentoringHandler
+	DocumentHandler     *httpHandlers.DocumentHandler
+	ESFHandler          *httpHandlers.ESFHandler
+	TaxHandler          *httpHandlers.TaxHandler
+	LocalBlobs          bool // local storage backend: serve signed blob URLs
 
 	AuthMW gin.HandlerFunc
 }
@@ -21,6 +44,11 @@ func RegisterRoutes(r *gin.Engine, deps *RouteDeps) {
 		auth.POST("/register", deps.UserHandler.Register)
 		auth.POST("/login", deps.UserHandler.Login)
 		auth.POST("/refresh", deps.UserHandler.Refresh)
+		auth.POST("/otp/request", deps.OTPHandler.Request)
+		auth.POST("/otp/verify", deps.OTPHandler.Verify)
+		auth.GET("/oidc/providers", deps.OIDCHandler.Providers)
+		auth.GET("/oidc/:provider/start", deps.OIDCHandler.Start)
+		auth.GET("/oidc/:provider/callback", deps.OIDCHandler.Callback)
 
 		authProtected := auth.Group("")
 		authProtected.Use(deps.AuthMW)
@@ -33,32 +61,219 @@ func RegisterRoutes(r *gin.Engine, deps *RouteDeps) {
 	{
 		users.GET("/me", deps.UserHandler.Me)
 		users.PATCH("/me", deps.UserHandler.UpdateMe)
-		users.GET("count", deps.UserHandler.Count)
+		users.POST("/me/roles", deps.UserHandler.AddMyRole)
+		users.GET("/me/identities", deps.OIDCHandler.ListMine)
+		users.POST("/me/identities/:provider", deps.OIDCHandler.Link)
+		users.DELETE("/me/identities/:id", deps.OIDCHandler.Unlink)
+
+		users.GET("/me/executor-profile", deps.ExecutorHandler.MyProfile)
+		users.PUT("/me/executor-profile", deps.ExecutorHandler.SaveProfile)
+		users.GET("/me/certificates", deps.ExecutorHandler.MyCertificates)
+		users.POST("/me/certificates", deps.ExecutorHandler.AddCertificate)
+		users.DELETE("/me/certificates/:id", deps.ExecutorHandler.DeleteCertificate)
+		users.GET("/me/portfolio", deps.ExecutorHandler.MyPortfolio)
+		users.POST("/me/portfolio", deps.ExecutorHandler.AddPortfolio)
+		users.PUT("/me/portfolio/:id", deps.ExecutorHandler.UpdatePortfolio)
+		users.DELETE("/me/portfolio/:id", deps.ExecutorHandler.DeletePortfolio)
+		users.GET("/me/invitations", deps.InvitationHandler.Mine)
+		users.GET("/me/saved-searches", deps.SavedSearchHandler.List)
+		users.POST("/me/saved-searches", deps.SavedSearchHandler.Create)
+		users.PUT("/me/saved-searches/:id", deps.SavedSearchHandler.Update)
+		users.DELETE("/me/saved-searches/:id", deps.SavedSearchHandler.Delete)
+		users.GET("/me/subscription", deps.SubscriptionHandler.Mine)
+		users.POST("/me/subscription", deps.SubscriptionHandler.Subscribe)
+		users.POST("/me/subscription/pay", deps.SubscriptionHandler.Pay)
+		users.PATCH("/me/subscription", deps.SubscriptionHandler.Update)
+		users.GET("/me/coach-profile", deps.MentoringHandler.MyProfile)
+		users.PUT("/me/coach-profile", deps.MentoringHandler.SaveProfile)
+		users.GET("/me/coach-slots", deps.MentoringHandler.MySlots)
+		users.POST("/me/coach-slots", deps.MentoringHandler.AddSlot)
+		users.DELETE("/me/coach-slots/:id", deps.MentoringHandler.DeleteSlot)
+		users.GET("/me/sessions", deps.MentoringHandler.MySessions)
+	}
+	// публичный профиль: рейтинг и отзывы
+	api.GET("/users/:id/rating", deps.ReviewHandler.Rating)
+	api.GET("/users/:id/reviews", deps.ReviewHandler.ForUser)
+	api.GET("/executors", deps.ExecutorHandler.Search)
+	api.GET("/executors/:id", deps.ExecutorHandler.Public)
+	api.GET("/taxonomy", deps.TaxonomyHandler.Get)
+	api.GET("/tax/rates", deps.TaxHandler.Rates)
+	api.GET("/tax/quote", deps.TaxHandler.Quote)
+	api.GET("/subscription-plans", deps.SubscriptionHandler.Plans)
+	api.GET("/coaches", deps.MentoringHandler.Search)
+	api.GET("/coaches/:id", deps.MentoringHandler.Public)
+	admin := api.Group("/admin")
+	admin.Use(deps.AuthMW, middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", deps.AdminHandler.ListUsers)
+		admin.GET("/users/:id", deps.AdminHandler.GetUser)
+		admin.POST("/users/:id/suspend", deps.AdminHandler.SuspendUser)
+		admin.POST("/users/:id/unsuspend", deps.AdminHandler.UnsuspendUser)
+		admin.DELETE("/users/:id", deps.AdminHandler.DeleteUser)
+		admin.POST("/users/:id/impersonate", deps.AdminHandler.Impersonate)
+		admin.POST("/users/:id/unlock", deps.UserHandler.Unlock)
+		admin.PUT("/users/:id/roles", deps.UserHandler.SetRoles)
+
+		admin.POST("/orders/:id/cancel", deps.AdminHandler.CancelOrder)
+		admin.POST("/orders/:id/archive", deps.AdminHandler.ArchiveOrder)
+
+		admin.GET("/orders/:id/conversations", deps.ChatHandler.AdminListByOrder)
+		admin.GET("/conversations/:id/messages", deps.ChatHandler.AdminMessages)
+
+		admin.POST("/bids/:id/hide", deps.AdminHandler.HideBid)
+
+		admin.GET("/users/count", deps.UserHandler.Count)
+		admin.GET("/stats/orders", deps.StatsHandler.Orders)
+		admin.GET("/stats/funnel", deps.StatsHandler.Funnel)
+		admin.GET("/stats/bids", deps.StatsHandler.Bids)
+		admin.GET("/stats/revenue", deps.StatsHandler.Revenue)
+		admin.GET("/stats/executors", deps.StatsHandler.ActiveExecutors)
+
+		admin.GET("/payments", deps.AdminHandler.ListPayments)
+		admin.GET("/payments/:id", deps.AdminHandler.GetPayment)
+		admin.PATCH("/payments/:id/status", deps.AdminHandler.SetPaymentStatus)
+
+		admin.POST("/esf/exports", deps.ESFHandler.Export)
+		admin.GET("/esf/exports", deps.ESFHandler.List)
+		admin.GET("/esf/exports/:id", deps.ESFHandler.Get)
+
+		admin.POST("/tax/rates", deps.TaxHandler.AddRate)
+		admin.DELETE("/tax/rates/:code/:from", deps.TaxHandler.DeleteRate)
+
+		admin.GET("/disputes", deps.DisputeHandler.AdminList)
+		admin.POST("/disputes/:id/assign", deps.DisputeHandler.Assign)
+		admin.POST("/disputes/:id/resolve", deps.DisputeHandler.Resolve)
+
+		admin.GET("/review-reports", deps.ReviewHandler.Reports)
+		admin.POST("/review-reports/:id/moderate", deps.ReviewHandler.Moderate)
+
+		admin.GET("/certificates", deps.ExecutorHandler.Certificates)
+		admin.POST("/certificates/:id/review", deps.ExecutorHandler.ReviewCertificate)
+
+		admin.GET("/taxonomy", deps.TaxonomyHandler.AdminGet)
+		admin.GET("/taxonomy/unmapped", deps.TaxonomyHandler.Unmapped)
+		admin.POST("/taxonomy/categories", deps.TaxonomyHandler.CreateCategory)
+		admin.PUT("/taxonomy/categories/:code", deps.TaxonomyHandler.UpdateCategory)
+		admin.DELETE("/taxonomy/categories/:code", deps.TaxonomyHandler.DeleteCategory)
+		admin.POST("/taxonomy/regions", deps.TaxonomyHandler.CreateRegion)
+		admin.PUT("/taxonomy/regions/:kato", deps.TaxonomyHandler.UpdateRegion)
+		admin.DELETE("/taxonomy/regions/:kato", deps.TaxonomyHandler.DeleteRegion)
 	}
 	orders := api.Group("/orders")
 	{
 		orders.GET("", deps.OrderHandler.List)
-		orders.GET("/:id", deps.OrderHandler.GetByID)
+		orders.GET("/:id", middleware.Optional(deps.AuthMW), deps.OrderHandler.GetByID)
 
 		orderAuth := orders.Group("")
 		orderAuth.Use(deps.AuthMW)
 		{
-			orderAuth.POST("", deps.OrderHandler.Create)
+			orderAuth.POST("", middleware.RequireRole(models.RoleClient), deps.OrderHandler.Create)
+			orderAuth.GET("/recommended", middleware.RequireRole(models.RoleExecutor), deps.MatchHandler.Recommended)
 			orderAuth.PATCH("/:id", deps.OrderHandler.Update)
 			orderAuth.DELETE("/:id", deps.OrderHandler.Delete)
 
 			orderAuth.POST("/:id/publish", deps.OrderHandler.Publish)
+			orderAuth.POST("/:id/pay", deps.OrderHandler.Pay)
 			orderAuth.POST("/:id/select-executor", deps.OrderHandler.SelectExecutor)
 			orderAuth.POST("/:id/start", deps.OrderHandler.Start)
 			orderAuth.POST("/:id/complete", deps.OrderHandler.Complete)
+			orderAuth.POST("/:id/accept", deps.OrderHandler.Accept)
+			orderAuth.POST("/:id/request-changes", deps.OrderHandler.RequestChanges)
+			orderAuth.GET("/:id/deliverables", deps.OrderHandler.Deliverables)
 			orderAuth.POST("/:id/cancel", deps.OrderHandler.Cancel)
 			orderAuth.GET("/:id/history", deps.OrderHandler.History)
+			orderAuth.POST("/:id/conversations", deps.ChatHandler.Open)
+			orderAuth.POST("/:id/disputes", deps.DisputeHandler.Open)
+			orderAuth.GET("/:id/disputes", deps.DisputeHandler.ListByOrder)
+			orderAuth.POST("/:id/reviews", deps.ReviewHandler.Create)
+			orderAuth.GET("/:id/reviews", deps.ReviewHandler.ListByOrder)
+			orderAuth.POST("/:id/bids", middleware.RequireRole(models.RoleExecutor), deps.BidHandler.CreateBid)
+			orderAuth.GET("/:id/bids", deps.BidHandler.ListByOrder)
+			orderAuth.POST("/:id/invitations", middleware.RequireRole(models.RoleClient), deps.InvitationHandler.Invite)
+			orderAuth.GET("/:id/invitations", deps.InvitationHandler.ListByOrder)
+			orderAuth.GET("/:id/documents", deps.DocumentHandler.ListByOrder)
+			orderAuth.GET("/:id/act", deps.DocumentHandler.Act)
+			orderAuth.GET("/:id/quote", deps.TaxHandler.OrderQuote)
 		}
 	}
+	conversations := api.Group("/conversations")
+	conversations.Use(deps.AuthMW)
+	{
+		conversations.GET("", deps.ChatHandler.ListMine)
+		conversations.GET("/:id/messages", deps.ChatHandler.Messages)
+		conversations.POST("/:id/messages", deps.ChatHandler.Send)
+		conversations.POST("/:id/read", deps.ChatHandler.MarkRead)
+	}
+	disputes := api.Group("/disputes")
+	disputes.Use(deps.AuthMW)
+	{
+		disputes.GET("/:id", deps.DisputeHandler.Get)
+		disputes.GET("/:id/messages", deps.DisputeHandler.Messages)
+		disputes.POST("/:id/messages", deps.DisputeHandler.AddMessage)
+	}
+	payments := api.Group("/payments")
+	payments.Use(deps.AuthMW)
+	{
+		payments.GET("/:id/invoice", deps.DocumentHandler.Invoice)
+		payments.GET("/:id/receipt", deps.DocumentHandler.Receipt)
+	}
+	sessions := api.Group("/sessions")
+	sessions.Use(deps.AuthMW)
+	{
+		sessions.POST("", deps.MentoringHandler.Book)
+		sessions.GET("/:id", deps.MentoringHandler.Get)
+		sessions.POST("/:id/pay", deps.MentoringHandler.Pay)
+		sessions.POST("/:id/cancel", deps.MentoringHandler.Cancel)
+		sessions.POST("/:id/reschedule", deps.MentoringHandler.Reschedule)
+		sessions.POST("/:id/reviews", deps.ReviewHandler.CreateForSession)
+		sessions.GET("/:id/reviews", deps.ReviewHandler.ListBySession)
+	}
+	reviews := api.Group("/reviews")
+	reviews.Use(deps.AuthMW)
+	{
+		reviews.PATCH("/:id", deps.ReviewHandler.Update)
+		reviews.POST("/:id/report", deps.ReviewHandler.Report)
+	}
+	invitations := api.Group("/invitations")
+	invitations.Use(deps.AuthMW)
+	{
+		invitations.POST("/:id/accept", deps.InvitationHandler.Accept)
+		invitations.POST("/:id/decline", deps.InvitationHandler.Decline)
+		invitations.POST("/:id/revoke", deps.InvitationHandler.Revoke)
+	}
+	// SSE: EventSource не умеет заголовки, токен можно передать в ?access_token=
+	api.GET("/events", middleware.TokenFromQuery(), deps.AuthMW, deps.EventHandler.Stream)
+
+	notifications := api.Group("/notifications")
+	notifications.Use(deps.AuthMW)
+	{
+		notifications.GET("", deps.NotificationHandler.List)
+		notifications.GET("/unread-count", deps.NotificationHandler.UnreadCount)
+		notifications.POST("/:id/read", deps.NotificationHandler.MarkRead)
+		notifications.POST("/read-all", deps.NotificationHandler.MarkAllRead)
+		notifications.GET("/preferences", deps.NotificationHandler.GetPreferences)
+		notifications.PUT("/preferences", deps.NotificationHandler.SavePreferences)
+	}
+
+	files := api.Group("/files")
+	files.Use(deps.AuthMW)
+	{
+		files.POST("", deps.FileHandler.CreateUpload)
+		files.GET("/:id", deps.FileHandler.Get)
+		files.POST("/:id/complete", deps.FileHandler.Complete)
+		files.GET("/:id/download", deps.FileHandler.Download)
+		files.DELETE("/:id", deps.FileHandler.Delete)
+	}
+	if deps.LocalBlobs {
+		// без AuthMW: доступ по подписи в URL
+		api.PUT("/blobs/*key", deps.FileHandler.LocalPut)
+		api.GET("/blobs/*key", deps.FileHandler.LocalGet)
+	}
+
 	orderBids := api.Group("/bids")
 	orderBids.Use(deps.AuthMW)
 	{
-		orderBids.POST("", deps.BidHandler.CreateBid)
+		orderBids.POST("", middleware.RequireRole(models.RoleExecutor), deps.BidHandler.CreateBid)
 		orderBids.GET("", deps.BidHandler.ListByOrder)
 	}
 	bids := api.Group("/bids")
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- access token revocation: JWT "ver" claim must match users.token_version
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INT NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- one-time SMS codes (only HMAC of the code is stored)
+CREATE TABLE IF NOT EXISTS otp_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    phone VARCHAR(32) NOT NULL,
+    code_hash TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    request_ip VARCHAR(64),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_otp_phone_created ON otp_codes (phone, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_otp_ip_created ON otp_codes (request_ip, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- brute-force protection: per-account counter + temporary lock
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_count INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;
+
+-- per-IP failed login attempts (sliding window)
+CREATE TABLE IF NOT EXISTS login_failures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(320),
+    ip VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_ip_created ON login_failures (ip, created_at DESC);
+
+COMMIT;
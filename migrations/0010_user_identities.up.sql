@@ -0,0 +1,28 @@
+BEGIN;
+
+-- external logins (OIDC); one BuhPro user may have several
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL, -- "sub" claim
+    email VARCHAR(320),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMP WITH TIME ZONE
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_user_identities_provider_subject ON user_identities (provider, subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities (user_id);
+
+-- pending authorization requests (state -> PKCE verifier, nonce)
+CREATE TABLE IF NOT EXISTS oidc_states (
+    state VARCHAR(128) PRIMARY KEY,
+    provider VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    link_user_id UUID NULL REFERENCES users(id) ON DELETE CASCADE, -- set when linking from profile
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+-- fixed role set; users.role stays the primary role, users.roles holds all of them
+UPDATE users SET role = 'executor' WHERE role NOT IN ('client','executor','coach','admin');
+
+-- до этой миграции роль при регистрации выбирал сам пользователь, так что любой
+-- 'admin' — самоназначенный. Понижаем до client, отзываем токены (token_version)
+-- и оставляем след в audit_logs; настоящим администраторам роль выдаётся заново:
+--   UPDATE users SET role='admin', roles=ARRAY['admin'], token_version=token_version+1 WHERE email='...';
+-- Только при первом применении (колонки roles ещё нет), чтобы повторный прогон
+-- не снял роль с уже восстановленных администраторов.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'users' AND column_name = 'roles') THEN
+        INSERT INTO audit_logs (actor_id, action, object_type, object_id, payload)
+        SELECT NULL, 'migration.admin_demoted', 'user', id, jsonb_build_object('email', email, 'reason', 'self-registered admin')
+        FROM users WHERE role = 'admin';
+        UPDATE users SET role = 'client', token_version = token_version + 1 WHERE role = 'admin';
+    END IF;
+END $$;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}';
+UPDATE users SET roles = ARRAY[role] WHERE cardinality(roles) = 0;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('client','executor','coach','admin'));
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_roles;
+ALTER TABLE users ADD CONSTRAINT chk_users_roles CHECK (roles <@ ARRAY['client','executor','coach','admin']::text[]);
+
+CREATE INDEX IF NOT EXISTS idx_users_roles ON users USING GIN (roles);
+
+COMMIT;
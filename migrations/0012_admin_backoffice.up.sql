@@ -0,0 +1,9 @@
+BEGIN;
+
+-- bids hidden by moderation are never shown to the client
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_audit_object ON audit_logs (object_type, object_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payments_created ON payments (created_at DESC);
+
+COMMIT;
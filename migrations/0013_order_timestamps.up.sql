@@ -0,0 +1,25 @@
+BEGIN;
+
+-- lifecycle timestamps used by analytics
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS executor_selected_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP WITH TIME ZONE;
+
+-- backfill: время из истории заказа (audit_logs), иначе — последнее изменение
+-- заказа; без этого старые заказы выпадают из аналитики по срокам
+UPDATE orders o SET executor_selected_at = COALESCE(
+        (SELECT min(a.created_at) FROM audit_logs a
+          WHERE a.object_type = 'order' AND a.object_id = o.id AND a.action = 'select_executor'),
+        o.updated_at)
+WHERE o.executor_selected_at IS NULL AND o.chosen_bid_id IS NOT NULL;
+
+UPDATE orders o SET completed_at = COALESCE(
+        (SELECT max(a.created_at) FROM audit_logs a
+          WHERE a.object_type = 'order' AND a.object_id = o.id AND a.action = 'complete_order'),
+        o.updated_at)
+WHERE o.completed_at IS NULL AND o.status = 'completed';
+
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders (created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_completed_at ON orders (completed_at);
+CREATE INDEX IF NOT EXISTS idx_bids_order_created ON bids (order_id, created_at);
+
+COMMIT;
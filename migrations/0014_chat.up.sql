@@ -0,0 +1,32 @@
+BEGIN;
+
+-- one conversation per (order, executor); unlocked by a visible bid
+CREATE TABLE IF NOT EXISTS conversations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES users(id),
+    executor_id UUID NOT NULL REFERENCES users(id),
+    bid_id UUID NOT NULL REFERENCES bids(id) ON DELETE CASCADE,
+    client_last_read_at TIMESTAMP WITH TIME ZONE,
+    executor_last_read_at TIMESTAMP WITH TIME ZONE,
+    last_message_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE, -- order completed/cancelled: read-only
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_conversations_order_executor ON conversations (order_id, executor_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_client ON conversations (client_id, last_message_at DESC);
+CREATE INDEX IF NOT EXISTS idx_conversations_executor ON conversations (executor_id, last_message_at DESC);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL DEFAULT '',
+    attachments JSONB DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_created ON messages (conversation_id, created_at DESC);
+
+COMMIT;
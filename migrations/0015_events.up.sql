@@ -0,0 +1,15 @@
+BEGIN;
+
+-- per-recipient real-time events; BIGSERIAL id doubles as SSE Last-Event-ID
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_events_created ON events (created_at);
+
+COMMIT;
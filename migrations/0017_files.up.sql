@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS files (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL CHECK (size > 0),
+    mime_type VARCHAR(128) NOT NULL,
+    checksum_sha256 VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending', -- pending|uploaded
+    linked_type VARCHAR(32) CHECK (linked_type IN ('order','bid','message','organization')),
+    linked_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    uploaded_at TIMESTAMP WITH TIME ZONE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_files_owner ON files (owner_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_files_linked ON files (linked_type, linked_id);
+
+-- attachments теперь — массив id файлов; прежний произвольный JSON сохраняем рядом
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS legacy_attachments JSONB;
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS legacy_attachments JSONB;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS legacy_attachments JSONB;
+
+UPDATE orders SET legacy_attachments = attachments, attachments = '[]'::jsonb
+WHERE attachments IS NOT NULL AND attachments <> '[]'::jsonb;
+UPDATE bids SET legacy_attachments = attachments, attachments = '[]'::jsonb
+WHERE attachments IS NOT NULL AND attachments <> '[]'::jsonb;
+UPDATE messages SET legacy_attachments = attachments, attachments = '[]'::jsonb
+WHERE attachments IS NOT NULL AND attachments <> '[]'::jsonb;
+
+UPDATE orders SET attachments = '[]'::jsonb WHERE attachments IS NULL;
+UPDATE bids SET attachments = '[]'::jsonb WHERE attachments IS NULL;
+UPDATE messages SET attachments = '[]'::jsonb WHERE attachments IS NULL;
+
+COMMIT;
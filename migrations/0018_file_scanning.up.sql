@@ -0,0 +1,14 @@
+BEGIN;
+
+-- pending -> (upload) -> pending_scan -> clean | quarantined | rejected
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE files ADD COLUMN IF NOT EXISTS scan_result TEXT;
+
+-- файлы, загруженные до появления проверки, тоже проходят её
+UPDATE files SET status = 'pending_scan' WHERE status = 'uploaded';
+
+CREATE INDEX IF NOT EXISTS idx_files_pending_scan ON files (uploaded_at) WHERE status = 'pending_scan';
+
+COMMIT;
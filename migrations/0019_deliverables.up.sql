@@ -0,0 +1,27 @@
+BEGIN;
+
+-- каждая сдача работы исполнителем — новая версия; клиент принимает или возвращает на доработку
+CREATE TABLE IF NOT EXISTS deliverables (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    executor_id UUID NOT NULL REFERENCES users(id),
+    version INT NOT NULL,
+    summary TEXT NOT NULL,
+    files JSONB NOT NULL DEFAULT '[]'::jsonb,
+    status VARCHAR(32) NOT NULL DEFAULT 'submitted'
+        CHECK (status IN ('submitted','accepted','changes_requested')),
+    review_comment TEXT,
+    reviewed_by UUID REFERENCES users(id),
+    auto_accepted BOOLEAN NOT NULL DEFAULT false,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (order_id, version)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_deliverables_pending ON deliverables (submitted_at) WHERE status = 'submitted';
+
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_linked_type_check;
+ALTER TABLE files ADD CONSTRAINT files_linked_type_check
+    CHECK (linked_type IN ('order','bid','message','organization','deliverable'));
+
+COMMIT;
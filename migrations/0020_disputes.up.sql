@@ -0,0 +1,45 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS disputes (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    opened_by UUID NOT NULL REFERENCES users(id),
+    client_id UUID NOT NULL REFERENCES users(id),
+    executor_id UUID NOT NULL REFERENCES users(id),
+    prior_status VARCHAR(32) NOT NULL, -- статус заказа до спора
+    reason TEXT NOT NULL,
+    evidence JSONB NOT NULL DEFAULT '[]'::jsonb, -- file ids
+    status VARCHAR(32) NOT NULL DEFAULT 'open' CHECK (status IN ('open','in_review','resolved')),
+    arbiter_id UUID REFERENCES users(id),
+    resolution VARCHAR(32) CHECK (resolution IN ('refund','split','release')),
+    executor_amount BIGINT,
+    client_amount BIGINT,
+    resolution_note TEXT,
+    assign_due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    resolve_due_at TIMESTAMP WITH TIME ZONE,
+    sla_breached_at TIMESTAMP WITH TIME ZONE,
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    assigned_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE
+    );
+
+-- не больше одного активного спора на заказ
+CREATE UNIQUE INDEX IF NOT EXISTS uq_disputes_active_order ON disputes (order_id) WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes (status, opened_at);
+
+CREATE TABLE IF NOT EXISTS dispute_messages (
+    id UUID PRIMARY KEY,
+    dispute_id UUID NOT NULL REFERENCES disputes(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL DEFAULT '',
+    attachments JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_dispute_messages ON dispute_messages (dispute_id, created_at);
+
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_linked_type_check;
+ALTER TABLE files ADD CONSTRAINT files_linked_type_check
+    CHECK (linked_type IN ('order','bid','message','organization','deliverable','dispute','dispute_message'));
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+-- взаимные отзывы по завершённому заказу; скрыты, пока обе стороны не оставят
+-- отзыв или не истечёт срок (published_at)
+CREATE TABLE IF NOT EXISTS reviews (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id),
+    target_id UUID NOT NULL REFERENCES users(id),
+    author_role VARCHAR(32) NOT NULL CHECK (author_role IN ('client','executor')),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    quality SMALLINT NOT NULL CHECK (quality BETWEEN 1 AND 5),
+    timeliness SMALLINT NOT NULL CHECK (timeliness BETWEEN 1 AND 5),
+    communication SMALLINT NOT NULL CHECK (communication BETWEEN 1 AND 5),
+    text TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'published' CHECK (status IN ('published','hidden')),
+    editable_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (order_id, author_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_reviews_target ON reviews (target_id, author_role, published_at DESC);
+
+CREATE TABLE IF NOT EXISTS review_reports (
+    id UUID PRIMARY KEY,
+    review_id UUID NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'open' CHECK (status IN ('open','upheld','dismissed')),
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (review_id, reporter_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_review_reports_status ON review_reports (status, created_at);
+
+COMMIT;
@@ -0,0 +1,53 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS executor_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    bio TEXT NOT NULL DEFAULT '',
+    specializations TEXT[] NOT NULL DEFAULT '{}', -- значения orders.category
+    regions TEXT[] NOT NULL DEFAULT '{}',
+    software TEXT[] NOT NULL DEFAULT '{}',
+    experience_years SMALLINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_executor_profiles_spec ON executor_profiles USING GIN (specializations);
+CREATE INDEX IF NOT EXISTS idx_executor_profiles_regions ON executor_profiles USING GIN (regions);
+
+CREATE TABLE IF NOT EXISTS executor_certificates (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('CAP','CIPA','DipIFR','ACCA','other')),
+    title TEXT NOT NULL DEFAULT '',
+    number VARCHAR(128) NOT NULL DEFAULT '',
+    issued_at DATE,
+    file_id UUID REFERENCES files(id) ON DELETE SET NULL, -- скан сертификата
+    status VARCHAR(32) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','verified','rejected')),
+    reject_reason TEXT,
+    verified_by UUID REFERENCES users(id),
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_executor_certificates_user ON executor_certificates (user_id);
+CREATE INDEX IF NOT EXISTS idx_executor_certificates_status ON executor_certificates (status, created_at);
+
+CREATE TABLE IF NOT EXISTS executor_portfolio (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(128) NOT NULL DEFAULT '',
+    year SMALLINT,
+    files JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_executor_portfolio_user ON executor_portfolio (user_id, created_at DESC);
+
+ALTER TABLE files DROP CONSTRAINT IF EXISTS files_linked_type_check;
+ALTER TABLE files ADD CONSTRAINT files_linked_type_check
+    CHECK (linked_type IN ('order','bid','message','organization','deliverable','dispute','dispute_message','certificate','portfolio'));
+
+COMMIT;
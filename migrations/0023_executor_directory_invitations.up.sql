@@ -0,0 +1,28 @@
+BEGIN;
+
+-- каталог исполнителей: ставка "от" и готовность брать новые заказы
+ALTER TABLE executor_profiles
+    ADD COLUMN IF NOT EXISTS price_from BIGINT CHECK (price_from IS NULL OR price_from >= 0),
+    ADD COLUMN IF NOT EXISTS available BOOLEAN NOT NULL DEFAULT true;
+
+-- private — заказ не попадает в общую ленту, ставки только по приглашению
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public','private'));
+
+CREATE TABLE IF NOT EXISTS order_invitations (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    executor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL DEFAULT '',
+    fee_discount SMALLINT NOT NULL DEFAULT 100 CHECK (fee_discount BETWEEN 0 AND 100), -- % скидки на плату за ставку
+    status VARCHAR(32) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','declined','revoked','bid_placed')),
+    bid_id UUID REFERENCES bids(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    responded_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (order_id, executor_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_order_invitations_executor ON order_invitations(executor_id, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- формат работы исполнителя: online — только удалённо, offline — только выезд, any — любой
+ALTER TABLE executor_profiles
+    ADD COLUMN IF NOT EXISTS work_mode VARCHAR(16) NOT NULL DEFAULT 'any' CHECK (work_mode IN ('any','online','offline'));
+
+-- рекомендации: оценка опубликованного заказа для исполнителя с причинами
+CREATE TABLE IF NOT EXISTS order_matches (
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    executor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 0 AND 100),
+    reasons JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    notified_at TIMESTAMP WITH TIME ZONE, -- попал в дайджест
+    PRIMARY KEY (order_id, executor_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_order_matches_executor ON order_matches (executor_id, score DESC);
+CREATE INDEX IF NOT EXISTS idx_order_matches_digest ON order_matches (executor_id) WHERE notified_at IS NULL;
+
+COMMIT;
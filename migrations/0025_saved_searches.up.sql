@@ -0,0 +1,32 @@
+BEGIN;
+
+-- сохранённые поиски: фильтры те же, что у GET /orders (category, region, min_budget, max_budget)
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}'::jsonb,
+    channel VARCHAR(16) NOT NULL DEFAULT 'in_app' CHECK (channel IN ('in_app','email')),
+    frequency VARCHAR(16) NOT NULL DEFAULT 'instant' CHECK (frequency IN ('instant','daily')),
+    last_digest_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches (user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_category ON saved_searches ((filters->>'category'));
+
+-- один заказ — одно оповещение пользователю, даже если совпало несколько поисков;
+-- sent_at IS NULL — ждёт ежедневного дайджеста
+CREATE TABLE IF NOT EXISTS saved_search_alerts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (user_id, order_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_saved_search_alerts_pending ON saved_search_alerts (search_id) WHERE sent_at IS NULL;
+
+COMMIT;
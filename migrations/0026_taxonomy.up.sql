@@ -0,0 +1,186 @@
+BEGIN;
+
+-- справочник категорий: два уровня (категория -> подкатегория)
+CREATE TABLE IF NOT EXISTS categories (
+    code VARCHAR(64) PRIMARY KEY CHECK (code ~ '^[a-z0-9][a-z0-9_.]*$'),
+    parent_code VARCHAR(64) REFERENCES categories(code),
+    name_ru TEXT NOT NULL,
+    name_kk TEXT NOT NULL,
+    name_en TEXT NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}', -- написания, которые распознаются как эта категория
+    sort INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true, -- неактивные не принимаются в новых значениях
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories (parent_code);
+
+-- регионы по КАТО (9 цифр), иерархия любой глубины
+CREATE TABLE IF NOT EXISTS regions (
+    kato VARCHAR(9) PRIMARY KEY CHECK (kato ~ '^[0-9]{9}$'),
+    parent_kato VARCHAR(9) REFERENCES regions(kato),
+    name_ru TEXT NOT NULL,
+    name_kk TEXT NOT NULL,
+    name_en TEXT NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    sort INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_regions_parent ON regions (parent_kato);
+
+INSERT INTO categories (code, parent_code, name_ru, name_kk, name_en, aliases, sort) VALUES
+    ('accounting', NULL, 'Бухгалтерский учёт', 'Бухгалтерлік есеп', 'Accounting', '{бухучет,бухгалтерия}', 10),
+    ('reporting', NULL, 'Налоговая и статистическая отчётность', 'Салықтық және статистикалық есептілік', 'Tax and statistical reporting', '{отчетность,налоги,налоговая отчетность,tax}', 20),
+    ('payroll', NULL, 'Зарплата и кадры', 'Жалақы және кадрлар', 'Payroll and HR', '{зарплата,кадры,hr}', 30),
+    ('audit', NULL, 'Аудит', 'Аудит', 'Audit', '{}', 40),
+    ('consulting', NULL, 'Консультации', 'Кеңес беру', 'Consulting', '{консультация}', 50),
+    ('1c', NULL, '1С', '1С', '1C', '{1с}', 60),
+    ('registration', NULL, 'Регистрация и ликвидация бизнеса', 'Бизнесті тіркеу және тарату', 'Business registration and liquidation', '{регистрация,ликвидация}', 70)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO categories (code, parent_code, name_ru, name_kk, name_en, aliases, sort) VALUES
+    ('accounting.outsourcing', 'accounting', 'Ведение учёта на аутсорсе', 'Есепті аутсорсингте жүргізу', 'Outsourced bookkeeping', '{}', 10),
+    ('accounting.restoration', 'accounting', 'Восстановление учёта', 'Есепті қалпына келтіру', 'Accounting restoration', '{}', 20),
+    ('accounting.primary_docs', 'accounting', 'Первичная документация', 'Бастапқы құжаттама', 'Primary documents', '{первичка}', 30),
+    ('reporting.tax_forms', 'reporting', 'Налоговые формы (ФНО)', 'Салықтық нысандар (СЕН)', 'Tax returns', '{фно}', 10),
+    ('reporting.sole_proprietor', 'reporting', 'Отчётность ИП', 'ЖК есептілігі', 'Sole proprietor reporting', '{ип,910}', 20),
+    ('reporting.statistics', 'reporting', 'Статистическая отчётность', 'Статистикалық есептілік', 'Statistical reporting', '{статистика}', 30),
+    ('payroll.calculation', 'payroll', 'Расчёт зарплаты и налогов', 'Жалақы мен салықтарды есептеу', 'Payroll calculation', '{}', 10),
+    ('payroll.hr_records', 'payroll', 'Кадровое делопроизводство', 'Кадрлық іс жүргізу', 'HR records', '{кадровый учет}', 20),
+    ('audit.statutory', 'audit', 'Обязательный аудит', 'Міндетті аудит', 'Statutory audit', '{}', 10),
+    ('audit.tax', 'audit', 'Налоговый аудит', 'Салықтық аудит', 'Tax audit', '{}', 20),
+    ('consulting.tax', 'consulting', 'Налоговые консультации', 'Салықтық кеңес', 'Tax consulting', '{}', 10),
+    ('consulting.ifrs', 'consulting', 'МСФО', 'ХҚЕС', 'IFRS', '{мсфо,ifrs}', 20),
+    ('1c.setup', '1c', 'Внедрение и настройка 1С', '1С енгізу және баптау', '1C setup', '{}', 10),
+    ('1c.support', '1c', 'Сопровождение 1С', '1С сүйемелдеу', '1C support', '{}', 20)
+ON CONFLICT (code) DO NOTHING;
+
+-- города республиканского значения и области
+INSERT INTO regions (kato, name_ru, name_kk, name_en, aliases, sort) VALUES
+    ('710000000', 'Астана', 'Астана', 'Astana', '{нур-султан,nur-sultan,г. астана}', 10),
+    ('750000000', 'Алматы', 'Алматы', 'Almaty', '{алма-ата,alma-ata,г. алматы}', 20),
+    ('790000000', 'Шымкент', 'Шымкент', 'Shymkent', '{чимкент,г. шымкент}', 30),
+    ('100000000', 'Абайская область', 'Абай облысы', 'Abai Region', '{}', 100),
+    ('110000000', 'Акмолинская область', 'Ақмола облысы', 'Akmola Region', '{}', 110),
+    ('150000000', 'Актюбинская область', 'Ақтөбе облысы', 'Aktobe Region', '{}', 120),
+    ('190000000', 'Алматинская область', 'Алматы облысы', 'Almaty Region', '{}', 130),
+    ('230000000', 'Атырауская область', 'Атырау облысы', 'Atyrau Region', '{}', 140),
+    ('270000000', 'Западно-Казахстанская область', 'Батыс Қазақстан облысы', 'West Kazakhstan Region', '{зко}', 150),
+    ('310000000', 'Жамбылская область', 'Жамбыл облысы', 'Jambyl Region', '{}', 160),
+    ('330000000', 'Область Жетісу', 'Жетісу облысы', 'Jetisu Region', '{}', 170),
+    ('350000000', 'Карагандинская область', 'Қарағанды облысы', 'Karaganda Region', '{}', 180),
+    ('390000000', 'Костанайская область', 'Қостанай облысы', 'Kostanay Region', '{}', 190),
+    ('430000000', 'Кызылординская область', 'Қызылорда облысы', 'Kyzylorda Region', '{}', 200),
+    ('470000000', 'Мангистауская область', 'Маңғыстау облысы', 'Mangystau Region', '{}', 210),
+    ('550000000', 'Павлодарская область', 'Павлодар облысы', 'Pavlodar Region', '{}', 220),
+    ('590000000', 'Северо-Казахстанская область', 'Солтүстік Қазақстан облысы', 'North Kazakhstan Region', '{ско}', 230),
+    ('610000000', 'Туркестанская область', 'Түркістан облысы', 'Turkistan Region', '{}', 240),
+    ('620000000', 'Область Ұлытау', 'Ұлытау облысы', 'Ulytau Region', '{}', 250),
+    ('630000000', 'Восточно-Казахстанская область', 'Шығыс Қазақстан облысы', 'East Kazakhstan Region', '{вко}', 260)
+ON CONFLICT (kato) DO NOTHING;
+
+-- значения, которые не удалось сопоставить при переносе (для ручного разбора)
+CREATE TABLE IF NOT EXISTS taxonomy_unmapped (
+    id BIGSERIAL PRIMARY KEY,
+    object_type VARCHAR(32) NOT NULL, -- order | executor_profile | saved_search
+    object_id UUID NOT NULL,
+    field VARCHAR(32) NOT NULL,
+    value TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+-- перенос свободного текста: код, любое из названий или alias, без учёта регистра и е/ё
+CREATE FUNCTION pg_temp.taxonomy_key(v TEXT) RETURNS TEXT AS $$
+    SELECT translate(lower(btrim(v)), 'ё', 'е')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE FUNCTION pg_temp.map_category(v TEXT, parent TEXT) RETURNS TEXT AS $$
+    SELECT c.code FROM categories c
+    WHERE c.parent_code IS NOT DISTINCT FROM parent
+        AND pg_temp.taxonomy_key(v) IN (SELECT pg_temp.taxonomy_key(x) FROM unnest(c.aliases || ARRAY[c.code, c.name_ru, c.name_kk, c.name_en]) x)
+    ORDER BY c.sort LIMIT 1
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION pg_temp.map_region(v TEXT) RETURNS TEXT AS $$
+    SELECT r.kato FROM regions r
+    WHERE pg_temp.taxonomy_key(v) IN (SELECT pg_temp.taxonomy_key(x) FROM unnest(r.aliases || ARRAY[r.kato, r.name_ru, r.name_kk, r.name_en]) x)
+    ORDER BY r.sort LIMIT 1
+$$ LANGUAGE sql STABLE;
+
+-- orders
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'order', id, 'category', category FROM orders
+WHERE btrim(COALESCE(category, '')) <> '' AND pg_temp.map_category(category, NULL) IS NULL;
+
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'order', id, 'subcategory', subcategory FROM orders
+WHERE btrim(COALESCE(subcategory, '')) <> ''
+    AND (pg_temp.map_category(category, NULL) IS NULL OR pg_temp.map_category(subcategory, pg_temp.map_category(category, NULL)) IS NULL);
+
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'order', id, 'region', region FROM orders
+WHERE btrim(COALESCE(region, '')) <> '' AND pg_temp.map_region(region) IS NULL;
+
+UPDATE orders SET
+    category = pg_temp.map_category(category, NULL),
+    subcategory = CASE WHEN pg_temp.map_category(category, NULL) IS NOT NULL
+        THEN pg_temp.map_category(subcategory, pg_temp.map_category(category, NULL)) END,
+    region = pg_temp.map_region(region)
+WHERE category IS NOT NULL OR subcategory IS NOT NULL OR region IS NOT NULL;
+
+-- повторный прогон (после частичного применения) не падает на существующих ограничениях
+ALTER TABLE orders
+    DROP CONSTRAINT IF EXISTS orders_category_fk,
+    DROP CONSTRAINT IF EXISTS orders_subcategory_fk,
+    DROP CONSTRAINT IF EXISTS orders_subcategory_check,
+    DROP CONSTRAINT IF EXISTS orders_region_fk;
+
+-- цель составного FK: подкатегория вместе со своим родителем
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_parent_code_key;
+ALTER TABLE categories ADD CONSTRAINT categories_parent_code_key UNIQUE (parent_code, code);
+
+-- подкатегория должна принадлежать категории заказа; без категории подкатегории нет
+-- (составной FK с NULL в category не проверяется)
+ALTER TABLE orders
+    ADD CONSTRAINT orders_category_fk FOREIGN KEY (category) REFERENCES categories(code),
+    ADD CONSTRAINT orders_subcategory_fk FOREIGN KEY (category, subcategory) REFERENCES categories(parent_code, code),
+    ADD CONSTRAINT orders_subcategory_check CHECK (subcategory IS NULL OR category IS NOT NULL),
+    ADD CONSTRAINT orders_region_fk FOREIGN KEY (region) REFERENCES regions(kato);
+
+-- executor profiles: несопоставленные теги убираются
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'executor_profile', p.user_id, 'specializations', s FROM executor_profiles p, unnest(p.specializations) s
+WHERE pg_temp.map_category(s, NULL) IS NULL;
+
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'executor_profile', p.user_id, 'regions', s FROM executor_profiles p, unnest(p.regions) s
+WHERE pg_temp.map_region(s) IS NULL;
+
+UPDATE executor_profiles SET
+    specializations = ARRAY(
+        SELECT m FROM (SELECT pg_temp.map_category(s, NULL) m, min(i) i FROM unnest(specializations) WITH ORDINALITY u(s, i) GROUP BY 1) x
+        WHERE m IS NOT NULL ORDER BY i),
+    regions = ARRAY(
+        SELECT m FROM (SELECT pg_temp.map_region(s) m, min(i) i FROM unnest(regions) WITH ORDINALITY u(s, i) GROUP BY 1) x
+        WHERE m IS NOT NULL ORDER BY i);
+
+-- saved searches: несопоставленные значения остаются (ничему не соответствуют), пользователь исправит сам
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'saved_search', id, 'category', filters->>'category' FROM saved_searches
+WHERE filters ? 'category' AND pg_temp.map_category(filters->>'category', NULL) IS NULL;
+
+INSERT INTO taxonomy_unmapped (object_type, object_id, field, value)
+SELECT 'saved_search', id, 'region', filters->>'region' FROM saved_searches
+WHERE filters ? 'region' AND pg_temp.map_region(filters->>'region') IS NULL;
+
+UPDATE saved_searches SET filters = filters || jsonb_build_object('category', pg_temp.map_category(filters->>'category', NULL))
+WHERE pg_temp.map_category(filters->>'category', NULL) IS NOT NULL;
+
+UPDATE saved_searches SET filters = filters || jsonb_build_object('region', pg_temp.map_region(filters->>'region'))
+WHERE pg_temp.map_region(filters->>'region') IS NOT NULL;
+
+COMMIT;
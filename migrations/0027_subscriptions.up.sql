@@ -0,0 +1,44 @@
+BEGIN;
+
+-- тарифы исполнителей: месячная квота ставок, сниженная плата сверх квоты, бейдж и приоритет ставок
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    code VARCHAR(32) PRIMARY KEY,
+    name TEXT NOT NULL,
+    price BIGINT NOT NULL CHECK (price > 0), -- KZT в месяц
+    bid_quota INT NOT NULL CHECK (bid_quota >= 0), -- ставок в месяц без оплаты
+    overage_fee BIGINT NOT NULL CHECK (overage_fee >= 0), -- плата за ставку сверх квоты
+    badge VARCHAR(32) NOT NULL DEFAULT '',
+    priority SMALLINT NOT NULL DEFAULT 0, -- ставки с большим приоритетом выше в списке клиента
+    active BOOLEAN NOT NULL DEFAULT true,
+    sort INT NOT NULL DEFAULT 0
+    );
+
+INSERT INTO subscription_plans (code, name, price, bid_quota, overage_fee, badge, priority, sort) VALUES
+    ('basic', 'Basic', 4900, 15, 350, 'basic', 1, 10),
+    ('pro', 'Pro', 14900, 60, 200, 'pro', 2, 20)
+ON CONFLICT (code) DO NOTHING;
+
+-- текущая подписка исполнителя (одна на пользователя); история — в payments
+CREATE TABLE IF NOT EXISTS executor_subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plan_code VARCHAR(32) NOT NULL REFERENCES subscription_plans(code),
+    status VARCHAR(16) NOT NULL CHECK (status IN ('pending','active','grace','expired')),
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE,
+    grace_until TIMESTAMP WITH TIME ZONE, -- grace: продление не оплачено, условия тарифа ещё действуют
+    bids_used INT NOT NULL DEFAULT 0,
+    auto_renew BOOLEAN NOT NULL DEFAULT true,
+    next_plan_code VARCHAR(32) REFERENCES subscription_plans(code), -- понижение тарифа со следующего периода
+    pending_payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    pending_kind VARCHAR(16) CHECK (pending_kind IN ('new','renewal','upgrade')),
+    pending_plan_code VARCHAR(32) REFERENCES subscription_plans(code),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_executor_subscriptions_due ON executor_subscriptions (status, period_end);
+
+-- приоритет тарифа на момент ставки
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+COMMIT;
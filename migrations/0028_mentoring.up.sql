@@ -0,0 +1,66 @@
+BEGIN;
+
+-- профиль наставника (роль coach): почасовая ставка и темы консультаций
+CREATE TABLE IF NOT EXISTS coach_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    headline TEXT NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    topics TEXT[] NOT NULL DEFAULT '{}', -- коды categories
+    languages TEXT[] NOT NULL DEFAULT '{}', -- ru | kk | en
+    experience_years SMALLINT NOT NULL DEFAULT 0,
+    hourly_rate BIGINT NOT NULL CHECK (hourly_rate > 0), -- KZT
+    active BOOLEAN NOT NULL DEFAULT true, -- виден в каталоге и принимает записи
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_coach_profiles_topics ON coach_profiles USING GIN (topics);
+
+-- окна, в которые наставник готов провести консультацию
+CREATE TABLE IF NOT EXISTS coach_slots (
+    id UUID PRIMARY KEY,
+    coach_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open','booked')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_coach_slots_coach ON coach_slots (coach_id, starts_at);
+
+CREATE TABLE IF NOT EXISTS mentoring_sessions (
+    id UUID PRIMARY KEY,
+    coach_id UUID NOT NULL REFERENCES users(id),
+    mentee_id UUID NOT NULL REFERENCES users(id),
+    slot_id UUID REFERENCES coach_slots(id) ON DELETE SET NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    topic TEXT NOT NULL DEFAULT '',
+    price BIGINT NOT NULL, -- KZT, фиксируется при записи
+    status VARCHAR(32) NOT NULL CHECK (status IN ('pending_payment','scheduled','completed','cancelled')),
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    reschedules SMALLINT NOT NULL DEFAULT 0,
+    cancelled_by UUID REFERENCES users(id),
+    cancel_reason TEXT,
+    refunded BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_mentoring_sessions_coach ON mentoring_sessions (coach_id, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_mentoring_sessions_mentee ON mentoring_sessions (mentee_id, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_mentoring_sessions_status ON mentoring_sessions (status, ends_at);
+
+-- отзывы по консультациям: ровно одно из order_id / session_id;
+-- mentee оценивает наставника, coach — подопечного
+ALTER TABLE reviews ALTER COLUMN order_id DROP NOT NULL;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES mentoring_sessions(id) ON DELETE CASCADE;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_author_role_check;
+ALTER TABLE reviews ADD CONSTRAINT reviews_author_role_check CHECK (author_role IN ('client','executor','coach','mentee'));
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS chk_reviews_subject;
+ALTER TABLE reviews ADD CONSTRAINT chk_reviews_subject CHECK ((order_id IS NULL) <> (session_id IS NULL));
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reviews_session_author ON reviews (session_id, author_id) WHERE session_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,42 @@
+BEGIN;
+
+-- закрывающие документы: счёт на оплату по платежу (выставляет платформа)
+-- и акт выполненных работ (АВР) по завершённому заказу (выставляет исполнитель)
+CREATE TABLE IF NOT EXISTS documents (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('invoice','act')),
+    issuer_key TEXT NOT NULL, -- 'platform' или id пользователя-исполнителя; своя нумерация у каждого
+    number TEXT NOT NULL,
+    issuer_user_id UUID REFERENCES users(id),
+    buyer_user_id UUID NOT NULL REFERENCES users(id),
+    issuer JSONB NOT NULL DEFAULT '{}'::jsonb, -- реквизиты на момент выставления
+    buyer JSONB NOT NULL DEFAULT '{}'::jsonb,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    lines JSONB NOT NULL DEFAULT '[]'::jsonb,
+    amount BIGINT NOT NULL, -- KZT, с НДС
+    vat_rate SMALLINT NOT NULL DEFAULT 0, -- % ; 0 — без НДС
+    vat_amount BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(8) NOT NULL DEFAULT 'KZT',
+    storage_key TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    UNIQUE (issuer_key, kind, number)
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_documents_invoice_payment ON documents (payment_id) WHERE kind = 'invoice';
+CREATE UNIQUE INDEX IF NOT EXISTS uq_documents_act_order ON documents (order_id) WHERE kind = 'act';
+CREATE INDEX IF NOT EXISTS idx_documents_order ON documents (order_id);
+CREATE INDEX IF NOT EXISTS idx_documents_buyer ON documents (buyer_user_id, issued_at DESC);
+
+-- счётчики номеров: отдельная последовательность на эмитента, вид документа и год
+CREATE TABLE IF NOT EXISTS document_sequences (
+    issuer_key TEXT NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    year INT NOT NULL,
+    last_no INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (issuer_key, kind, year)
+    );
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN;
+
+-- выгрузки ЭСФ (XML для ИС ЭСФ) по сборам платформы за период
+CREATE TABLE IF NOT EXISTS esf_exports (
+    id UUID PRIMARY KEY,
+    period_from DATE NOT NULL,
+    period_to DATE NOT NULL, -- включительно
+    created_by UUID NOT NULL REFERENCES users(id),
+    invoices INT NOT NULL,
+    total BIGINT NOT NULL, -- KZT, с НДС
+    storage_key TEXT NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    skipped JSONB NOT NULL DEFAULT '[]'::jsonb, -- платежи, не прошедшие проверку схемы
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+    );
+
+-- платёж попадает в ЭСФ ровно один раз
+CREATE TABLE IF NOT EXISTS esf_exported_payments (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id),
+    export_id UUID NOT NULL REFERENCES esf_exports(id) ON DELETE CASCADE,
+    num TEXT NOT NULL, -- номер счёта-фактуры в учётной системе платформы
+    amount BIGINT NOT NULL
+    );
+
+CREATE INDEX IF NOT EXISTS idx_esf_exported_export ON esf_exported_payments (export_id);
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- ставки налогов с датой вступления в силу; действует последняя с effective_from <= даты операции
+CREATE TABLE IF NOT EXISTS tax_rates (
+    code VARCHAR(16) NOT NULL, -- 'vat'
+    rate SMALLINT NOT NULL CHECK (rate >= 0 AND rate < 100), -- %
+    effective_from DATE NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (code, effective_from)
+    );
+
+INSERT INTO tax_rates (code, rate, effective_from, note)
+VALUES ('vat', 12, '2009-01-01', 'НДС РК, Налоговый кодекс 2008')
+ON CONFLICT DO NOTHING;
+
+-- разбивка суммы платежа: amount = net_amount + vat_amount (НДС включён в amount)
+ALTER TABLE payments
+    ADD COLUMN IF NOT EXISTS net_amount BIGINT,
+    ADD COLUMN IF NOT EXISTS vat_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS vat_rate SMALLINT NOT NULL DEFAULT 0, -- % ; 0 — без НДС (эмитент не плательщик)
+    ADD COLUMN IF NOT EXISTS payer_vat BOOLEAN NOT NULL DEFAULT false; -- плательщик зарегистрирован по НДС (НДС к зачёту)
+
+-- прежние сборы платформы — с НДС 12%, только если платформа зарегистрирована
+-- по НДС (PLATFORM_VAT_PAYER); SQL этого не знает, поэтому по умолчанию платежи
+-- остаются без НДС. Плательщику НДС перед миграцией:
+--   ALTER DATABASE buhpro SET buhpro.platform_vat_payer = 'true';
+-- (или PGOPTIONS='-c buhpro.platform_vat_payer=true' для запуска миграций)
+UPDATE payments
+SET vat_rate = 12, vat_amount = (amount * 24 + 112) / 224
+WHERE net_amount IS NULL AND related_type IN ('bid_fee','order_publish','subscription') AND amount > 0
+  AND COALESCE(current_setting('buhpro.platform_vat_payer', true), '') = 'true';
+UPDATE payments SET net_amount = amount - vat_amount WHERE net_amount IS NULL;
+ALTER TABLE payments ALTER COLUMN net_amount SET NOT NULL;
+
+COMMIT;
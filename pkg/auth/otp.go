@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+)
+
+// GenerateOTP returns a random numeric code of the given length.
+func GenerateOTP(digits int) (string, error) {
+	max := big.NewInt(10)
+	b := make([]byte, digits)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = byte('0' + n.Int64())
+	}
+	return string(b), nil
+}
+
+// HashOTP — HMAC, а не голый sha256: 6-значный код иначе перебирается офлайн
+func HashOTP(secret, phone, code string) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(phone + ":" + code))
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -8,30 +8,49 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// JWTConfig — параметры подписи и проверки access-токенов
+type JWTConfig struct {
+	Secret           string
+	Issuer           string
+	Audience         string
+	AccessTTLMinutes int
+	RefreshTTLDays   int
+}
+
 type Claims struct {
-	UserID    string `json:"user_id"`
-	Role      string `json:"role"`
-	TokenType string `json:"token_type"` // "access" or "refresh"
+	UserID       string `json:"user_id"`
+	Role         string `json:"role"`
+	TokenType    string `json:"token_type"` // "access" or "refresh"
+	TokenVersion int    `json:"ver"`        // must match users.token_version, bumped on revocation
+	// impersonation by support staff: admin user id, token allows only safe methods
+	ImpersonatorID string `json:"imp,omitempty"`
+	ReadOnly       bool   `json:"ro,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateTokens(secret string, userID, role string, accessTTLMinutes int, refreshTTLDays int) (string, string, error) {
+func GenerateTokens(cfg JWTConfig, userID, role string, tokenVersion int) (string, string, error) {
 	now := time.Now()
 
 	// access token (JWT)
 	accessClaims := Claims{
-		UserID:    userID,
-		Role:      role,
-		TokenType: "access",
+		UserID:       userID,
+		Role:         role,
+		TokenType:    "access",
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    cfg.Issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{cfg.Audience},
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(accessTTLMinutes) * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(cfg.AccessTTLMinutes) * time.Minute)),
 		},
 	}
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	at, err := accessToken.SignedString([]byte(secret))
+	at, err := accessToken.SignedString([]byte(cfg.Secret))
 	if err != nil {
 		return "", "", err
 	}
@@ -46,19 +65,29 @@ func GenerateTokens(secret string, userID, role string, accessTTLMinutes int, re
 	return at, plainRefresh, nil
 }
 
-func ParseAccessToken(secret, tokenStr string) (*Claims, error) {
-	return parseTokenOfType(secret, tokenStr, "access")
+func ParseAccessToken(cfg JWTConfig, tokenStr string) (*Claims, error) {
+	return parseTokenOfType(cfg, tokenStr, "access")
 }
 
 // ParseRefreshToken validates refresh token and returns claims.
-func ParseRefreshToken(secret, tokenStr string) (*Claims, error) {
-	return parseTokenOfType(secret, tokenStr, "refresh")
+func ParseRefreshToken(cfg JWTConfig, tokenStr string) (*Claims, error) {
+	return parseTokenOfType(cfg, tokenStr, "refresh")
 }
 
-func parseTokenOfType(secret, tokenStr, wantType string) (*Claims, error) {
+func parseTokenOfType(cfg JWTConfig, tokenStr, wantType string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		return []byte(cfg.Secret), nil
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -75,3 +104,26 @@ func HashToken(token string) string {
 	h := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(h[:])
 }
+
+// GenerateImpersonationToken — короткоживущий read-only access-токен от имени
+// пользователя для поддержки; refresh-токен не выдаётся.
+func GenerateImpersonationToken(cfg JWTConfig, userID, role string, tokenVersion int, impersonatorID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID:         userID,
+		Role:           role,
+		TokenType:      "access",
+		TokenVersion:   tokenVersion,
+		ImpersonatorID: impersonatorID,
+		ReadOnly:       true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    cfg.Issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{cfg.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.Secret))
+}
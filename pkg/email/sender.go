@@ -0,0 +1,130 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmailSender отправляет письма; SMTP и локальная заглушка реализуют этот интерфейс
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LogSender — локальная заглушка: пишет письма в лог или в файл
+type LogSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogSender: path == "" — писать в стандартный лог
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+func (s *LogSender) Send(ctx context.Context, to, subject, body string) error {
+	if s.path == "" {
+		log.Printf("EMAIL to %s: %s\n%s", to, subject, body)
+		return nil
+	}
+	line := fmt.Sprintf("%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, subject, strings.ReplaceAll(body, "\n", " "))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
+
+// SMTPSender — отправка через SMTP (PLAIN auth, STARTTLS если сервер поддерживает)
+type SMTPSender struct {
+	addr     string
+	host     string
+	user     string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host string, port int, user, password, from string) *SMTPSender {
+	return &SMTPSender{addr: net.JoinHostPort(host, fmt.Sprint(port)), host: host, user: user, password: password, from: from}
+}
+
+// smtpTimeout — предел на всю отправку, если у ctx нет своего дедлайна
+const smtpTimeout = 30 * time.Second
+
+// Send — то же, что smtp.SendMail, но соединение ограничено дедлайном ctx
+// (и рвётся при его отмене): зависший сервер не держит воркер уведомлений
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	if strings.ContainsAny(s.from+to, "\r\n") {
+		return errors.New("smtp: address contains CR or LF")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.user != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.user, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+	msg := "From: " + s.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: 8bit\r\n\r\n" +
+		strings.ReplaceAll(body, "\n", "\r\n")
+	if err := c.Mail(s.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,146 @@
+// Package esf — выгрузка электронных счетов-фактур для ИС ЭСФ
+// (формат v2.esf) и их проверка по схеме invoice_v2.xsd и по суммам строк.
+package esf
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed invoice_v2.xsd
+var invoiceXSD []byte
+
+const (
+	InvoiceOrdinary = "ORDINARY_INVOICE"
+	DateLayout      = "02.01.2006"
+)
+
+type Container struct {
+	XMLName  xml.Name   `xml:"v2:invoiceContainer"`
+	XMLNS    string     `xml:"xmlns:v2,attr"`
+	Invoices []*Invoice `xml:"invoiceSet>invoice"`
+}
+
+type Invoice struct {
+	Date             string     `xml:"date"`
+	InvoiceType      string     `xml:"invoiceType"`
+	Num              string     `xml:"num"`
+	OperatorFullname string     `xml:"operatorFullname"`
+	TurnoverDate     string     `xml:"turnoverDate"`
+	Seller           Seller     `xml:"sellers>seller"`
+	Customer         Customer   `xml:"customers>customer"`
+	ProductSet       ProductSet `xml:"productSet"`
+}
+
+type Seller struct {
+	TIN     string `xml:"tin"`
+	Name    string `xml:"name"`
+	Address string `xml:"address"`
+	Bank    string `xml:"bank,omitempty"`
+	BIK     string `xml:"bik,omitempty"`
+	IIK     string `xml:"iik,omitempty"`
+	Kbe     string `xml:"kbe,omitempty"`
+}
+
+type Customer struct {
+	TIN         string `xml:"tin"`
+	Name        string `xml:"name"`
+	Address     string `xml:"address"`
+	CountryCode string `xml:"countryCode"`
+}
+
+type ProductSet struct {
+	CurrencyCode         string     `xml:"currencyCode"`
+	Products             []*Product `xml:"products>product"`
+	TotalPriceWithoutTax Amount     `xml:"totalPriceWithoutTax"`
+	TotalNdsAmount       Amount     `xml:"totalNdsAmount"`
+	TotalPriceWithTax    Amount     `xml:"totalPriceWithTax"`
+	TotalTurnoverSize    Amount     `xml:"totalTurnoverSize"`
+}
+
+// Product — строка раздела G; NdsRate == nil — «Без НДС»
+type Product struct {
+	Description      string `xml:"description"`
+	UnitNomenclature string `xml:"unitNomenclature"`
+	Quantity         int64  `xml:"quantity"`
+	UnitPrice        Amount `xml:"unitPrice"`
+	PriceWithoutTax  Amount `xml:"priceWithoutTax"`
+	NdsRate          *int   `xml:"ndsRate,omitempty"`
+	NdsAmount        Amount `xml:"ndsAmount"`
+	PriceWithTax     Amount `xml:"priceWithTax"`
+	TurnoverSize     Amount `xml:"turnoverSize"`
+}
+
+// Amount — сумма в тиынах, в XML пишется как 1250.00
+type Amount int64
+
+func (a Amount) MarshalText() ([]byte, error) {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return []byte(fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)), nil
+}
+
+// Tenge converts whole tenge to Amount
+func Tenge(v int64) Amount { return Amount(v * 100) }
+
+// Totals fills the productSet totals from its products
+func (ps *ProductSet) Totals() {
+	ps.TotalPriceWithoutTax, ps.TotalNdsAmount, ps.TotalPriceWithTax, ps.TotalTurnoverSize = 0, 0, 0, 0
+	for _, p := range ps.Products {
+		ps.TotalPriceWithoutTax += p.PriceWithoutTax
+		ps.TotalNdsAmount += p.NdsAmount
+		ps.TotalPriceWithTax += p.PriceWithTax
+		ps.TotalTurnoverSize += p.TurnoverSize
+	}
+}
+
+// Marshal renders the invoices as one v2.esf container document
+func Marshal(invoices []*Invoice) ([]byte, error) {
+	body, err := xml.MarshalIndent(&Container{XMLNS: "v2.esf", Invoices: invoices}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ValidationError — нарушения схемы, по одному на строку
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return "esf: document does not match the schema: " + strings.Join(e.Violations, "; ")
+}
+
+var (
+	schemaOnce sync.Once
+	schemaErr  error
+	parsed     *schema
+)
+
+// Validate checks a rendered document against invoice_v2.xsd, then that the
+// line amounts add up to the productSet totals
+func Validate(doc []byte) error {
+	schemaOnce.Do(func() { parsed, schemaErr = parseSchema(invoiceXSD) })
+	if schemaErr != nil {
+		return schemaErr
+	}
+	violations, err := parsed.validate(doc)
+	if err != nil {
+		return fmt.Errorf("esf: malformed xml: %w", err)
+	}
+	// суммы схема не проверяет; по документу, не прошедшему схему, они не считаются
+	if len(violations) == 0 {
+		violations = checkTotals(doc)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package esf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testInvoice() *Invoice {
+	rate := 12
+	ps := ProductSet{CurrencyCode: "KZT", Products: []*Product{
+		{
+			Description: "Размещение заказа на платформе BuhPro", UnitNomenclature: "усл.", Quantity: 1,
+			UnitPrice: Tenge(1120), PriceWithoutTax: Tenge(1000), NdsRate: &rate, NdsAmount: Tenge(120),
+			PriceWithTax: Tenge(1120), TurnoverSize: Tenge(1000),
+		},
+		{
+			Description: "Отклик на заказ на платформе BuhPro", UnitNomenclature: "усл.", Quantity: 2,
+			UnitPrice: Tenge(250), PriceWithoutTax: Tenge(500), NdsAmount: 0,
+			PriceWithTax: Tenge(500), TurnoverSize: Tenge(500),
+		},
+	}}
+	ps.Totals()
+	return &Invoice{
+		Date:             "01.03.2026",
+		InvoiceType:      InvoiceOrdinary,
+		Num:              "ESF-2026-00001",
+		OperatorFullname: "Иванов Иван",
+		TurnoverDate:     "28.02.2026",
+		Seller: Seller{
+			TIN: "123456789012", Name: "ТОО «BuhPro»", Address: "Астана", Bank: "АО «Банк»",
+			BIK: "HSBKKZKX", IIK: "KZ123456789012345678", Kbe: "17",
+		},
+		Customer:   Customer{TIN: "210987654321", Name: "ТОО «Клиент»", Address: "Алматы", CountryCode: "KZ"},
+		ProductSet: ps,
+	}
+}
+
+func marshal(t *testing.T, invoices ...*Invoice) []byte {
+	t.Helper()
+	doc, err := Marshal(invoices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// violationsOf validates doc and returns the schema/totals violations
+func violationsOf(t *testing.T, doc []byte) []string {
+	t.Helper()
+	err := Validate(doc)
+	if err == nil {
+		return nil
+	}
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("want ValidationError, got %v", err)
+	}
+	return ve.Violations
+}
+
+func expectViolation(t *testing.T, doc []byte, substr string) {
+	t.Helper()
+	vs := violationsOf(t, doc)
+	for _, v := range vs {
+		if strings.Contains(v, substr) {
+			return
+		}
+	}
+	t.Fatalf("want a violation containing %q, got %q", substr, vs)
+}
+
+func TestValidateAcceptsInvoice(t *testing.T) {
+	if vs := violationsOf(t, marshal(t, testInvoice(), testInvoice())); len(vs) > 0 {
+		t.Fatalf("unexpected violations: %q", vs)
+	}
+}
+
+func TestValidateSchemaViolations(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(inv *Invoice)
+		want   string
+	}{
+		{"short tin", func(inv *Invoice) { inv.Seller.TIN = "12345678901" }, "sellers[1]/seller[1]/tin[1]"},
+		{"empty customer name", func(inv *Invoice) { inv.Customer.Name = "" }, "customer[1]/name[1]: length 0"},
+		{"bad date", func(inv *Invoice) { inv.Date = "2026-03-01" }, "date[1]"},
+		{"unknown invoice type", func(inv *Invoice) { inv.InvoiceType = "DRAFT" }, "is not one of"},
+		{"bad iik", func(inv *Invoice) { inv.Seller.IIK = "US123" }, "iik[1]"},
+		{"long num", func(inv *Invoice) { inv.Num = strings.Repeat("9", 31) }, "num[1]: length 31"},
+		{"zero quantity", func(inv *Invoice) { inv.ProductSet.Products[0].Quantity = 0 }, "must be greater than 0"},
+		{"rate above 100", func(inv *Invoice) { r := 112; inv.ProductSet.Products[0].NdsRate = &r }, "greater than 100"},
+		{"negative amount", func(inv *Invoice) {
+			inv.ProductSet.Products[1].UnitPrice = -1
+		}, "less than 0"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			inv := testInvoice()
+			c.modify(inv)
+			expectViolation(t, marshal(t, inv), c.want)
+		})
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	doc := string(marshal(t, testInvoice()))
+	cases := []struct {
+		name, from, to, want string
+	}{
+		{"missing element", "<turnoverDate>28.02.2026</turnoverDate>", "", "missing element turnoverDate"},
+		{"unexpected element", "<countryCode>KZ</countryCode>", "<countryCode>KZ</countryCode><phone>1</phone>", "unexpected element phone"},
+		{"fraction digits", "<unitPrice>250.00</unitPrice>", "<unitPrice>250.005</unitPrice>", "more than 2 fraction digits"},
+		{"not a number", "<ndsRate>12</ndsRate>", "<ndsRate>12.5</ndsRate>", "not a valid integer"},
+		{"wrong root", "v2:invoiceContainer", "v2:container", "unexpected root element"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if !strings.Contains(doc, c.from) {
+				t.Fatalf("fixture has no %q", c.from)
+			}
+			expectViolation(t, []byte(strings.ReplaceAll(doc, c.from, c.to)), c.want)
+		})
+	}
+}
+
+func TestValidateTotals(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(inv *Invoice)
+		want   string
+	}{
+		{"total does not match lines", func(inv *Invoice) { inv.ProductSet.TotalPriceWithTax += Tenge(1) },
+			"totalPriceWithTax 1621.00 does not equal the sum of products 1620.00"},
+		{"total nds does not match lines", func(inv *Invoice) { inv.ProductSet.TotalNdsAmount = 0 },
+			"totalNdsAmount 0.00 does not equal"},
+		{"line does not add up", func(inv *Invoice) {
+			inv.ProductSet.Products[0].PriceWithTax = Tenge(1200)
+			inv.ProductSet.Totals()
+		}, "product[1]: priceWithTax 1200.00 is not priceWithoutTax 1000.00 + ndsAmount 120.00"},
+		{"nds without rate", func(inv *Invoice) {
+			p := inv.ProductSet.Products[1]
+			p.PriceWithoutTax, p.NdsAmount = Tenge(450), Tenge(50)
+			inv.ProductSet.Totals()
+		}, "product[2]: ndsAmount 50.00 without ndsRate"},
+		{"nds does not match rate", func(inv *Invoice) {
+			p := inv.ProductSet.Products[0]
+			p.PriceWithoutTax, p.NdsAmount, p.TurnoverSize = Tenge(1100), Tenge(20), Tenge(1100)
+			inv.ProductSet.Totals()
+		}, "ndsAmount 20.00 does not match ndsRate 12 of 1100.00"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			inv := testInvoice()
+			c.modify(inv)
+			// второй счёт контейнера корректен: ошибка указывает на первый
+			doc := marshal(t, inv, testInvoice())
+			expectViolation(t, doc, c.want)
+			expectViolation(t, doc, "/invoiceContainer/invoiceSet[1]/invoice[1]/productSet")
+		})
+	}
+}
+
+// НДС выделяется из суммы с НДС и округляется до тенге: такое расхождение допустимо
+func TestValidateNdsRounding(t *testing.T) {
+	inv := testInvoice()
+	p := inv.ProductSet.Products[0]
+	// 1130 с НДС 12%: НДС 121.07 -> 121, без НДС 1009; 1009 * 12% = 121.08
+	p.UnitPrice, p.PriceWithTax = Tenge(1130), Tenge(1130)
+	p.PriceWithoutTax, p.NdsAmount, p.TurnoverSize = Tenge(1009), Tenge(121), Tenge(1009)
+	inv.ProductSet.Totals()
+	if vs := violationsOf(t, marshal(t, inv)); len(vs) > 0 {
+		t.Fatalf("unexpected violations: %q", vs)
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	err := Validate([]byte("<v2:invoiceContainer xmlns:v2=\"v2.esf\"><invoiceSet>"))
+	var ve *ValidationError
+	if err == nil || errors.As(err, &ve) {
+		t.Fatalf("want a malformed xml error, got %v", err)
+	}
+}
+
+func TestAmountText(t *testing.T) {
+	for v, want := range map[Amount]string{0: "0.00", 5: "0.05", Tenge(1250): "1250.00", -150: "-1.50"} {
+		if got := v.text(); got != want {
+			t.Fatalf("%d: got %s, want %s", v, got, want)
+		}
+	}
+}
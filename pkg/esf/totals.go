@@ -0,0 +1,115 @@
+package esf
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ndsTolerance — допустимое расхождение НДС строки с ставкой от суммы без
+// НДС: НДС выделяется из суммы с НДС и округляется до тенге (в тиынах)
+const ndsTolerance = 100
+
+// checkTotals проверяет то, что не выражается схемой: в каждой строке сумма
+// с НДС = без НДС + НДС (и НДС соответствует ставке), итоги productSet равны
+// суммам строк. Документ должен уже соответствовать схеме.
+func checkTotals(doc []byte) []string {
+	root, err := parseTree(doc)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var errs []string
+	set := child(root, "invoiceSet")
+	if set == nil {
+		return nil
+	}
+	n := 0
+	for _, inv := range set.children {
+		if inv.name != "invoice" {
+			continue
+		}
+		n++
+		path := fmt.Sprintf("/%s/invoiceSet[1]/invoice[%d]/productSet", root.name, n)
+		ps := child(inv, "productSet")
+		if ps == nil {
+			continue
+		}
+		var sum [4]int64
+		i := 0
+		for _, p := range children(child(ps, "products"), "product") {
+			i++
+			ppath := fmt.Sprintf("%s/products[1]/product[%d]", path, i)
+			without, nds, with := tiyn(child(p, "priceWithoutTax")), tiyn(child(p, "ndsAmount")), tiyn(child(p, "priceWithTax"))
+			if without+nds != with {
+				errs = append(errs, fmt.Sprintf("%s: priceWithTax %s is not priceWithoutTax %s + ndsAmount %s",
+					ppath, Amount(with).text(), Amount(without).text(), Amount(nds).text()))
+			}
+			if rate := child(p, "ndsRate"); rate == nil {
+				if nds != 0 {
+					errs = append(errs, fmt.Sprintf("%s: ndsAmount %s without ndsRate", ppath, Amount(nds).text()))
+				}
+			} else if r, ok := new(big.Rat).SetString(strings.TrimSpace(rate.text.String())); ok {
+				want := new(big.Rat).Mul(new(big.Rat).SetInt64(without), new(big.Rat).Quo(r, big.NewRat(100, 1)))
+				diff := new(big.Rat).Sub(want, new(big.Rat).SetInt64(nds))
+				if diff.Abs(diff).Cmp(big.NewRat(ndsTolerance, 1)) > 0 {
+					errs = append(errs, fmt.Sprintf("%s: ndsAmount %s does not match ndsRate %s of %s",
+						ppath, Amount(nds).text(), r.RatString(), Amount(without).text()))
+				}
+			}
+			sum[0] += without
+			sum[1] += nds
+			sum[2] += with
+			sum[3] += tiyn(child(p, "turnoverSize"))
+		}
+		for k, name := range []string{"totalPriceWithoutTax", "totalNdsAmount", "totalPriceWithTax", "totalTurnoverSize"} {
+			if got := tiyn(child(ps, name)); got != sum[k] {
+				errs = append(errs, fmt.Sprintf("%s: %s %s does not equal the sum of products %s",
+					path, name, Amount(got).text(), Amount(sum[k]).text()))
+			}
+		}
+	}
+	return errs
+}
+
+func child(n *node, name string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func children(n *node, name string) []*node {
+	if n == nil {
+		return nil
+	}
+	var out []*node
+	for _, c := range n.children {
+		if c.name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// tiyn reads an amount element (не более 2 знаков после точки — проверено схемой)
+func tiyn(n *node) int64 {
+	if n == nil {
+		return 0
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(n.text.String()))
+	if !ok {
+		return 0
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	return r.Num().Int64() / r.Denom().Int64()
+}
+
+func (a Amount) text() string {
+	b, _ := a.MarshalText()
+	return string(b)
+}
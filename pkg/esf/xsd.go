@@ -0,0 +1,350 @@
+package esf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Минимальный валидатор XML Schema — ровно то, что использует invoice_v2.xsd:
+// именованные и вложенные simpleType/complexType, xs:sequence с
+// minOccurs/maxOccurs и фасеты pattern, enumeration, min/maxLength,
+// min/maxInclusive, minExclusive, fractionDigits над xs:string,
+// xs:decimal и xs:integer. Пространства имён сравниваются по локальным именам.
+
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     strings.Builder
+}
+
+func parseTree(data []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*node
+	var root *node
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF && root != nil && len(stack) == 0 {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: map[string]string{}}
+			for _, a := range t.Attr {
+				n.attrs[a.Name.Local] = a.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+}
+
+type schema struct {
+	elements map[string]*element
+	complex  map[string]*complexType
+	simple   map[string]*simpleType
+}
+
+type element struct {
+	name     string
+	typeName string
+	min, max int // max < 0 — unbounded
+	complex  *complexType
+	simple   *simpleType
+}
+
+type complexType struct {
+	sequence []*element
+}
+
+type simpleType struct {
+	base         string // builtin (string|decimal|integer) или имя другого simpleType
+	patterns     []*regexp.Regexp
+	enum         []string
+	minLen       int
+	maxLen       int // -1 — без ограничения
+	minIncl      *big.Rat
+	maxIncl      *big.Rat
+	minExcl      *big.Rat
+	fractionDigs int // -1 — без ограничения
+}
+
+func localName(qname string) string {
+	if i := strings.IndexByte(qname, ':'); i >= 0 {
+		return qname[i+1:]
+	}
+	return qname
+}
+
+func parseSchema(data []byte) (*schema, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.name != "schema" {
+		return nil, fmt.Errorf("xsd: root is %s, not schema", root.name)
+	}
+	s := &schema{elements: map[string]*element{}, complex: map[string]*complexType{}, simple: map[string]*simpleType{}}
+	for _, c := range root.children {
+		switch c.name {
+		case "element":
+			e, err := parseElement(c)
+			if err != nil {
+				return nil, err
+			}
+			s.elements[e.name] = e
+		case "complexType":
+			ct, err := parseComplex(c)
+			if err != nil {
+				return nil, err
+			}
+			s.complex[c.attrs["name"]] = ct
+		case "simpleType":
+			st, err := parseSimple(c)
+			if err != nil {
+				return nil, err
+			}
+			s.simple[c.attrs["name"]] = st
+		}
+	}
+	return s, nil
+}
+
+func parseElement(n *node) (*element, error) {
+	e := &element{name: n.attrs["name"], typeName: n.attrs["type"], min: 1, max: 1}
+	if v, ok := n.attrs["minOccurs"]; ok {
+		e.min, _ = strconv.Atoi(v)
+	}
+	if v, ok := n.attrs["maxOccurs"]; ok {
+		if v == "unbounded" {
+			e.max = -1
+		} else {
+			e.max, _ = strconv.Atoi(v)
+		}
+	}
+	for _, c := range n.children {
+		var err error
+		switch c.name {
+		case "complexType":
+			e.complex, err = parseComplex(c)
+		case "simpleType":
+			e.simple, err = parseSimple(c)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func parseComplex(n *node) (*complexType, error) {
+	ct := &complexType{}
+	for _, c := range n.children {
+		if c.name != "sequence" {
+			continue
+		}
+		for _, el := range c.children {
+			if el.name != "element" {
+				return nil, fmt.Errorf("xsd: unsupported %s in sequence", el.name)
+			}
+			e, err := parseElement(el)
+			if err != nil {
+				return nil, err
+			}
+			ct.sequence = append(ct.sequence, e)
+		}
+	}
+	return ct, nil
+}
+
+func parseSimple(n *node) (*simpleType, error) {
+	st := &simpleType{maxLen: -1, fractionDigs: -1}
+	for _, r := range n.children {
+		if r.name != "restriction" {
+			continue
+		}
+		st.base = localName(r.attrs["base"])
+		for _, f := range r.children {
+			v := f.attrs["value"]
+			var err error
+			switch f.name {
+			case "pattern":
+				var re *regexp.Regexp
+				// шаблоны XSD неявно привязаны к началу и концу значения
+				if re, err = regexp.Compile("^(?:" + v + ")$"); err == nil {
+					st.patterns = append(st.patterns, re)
+				}
+			case "enumeration":
+				st.enum = append(st.enum, v)
+			case "minLength":
+				st.minLen, err = strconv.Atoi(v)
+			case "maxLength":
+				st.maxLen, err = strconv.Atoi(v)
+			case "fractionDigits":
+				st.fractionDigs, err = strconv.Atoi(v)
+			case "minInclusive":
+				st.minIncl, err = parseRat(v)
+			case "maxInclusive":
+				st.maxIncl, err = parseRat(v)
+			case "minExclusive":
+				st.minExcl, err = parseRat(v)
+			default:
+				err = fmt.Errorf("unsupported facet %s", f.name)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("xsd: %s: %w", f.name, err)
+			}
+		}
+	}
+	return st, nil
+}
+
+func parseRat(v string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(v)
+	if !ok {
+		return nil, fmt.Errorf("bad number %q", v)
+	}
+	return r, nil
+}
+
+var decimalRe = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// validate checks an instance document; returns every violation with its path
+func (s *schema) validate(data []byte) ([]string, error) {
+	root, err := parseTree(data)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := s.elements[root.name]
+	if !ok {
+		return []string{fmt.Sprintf("/%s: unexpected root element", root.name)}, nil
+	}
+	var errs []string
+	s.checkElement(e, root, "/"+root.name, &errs)
+	return errs, nil
+}
+
+func (s *schema) checkElement(e *element, n *node, path string, errs *[]string) {
+	ct, st := e.complex, e.simple
+	if ct == nil && st == nil && e.typeName != "" {
+		name := localName(e.typeName)
+		if c, ok := s.complex[name]; ok {
+			ct = c
+		} else if t, ok := s.simple[name]; ok {
+			st = t
+		} else {
+			st = &simpleType{base: name, maxLen: -1, fractionDigs: -1}
+		}
+	}
+	if ct != nil {
+		s.checkSequence(ct, n, path, errs)
+		return
+	}
+	if len(n.children) > 0 {
+		*errs = append(*errs, path+": element content is not allowed")
+		return
+	}
+	if st == nil {
+		st = &simpleType{base: "string", maxLen: -1, fractionDigs: -1}
+	}
+	s.checkValue(st, strings.TrimSpace(n.text.String()), path, errs)
+}
+
+func (s *schema) checkSequence(ct *complexType, n *node, path string, errs *[]string) {
+	if strings.TrimSpace(n.text.String()) != "" {
+		*errs = append(*errs, path+": text content is not allowed")
+	}
+	i := 0
+	for _, e := range ct.sequence {
+		count := 0
+		for i < len(n.children) && n.children[i].name == e.name && (e.max < 0 || count < e.max) {
+			child := n.children[i]
+			s.checkElement(e, child, fmt.Sprintf("%s/%s[%d]", path, e.name, count+1), errs)
+			count++
+			i++
+		}
+		if count < e.min {
+			*errs = append(*errs, fmt.Sprintf("%s: missing element %s", path, e.name))
+		}
+	}
+	for ; i < len(n.children); i++ {
+		*errs = append(*errs, fmt.Sprintf("%s: unexpected element %s", path, n.children[i].name))
+	}
+}
+
+func (s *schema) checkValue(st *simpleType, v, path string, errs *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	// фасеты базового именованного типа проверяются раньше собственных
+	if parent, ok := s.simple[st.base]; ok {
+		s.checkValue(parent, v, path, errs)
+	} else {
+		switch st.base {
+		case "decimal", "integer":
+			if !decimalRe.MatchString(v) || (st.base == "integer" && strings.Contains(v, ".")) {
+				fail("%q is not a valid %s", v, st.base)
+				return
+			}
+		}
+	}
+	for _, re := range st.patterns {
+		if !re.MatchString(v) {
+			fail("%q does not match pattern %s", v, re.String())
+		}
+	}
+	if len(st.enum) > 0 {
+		found := false
+		for _, allowed := range st.enum {
+			found = found || allowed == v
+		}
+		if !found {
+			fail("%q is not one of %s", v, strings.Join(st.enum, ", "))
+		}
+	}
+	if l := len([]rune(v)); l < st.minLen || (st.maxLen >= 0 && l > st.maxLen) {
+		fail("length %d is out of range", l)
+	}
+	if st.minIncl == nil && st.maxIncl == nil && st.minExcl == nil && st.fractionDigs < 0 {
+		return
+	}
+	num, ok := new(big.Rat).SetString(v)
+	if !ok {
+		fail("%q is not a number", v)
+		return
+	}
+	if st.minIncl != nil && num.Cmp(st.minIncl) < 0 {
+		fail("%s is less than %s", v, st.minIncl.RatString())
+	}
+	if st.maxIncl != nil && num.Cmp(st.maxIncl) > 0 {
+		fail("%s is greater than %s", v, st.maxIncl.RatString())
+	}
+	if st.minExcl != nil && num.Cmp(st.minExcl) <= 0 {
+		fail("%s must be greater than %s", v, st.minExcl.RatString())
+	}
+	if st.fractionDigs >= 0 {
+		if i := strings.IndexByte(v, '.'); i >= 0 && len(strings.TrimRight(v[i+1:], "0")) > st.fractionDigs {
+			fail("%s has more than %d fraction digits", v, st.fractionDigs)
+		}
+	}
+}
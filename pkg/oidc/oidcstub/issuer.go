@@ -0,0 +1,179 @@
+// Package oidcstub — минимальный OIDC-провайдер в памяти процесса для тестов
+// и локальной разработки: авторизует без логина, поддерживает PKCE (S256).
+package oidcstub
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "stub-key"
+
+type authRequest struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	subject       string
+	email         string
+}
+
+// Issuer is an in-process OIDC issuer. The authorize endpoint approves every
+// request immediately; the subject/email are taken from the login_hint parameter.
+type Issuer struct {
+	URL      string
+	ClientID string
+
+	srv *httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+func NewIssuer(clientID string) (*Issuer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	is := &Issuer{ClientID: clientID, key: key, codes: make(map[string]authRequest)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", is.discovery)
+	mux.HandleFunc("/authorize", is.authorize)
+	mux.HandleFunc("/token", is.token)
+	mux.HandleFunc("/jwks", is.jwks)
+	is.srv = httptest.NewServer(mux)
+	is.URL = is.srv.URL
+	return is, nil
+}
+
+func (is *Issuer) Close() { is.srv.Close() }
+
+func (is *Issuer) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                is.URL,
+		"authorization_endpoint":                is.URL + "/authorize",
+		"token_endpoint":                        is.URL + "/token",
+		"jwks_uri":                              is.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (is *Issuer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != is.ClientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	sub := q.Get("login_hint")
+	if sub == "" {
+		sub = "stub-user"
+	}
+	code := randomString()
+	is.mu.Lock()
+	is.codes[code] = authRequest{
+		clientID:      q.Get("client_id"),
+		redirectURI:   q.Get("redirect_uri"),
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		subject:       sub,
+		email:         sub + "@stub.local",
+	}
+	is.mu.Unlock()
+
+	u, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	rq := u.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	u.RawQuery = rq.Encode()
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+func (is *Issuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	code := r.PostForm.Get("code")
+	is.mu.Lock()
+	ar, ok := is.codes[code]
+	delete(is.codes, code) // коды одноразовые
+	is.mu.Unlock()
+	if !ok || ar.clientID != r.PostForm.Get("client_id") || ar.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	h := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(h[:]) != ar.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            is.URL,
+		"sub":            ar.subject,
+		"aud":            ar.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          ar.nonce,
+		"email":          ar.email,
+		"email_verified": true,
+		"name":           ar.subject,
+	})
+	tok.Header["kid"] = keyID
+	idToken, err := tok.SignedString(is.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (is *Issuer) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := is.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomString returns n random bytes encoded as base64url (state, nonce, PKCE verifier)
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewCodeVerifier — PKCE code_verifier (RFC 7636: 43..128 символов)
+func NewCodeVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// CodeChallengeS256 — code_challenge для метода S256
+func CodeChallengeS256(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
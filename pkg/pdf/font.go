@@ -0,0 +1,216 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrBadFont = errors.New("pdf: unsupported TrueType font")
+
+// Font — TrueType-шрифт, встраиваемый в документ (Identity-H, только
+// использованные глифы), поэтому кириллица и казахские буквы выводятся без
+// отдельных кодировок
+type Font struct {
+	data       []byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	glyphs     map[rune]uint16
+	advances   []uint16 // по glyph id
+}
+
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFont(data)
+}
+
+// ParseFont reads the tables needed for layout: head, hhea, maxp, hmtx, cmap
+func ParseFont(data []byte) (*Font, error) {
+	tables, err := tableDirectory(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: no %s table", ErrBadFont, tag)
+		}
+	}
+	f := &Font{data: data}
+	head, hhea := tables["head"], tables["hhea"]
+	if len(head) < 54 || len(hhea) < 36 || len(tables["maxp"]) < 6 {
+		return nil, ErrBadFont
+	}
+	f.unitsPerEm = int(u16(head, 18))
+	if f.unitsPerEm == 0 {
+		return nil, ErrBadFont
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int(int16(u16(head, 36+2*i)))
+	}
+	f.ascent = int(int16(u16(hhea, 4)))
+	f.descent = int(int16(u16(hhea, 6)))
+
+	numGlyphs := int(u16(tables["maxp"], 4))
+	numMetrics := int(u16(hhea, 34))
+	hmtx := tables["hmtx"]
+	if numMetrics == 0 || len(hmtx) < 4*numMetrics {
+		return nil, ErrBadFont
+	}
+	f.advances = make([]uint16, numGlyphs)
+	for g := range f.advances {
+		if g < numMetrics {
+			f.advances[g] = u16(hmtx, 4*g)
+		} else {
+			f.advances[g] = f.advances[numMetrics-1]
+		}
+	}
+	if f.glyphs, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Width returns the advance width of s in points at the given size
+func (f *Font) Width(s string, size float64) float64 {
+	var w int
+	for _, r := range s {
+		w += int(f.advance(f.glyph(r)))
+	}
+	return float64(w) * size / float64(f.unitsPerEm)
+}
+
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r] // 0 — .notdef
+}
+
+func (f *Font) advance(g uint16) uint16 {
+	if int(g) < len(f.advances) {
+		return f.advances[g]
+	}
+	return 0
+}
+
+// scale converts font units to the PDF glyph space (1000 per em)
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+func tableDirectory(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrBadFont
+	}
+	if v := binary.BigEndian.Uint32(data); v != 0x00010000 && v != 0x74727565 { // 'true'
+		return nil, fmt.Errorf("%w: not a TrueType outline font", ErrBadFont)
+	}
+	n := int(u16(data, 4))
+	if len(data) < 12+16*n {
+		return nil, ErrBadFont
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := data[12+16*i:]
+		off, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(off)+uint64(length) > uint64(len(data)) {
+			return nil, ErrBadFont
+		}
+		tables[string(rec[:4])] = data[off : off+length]
+	}
+	return tables, nil
+}
+
+// parseCmap picks the Unicode subtable: format 12 (full range) if present,
+// otherwise format 4 (BMP)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrBadFont
+	}
+	var bmp, full []byte
+	n := int(u16(cmap, 2))
+	for i := 0; i < n && 4+8*i+8 <= len(cmap); i++ {
+		rec := cmap[4+8*i:]
+		platform, encoding := u16(rec, 0), u16(rec, 2)
+		off := int(binary.BigEndian.Uint32(rec[4:]))
+		if off+4 > len(cmap) {
+			continue
+		}
+		sub := cmap[off:]
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		switch {
+		case unicode && u16(sub, 0) == 12:
+			full = sub
+		case unicode && u16(sub, 0) == 4:
+			bmp = sub
+		}
+	}
+	switch {
+	case full != nil:
+		return cmapFormat12(full)
+	case bmp != nil:
+		return cmapFormat4(bmp)
+	}
+	return nil, fmt.Errorf("%w: no unicode cmap", ErrBadFont)
+}
+
+func cmapFormat4(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 14 {
+		return nil, ErrBadFont
+	}
+	segs := int(u16(sub, 6)) / 2
+	ends, starts := 14, 16+2*segs
+	deltas, ranges := starts+2*segs, starts+4*segs
+	if len(sub) < ranges+2*segs {
+		return nil, ErrBadFont
+	}
+	out := make(map[rune]uint16)
+	for i := 0; i < segs; i++ {
+		end, start := int(u16(sub, ends+2*i)), int(u16(sub, starts+2*i))
+		delta, rangeOff := u16(sub, deltas+2*i), int(u16(sub, ranges+2*i))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var g uint16
+			if rangeOff == 0 {
+				g = uint16(c) + delta
+			} else {
+				at := ranges + 2*i + rangeOff + 2*(c-start)
+				if at+2 > len(sub) {
+					continue
+				}
+				if g = u16(sub, at); g != 0 {
+					g += delta
+				}
+			}
+			if g != 0 {
+				out[rune(c)] = g
+			}
+		}
+	}
+	return out, nil
+}
+
+func cmapFormat12(sub []byte) (map[rune]uint16, error) {
+	if len(sub) < 16 {
+		return nil, ErrBadFont
+	}
+	groups := int(binary.BigEndian.Uint32(sub[12:]))
+	if len(sub) < 16+12*groups {
+		return nil, ErrBadFont
+	}
+	out := make(map[rune]uint16)
+	for i := 0; i < groups; i++ {
+		g := sub[16+12*i:]
+		start, end, gid := binary.BigEndian.Uint32(g), binary.BigEndian.Uint32(g[4:]), binary.BigEndian.Uint32(g[8:])
+		for c := start; c <= end && gid <= 0xFFFF; c, gid = c+1, gid+1 {
+			out[rune(c)] = uint16(gid)
+		}
+	}
+	return out, nil
+}
+
+func u16(b []byte, off int) uint16 {
+	return binary.BigEndian.Uint16(b[off:])
+}
@@ -0,0 +1,288 @@
+// Package pdf — минимальный генератор PDF для закрывающих документов:
+// страницы A4, текст одним встроенным TrueType-шрифтом, линии и рамки.
+// Координаты — в пунктах от левого верхнего угла страницы.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	PageWidth  = 595.28 // A4
+	PageHeight = 841.89
+)
+
+type Document struct {
+	font  *Font
+	pages []*bytes.Buffer
+	used  map[uint16]rune // glyph id -> символ, для ToUnicode и ширин
+	title string
+}
+
+func New(f *Font) *Document {
+	return &Document{font: f, used: map[uint16]rune{}}
+}
+
+// SetTitle fills the Info dictionary (shown as the window title by viewers)
+func (d *Document) SetTitle(t string) { d.title = t }
+
+func (d *Document) Font() *Font { return d.font }
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline at (x, y)
+func (d *Document) Text(x, y, size float64, s string) {
+	d.text(x, y, size, s, false)
+}
+
+// Bold draws s with a stroked outline — заменяет отдельное жирное начертание,
+// чтобы не встраивать второй шрифт
+func (d *Document) Bold(x, y, size float64, s string) {
+	d.text(x, y, size, s, true)
+}
+
+// TextRight draws s so that it ends at x
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.text(x-d.font.Width(s, size), y, size, s, false)
+}
+
+func (d *Document) text(x, y, size float64, s string, bold bool) {
+	if s == "" {
+		return
+	}
+	var hex strings.Builder
+	for _, r := range s {
+		g := d.font.glyph(r)
+		if _, ok := d.used[g]; !ok {
+			d.used[g] = r
+		}
+		fmt.Fprintf(&hex, "%04X", g)
+	}
+	p := d.page()
+	if bold {
+		fmt.Fprintf(p, "q %.2f w BT 2 Tr /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET Q\n", size/30, size, x, PageHeight-y, hex.String())
+		return
+	}
+	fmt.Fprintf(p, "BT /F1 %.2f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, PageHeight-y, hex.String())
+}
+
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Rect strokes a rectangle with the top-left corner at (x, y)
+func (d *Document) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f %.2f %.2f re S\n", width, x, PageHeight-y-h, w, h)
+}
+
+// Wrap splits s into lines no wider than maxWidth (по словам; слишком
+// длинное слово переносится посимвольно)
+func (d *Document) Wrap(s string, size, maxWidth float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			try := word
+			if line != "" {
+				try = line + " " + word
+			}
+			if d.font.Width(try, size) <= maxWidth {
+				line = try
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && d.font.Width(line+string(r), size) > maxWidth {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Объекты: 1 Catalog, 2 Pages, 3 Info, 4 Type0, 5 CIDFontType2,
+// 6 FontDescriptor, 7 FontFile2, 8 ToUnicode, далее пары Page + Contents
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	out := &writer{w: w}
+	out.printf("%%PDF-1.7\n%%\xe2\xe3\xcf\xd3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 9+2*i)
+	}
+	out.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	out.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	out.object(3, fmt.Sprintf("<< /Title %s /Producer (BuhPro) >>", textString(d.title)))
+
+	f := d.font
+	// встраивается только подмножество шрифта с глифами документа
+	glyphs := d.sortedGlyphs()
+	data, err := f.subset(glyphs)
+	if err != nil {
+		return out.n, err
+	}
+	name := subsetTag(glyphs) + "+BuhProFont"
+	out.object(4, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [5 0 R] /ToUnicode 8 0 R >>", name))
+	out.object(5, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor 6 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>", name, d.widths()))
+	out.object(6, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 7 0 R >>", name,
+		f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]), f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent)))
+	if err := out.stream(7, fmt.Sprintf("/Length1 %d", len(data)), data); err != nil {
+		return out.n, err
+	}
+	if err := out.stream(8, "", d.toUnicode()); err != nil {
+		return out.n, err
+	}
+	for i, p := range d.pages {
+		page, content := 9+2*i, 10+2*i
+		out.object(page, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 4 0 R >> >> /Contents %d 0 R >>", PageWidth, PageHeight, content))
+		if err := out.stream(content, "", p.Bytes()); err != nil {
+			return out.n, err
+		}
+	}
+
+	xref := out.n
+	total := 9 + 2*len(d.pages)
+	out.printf("xref\n0 %d\n0000000000 65535 f \n", total)
+	for i := 1; i < total; i++ {
+		out.printf("%010d 00000 n \n", out.offsets[i])
+	}
+	out.printf("trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", total, xref)
+	return out.n, out.err
+}
+
+func (d *Document) sortedGlyphs() []uint16 {
+	gs := make([]uint16, 0, len(d.used))
+	for g := range d.used {
+		gs = append(gs, g)
+	}
+	sort.Slice(gs, func(i, j int) bool { return gs[i] < gs[j] })
+	return gs
+}
+
+func (d *Document) widths() string {
+	var b strings.Builder
+	for _, g := range d.sortedGlyphs() {
+		fmt.Fprintf(&b, "%d [%d] ", g, d.font.scale(int(d.font.advance(g))))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (d *Document) toUnicode() []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	gs := d.sortedGlyphs()
+	for len(gs) > 0 {
+		chunk := gs
+		if len(chunk) > 100 { // ограничение PostScript на размер блока
+			chunk = chunk[:100]
+		}
+		gs = gs[len(chunk):]
+		fmt.Fprintf(&b, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&b, "<%04X> <", g)
+			for _, u := range utf16.Encode([]rune{d.used[g]}) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+// textString encodes s as a UTF-16BE PDF string
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// writer tracks byte offsets of objects for the xref table
+type writer struct {
+	w       io.Writer
+	n       int64
+	err     error
+	offsets map[int]int64
+}
+
+func (o *writer) printf(format string, args ...interface{}) {
+	if o.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(o.w, format, args...)
+	o.n += int64(n)
+	o.err = err
+}
+
+func (o *writer) object(id int, body string) {
+	if o.offsets == nil {
+		o.offsets = map[int]int64{}
+	}
+	o.offsets[id] = o.n
+	o.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (o *writer) stream(id int, dict string, data []byte) error {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if o.offsets == nil {
+		o.offsets = map[int]int64{}
+	}
+	o.offsets[id] = o.n
+	o.printf("%d 0 obj\n<< /Length %d /Filter /FlateDecode %s >>\nstream\n", id, z.Len(), dict)
+	if o.err == nil {
+		n, err := o.w.Write(z.Bytes())
+		o.n += int64(n)
+		o.err = err
+	}
+	o.printf("\nendstream\nendobj\n")
+	return o.err
+}
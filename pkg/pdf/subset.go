@@ -0,0 +1,204 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+)
+
+// subsetTables — таблицы, которые нужны просмотрщику для CIDFontType2
+// (cmap не нужен: CIDToGIDMap Identity); остальные не встраиваются
+var subsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// subset returns a TrueType font with outlines only for the given glyphs
+// (and the composite parts they reference). Glyph ids are kept, unused glyphs
+// become empty, so the document's Identity mapping stays valid.
+func (f *Font) subset(used []uint16) ([]byte, error) {
+	tables, err := tableDirectory(f.data)
+	if err != nil {
+		return nil, err
+	}
+	head, loca, glyf := tables["head"], tables["loca"], tables["glyf"]
+	if loca == nil || glyf == nil {
+		return nil, fmt.Errorf("%w: no glyf/loca tables", ErrBadFont)
+	}
+	numGlyphs := len(f.advances)
+	offsets := make([]int, numGlyphs+1)
+	long := int16(u16(head, 50)) == 1
+	for i := range offsets {
+		switch {
+		case long && len(loca) >= 4*(i+1):
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		case !long && len(loca) >= 2*(i+1):
+			offsets[i] = 2 * int(u16(loca, 2*i))
+		default:
+			return nil, fmt.Errorf("%w: short loca table", ErrBadFont)
+		}
+	}
+	outline := func(g int) []byte {
+		start, end := offsets[g], offsets[g+1]
+		if start >= end || end > len(glyf) {
+			return nil
+		}
+		return glyf[start:end]
+	}
+
+	keep := map[int]bool{0: true} // .notdef обязателен
+	queue := []int{0}
+	for _, g := range used {
+		if int(g) < numGlyphs && !keep[int(g)] {
+			keep[int(g)] = true
+			queue = append(queue, int(g))
+		}
+	}
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		for _, c := range components(outline(g)) {
+			if c < numGlyphs && !keep[c] {
+				keep[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	// glyf и loca пересобираются в длинном формате
+	var newGlyf []byte
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for g := 0; g < numGlyphs; g++ {
+		binary.BigEndian.PutUint32(newLoca[4*g:], uint32(len(newGlyf)))
+		if keep[g] {
+			newGlyf = append(newGlyf, outline(g)...)
+			for len(newGlyf)%4 != 0 {
+				newGlyf = append(newGlyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(len(newGlyf)))
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(newHead[8:], 0) // checkSumAdjustment — ниже
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+
+	out := map[string][]byte{"head": newHead, "loca": newLoca, "glyf": newGlyf}
+	for _, tag := range subsetTables {
+		if _, ok := out[tag]; !ok && tables[tag] != nil {
+			out[tag] = tables[tag]
+		}
+	}
+	data := writeFont(out)
+	// смещение head в новом файле: таблицы идут в порядке тегов
+	binary.BigEndian.PutUint32(data[headOffset(data):][8:], 0xB1B0AFBA-checksum(data))
+	return data, nil
+}
+
+// subsetTag — префикс имени подмножества шрифта (6 заглавных букв), зависит от набора глифов
+func subsetTag(used []uint16) string {
+	b := make([]byte, 2*len(used))
+	for i, g := range used {
+		binary.BigEndian.PutUint16(b[2*i:], g)
+	}
+	h := crc32.ChecksumIEEE(b)
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(h%26)
+		h /= 26
+	}
+	return string(tag)
+}
+
+// components — glyph ids, на которые ссылается составной глиф
+func components(g []byte) []int {
+	if len(g) < 10 || int16(u16(g, 0)) >= 0 {
+		return nil
+	}
+	const (
+		argWords   = 0x0001
+		hasScale   = 0x0008
+		more       = 0x0020
+		hasXYScale = 0x0040
+		has2x2     = 0x0080
+	)
+	var out []int
+	for at := 10; at+4 <= len(g); {
+		flags := u16(g, at)
+		out = append(out, int(u16(g, at+2)))
+		at += 4
+		if flags&argWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&hasScale != 0:
+			at += 2
+		case flags&hasXYScale != 0:
+			at += 4
+		case flags&has2x2 != 0:
+			at += 8
+		}
+		if flags&more == 0 {
+			break
+		}
+	}
+	return out
+}
+
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	n := len(tags)
+	entry := 1
+	for entry*2 <= n {
+		entry *= 2
+	}
+	searchRange := entry * 16
+	selector := 0
+	for 1<<(selector+1) <= entry {
+		selector++
+	}
+	data := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(data, 0x00010000)
+	binary.BigEndian.PutUint16(data[4:], uint16(n))
+	binary.BigEndian.PutUint16(data[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(data[8:], uint16(selector))
+	binary.BigEndian.PutUint16(data[10:], uint16(n*16-searchRange))
+	for i, tag := range tags {
+		t := tables[tag]
+		rec := data[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], checksum(t))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(t)))
+		data = append(data, t...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	return data
+}
+
+func headOffset(data []byte) int {
+	n := int(u16(data, 4))
+	for i := 0; i < n; i++ {
+		rec := data[12+16*i:]
+		if string(rec[:4]) == "head" {
+			return int(binary.BigEndian.Uint32(rec[8:]))
+		}
+	}
+	return 0
+}
+
+// checksum — сумма 32-битных слов (хвост дополняется нулями)
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var w [4]byte
+		copy(w[:], b[i:])
+		sum += binary.BigEndian.Uint32(w[:])
+	}
+	return sum
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import "bytes"
+
+// HeadSize — сколько первых байт нужно MatchesMIME
+const HeadSize = 512
+
+var (
+	magicPDF  = []byte("%PDF-")
+	magicPNG  = []byte("\x89PNG\r\n\x1a\n")
+	magicJPEG = []byte{0xFF, 0xD8, 0xFF}
+	magicZIP  = []byte("PK\x03\x04")
+	magicOLE  = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1} // doc/xls
+	utf8BOM   = []byte{0xEF, 0xBB, 0xBF}
+)
+
+// MatchesMIME checks the file signature (first bytes) against the declared type.
+// Unknown types are rejected.
+func MatchesMIME(head []byte, mimeType string) bool {
+	switch mimeType {
+	case "application/pdf":
+		return bytes.HasPrefix(head, magicPDF)
+	case "image/png":
+		return bytes.HasPrefix(head, magicPNG)
+	case "image/jpeg":
+		return bytes.HasPrefix(head, magicJPEG)
+	case "image/webp":
+		return len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WEBP"
+	case "application/zip",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return bytes.HasPrefix(head, magicZIP)
+	case "application/msword", "application/vnd.ms-excel":
+		return bytes.HasPrefix(head, magicOLE)
+	case "application/xml", "text/xml":
+		t := bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+		return isText(head) && bytes.HasPrefix(t, []byte("<"))
+	case "text/plain", "text/csv":
+		return isText(head)
+	}
+	return false
+}
+
+// isText: без NUL-байтов (кодировка не проверяется — выписки банков часто в cp1251)
+func isText(head []byte) bool {
+	return bytes.IndexByte(head, 0) < 0
+}
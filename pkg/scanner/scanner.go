@@ -0,0 +1,121 @@
+// Package scanner — антивирусная проверка загруженных файлов.
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Result — вердикт сканера; Signature заполнена для заражённых файлов
+type Result struct {
+	Clean     bool
+	Signature string
+}
+
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+const clamdChunkSize = 64 << 10
+
+// ClamdScanner — клиент clamd (ClamAV) по TCP, команда INSTREAM
+type ClamdScanner struct {
+	addr    string
+	timeout time.Duration
+}
+
+func NewClamdScanner(addr string, timeout time.Duration) *ClamdScanner {
+	return &ClamdScanner{addr: addr, timeout: timeout}
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	d := net.Dialer{Timeout: s.timeout}
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, clamdChunkSize)
+	var size [4]byte
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			if _, err := w.Write(size[:]); err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return nil, err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+	}
+	binary.BigEndian.PutUint32(size[:], 0)
+	if _, err := w.Write(size[:]); err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply: "stream: OK" | "stream: <sig> FOUND" | "<msg> ERROR"
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
+
+// EICAR — стандартная тестовая строка антивирусов
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// StubScanner — для разработки и тестов: «заражён» только файл с EICAR
+type StubScanner struct{}
+
+func NewStubScanner() *StubScanner { return &StubScanner{} }
+
+func (StubScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Contains(b, []byte(EICAR)) {
+		return &Result{Signature: "Eicar-Test-Signature"}, nil
+	}
+	return &Result{Clean: true}, nil
+}
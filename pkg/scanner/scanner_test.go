@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStubScannerClean(t *testing.T) {
+	res, err := NewStubScanner().Scan(context.Background(), strings.NewReader("%PDF-1.7 обычный документ"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Clean || res.Signature != "" {
+		t.Fatalf("want clean, got %+v", res)
+	}
+}
+
+func TestStubScannerEmpty(t *testing.T) {
+	res, err := NewStubScanner().Scan(context.Background(), bytes.NewReader(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Clean {
+		t.Fatalf("want clean, got %+v", res)
+	}
+}
+
+func TestStubScannerEICAR(t *testing.T) {
+	// сигнатура не в начале и за границей первого чтения
+	body := io.MultiReader(
+		bytes.NewReader(bytes.Repeat([]byte{'a'}, 100<<10)),
+		strings.NewReader(EICAR),
+		strings.NewReader("trailer"),
+	)
+	res, err := NewStubScanner().Scan(context.Background(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Clean || res.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("want EICAR detected, got %+v", res)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("storage gone") }
+
+func TestStubScannerReadError(t *testing.T) {
+	if _, err := NewStubScanner().Scan(context.Background(), failingReader{}); err == nil {
+		t.Fatal("want read error, got nil")
+	}
+}
+
+func TestParseClamdReply(t *testing.T) {
+	res, err := parseClamdReply("stream: OK")
+	if err != nil || !res.Clean {
+		t.Fatalf("OK: %+v, %v", res, err)
+	}
+	res, err = parseClamdReply("stream: Eicar-Signature FOUND")
+	if err != nil || res.Clean || res.Signature != "Eicar-Signature" {
+		t.Fatalf("FOUND: %+v, %v", res, err)
+	}
+	if _, err := parseClamdReply("INSTREAM size limit exceeded. ERROR"); err == nil {
+		t.Fatal("ERROR reply must fail (file stays pending_scan)")
+	}
+}
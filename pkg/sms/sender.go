@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// SMSSender отправляет SMS; реальные провайдеры подключаются через этот интерфейс
+type SMSSender interface {
+	Send(ctx context.Context, phone, text string) error
+}
+
+// LogSender — локальная заглушка: пишет сообщения в лог или в файл,
+// чтобы OTP-флоу можно было проверять без провайдера.
+type LogSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogSender: path == "" — писать в стандартный лог
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+func (s *LogSender) Send(ctx context.Context, phone, text string) error {
+	line := fmt.Sprintf("%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phone, text)
+	if s.path == "" {
+		log.Printf("SMS to %s: %s", phone, text)
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBadSignature = errors.New("invalid or expired signature")
+	ErrTooLarge     = errors.New("object too large")
+)
+
+// LocalStore — хранение на диске для разработки. Presigned URL ведут на
+// собственный эндпоинт API (baseURL/<key>), подпись — HMAC(secret).
+type LocalStore struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalStore(dir, baseURL, secret string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/"), secret: []byte(secret)}, nil
+}
+
+func (s *LocalStore) PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (*UploadURL, error) {
+	exp := time.Now().Add(ttl)
+	return &UploadURL{
+		URL:       s.signedURL(http.MethodPut, key, "", exp),
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": contentType},
+		ExpiresAt: exp,
+	}, nil
+}
+
+func (s *LocalStore) PresignGet(ctx context.Context, key, downloadName string, ttl time.Duration) (string, error) {
+	return s.signedURL(http.MethodGet, key, downloadName, time.Now().Add(ttl)), nil
+}
+
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	f, err := s.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Size: st.Size()}, nil
+}
+
+func (s *LocalStore) Copy(ctx context.Context, src, dst string) (*ObjectInfo, error) {
+	f, err := s.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if err := s.Save(dst, io.TeeReader(f, h), st.Size()); err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Size: st.Size(), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func (s *LocalStore) Read(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.Open(key)
+}
+
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, body []byte) error {
+	return s.Save(key, bytes.NewReader(body), int64(len(body)))
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Verify checks a signed URL produced by PresignPut/PresignGet
+func (s *LocalStore) Verify(method, key string, q url.Values) error {
+	exp, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrBadSignature
+	}
+	want := s.sign(method, key, q.Get("name"), exp)
+	if !hmac.Equal([]byte(want), []byte(q.Get("sig"))) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Save writes the object body, rejecting anything above maxSize bytes
+func (s *LocalStore) Save(key string, r io.Reader, maxSize int64) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+	tmp := p + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, maxSize+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxSize {
+		err = ErrTooLarge
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *LocalStore) Open(key string) (*os.File, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+func (s *LocalStore) signedURL(method, key, name string, exp time.Time) string {
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(exp.Unix(), 10))
+	if name != "" {
+		q.Set("name", name)
+	}
+	q.Set("sig", s.sign(method, key, name, exp.Unix()))
+	return s.baseURL + "/" + strings.TrimPrefix(key, "/") + "?" + q.Encode()
+}
+
+func (s *LocalStore) sign(method, key, name string, exp int64) string {
+	m := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(m, "%s\n%s\n%s\n%d", method, strings.TrimPrefix(key, "/"), name, exp)
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config — S3-совместимое хранилище (MinIO), path-style адресация
+type S3Config struct {
+	Endpoint  string // e.g. http://localhost:9000
+	Region    string
+	Bucket    string // "" — бакет уже в Endpoint (virtual-hosted style)
+	AccessKey string
+	SecretKey string
+}
+
+// S3Store подписывает запросы AWS Signature V4 (query-string presign)
+type S3Store struct {
+	cfg    S3Config
+	base   *url.URL
+	client *http.Client
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	u, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("storage: invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3Store{cfg: cfg, base: u, client: &http.Client{Timeout: 5 * time.Minute}}, nil
+}
+
+func (s *S3Store) PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (*UploadURL, error) {
+	headers := map[string]string{"Content-Type": contentType}
+	u := s.presign(http.MethodPut, key, nil, headers, ttl, time.Now())
+	return &UploadURL{URL: u, Method: http.MethodPut, Headers: headers, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (s *S3Store) PresignGet(ctx context.Context, key, downloadName string, ttl time.Duration) (string, error) {
+	q := url.Values{}
+	if downloadName != "" {
+		q.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": downloadName}))
+	}
+	return s.presign(http.MethodGet, key, q, nil, ttl, time.Now()), nil
+}
+
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storage: HEAD %s: %s", key, resp.Status)
+	}
+	return &ObjectInfo{Size: resp.ContentLength}, nil
+}
+
+func (s *S3Store) Copy(ctx context.Context, src, dst string) (*ObjectInfo, error) {
+	rc, err := s.do(ctx, http.MethodGet, src)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Body.Close()
+	switch rc.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("storage: GET %s: %s", src, rc.Status)
+	}
+	contentType := rc.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	headers := map[string]string{"Content-Type": contentType}
+	h := sha256.New()
+	body := io.TeeReader(rc.Body, h)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.presign(http.MethodPut, dst, nil, headers, time.Minute, time.Now()), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = rc.ContentLength
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storage: PUT %s: %s", dst, resp.Status)
+	}
+	return &ObjectInfo{Size: rc.ContentLength, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func (s *S3Store) Read(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("storage: GET %s: %s", key, resp.Status)
+	}
+}
+
+func (s *S3Store) Put(ctx context.Context, key, contentType string, body []byte) error {
+	headers := map[string]string{"Content-Type": contentType}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.presign(http.MethodPut, key, nil, headers, time.Minute, time.Now()), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("storage: PUT %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("storage: DELETE %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.presign(method, key, nil, nil, time.Minute, time.Now()), nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.Do(req)
+}
+
+// presign builds a SigV4 query-signed URL; headers (besides host) are signed
+// and must be sent by the client unchanged.
+func (s *S3Store) presign(method, key string, query url.Values, headers map[string]string, ttl time.Duration, now time.Time) string {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+
+	path := s.base.Path + "/" + strings.TrimPrefix(key, "/")
+	if s.cfg.Bucket != "" {
+		path = s.base.Path + "/" + s.cfg.Bucket + "/" + strings.TrimPrefix(key, "/")
+	}
+
+	signed := map[string]string{"host": s.base.Host}
+	for k, v := range headers {
+		signed[strings.ToLower(k)] = strings.TrimSpace(v)
+	}
+	names := make([]string, 0, len(signed))
+	for k := range signed {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonHeaders strings.Builder
+	for _, k := range names {
+		canonHeaders.WriteString(k + ":" + signed[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", amzDate)
+	q.Set("X-Amz-Expires", fmt.Sprint(int(ttl.Seconds())))
+	q.Set("X-Amz-SignedHeaders", signedHeaders)
+	canonQuery := canonicalQuery(q)
+
+	canonRequest := strings.Join([]string{
+		method, uriEncode(path, false), canonQuery, canonHeaders.String(), signedHeaders, "UNSIGNED-PAYLOAD",
+	}, "\n")
+	h := sha256.Sum256([]byte(canonRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(h[:])
+
+	k := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), day)
+	k = hmacSHA256(k, s.cfg.Region)
+	k = hmacSHA256(k, "s3")
+	k = hmacSHA256(k, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(k, stringToSign))
+
+	return s.base.Scheme + "://" + s.base.Host + uriEncode(path, false) + "?" + canonQuery + "&X-Amz-Signature=" + sig
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode — RFC 3986 encoding as required by SigV4 ('/' kept in paths)
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
@@ -0,0 +1,42 @@
+// Package storage — хранилище файлов (S3/MinIO или локальная ФС).
+// Клиенты загружают и скачивают содержимое напрямую по presigned URL,
+// API только выдаёт ссылки и проверяет результат.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo — результат проверки загруженного объекта
+type ObjectInfo struct {
+	Size   int64
+	SHA256 string // hex, посчитан сервером по содержимому (только Copy)
+}
+
+// UploadURL — ссылка для загрузки; Headers клиент обязан отправить как есть
+type UploadURL struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type BlobStore interface {
+	PresignPut(ctx context.Context, key, contentType string, ttl time.Duration) (*UploadURL, error)
+	PresignGet(ctx context.Context, key, downloadName string, ttl time.Duration) (string, error)
+	// Stat returns the object size (SHA256 is not filled)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Copy streams src into dst through the API and hashes exactly the bytes
+	// written: dst never has a presigned PUT, so its content cannot be swapped
+	Copy(ctx context.Context, src, dst string) (*ObjectInfo, error)
+	// Read streams the object content (server-side: scanning, validation)
+	Read(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put stores content generated by the server itself (документы, отчёты)
+	Put(ctx context.Context, key, contentType string, body []byte) error
+	Delete(ctx context.Context, key string) error
+}
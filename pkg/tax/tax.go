@@ -0,0 +1,71 @@
+// Package tax — расчёт НДС по ставкам с датой вступления в силу.
+// Суммы — целые тенге, округление до тенге по правилам арифметики.
+package tax
+
+import (
+	"sort"
+	"time"
+)
+
+// VAT — код ставки НДС в расписании
+const VAT = "vat"
+
+// Rate — ставка, действующая с From (включительно) до следующей ставки того же кода
+type Rate struct {
+	Code    string
+	Percent int
+	From    time.Time
+}
+
+// Schedule — ставки всех кодов; порядок не важен
+type Schedule []Rate
+
+// At returns the percent of code in force on day t; false — ставка ещё не действовала
+func (s Schedule) At(code string, t time.Time) (int, bool) {
+	rates := make([]Rate, 0, len(s))
+	for _, r := range s {
+		if r.Code == code {
+			rates = append(rates, r)
+		}
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].From.Before(rates[j].From) })
+	percent, ok := 0, false
+	for _, r := range rates {
+		if r.From.After(t) {
+			break
+		}
+		percent, ok = r.Percent, true
+	}
+	return percent, ok
+}
+
+// Breakdown — сумма без НДС, НДС и сумма с НДС
+type Breakdown struct {
+	Net   int64
+	VAT   int64
+	Gross int64
+	Rate  int
+}
+
+// Included splits a gross amount that already contains VAT at rate %
+func Included(gross int64, rate int) Breakdown {
+	vat := share(gross, int64(rate), 100+int64(rate))
+	return Breakdown{Net: gross - vat, VAT: vat, Gross: gross, Rate: rate}
+}
+
+// Added charges VAT at rate % on top of a net amount
+func Added(net int64, rate int) Breakdown {
+	vat := share(net, int64(rate), 100)
+	return Breakdown{Net: net, VAT: vat, Gross: net + vat, Rate: rate}
+}
+
+// share — amount * num / den с округлением половины от нуля
+func share(amount, num, den int64) int64 {
+	if num <= 0 || den <= 0 {
+		return 0
+	}
+	if amount < 0 {
+		return -share(-amount, num, den)
+	}
+	return (amount*num*2 + den) / (2 * den)
+}
@@ -0,0 +1,112 @@
+package tax
+
+import (
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestIncludedRounding(t *testing.T) {
+	cases := []struct {
+		gross    int64
+		rate     int
+		net, vat int64
+	}{
+		{1120, 12, 1000, 120},
+		{1130, 12, 1009, 121}, // 121.07 -> 121
+		{14, 12, 12, 2},       // 1.5 -> 2: половина — от нуля
+		{13, 12, 12, 1},       // 1.39 -> 1
+		{-14, 12, -12, -2},    // возврат округляется так же
+		{1000, 0, 1000, 0},
+		{0, 12, 0, 0},
+	}
+	for _, c := range cases {
+		b := Included(c.gross, c.rate)
+		if b.Net != c.net || b.VAT != c.vat || b.Gross != c.gross || b.Rate != c.rate {
+			t.Fatalf("Included(%d, %d) = %+v, want net %d vat %d", c.gross, c.rate, b, c.net, c.vat)
+		}
+	}
+}
+
+func TestAddedRounding(t *testing.T) {
+	cases := []struct {
+		net        int64
+		rate       int
+		vat, gross int64
+	}{
+		{1000, 12, 120, 1120},
+		{25, 12, 3, 28},    // 3.0
+		{125, 12, 15, 140}, // 15.0
+		{4, 12, 0, 4},      // 0.48 -> 0
+		{21, 12, 3, 24},    // 2.52 -> 3
+		{-21, 12, -3, -24},
+		{1000, 0, 0, 1000},
+	}
+	for _, c := range cases {
+		b := Added(c.net, c.rate)
+		if b.Net != c.net || b.VAT != c.vat || b.Gross != c.gross {
+			t.Fatalf("Added(%d, %d) = %+v, want vat %d gross %d", c.net, c.rate, b, c.vat, c.gross)
+		}
+	}
+}
+
+func TestShareHalfAwayFromZero(t *testing.T) {
+	for _, c := range []struct{ amount, want int64 }{{5, 3}, {-5, -3}, {4, 2}, {3, 2}, {1, 1}, {-1, -1}} {
+		if got := share(c.amount, 1, 2); got != c.want {
+			t.Fatalf("share(%d, 1, 2) = %d, want %d", c.amount, got, c.want)
+		}
+	}
+}
+
+func TestScheduleAt(t *testing.T) {
+	// порядок не важен, ставки других кодов не учитываются
+	s := Schedule{
+		{Code: VAT, Percent: 16, From: day("2026-01-01")},
+		{Code: "other", Percent: 50, From: day("2020-01-01")},
+		{Code: VAT, Percent: 12, From: day("2009-01-01")},
+		{Code: VAT, Percent: 20, From: day("2027-07-01")},
+	}
+	cases := []struct {
+		on   string
+		want int
+		ok   bool
+	}{
+		{"2008-12-31", 0, false}, // до первой ставки
+		{"2009-01-01", 12, true}, // день вступления — включительно
+		{"2025-12-31", 12, true},
+		{"2026-01-01", 16, true},
+		{"2027-06-30", 16, true},
+		{"2027-07-01", 20, true},
+		{"2030-01-01", 20, true},
+	}
+	for _, c := range cases {
+		got, ok := s.At(VAT, day(c.on))
+		if got != c.want || ok != c.ok {
+			t.Fatalf("At(%s) = %d, %v; want %d, %v", c.on, got, ok, c.want, c.ok)
+		}
+	}
+	if _, ok := s.At("excise", day("2030-01-01")); ok {
+		t.Fatal("unknown code must have no rate")
+	}
+	if _, ok := (Schedule{}).At(VAT, day("2030-01-01")); ok {
+		t.Fatal("empty schedule must have no rate")
+	}
+}
+
+func TestScheduleAtTimeOfDay(t *testing.T) {
+	s := Schedule{{Code: VAT, Percent: 12, From: day("2009-01-01")}, {Code: VAT, Percent: 16, From: day("2026-01-01")}}
+	// операция в течение дня вступления ставки уже по новой ставке
+	if got, _ := s.At(VAT, day("2026-01-01").Add(15*time.Hour)); got != 16 {
+		t.Fatalf("got %d, want 16", got)
+	}
+	if got, _ := s.At(VAT, day("2026-01-01").Add(-time.Second)); got != 12 {
+		t.Fatalf("got %d, want 12", got)
+	}
+}